@@ -4,12 +4,15 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/korotovsky/slack-mcp-server/pkg/handler"
+	"github.com/korotovsky/slack-mcp-server/pkg/metrics"
 	"github.com/korotovsky/slack-mcp-server/pkg/provider"
 	"github.com/korotovsky/slack-mcp-server/pkg/server"
 	"github.com/mattn/go-isatty"
@@ -66,9 +69,21 @@ func main() {
 		)
 	}
 
+	err = handler.ValidateAllowedChannelTypes(os.Getenv("SLACK_MCP_ALLOWED_CHANNEL_TYPES"))
+	if err != nil {
+		logger.Fatal("error in SLACK_MCP_ALLOWED_CHANNEL_TYPES",
+			zap.String("context", "console"),
+			zap.Error(err),
+		)
+	}
+
 	p := provider.New(transport, logger)
 	s := server.NewMCPServer(p, logger, enabledTools)
 
+	if addr := os.Getenv("SLACK_MCP_METRICS_ADDR"); addr != "" {
+		go serveMetrics(addr, logger)
+	}
+
 	go func() {
 		var once sync.Once
 
@@ -112,6 +127,8 @@ func main() {
 			logger.Info("Slack MCP Server is still warming up caches",
 				zap.String("context", "console"),
 			)
+			usersReady, channelsReady := p.SyncState()
+			handler.LogSyncNotReady(logger, usersReady, channelsReady)
 		}
 
 		if err := sseServer.Start(host + ":" + port); err != nil {
@@ -142,6 +159,8 @@ func main() {
 			logger.Info("Slack MCP Server is still warming up caches",
 				zap.String("context", "console"),
 			)
+			usersReady, channelsReady := p.SyncState()
+			handler.LogSyncNotReady(logger, usersReady, channelsReady)
 		}
 
 		if err := httpServer.Start(host + ":" + port); err != nil {
@@ -223,6 +242,23 @@ func newChannelsWatcher(p *provider.ApiProvider, once *sync.Once, logger *zap.Lo
 	}
 }
 
+func serveMetrics(addr string, logger *zap.Logger) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Default.Handler())
+
+	logger.Info("Metrics endpoint listening",
+		zap.String("context", "console"),
+		zap.String("addr", addr+"/metrics"),
+	)
+
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		logger.Error("Metrics server error",
+			zap.String("context", "console"),
+			zap.Error(err),
+		)
+	}
+}
+
 func validateToolConfig(config string) error {
 	if config == "" || config == "true" || config == "1" {
 		return nil
@@ -3,16 +3,27 @@ package handler
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"os"
+	"regexp"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gocarina/gocsv"
+	"github.com/korotovsky/slack-mcp-server/pkg/limiter"
 	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/korotovsky/slack-mcp-server/pkg/provider/edge"
+	"github.com/korotovsky/slack-mcp-server/pkg/provider/edge/fasttime"
 	"github.com/korotovsky/slack-mcp-server/pkg/server/auth"
 	"github.com/korotovsky/slack-mcp-server/pkg/text"
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/slack-go/slack"
 	"go.uber.org/zap"
+	"golang.org/x/time/rate"
 )
 
 type Channel struct {
@@ -114,14 +125,1238 @@ func (ch *ChannelsHandler) ChannelsHandler(ctx context.Context, request mcp.Call
 	types := request.GetString("channel_types", provider.PubChanType)
 	cursor := request.GetString("cursor", "")
 	limit := request.GetInt("limit", 0)
+	refreshCounts := request.GetBool("refresh_member_counts", false)
+	activeSince := strings.TrimSpace(request.GetString("active_since", ""))
 
 	ch.logger.Debug("Request parameters",
 		zap.String("sort", sortType),
 		zap.String("channel_types", types),
 		zap.String("cursor", cursor),
 		zap.Int("limit", limit),
+		zap.Bool("refresh_member_counts", refreshCounts),
+		zap.String("active_since", activeSince),
 	)
 
+	channelTypes := ch.parseChannelTypes(types)
+
+	if limit == 0 {
+		limit = 100
+		ch.logger.Debug("Limit not provided, using default", zap.Int("limit", limit))
+	}
+	if limit > 999 {
+		ch.logger.Warn("Limit exceeds maximum, capping to 999", zap.Int("requested", limit))
+		limit = 999
+	}
+
+	var (
+		nextcur     string
+		channelList []Channel
+	)
+
+	allChannels := ch.apiProvider.ProvideChannelsMaps().Channels
+	ch.logger.Debug("Total channels available", zap.Int("count", len(allChannels)))
+
+	channels := filterChannelsByPolicy(filterChannelsByTypes(allChannels, channelTypes), allowedChannelTypes())
+	ch.logger.Debug("Channels after filtering by type", zap.Int("count", len(channels)))
+
+	var (
+		activityNote string
+		sortNote     string
+		latest       map[string]time.Time
+	)
+	if sortType == "last_activity" && ch.apiProvider.IsOAuth() {
+		sortType = "popularity"
+		sortNote = "sort=last_activity requires browser session tokens (xoxc/xoxd), which can read the latest activity of every channel in one call; channels are sorted by popularity instead"
+	}
+	if activeSince != "" || sortType == "last_activity" {
+		if !ch.apiProvider.IsOAuth() {
+			counts, err := fetchClientCounts(ctx, ch.apiProvider.Slack().ClientCounts)
+			if err != nil {
+				ch.logger.Error("ClientCounts failed", zap.Error(err))
+				return nil, fmt.Errorf("failed to get client counts: %w", err)
+			}
+			latest = latestActivity(counts)
+		}
+	}
+	if activeSince != "" {
+		_, oldest, _, err := limitByExpression(activeSince, "")
+		if err != nil {
+			return nil, fmt.Errorf("invalid active_since: %w", err)
+		}
+		if ch.apiProvider.IsOAuth() {
+			activityNote = "active_since requires browser session tokens (xoxc/xoxd), which can read the latest activity of every channel in one call; the list is not filtered by activity"
+		} else {
+			oldestTs, _ := fasttime.TS2int(oldest)
+			var unknown int
+			channels, unknown = filterChannelsActiveSince(channels, latest, fasttime.Int2Time(oldestTs))
+			ch.logger.Debug("Channels after filtering by activity", zap.Int("count", len(channels)), zap.Int("unknown", unknown))
+			if unknown > 0 {
+				activityNote = fmt.Sprintf("%d channel(s) you are not a member of have no activity data and were left out", unknown)
+			}
+		}
+	}
+
+	var chans []provider.Channel
+
+	if sortType == "last_activity" {
+		// Sorted before paging, so every page continues the activity order
+		chans, nextcur = paginateChannelsByActivity(channels, latest, cursor, limit)
+	} else {
+		chans, nextcur = paginateChannels(
+			channels,
+			cursor,
+			limit,
+		)
+	}
+
+	ch.logger.Debug("Pagination results",
+		zap.Int("returned_count", len(chans)),
+		zap.Bool("has_next_page", nextcur != ""),
+	)
+
+	for _, channel := range chans {
+		channelList = append(channelList, Channel{
+			ID:          channel.ID,
+			Name:        channel.Name,
+			Topic:       channel.Topic,
+			Purpose:     channel.Purpose,
+			MemberCount: channel.MemberCount,
+		})
+	}
+
+	var refreshNote string
+	if refreshCounts {
+		skipped := refreshMemberCounts(ctx, channelList, limiter.Tier3.Limiter(), func(ctx context.Context, id string) (*slack.Channel, error) {
+			return ch.apiProvider.Slack().GetConversationInfoContext(ctx, &slack.GetConversationInfoInput{
+				ChannelID:         id,
+				IncludeNumMembers: true,
+			})
+		}, ch.logger)
+		if skipped > 0 {
+			refreshNote = fmt.Sprintf("member counts were refreshed for the first %d channels only; the other %d keep cached counts, which can be stale. Use a smaller limit to refresh every returned channel", maxMemberCountRefresh, skipped)
+		}
+	}
+
+	switch sortType {
+	case "popularity":
+		ch.logger.Debug("Sorting channels by popularity (member count)")
+		sort.Slice(channelList, func(i, j int) bool {
+			return channelList[i].MemberCount > channelList[j].MemberCount
+		})
+	case "last_activity":
+		ch.logger.Debug("Channels sorted by last activity while paging")
+	default:
+		ch.logger.Debug("No sorting applied", zap.String("sort_type", sortType))
+	}
+
+	if len(channelList) > 0 && nextcur != "" {
+		channelList[len(channelList)-1].Cursor = nextcur
+		ch.logger.Debug("Added cursor to last channel", zap.String("cursor", nextcur))
+	}
+
+	csvBytes, err := gocsv.MarshalBytes(&channelList)
+	if err != nil {
+		ch.logger.Error("Failed to marshal channels to CSV", zap.Error(err))
+		return nil, err
+	}
+
+	result := withEmptyResultNote(mcp.NewToolResultText(string(csvBytes)), len(channelList), "No channels matched the given channel types and filters")
+	for _, note := range []string{activityNote, sortNote, refreshNote} {
+		if note != "" {
+			result.Content = append(result.Content, mcp.NewTextContent(note))
+		}
+	}
+	return result, nil
+}
+
+// latestActivity maps conversation IDs to the time of their latest message, as
+// reported by client.counts
+func latestActivity(counts edge.ClientCountsResponse) map[string]time.Time {
+	latest := make(map[string]time.Time, len(counts.Channels)+len(counts.MPIMs)+len(counts.IMs))
+	for _, snaps := range [][]edge.ChannelSnapshot{counts.Channels, counts.MPIMs, counts.IMs} {
+		for _, snap := range snaps {
+			latest[snap.ID] = time.Time(snap.Latest)
+		}
+	}
+	return latest
+}
+
+// filterChannelsActiveSince keeps channels whose latest message is not older
+// than since. Channels without activity data, i.e. those the user is not a
+// member of, are dropped and counted separately from stale ones.
+func filterChannelsActiveSince(channels []provider.Channel, latest map[string]time.Time, since time.Time) ([]provider.Channel, int) {
+	kept := make([]provider.Channel, 0, len(channels))
+	unknown := 0
+	for _, c := range channels {
+		t, ok := latest[c.ID]
+		if !ok {
+			unknown++
+			continue
+		}
+		if !t.IsZero() && !t.Before(since) {
+			kept = append(kept, c)
+		}
+	}
+	return kept, unknown
+}
+
+// ChannelsByPrefixHandler lists channels whose name starts with a prefix, e.g.
+// all "inc-" channels, in alphabetical order
+func (ch *ChannelsHandler) ChannelsByPrefixHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("ChannelsByPrefixHandler called", zap.Any("params", request.Params))
+
+	if ready, err := ch.apiProvider.IsReady(); !ready {
+		ch.logger.Error("API provider not ready", zap.Error(err))
+		return nil, err
+	}
+
+	prefix := strings.TrimPrefix(strings.TrimSpace(request.GetString("prefix", "")), "#")
+	if prefix == "" {
+		return nil, errors.New("prefix is required")
+	}
+	channelTypes := ch.parseChannelTypes(request.GetString("channel_types", ""))
+	cursor := request.GetString("cursor", "")
+	limit := request.GetInt("limit", 100)
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 999 {
+		ch.logger.Warn("Limit exceeds maximum, capping to 999", zap.Int("requested", limit))
+		limit = 999
+	}
+
+	allChannels := ch.apiProvider.ProvideChannelsMaps().Channels
+	channels := filterChannelsByPolicy(filterChannelsByTypes(allChannels, channelTypes), allowedChannelTypes())
+	chans, nextcur := paginateChannelsByName(filterChannelsByPrefix(channels, prefix), cursor, limit)
+
+	var channelList []Channel
+	for _, channel := range chans {
+		channelList = append(channelList, Channel{
+			ID:          channel.ID,
+			Name:        channel.Name,
+			Topic:       channel.Topic,
+			Purpose:     channel.Purpose,
+			MemberCount: channel.MemberCount,
+		})
+	}
+	if len(channelList) > 0 && nextcur != "" {
+		channelList[len(channelList)-1].Cursor = nextcur
+	}
+
+	csvBytes, err := gocsv.MarshalBytes(&channelList)
+	if err != nil {
+		ch.logger.Error("Failed to marshal channels to CSV", zap.Error(err))
+		return nil, err
+	}
+
+	return withEmptyResultNote(mcp.NewToolResultText(string(csvBytes)), len(channelList), fmt.Sprintf("No channels start with %q", prefix)), nil
+}
+
+// ChannelResource is a result row of channels_resources
+type ChannelResource struct {
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	Type      string `json:"type"`
+	Permalink string `json:"permalink"`
+	Updated   string `json:"updated"`
+	Pinned    bool   `json:"pinned"`
+	Cursor    string `json:"cursor"`
+}
+
+// channelDocumentTypes are the file types listed by channels_resources even
+// when not pinned: canvases and posts
+var channelDocumentTypes = map[string]bool{
+	"canvas": true,
+	"quip":   true,
+	"post":   true,
+	"space":  true,
+}
+
+// ChannelsResourcesHandler lists the documents of a channel, its canvases and
+// posts plus any pinned files, as a reference shelf
+func (ch *ChannelsHandler) ChannelsResourcesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("ChannelsResourcesHandler called", zap.Any("params", request.Params))
+
+	if ready, err := ch.apiProvider.IsReady(); !ready {
+		ch.logger.Error("API provider not ready", zap.Error(err))
+		return nil, err
+	}
+
+	channel := strings.TrimSpace(request.GetString("channel_id", ""))
+	if channel == "" {
+		return nil, errors.New("channel_id is required")
+	}
+	channel, err := ch.resolveAllowedChannel(ctx, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := request.GetInt("limit", 100)
+	if limit < 1 || limit > 100 {
+		return nil, errors.New("limit must be between 1 and 100")
+	}
+	page, err := parsePageCursor(request.GetString("cursor", ""))
+	if err != nil {
+		ch.logger.Error("Invalid cursor", zap.Error(err))
+		return nil, err
+	}
+
+	api := ch.apiProvider.Slack()
+
+	// Pins are not paginated, so they are listed on the first page only but
+	// fetched on every page to leave pinned documents out of later pages.
+	pins, err := limiter.CallWithRetry(ctx, limiter.Tier2.Limiter(), 2, provider.SlackRetryAfter, func() (pinsPage, error) {
+		items, paging, err := api.ListPinsContext(ctx, channel)
+		return pinsPage{items: items, paging: paging}, err
+	})
+	if err != nil {
+		ch.logger.Error("Slack ListPinsContext failed", zap.String("channel", channel), zap.Error(err))
+		return nil, fmt.Errorf("failed to list pinned items of %s: %w", channel, err)
+	}
+
+	filesParams := slack.NewGetFilesParameters()
+	filesParams.Channel = channel
+	filesParams.Count = limit
+	filesParams.Page = page
+	files, err := limiter.CallWithRetry(ctx, limiter.Tier3.Limiter(), 2, provider.SlackRetryAfter, func() (filesPage, error) {
+		files, paging, err := api.GetFilesContext(ctx, filesParams)
+		return filesPage{files: files, paging: paging}, err
+	})
+	if err != nil {
+		ch.logger.Error("Slack GetFilesContext failed", zap.String("channel", channel), zap.Error(err))
+		return nil, fmt.Errorf("failed to list files of %s: %w", channel, err)
+	}
+
+	resources := channelResources(pins.items, files.files, page == 1)
+	nextCursor := ""
+	if files.paging != nil && files.paging.Page < files.paging.Pages {
+		nextCursor = base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("page:%d", files.paging.Page+1)))
+	}
+	if len(resources) > 0 {
+		resources[len(resources)-1].Cursor = nextCursor
+	}
+
+	csvBytes, err := gocsv.MarshalBytes(&resources)
+	if err != nil {
+		ch.logger.Error("Failed to marshal channel resources to CSV", zap.Error(err))
+		return nil, err
+	}
+	result := mcp.NewToolResultText(string(csvBytes))
+	if len(resources) == 0 && nextCursor != "" {
+		// Files are filtered after listing, so a page may hold no documents
+		// while later pages do
+		result.Content = append(result.Content, mcp.NewTextContent(fmt.Sprintf(
+			"No canvases, posts or pinned files on this page of files, continue with cursor %q", nextCursor,
+		)))
+		return result, nil
+	}
+	return withEmptyResultNote(result, len(resources), "No canvases, posts or pinned files found in this channel"), nil
+}
+
+type pinsPage struct {
+	items  []slack.Item
+	paging *slack.Paging
+}
+
+type filesPage struct {
+	files  []slack.File
+	paging *slack.Paging
+}
+
+// channelResources merges the pinned files of a channel with its canvases and
+// posts, each file listed once. Pinned files come first and only when
+// withPinned is set; pinned messages are skipped.
+func channelResources(pins []slack.Item, files []slack.File, withPinned bool) []ChannelResource {
+	seen := make(map[string]bool)
+	var resources []ChannelResource
+
+	for _, item := range pins {
+		if item.File == nil || seen[item.File.ID] {
+			continue
+		}
+		seen[item.File.ID] = true
+		if withPinned {
+			resources = append(resources, channelResource(*item.File, true))
+		}
+	}
+	for _, f := range files {
+		if seen[f.ID] || !channelDocumentTypes[f.Filetype] {
+			continue
+		}
+		seen[f.ID] = true
+		resources = append(resources, channelResource(f, false))
+	}
+	return resources
+}
+
+func channelResource(f slack.File, pinned bool) ChannelResource {
+	title := f.Title
+	if title == "" {
+		title = f.Name
+	}
+	fileType := f.PrettyType
+	if fileType == "" {
+		fileType = f.Filetype
+	}
+	updated := f.Timestamp
+	if updated < f.Created {
+		updated = f.Created
+	}
+	return ChannelResource{
+		ID:        f.ID,
+		Title:     title,
+		Type:      fileType,
+		Permalink: f.Permalink,
+		Updated:   formatJSONTime(updated),
+		Pinned:    pinned,
+	}
+}
+
+// maxMemberCountChannels caps the channels accepted by channels_member_counts
+const maxMemberCountChannels = 100
+
+// ChannelMemberCount is a result row of channels_member_counts
+type ChannelMemberCount struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	MemberCount int    `json:"memberCount"`
+	Source      string `json:"source"` // "cache", "api" or "unavailable"
+}
+
+// ChannelsMemberCountsHandler returns the member count of several channels at
+// once, served from the channels cache where possible
+func (ch *ChannelsHandler) ChannelsMemberCountsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("ChannelsMemberCountsHandler called", zap.Any("params", request.Params))
+
+	if ready, err := ch.apiProvider.IsReady(); !ready {
+		ch.logger.Error("API provider not ready", zap.Error(err))
+		return nil, err
+	}
+
+	var ids []string
+	seen := map[string]bool{}
+	for _, raw := range strings.Split(request.GetString("channel_ids", ""), ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		id, err := ch.resolveAllowedChannel(ctx, raw)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, errors.New("channel_ids is required")
+	}
+	if len(ids) > maxMemberCountChannels {
+		return nil, fmt.Errorf("channel_ids accepts at most %d channels, got %d", maxMemberCountChannels, len(ids))
+	}
+
+	channels := ch.apiProvider.ProvideChannelsMaps().Channels
+	counts := memberCounts(ctx, ids, channels, limiter.Tier3.Limiter(), func(ctx context.Context, id string) (*slack.Channel, error) {
+		return ch.apiProvider.Slack().GetConversationInfoContext(ctx, &slack.GetConversationInfoInput{
+			ChannelID:         id,
+			IncludeNumMembers: true,
+		})
+	}, ch.logger)
+
+	csvBytes, err := gocsv.MarshalBytes(&counts)
+	if err != nil {
+		ch.logger.Error("Failed to marshal member counts to CSV", zap.Error(err))
+		return nil, err
+	}
+	return mcp.NewToolResultText(string(csvBytes)), nil
+}
+
+// memberCounts looks up the member count of each channel in ids. Counts found in
+// the channels cache are used as is; channels missing from the cache or cached
+// with no members are fetched with conversations.info.
+func memberCounts(ctx context.Context, ids []string, channels map[string]provider.Channel, rl *rate.Limiter, fetch func(ctx context.Context, id string) (*slack.Channel, error), logger *zap.Logger) []ChannelMemberCount {
+	counts := make([]ChannelMemberCount, 0, len(ids))
+	for _, id := range ids {
+		row := ChannelMemberCount{ID: id, Source: "unavailable"}
+		if c, ok := channels[id]; ok {
+			row.Name = c.Name
+			if c.MemberCount > 0 {
+				row.MemberCount = c.MemberCount
+				row.Source = "cache"
+				counts = append(counts, row)
+				continue
+			}
+		}
+		if ctx.Err() == nil {
+			info, err := limiter.CallWithRetry(ctx, rl, 2, provider.SlackRetryAfter, func() (*slack.Channel, error) {
+				return fetch(ctx, id)
+			})
+			if err != nil {
+				logger.Warn("Failed to fetch member count", zap.String("channel", id), zap.Error(err))
+			} else {
+				if row.Name == "" && info.Name != "" {
+					row.Name = "#" + info.Name
+				}
+				row.MemberCount = info.NumMembers
+				row.Source = "api"
+			}
+		}
+		counts = append(counts, row)
+	}
+	return counts
+}
+
+// ChannelsListArchivedHandler lists archived channels, which are not part of the channels cache
+func (ch *ChannelsHandler) ChannelsListArchivedHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("ChannelsListArchivedHandler called", zap.Any("params", request.Params))
+
+	if ready, err := ch.apiProvider.IsReady(); !ready {
+		ch.logger.Error("API provider not ready", zap.Error(err))
+		return nil, err
+	}
+
+	query := strings.TrimSpace(request.GetString("query", ""))
+	cursor := request.GetString("cursor", "")
+	limit := request.GetInt("limit", 100)
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 999 {
+		ch.logger.Warn("Limit exceeds maximum, capping to 999", zap.Int("requested", limit))
+		limit = 999
+	}
+
+	archived, err := ch.apiProvider.GetArchivedChannels(ctx)
+	if err != nil {
+		ch.logger.Error("Failed to fetch archived channels", zap.Error(err))
+		return nil, fmt.Errorf("failed to fetch archived channels: %v", err)
+	}
+
+	chans, nextcur := paginateChannels(filterChannelsByName(archived, query), cursor, limit)
+
+	var channelList []Channel
+	for _, channel := range chans {
+		channelList = append(channelList, Channel{
+			ID:          channel.ID,
+			Name:        channel.Name,
+			Topic:       channel.Topic,
+			Purpose:     channel.Purpose,
+			MemberCount: channel.MemberCount,
+		})
+	}
+	if len(channelList) > 0 && nextcur != "" {
+		channelList[len(channelList)-1].Cursor = nextcur
+	}
+
+	csvBytes, err := gocsv.MarshalBytes(&channelList)
+	if err != nil {
+		ch.logger.Error("Failed to marshal channels to CSV", zap.Error(err))
+		return nil, err
+	}
+
+	return withEmptyResultNote(mcp.NewToolResultText(string(csvBytes)), len(channelList), "No archived channels matched the query"), nil
+}
+
+// ChannelsDefaultsHandler lists the channels every new member of the workspace
+// joins automatically. The list comes from the team preferences of the client
+// boot, which not every token can read; in that case an empty result with a note
+// is returned instead of an error.
+func (ch *ChannelsHandler) ChannelsDefaultsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("ChannelsDefaultsHandler called", zap.Any("params", request.Params))
+
+	if ready, err := ch.apiProvider.IsReady(); !ready {
+		ch.logger.Error("API provider not ready", zap.Error(err))
+		return nil, err
+	}
+
+	boot, err := ch.apiProvider.Slack().ClientUserBoot(ctx)
+	if err != nil {
+		ch.logger.Warn("Failed to fetch team preferences", zap.Error(err))
+	}
+
+	channelList, note := defaultChannels(boot, err, ch.apiProvider.ProvideChannelsMaps().Channels, allowedChannelTypes())
+	csvBytes, err := gocsv.MarshalBytes(&channelList)
+	if err != nil {
+		ch.logger.Error("Failed to marshal channels to CSV", zap.Error(err))
+		return nil, err
+	}
+
+	result := mcp.NewToolResultText(string(csvBytes))
+	if note != "" {
+		result.Content = append(result.Content, mcp.NewTextContent(note))
+	}
+	return result, nil
+}
+
+// defaultChannels resolves the default channels of the team preferences against
+// the channels cache. Channels missing from the cache are returned by ID. When
+// boot is unavailable no channels are returned and note explains why.
+func defaultChannels(boot *edge.ClientUserBootResponse, bootErr error, channels map[string]provider.Channel, policy channelTypePolicy) ([]Channel, string) {
+	if boot == nil {
+		note := "Default channels are not available to this token: they are read from the team preferences of client.userBoot, which requires a browser session token (xoxc/xoxd)"
+		if bootErr != nil {
+			note += fmt.Sprintf(" (%v)", bootErr)
+		}
+		return nil, note
+	}
+
+	list := resolveDefaultChannels(boot.Team.Prefs.DefaultChannels, channels, policy)
+	if len(list) == 0 {
+		return list, "The workspace has no default channels configured"
+	}
+	return list, ""
+}
+
+// resolveDefaultChannels looks up the default channel IDs of the team
+// preferences in the channels cache. Channels missing from the cache are
+// returned by ID only, channels of types the policy does not allow are skipped.
+func resolveDefaultChannels(ids []string, channels map[string]provider.Channel, policy channelTypePolicy) []Channel {
+	var list []Channel
+	for _, id := range ids {
+		c, ok := channels[id]
+		if !ok {
+			list = append(list, Channel{ID: id})
+			continue
+		}
+		if !policy.allows(channelTypeOf(c)) {
+			continue
+		}
+		list = append(list, Channel{
+			ID:          c.ID,
+			Name:        c.Name,
+			Topic:       c.Topic,
+			Purpose:     c.Purpose,
+			MemberCount: c.MemberCount,
+		})
+	}
+	return list
+}
+
+// InviteResult is the outcome of inviting a single user to a channel
+type InviteResult struct {
+	User   string `json:"user"`
+	UserID string `json:"userID"`
+	Status string `json:"status"` // "invited" or "failed"
+	Error  string `json:"error"`
+}
+
+// ChannelsInviteHandler invites users to a channel, one by one so that a user who
+// is already a member does not fail the whole request
+func (ch *ChannelsHandler) ChannelsInviteHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("ChannelsInviteHandler called", zap.Any("params", request.Params))
+
+	if ready, err := ch.apiProvider.IsReady(); !ready {
+		ch.logger.Error("API provider not ready", zap.Error(err))
+		return nil, err
+	}
+
+	toolConfig := os.Getenv("SLACK_MCP_INVITE_TOOL")
+	if toolConfig == "" {
+		if !strings.Contains(os.Getenv("SLACK_MCP_ENABLED_TOOLS"), "channels_invite") {
+			ch.logger.Error("Invite tool disabled by default")
+			return nil, errors.New(
+				"by default, the channels_invite tool is disabled to guard Slack workspaces against unwanted membership changes. " +
+					"To enable it, set the SLACK_MCP_INVITE_TOOL environment variable to true, 1, or comma separated list of channels " +
+					"to limit where the MCP can invite users, e.g. 'SLACK_MCP_INVITE_TOOL=C1234567890', 'SLACK_MCP_INVITE_TOOL=!C1234567890' " +
+					"to enable all except one or 'SLACK_MCP_INVITE_TOOL=true' for all channels",
+			)
+		}
+		toolConfig = "true"
+	}
+
+	channel := strings.TrimSpace(request.GetString("channel_id", ""))
+	if channel == "" {
+		return nil, errors.New("channel_id is required")
+	}
+	channel, err := ch.resolveAllowedChannel(ctx, channel)
+	if err != nil {
+		return nil, err
+	}
+	if !isChannelAllowedForConfig(channel, toolConfig) {
+		ch.logger.Warn("Invite tool not allowed for channel", zap.String("channel", channel), zap.String("policy", toolConfig))
+		return nil, fmt.Errorf("channels_invite tool is not allowed for channel %q, applied policy: %s", channel, toolConfig)
+	}
+
+	rawUsers := request.GetString("users", "")
+	if strings.TrimSpace(rawUsers) == "" {
+		return nil, errors.New("users is required")
+	}
+
+	resolved, results := resolveInviteUsers(rawUsers, ch.apiProvider.ProvideUsersMap())
+	rl := limiter.Tier3.Limiter()
+	results = append(results, inviteEach(resolved, func(userID string) error {
+		_, err := limiter.CallWithRetry(ctx, rl, 2, provider.SlackRetryAfter, func() (*slack.Channel, error) {
+			return ch.apiProvider.Slack().InviteUsersToConversationContext(ctx, channel, userID)
+		})
+		return err
+	})...)
+
+	ch.logger.Info("Invited users to channel",
+		zap.String("channel", channel),
+		zap.Int("requested", len(results)),
+		zap.Int("resolved", len(resolved)))
+
+	csvBytes, err := gocsv.MarshalBytes(&results)
+	if err != nil {
+		ch.logger.Error("Failed to marshal invite results to CSV", zap.Error(err))
+		return nil, err
+	}
+	return mcp.NewToolResultText(string(csvBytes)), nil
+}
+
+// resolvedInvitee is a user from the users parameter that was found in the cache
+type resolvedInvitee struct {
+	user   string
+	userID string
+}
+
+// resolveInviteUsers resolves a comma-separated list of user IDs and @handles
+// against the users cache. Users that cannot be resolved are returned as failed
+// results right away; duplicates are invited once.
+func resolveInviteUsers(raw string, users *provider.UsersCache) ([]resolvedInvitee, []InviteResult) {
+	var (
+		resolved []resolvedInvitee
+		failed   []InviteResult
+	)
+	seen := make(map[string]bool)
+
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		handle := strings.TrimPrefix(strings.TrimSuffix(strings.TrimPrefix(item, "<@"), ">"), "@")
+
+		var userID string
+		if users != nil {
+			if u, ok := users.Users[handle]; ok {
+				userID = u.ID
+			} else if id, ok := users.UsersInv[handle]; ok {
+				userID = id
+			}
+		}
+		if userID == "" {
+			failed = append(failed, InviteResult{User: item, Status: "failed", Error: "user not found"})
+			continue
+		}
+		if seen[userID] {
+			continue
+		}
+		seen[userID] = true
+		resolved = append(resolved, resolvedInvitee{user: item, userID: userID})
+	}
+	return resolved, failed
+}
+
+// inviteEach invites every user separately and records a result per user
+func inviteEach(users []resolvedInvitee, invite func(userID string) error) []InviteResult {
+	results := make([]InviteResult, 0, len(users))
+	for _, u := range users {
+		r := InviteResult{User: u.user, UserID: u.userID, Status: "invited"}
+		if err := invite(u.userID); err != nil {
+			r.Status = "failed"
+			r.Error = err.Error()
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// CreatedChannel is the result row of channels_create
+type CreatedChannel struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// channelNameMaxLen is the maximum length of a Slack channel name
+const channelNameMaxLen = 80
+
+var channelNameRe = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// ChannelsCreateHandler creates a public or private channel and refreshes the
+// channels cache so the new channel can be referenced by name right away
+func (ch *ChannelsHandler) ChannelsCreateHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("ChannelsCreateHandler called", zap.Any("params", request.Params))
+
+	if ready, err := ch.apiProvider.IsReady(); !ready {
+		ch.logger.Error("API provider not ready", zap.Error(err))
+		return nil, err
+	}
+
+	toolConfig := channelAdminToolConfig("channels_create")
+	if toolConfig == "" {
+		ch.logger.Error("Channel admin tool disabled by default")
+		return nil, errors.New(
+			"by default, the channels_create tool is disabled to guard Slack workspaces against unwanted channels. " +
+				"To enable it, set the SLACK_MCP_CHANNEL_ADMIN_TOOL environment variable to true or 1",
+		)
+	}
+	if !allowsNewChannels(toolConfig) {
+		ch.logger.Warn("Channel admin tool limited to listed channels", zap.String("policy", toolConfig))
+		return nil, fmt.Errorf("channels_create tool is not allowed while SLACK_MCP_CHANNEL_ADMIN_TOOL limits changes to the listed channels, applied policy: %s", toolConfig)
+	}
+
+	name, err := validateChannelName(request.GetString("name", ""))
+	if err != nil {
+		ch.logger.Error("Invalid channel name", zap.Error(err))
+		return nil, err
+	}
+	isPrivate := request.GetBool("is_private", false)
+	topic := strings.TrimSpace(request.GetString("topic", ""))
+	purpose := strings.TrimSpace(request.GetString("purpose", ""))
+
+	api := ch.apiProvider.Slack()
+	created, err := api.CreateConversationContext(ctx, slack.CreateConversationParams{
+		ChannelName: name,
+		IsPrivate:   isPrivate,
+	})
+	if err != nil {
+		ch.logger.Error("Failed to create channel", zap.String("name", name), zap.Error(err))
+		return nil, fmt.Errorf("failed to create channel %q: %w", name, err)
+	}
+
+	ch.logger.Info("Created channel",
+		zap.String("channel", created.ID),
+		zap.String("name", created.Name),
+		zap.Bool("is_private", isPrivate))
+
+	var notes []string
+	if topic != "" {
+		if _, err := api.SetTopicOfConversationContext(ctx, created.ID, topic); err != nil {
+			ch.logger.Warn("Failed to set channel topic", zap.String("channel", created.ID), zap.Error(err))
+			notes = append(notes, fmt.Sprintf("failed to set topic: %v", err))
+		}
+	}
+	if purpose != "" {
+		if _, err := api.SetPurposeOfConversationContext(ctx, created.ID, purpose); err != nil {
+			ch.logger.Warn("Failed to set channel purpose", zap.String("channel", created.ID), zap.Error(err))
+			notes = append(notes, fmt.Sprintf("failed to set purpose: %v", err))
+		}
+	}
+	if note := refreshChannelsAfterCreate(ctx, ch.apiProvider.ForceRefreshChannels, ch.logger); note != "" {
+		notes = append(notes, note)
+	}
+
+	rows := []CreatedChannel{{ID: created.ID, Name: "#" + created.Name}}
+	csvBytes, err := gocsv.MarshalBytes(&rows)
+	if err != nil {
+		ch.logger.Error("Failed to marshal created channel to CSV", zap.Error(err))
+		return nil, err
+	}
+
+	result := mcp.NewToolResultText(string(csvBytes))
+	for _, note := range notes {
+		result.Content = append(result.Content, mcp.NewTextContent(note))
+	}
+	return result, nil
+}
+
+// ChannelState is the result row of channels_archive and channels_unarchive
+type ChannelState struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	IsArchived bool   `json:"isArchived"`
+}
+
+// ChannelsArchiveHandler archives a channel
+func (ch *ChannelsHandler) ChannelsArchiveHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("ChannelsArchiveHandler called", zap.Any("params", request.Params))
+	return ch.setChannelArchived(ctx, request, "channels_archive", true)
+}
+
+// ChannelsUnarchiveHandler unarchives a channel
+func (ch *ChannelsHandler) ChannelsUnarchiveHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("ChannelsUnarchiveHandler called", zap.Any("params", request.Params))
+	return ch.setChannelArchived(ctx, request, "channels_unarchive", false)
+}
+
+func (ch *ChannelsHandler) setChannelArchived(ctx context.Context, request mcp.CallToolRequest, toolName string, archive bool) (*mcp.CallToolResult, error) {
+	if ready, err := ch.apiProvider.IsReady(); !ready {
+		ch.logger.Error("API provider not ready", zap.Error(err))
+		return nil, err
+	}
+
+	toolConfig := channelAdminToolConfig(toolName)
+	if toolConfig == "" {
+		ch.logger.Error("Channel admin tool disabled by default", zap.String("tool", toolName))
+		return nil, fmt.Errorf(
+			"by default, the %s tool is disabled to guard Slack workspaces against destructive changes. "+
+				"To enable it, set the SLACK_MCP_CHANNEL_ADMIN_TOOL environment variable to true, 1, or comma separated list of channels "+
+				"to limit which channels the MCP can change, e.g. 'SLACK_MCP_CHANNEL_ADMIN_TOOL=C1234567890'", toolName,
+		)
+	}
+
+	channel := strings.TrimSpace(request.GetString("channel_id", ""))
+	if channel == "" {
+		return nil, errors.New("channel_id is required")
+	}
+	channel, err := ch.resolveAllowedChannel(ctx, channel)
+	if err != nil {
+		return nil, err
+	}
+	if !isChannelAllowedForConfig(channel, toolConfig) {
+		ch.logger.Warn("Channel admin tool not allowed for channel", zap.String("channel", channel), zap.String("policy", toolConfig))
+		return nil, fmt.Errorf("%s tool is not allowed for channel %q, applied policy: %s", toolName, channel, toolConfig)
+	}
+
+	action := "unarchive"
+	if archive {
+		action = "archive"
+	}
+	if err := requireConfirm(action, channel, request.GetBool("confirm", false)); err != nil {
+		ch.logger.Warn("Channel archive state change not confirmed", zap.String("channel", channel), zap.String("action", action))
+		return nil, err
+	}
+
+	api := ch.apiProvider.Slack()
+	if archive {
+		err = api.ArchiveConversationContext(ctx, channel)
+	} else {
+		err = api.UnArchiveConversationContext(ctx, channel)
+	}
+	if err != nil {
+		ch.logger.Error("Failed to change channel archive state",
+			zap.String("channel", channel), zap.String("action", action), zap.Error(err))
+		return nil, fmt.Errorf("failed to %s channel %q: %w", action, channel, err)
+	}
+
+	if !ch.apiProvider.SetChannelArchived(channel, archive) {
+		ch.logger.Debug("Channel not in cache, archived flag not updated", zap.String("channel", channel))
+	}
+	ch.logger.Info("Changed channel archive state", zap.String("channel", channel), zap.Bool("archived", archive))
+
+	state := []ChannelState{{ID: channel, Name: ch.apiProvider.ProvideChannelsMaps().Channels[channel].Name, IsArchived: archive}}
+	csvBytes, err := gocsv.MarshalBytes(&state)
+	if err != nil {
+		ch.logger.Error("Failed to marshal channel state to CSV", zap.Error(err))
+		return nil, err
+	}
+	return mcp.NewToolResultText(string(csvBytes)), nil
+}
+
+// channelAdminToolConfig returns SLACK_MCP_CHANNEL_ADMIN_TOOL, falling back to
+// "true" when the tool is explicitly listed in SLACK_MCP_ENABLED_TOOLS
+func channelAdminToolConfig(toolName string) string {
+	toolConfig := os.Getenv("SLACK_MCP_CHANNEL_ADMIN_TOOL")
+	if toolConfig == "" && toolListed(os.Getenv("SLACK_MCP_ENABLED_TOOLS"), toolName) {
+		toolConfig = "true"
+	}
+	return toolConfig
+}
+
+// toolListed reports whether toolName is one of the comma separated names in
+// enabledTools. Names must match exactly, so channels_archive is not enabled by
+// listing channels_unarchive.
+func toolListed(enabledTools, toolName string) bool {
+	for _, tool := range strings.Split(enabledTools, ",") {
+		if strings.TrimSpace(tool) == toolName {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsNewChannels reports whether an admin tool config, parsed like
+// isChannelAllowedForConfig does, lets the MCP create channels. An allowlist
+// names existing channels only, so it does not, while an exclusion list
+// cannot exclude a channel that does not exist yet.
+func allowsNewChannels(config string) bool {
+	config = strings.TrimSpace(config)
+	return config == "true" || config == "1" || strings.HasPrefix(config, "!")
+}
+
+// requireConfirm guards destructive channel changes behind an explicit confirm=true
+func requireConfirm(action, channel string, confirm bool) error {
+	if confirm {
+		return nil
+	}
+	return fmt.Errorf("refusing to %s channel %q without confirmation: call again with confirm=true", action, channel)
+}
+
+// validateChannelName checks name against Slack's channel naming rules and
+// returns it without a leading '#'
+func validateChannelName(name string) (string, error) {
+	name = strings.TrimPrefix(strings.TrimSpace(name), "#")
+	if name == "" {
+		return "", errors.New("name is required")
+	}
+	if len(name) > channelNameMaxLen {
+		return "", fmt.Errorf("channel name %q is too long: %d characters, maximum is %d", name, len(name), channelNameMaxLen)
+	}
+	if !channelNameRe.MatchString(name) {
+		suggestion := strings.Map(func(r rune) rune {
+			switch {
+			case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_', r == '-':
+				return r
+			case r >= 'A' && r <= 'Z':
+				return r + ('a' - 'A')
+			case r == ' ', r == '.':
+				return '-'
+			}
+			return -1
+		}, name)
+		msg := fmt.Sprintf("channel name %q is invalid: names may only contain lowercase letters, numbers, hyphens and underscores, without spaces or periods", name)
+		if suggestion != "" {
+			msg += fmt.Sprintf(", e.g. %q", suggestion)
+		}
+		return "", errors.New(msg)
+	}
+	return name, nil
+}
+
+// refreshChannelsAfterCreate refreshes the channels cache after a channel was
+// created. It returns a note for the caller when the new channel cannot be
+// resolved by name yet.
+func refreshChannelsAfterCreate(ctx context.Context, refresh func(ctx context.Context) error, logger *zap.Logger) string {
+	err := refresh(ctx)
+	if err == nil {
+		return ""
+	}
+	if errors.Is(err, provider.ErrRefreshRateLimited) {
+		logger.Warn("Channels cache refresh after create was rate-limited")
+		return "channels cache refresh was rate-limited; refer to the new channel by ID until the next refresh"
+	}
+	logger.Warn("Failed to refresh channels cache after create", zap.Error(err))
+	return fmt.Sprintf("failed to refresh channels cache: %v; refer to the new channel by ID until the next refresh", err)
+}
+
+// TeamSetting is a result row of admin_team_info
+type TeamSetting struct {
+	Setting string `json:"setting"`
+	Value   string `json:"value"`
+}
+
+// AdminTeamInfoHandler returns non-sensitive workspace settings. Settings whose
+// source API is not available to the token are omitted instead of failing.
+func (ch *ChannelsHandler) AdminTeamInfoHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("AdminTeamInfoHandler called", zap.Any("params", request.Params))
+
+	if ready, err := ch.apiProvider.IsReady(); !ready {
+		ch.logger.Error("API provider not ready", zap.Error(err))
+		return nil, err
+	}
+
+	toolConfig := os.Getenv("SLACK_MCP_ADMIN_TOOL")
+	if toolConfig == "" && strings.Contains(os.Getenv("SLACK_MCP_ENABLED_TOOLS"), "admin_team_info") {
+		toolConfig = "true"
+	}
+	if toolConfig != "1" && toolConfig != "true" && toolConfig != "yes" {
+		ch.logger.Error("Admin tool disabled by default")
+		return nil, errors.New(
+			"by default, the admin_team_info tool is disabled. " +
+				"To enable it, set the SLACK_MCP_ADMIN_TOOL environment variable to true or 1",
+		)
+	}
+
+	api := ch.apiProvider.Slack()
+	info, infoErr := api.GetTeamInfoContext(ctx)
+	if infoErr != nil {
+		ch.logger.Warn("Failed to fetch team info", zap.Error(infoErr))
+	}
+	boot, bootErr := api.ClientUserBoot(ctx)
+	if bootErr != nil {
+		ch.logger.Warn("Failed to fetch team preferences", zap.Error(bootErr))
+	}
+	if infoErr != nil && bootErr != nil {
+		return nil, fmt.Errorf("failed to fetch team info: %w", infoErr)
+	}
+
+	settings, omitted := teamSettings(info, boot, ch.apiProvider.ProvideChannelsMaps().Channels, allowedChannelTypes())
+	csvBytes, err := gocsv.MarshalBytes(&settings)
+	if err != nil {
+		ch.logger.Error("Failed to marshal team settings to CSV", zap.Error(err))
+		return nil, err
+	}
+
+	contents := []mcp.Content{mcp.NewTextContent(string(csvBytes))}
+	if len(omitted) > 0 {
+		contents = append(contents, mcp.NewTextContent(fmt.Sprintf(
+			"Omitted settings not available to this token (missing scopes or unsupported token type): %s",
+			strings.Join(omitted, ", "),
+		)))
+	}
+	return &mcp.CallToolResult{Content: contents}, nil
+}
+
+// teamSettings flattens team.info and the team preferences from the client
+// boot into setting/value rows. A nil source is skipped and its settings are
+// reported as omitted. Default channels are resolved like channels_defaults
+// does.
+func teamSettings(info *slack.TeamInfo, boot *edge.ClientUserBootResponse, channels map[string]provider.Channel, policy channelTypePolicy) ([]TeamSetting, []string) {
+	var settings []TeamSetting
+	var omitted []string
+
+	if info != nil {
+		settings = append(settings,
+			TeamSetting{Setting: "team_id", Value: info.ID},
+			TeamSetting{Setting: "name", Value: info.Name},
+			TeamSetting{Setting: "domain", Value: info.Domain},
+		)
+		if info.EmailDomain != "" {
+			settings = append(settings, TeamSetting{Setting: "email_domain", Value: info.EmailDomain})
+		}
+	} else {
+		omitted = append(omitted, "team_id", "name", "domain")
+	}
+
+	if boot == nil {
+		return settings, append(omitted, "default_channels", "slack_connect_enabled", "message_retention", "file_retention")
+	}
+
+	prefs := boot.Team.Prefs
+	var defaults []string
+	for _, c := range resolveDefaultChannels(prefs.DefaultChannels, channels, policy) {
+		if c.Name != "" {
+			defaults = append(defaults, c.Name)
+		} else {
+			defaults = append(defaults, c.ID)
+		}
+	}
+	slackConnect := prefs.CanCreateSlackConnectChannelInvite || prefs.CanAcceptSlackConnectChannelInvites
+	settings = append(settings,
+		TeamSetting{Setting: "default_channels", Value: strings.Join(defaults, " ")},
+		TeamSetting{Setting: "slack_connect_enabled", Value: fmt.Sprintf("%t", slackConnect)},
+		TeamSetting{Setting: "message_retention", Value: formatRetention(prefs.RetentionType, prefs.RetentionDuration)},
+		TeamSetting{Setting: "file_retention", Value: formatRetention(prefs.FileRetentionType, prefs.FileRetentionDuration)},
+	)
+	return settings, omitted
+}
+
+// formatRetention renders a Slack retention preference, where type 0 keeps
+// everything and any other type keeps content for duration days
+func formatRetention(retentionType, duration int64) string {
+	if retentionType == 0 {
+		return "keep all"
+	}
+	return fmt.Sprintf("%d days", duration)
+}
+
+// maxMemberCountRefresh caps the conversations.info calls refresh_member_counts
+// makes in one tool call, roughly one minute of Tier 3 budget.
+const maxMemberCountRefresh = 50
+
+// refreshMemberCounts replaces the cached member counts of channels with fresh
+// ones from conversations.info, one rate limited call per channel. Only the
+// first maxMemberCountRefresh channels are refreshed; the number of channels
+// left with their cached count is returned. Channels whose lookup fails keep
+// their cached count.
+func refreshMemberCounts(ctx context.Context, channels []Channel, rl *rate.Limiter, fetch func(ctx context.Context, id string) (*slack.Channel, error), logger *zap.Logger) int {
+	skipped := 0
+	if len(channels) > maxMemberCountRefresh {
+		skipped = len(channels) - maxMemberCountRefresh
+		channels = channels[:maxMemberCountRefresh]
+	}
+	for i := range channels {
+		info, err := limiter.CallWithRetry(ctx, rl, 2, provider.SlackRetryAfter, func() (*slack.Channel, error) {
+			return fetch(ctx, channels[i].ID)
+		})
+		if err != nil {
+			logger.Warn("Failed to refresh member count", zap.String("channel", channels[i].ID), zap.Error(err))
+			if ctx.Err() != nil {
+				return skipped
+			}
+			continue
+		}
+		channels[i].MemberCount = info.NumMembers
+	}
+	return skipped
+}
+
+// channelTypePolicy is the set of channel types allowed by
+// SLACK_MCP_ALLOWED_CHANNEL_TYPES. A nil policy allows every type.
+type channelTypePolicy map[string]bool
+
+func allowedChannelTypes() channelTypePolicy {
+	return channelTypePolicyFrom(os.Getenv("SLACK_MCP_ALLOWED_CHANNEL_TYPES"))
+}
+
+// channelTypePolicyFrom parses a comma-separated list of channel types
+func channelTypePolicyFrom(raw string) channelTypePolicy {
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+	policy := channelTypePolicy{}
+	for _, t := range strings.Split(raw, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			policy[t] = true
+		}
+	}
+	return policy
+}
+
+// ValidateAllowedChannelTypes rejects SLACK_MCP_ALLOWED_CHANNEL_TYPES values that
+// are not channel types, since a typo would otherwise silently block every channel
+func ValidateAllowedChannelTypes(raw string) error {
+	var invalid []string
+	for t := range channelTypePolicyFrom(raw) {
+		if !slices.Contains(provider.AllChanTypes, t) {
+			invalid = append(invalid, t)
+		}
+	}
+	if len(invalid) > 0 {
+		sort.Strings(invalid)
+		return fmt.Errorf("invalid channel type(s): %s. Valid types are: %s",
+			strings.Join(invalid, ", "),
+			strings.Join(provider.AllChanTypes, ", "))
+	}
+	return nil
+}
+
+// allows reports whether channels of type t may be exposed. An empty type means
+// it could not be determined and is only allowed without a policy.
+func (p channelTypePolicy) allows(t string) bool {
+	return p == nil || p[t]
+}
+
+// check refuses operations on channel id when its type is not allowed, or
+// cannot be determined while a policy is set
+func (p channelTypePolicy) check(id string, channels map[string]provider.Channel) error {
+	t := channelTypeByID(id, channels)
+	switch {
+	case p.allows(t):
+		return nil
+	case t == "":
+		return fmt.Errorf("channel %q is not in the channels cache, so its type cannot be checked against SLACK_MCP_ALLOWED_CHANNEL_TYPES", id)
+	}
+	return fmt.Errorf("channel %q is of type %s, which is not allowed by SLACK_MCP_ALLOWED_CHANNEL_TYPES", id, t)
+}
+
+// channelTypeOf returns the conversation type of c as one of provider.AllChanTypes
+func channelTypeOf(c provider.Channel) string {
+	switch {
+	case c.IsIM:
+		return "im"
+	case c.IsMpIM:
+		return "mpim"
+	case c.IsPrivate:
+		return provider.PrivateChanType
+	default:
+		return provider.PubChanType
+	}
+}
+
+// channelTypeByID returns the type of channel id from the cache. Uncached DMs
+// are recognized by their D prefix; any other uncached ID yields "".
+func channelTypeByID(id string, channels map[string]provider.Channel) string {
+	if c, ok := channels[id]; ok {
+		return channelTypeOf(c)
+	}
+	if strings.HasPrefix(id, "D") {
+		return "im"
+	}
+	return ""
+}
+
+// resolveAllowedChannel resolves channel with the handler's channels cache and
+// refuses types SLACK_MCP_ALLOWED_CHANNEL_TYPES does not allow
+func (ch *ChannelsHandler) resolveAllowedChannel(ctx context.Context, channel string) (string, error) {
+	return resolveAllowedChannel(ctx, channel, ch.apiProvider.ProvideChannelsMaps, ch.apiProvider.ForceRefreshChannels, ch.logger)
+}
+
+// parseChannelTypes validates a comma-separated list of channel types, falling
+// back to public and private channels when none is valid
+func (ch *ChannelsHandler) parseChannelTypes(types string) []string {
 	// MCP Inspector v0.14.0 has issues with Slice type
 	// introspection, so some type simplification makes sense here
 	channelTypes := []string{}
@@ -141,72 +1376,52 @@ func (ch *ChannelsHandler) ChannelsHandler(ctx context.Context, request mcp.Call
 	}
 
 	ch.logger.Debug("Validated channel types", zap.Strings("types", channelTypes))
+	return channelTypes
+}
 
-	if limit == 0 {
-		limit = 100
-		ch.logger.Debug("Limit not provided, using default", zap.Int("limit", limit))
+// filterChannelsByPolicy drops channels whose type the policy does not allow
+func filterChannelsByPolicy(channels []provider.Channel, policy channelTypePolicy) []provider.Channel {
+	if policy == nil {
+		return channels
 	}
-	if limit > 999 {
-		ch.logger.Warn("Limit exceeds maximum, capping to 999", zap.Int("requested", limit))
-		limit = 999
+	kept := make([]provider.Channel, 0, len(channels))
+	for _, c := range channels {
+		if policy.allows(channelTypeOf(c)) {
+			kept = append(kept, c)
+		}
 	}
+	return kept
+}
 
-	var (
-		nextcur     string
-		channelList []Channel
-	)
-
-	allChannels := ch.apiProvider.ProvideChannelsMaps().Channels
-	ch.logger.Debug("Total channels available", zap.Int("count", len(allChannels)))
-
-	channels := filterChannelsByTypes(allChannels, channelTypes)
-	ch.logger.Debug("Channels after filtering by type", zap.Int("count", len(channels)))
-
-	var chans []provider.Channel
-
-	chans, nextcur = paginateChannels(
-		channels,
-		cursor,
-		limit,
-	)
-
-	ch.logger.Debug("Pagination results",
-		zap.Int("returned_count", len(chans)),
-		zap.Bool("has_next_page", nextcur != ""),
-	)
-
-	for _, channel := range chans {
-		channelList = append(channelList, Channel{
-			ID:          channel.ID,
-			Name:        channel.Name,
-			Topic:       channel.Topic,
-			Purpose:     channel.Purpose,
-			MemberCount: channel.MemberCount,
-		})
+// filterChannelsByName keeps channels whose name contains query (case-insensitive).
+// An empty query keeps all channels.
+func filterChannelsByName(channels []provider.Channel, query string) []provider.Channel {
+	if query == "" {
+		return channels
 	}
+	query = strings.ToLower(strings.TrimPrefix(query, "#"))
 
-	switch sortType {
-	case "popularity":
-		ch.logger.Debug("Sorting channels by popularity (member count)")
-		sort.Slice(channelList, func(i, j int) bool {
-			return channelList[i].MemberCount > channelList[j].MemberCount
-		})
-	default:
-		ch.logger.Debug("No sorting applied", zap.String("sort_type", sortType))
+	var result []provider.Channel
+	for _, c := range channels {
+		if strings.Contains(strings.ToLower(c.Name), query) {
+			result = append(result, c)
+		}
 	}
+	return result
+}
 
-	if len(channelList) > 0 && nextcur != "" {
-		channelList[len(channelList)-1].Cursor = nextcur
-		ch.logger.Debug("Added cursor to last channel", zap.String("cursor", nextcur))
-	}
+// filterChannelsByPrefix keeps channels whose name starts with prefix
+// (case-insensitive). A leading # on either side is ignored.
+func filterChannelsByPrefix(channels []provider.Channel, prefix string) []provider.Channel {
+	prefix = strings.ToLower(strings.TrimPrefix(prefix, "#"))
 
-	csvBytes, err := gocsv.MarshalBytes(&channelList)
-	if err != nil {
-		ch.logger.Error("Failed to marshal channels to CSV", zap.Error(err))
-		return nil, err
+	var result []provider.Channel
+	for _, c := range channels {
+		if strings.HasPrefix(strings.ToLower(strings.TrimPrefix(c.Name, "#")), prefix) {
+			result = append(result, c)
+		}
 	}
-
-	return mcp.NewToolResultText(string(csvBytes)), nil
+	return result
 }
 
 func filterChannelsByTypes(channels map[string]provider.Channel, types []string) []provider.Channel {
@@ -311,3 +1526,98 @@ func paginateChannels(channels []provider.Channel, cursor string, limit int) ([]
 
 	return paged, nextCursor
 }
+
+// paginateChannelsByActivity is paginateChannels for results ordered by their
+// latest message, most recent first. Channels without activity data, i.e.
+// those the user is not a member of, come last; ties are ordered by ID. The
+// cursor holds the activity time and ID of the last channel returned.
+func paginateChannelsByActivity(channels []provider.Channel, latest map[string]time.Time, cursor string, limit int) ([]provider.Channel, string) {
+	key := func(id string) int64 {
+		if t := latest[id]; !t.IsZero() {
+			return t.UnixNano()
+		}
+		return 0
+	}
+	// after reports whether the channel at (ts, id) comes after (lastTs, lastID)
+	after := func(ts int64, id string, lastTs int64, lastID string) bool {
+		if ts != lastTs {
+			return ts < lastTs
+		}
+		return id > lastID
+	}
+	sort.Slice(channels, func(i, j int) bool {
+		return after(key(channels[j].ID), channels[j].ID, key(channels[i].ID), channels[i].ID)
+	})
+
+	startIndex := 0
+	if cursor != "" {
+		decoded, err := base64.StdEncoding.DecodeString(cursor)
+		var (
+			lastTs int64
+			lastID string
+		)
+		if err == nil {
+			rawTs, id, found := strings.Cut(string(decoded), "/")
+			lastTs, err = strconv.ParseInt(rawTs, 10, 64)
+			if err == nil && !found {
+				err = errors.New("missing channel ID")
+			}
+			lastID = id
+		}
+		if err == nil {
+			startIndex = sort.Search(len(channels), func(i int) bool {
+				return after(key(channels[i].ID), channels[i].ID, lastTs, lastID)
+			})
+		} else {
+			zap.L().Warn("Failed to decode cursor",
+				zap.String("cursor", cursor),
+				zap.Error(err),
+			)
+		}
+	}
+
+	endIndex := min(startIndex+limit, len(channels))
+	paged := channels[startIndex:endIndex]
+
+	var nextCursor string
+	if endIndex < len(channels) {
+		last := channels[endIndex-1]
+		nextCursor = base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%d/%s", key(last.ID), last.ID)))
+	}
+	return paged, nextCursor
+}
+
+// paginateChannelsByName is paginateChannels for results ordered by name. The
+// cursor holds the name of the last channel returned.
+func paginateChannelsByName(channels []provider.Channel, cursor string, limit int) ([]provider.Channel, string) {
+	sort.Slice(channels, func(i, j int) bool {
+		if channels[i].Name != channels[j].Name {
+			return channels[i].Name < channels[j].Name
+		}
+		return channels[i].ID < channels[j].ID
+	})
+
+	startIndex := 0
+	if cursor != "" {
+		if decoded, err := base64.StdEncoding.DecodeString(cursor); err == nil {
+			lastName := string(decoded)
+			startIndex = sort.Search(len(channels), func(i int) bool {
+				return channels[i].Name > lastName
+			})
+		} else {
+			zap.L().Warn("Failed to decode cursor",
+				zap.String("cursor", cursor),
+				zap.Error(err),
+			)
+		}
+	}
+
+	endIndex := min(startIndex+limit, len(channels))
+	paged := channels[startIndex:endIndex]
+
+	var nextCursor string
+	if endIndex < len(channels) {
+		nextCursor = base64.StdEncoding.EncodeToString([]byte(channels[endIndex-1].Name))
+	}
+	return paged, nextCursor
+}
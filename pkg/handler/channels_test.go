@@ -3,6 +3,7 @@ package handler
 import (
 	"context"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -11,11 +12,17 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/korotovsky/slack-mcp-server/pkg/provider/edge"
+	"github.com/korotovsky/slack-mcp-server/pkg/provider/edge/fasttime"
 	"github.com/korotovsky/slack-mcp-server/pkg/test/util"
 	"github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/slack-go/slack"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
 )
 
 type testEnv struct {
@@ -161,3 +168,607 @@ func TestIntegrationPrivateChannelsList(t *testing.T) {
 
 	runChannelTest(t, env, "private_channel", expectedChannels)
 }
+
+func TestUnitFilterChannelsByName(t *testing.T) {
+	channels := []provider.Channel{
+		{ID: "C1", Name: "#project-apollo"},
+		{ID: "C2", Name: "#Project-Gemini"},
+		{ID: "C3", Name: "#random"},
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		wantIDs []string
+	}{
+		{"empty query keeps all", "", []string{"C1", "C2", "C3"}},
+		{"substring match is case-insensitive", "project", []string{"C1", "C2"}},
+		{"hash prefix is ignored", "#random", []string{"C3"}},
+		{"no match", "unknown", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ids []string
+			for _, c := range filterChannelsByName(channels, tt.query) {
+				ids = append(ids, c.ID)
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+		})
+	}
+}
+
+func TestUnitChannelsByPrefix(t *testing.T) {
+	channels := []provider.Channel{
+		{ID: "C1", Name: "#inc-2024-db-outage"},
+		{ID: "C2", Name: "#random"},
+		{ID: "C3", Name: "#INC-2023-dns"},
+		{ID: "C4", Name: "#team-inc"},
+		{ID: "C5", Name: "#inc-2024-api-latency"},
+		{ID: "C6", Name: "#proj-inc-tracker"},
+	}
+
+	names := func(chans []provider.Channel) []string {
+		var out []string
+		for _, c := range chans {
+			out = append(out, c.Name)
+		}
+		return out
+	}
+
+	matched := filterChannelsByPrefix(channels, "#inc-")
+	page, next := paginateChannelsByName(matched, "", 100)
+	assert.Equal(t, []string{"#INC-2023-dns", "#inc-2024-api-latency", "#inc-2024-db-outage"}, names(page),
+		"only prefix matches are returned, sorted by name")
+	assert.Empty(t, next)
+
+	t.Run("pages continue in name order", func(t *testing.T) {
+		matched := filterChannelsByPrefix(channels, "inc-")
+		first, next := paginateChannelsByName(matched, "", 2)
+		assert.Equal(t, []string{"#INC-2023-dns", "#inc-2024-api-latency"}, names(first))
+		require.NotEmpty(t, next)
+
+		second, next := paginateChannelsByName(matched, next, 2)
+		assert.Equal(t, []string{"#inc-2024-db-outage"}, names(second))
+		assert.Empty(t, next)
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		assert.Empty(t, filterChannelsByPrefix(channels, "ops-"))
+	})
+}
+
+func TestUnitResolveInviteUsers(t *testing.T) {
+	users := &provider.UsersCache{
+		Users: map[string]slack.User{
+			"U1": {ID: "U1", Name: "alice"},
+			"U2": {ID: "U2", Name: "bob"},
+		},
+		UsersInv: map[string]string{
+			"alice": "U1",
+			"bob":   "U2",
+		},
+	}
+
+	tests := []struct {
+		name        string
+		raw         string
+		wantIDs     []string
+		wantFailure []string
+	}{
+		{"user IDs", "U1,U2", []string{"U1", "U2"}, nil},
+		{"handles with and without @", "@alice, bob", []string{"U1", "U2"}, nil},
+		{"mention syntax", "<@U2>", []string{"U2"}, nil},
+		{"duplicates are invited once", "U1,@alice", []string{"U1"}, nil},
+		{"unknown users are reported", "@alice,@nobody,U9", []string{"U1"}, []string{"@nobody", "U9"}},
+		{"empty items are skipped", ",U1,,", []string{"U1"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolved, failed := resolveInviteUsers(tt.raw, users)
+
+			var ids []string
+			for _, u := range resolved {
+				ids = append(ids, u.userID)
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+
+			var failedUsers []string
+			for _, f := range failed {
+				assert.Equal(t, "failed", f.Status)
+				assert.Equal(t, "user not found", f.Error)
+				failedUsers = append(failedUsers, f.User)
+			}
+			assert.Equal(t, tt.wantFailure, failedUsers)
+		})
+	}
+}
+
+func TestUnitInviteEach(t *testing.T) {
+	users := []resolvedInvitee{
+		{user: "@alice", userID: "U1"},
+		{user: "@bob", userID: "U2"},
+		{user: "U3", userID: "U3"},
+	}
+
+	var called []string
+	results := inviteEach(users, func(userID string) error {
+		called = append(called, userID)
+		if userID == "U2" {
+			return fmt.Errorf("already_in_channel")
+		}
+		return nil
+	})
+
+	assert.Equal(t, []string{"U1", "U2", "U3"}, called, "every user is invited even after a failure")
+	require.Len(t, results, 3)
+	assert.Equal(t, InviteResult{User: "@alice", UserID: "U1", Status: "invited"}, results[0])
+	assert.Equal(t, InviteResult{User: "@bob", UserID: "U2", Status: "failed", Error: "already_in_channel"}, results[1])
+	assert.Equal(t, InviteResult{User: "U3", UserID: "U3", Status: "invited"}, results[2])
+}
+
+func TestUnitRefreshMemberCounts(t *testing.T) {
+	all := []provider.Channel{
+		{ID: "C1", Name: "#alpha", MemberCount: 0},
+		{ID: "C2", Name: "#beta", MemberCount: 3},
+		{ID: "C3", Name: "#gamma", MemberCount: 0},
+	}
+	page, _ := paginateChannels(all, "", 2)
+	channels := make([]Channel, 0, len(page))
+	for _, c := range page {
+		channels = append(channels, Channel{ID: c.ID, Name: c.Name, MemberCount: c.MemberCount})
+	}
+
+	var fetched []string
+	fetch := func(ctx context.Context, id string) (*slack.Channel, error) {
+		fetched = append(fetched, id)
+		if id == "C2" {
+			return nil, fmt.Errorf("channel_not_found")
+		}
+		ch := &slack.Channel{}
+		ch.NumMembers = 42
+		return ch, nil
+	}
+	// A burst of exactly one token per page entry and no refill during the test
+	rl := rate.NewLimiter(rate.Every(time.Hour), len(channels))
+
+	skipped := refreshMemberCounts(context.Background(), channels, rl, fetch, zap.NewNop())
+
+	assert.Zero(t, skipped)
+	assert.Equal(t, []string{"C1", "C2"}, fetched, "only the returned page is refreshed")
+	assert.Equal(t, 42, channels[0].MemberCount)
+	assert.Equal(t, 3, channels[1].MemberCount, "failed lookups keep the cached count")
+	assert.False(t, rl.Allow(), "every lookup goes through the limiter")
+
+	t.Run("large pages are capped", func(t *testing.T) {
+		channels := make([]Channel, maxMemberCountRefresh+7)
+		for i := range channels {
+			channels[i] = Channel{ID: fmt.Sprintf("C%d", i), MemberCount: 1}
+		}
+		calls := 0
+		fetch := func(ctx context.Context, id string) (*slack.Channel, error) {
+			calls++
+			ch := &slack.Channel{}
+			ch.NumMembers = 42
+			return ch, nil
+		}
+
+		skipped := refreshMemberCounts(context.Background(), channels, rate.NewLimiter(rate.Inf, 0), fetch, zap.NewNop())
+
+		assert.Equal(t, 7, skipped)
+		assert.Equal(t, maxMemberCountRefresh, calls)
+		assert.Equal(t, 42, channels[maxMemberCountRefresh-1].MemberCount)
+		assert.Equal(t, 1, channels[maxMemberCountRefresh].MemberCount, "channels past the cap keep the cached count")
+	})
+}
+
+func TestUnitMemberCounts(t *testing.T) {
+	channels := map[string]provider.Channel{
+		"C1": {ID: "C1", Name: "#alpha", MemberCount: 12},
+		"C2": {ID: "C2", Name: "#beta", MemberCount: 0},
+		"C3": {ID: "C3", Name: "#gamma", MemberCount: 7},
+	}
+
+	var fetched []string
+	fetch := func(ctx context.Context, id string) (*slack.Channel, error) {
+		fetched = append(fetched, id)
+		if id == "C5" {
+			return nil, fmt.Errorf("channel_not_found")
+		}
+		ch := &slack.Channel{}
+		ch.Name = "delta"
+		ch.NumMembers = 42
+		return ch, nil
+	}
+	// Only the cache misses may consume a token
+	rl := rate.NewLimiter(rate.Every(time.Hour), 3)
+
+	counts := memberCounts(context.Background(), []string{"C1", "C2", "C3", "C4", "C5"}, channels, rl, fetch, zap.NewNop())
+
+	assert.Equal(t, []string{"C2", "C4", "C5"}, fetched, "only channels missing or zero in the cache are fetched")
+	assert.Equal(t, []ChannelMemberCount{
+		{ID: "C1", Name: "#alpha", MemberCount: 12, Source: "cache"},
+		{ID: "C2", Name: "#beta", MemberCount: 42, Source: "api"},
+		{ID: "C3", Name: "#gamma", MemberCount: 7, Source: "cache"},
+		{ID: "C4", Name: "#delta", MemberCount: 42, Source: "api"},
+		{ID: "C5", Source: "unavailable"},
+	}, counts)
+	assert.False(t, rl.Allow(), "every lookup goes through the limiter")
+}
+
+func TestUnitChannelTypePolicy(t *testing.T) {
+	channels := map[string]provider.Channel{
+		"C1": {ID: "C1", Name: "#general"},
+		"G1": {ID: "G1", Name: "#secret", IsPrivate: true},
+		"D1": {ID: "D1", Name: "@alice", IsIM: true, User: "U1"},
+		"G2": {ID: "G2", Name: "mpdm-alice--bob-1", IsMpIM: true},
+	}
+
+	t.Run("unset allows everything", func(t *testing.T) {
+		t.Setenv("SLACK_MCP_ALLOWED_CHANNEL_TYPES", "")
+		policy := allowedChannelTypes()
+		assert.Nil(t, policy)
+		assert.NoError(t, policy.check("D1", channels))
+	})
+
+	t.Run("channels_list excludes disallowed types", func(t *testing.T) {
+		t.Setenv("SLACK_MCP_ALLOWED_CHANNEL_TYPES", "public_channel, private_channel")
+		listed := filterChannelsByPolicy(filterChannelsByTypes(channels, provider.AllChanTypes), allowedChannelTypes())
+
+		var ids []string
+		for _, c := range listed {
+			ids = append(ids, c.ID)
+		}
+		assert.ElementsMatch(t, []string{"C1", "G1"}, ids)
+	})
+
+	t.Run("history refuses a DM", func(t *testing.T) {
+		t.Setenv("SLACK_MCP_ALLOWED_CHANNEL_TYPES", "public_channel,private_channel")
+		policy := allowedChannelTypes()
+
+		err := policy.check("D1", channels)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `channel "D1" is of type im`)
+		assert.Contains(t, err.Error(), "SLACK_MCP_ALLOWED_CHANNEL_TYPES")
+
+		assert.Error(t, policy.check("D9", channels), "uncached DMs are recognized by their prefix")
+		assert.Error(t, policy.check("G2", channels))
+		assert.NoError(t, policy.check("C1", channels))
+		assert.NoError(t, policy.check("G1", channels))
+	})
+
+	t.Run("uncached channels are refused while a policy is set", func(t *testing.T) {
+		t.Setenv("SLACK_MCP_ALLOWED_CHANNEL_TYPES", "public_channel")
+		err := allowedChannelTypes().check("C9", channels)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not in the channels cache")
+
+		t.Setenv("SLACK_MCP_ALLOWED_CHANNEL_TYPES", "")
+		assert.NoError(t, allowedChannelTypes().check("C9", channels))
+	})
+
+	t.Run("unknown types are rejected at startup", func(t *testing.T) {
+		assert.NoError(t, ValidateAllowedChannelTypes(""))
+		assert.NoError(t, ValidateAllowedChannelTypes("public_channel, im"))
+
+		err := ValidateAllowedChannelTypes("public_chanel,im,dm")
+		require.Error(t, err)
+		assert.EqualError(t, err, "invalid channel type(s): dm, public_chanel. Valid types are: mpim, im, public_channel, private_channel")
+	})
+
+	t.Run("activity rows and DM types follow the policy", func(t *testing.T) {
+		t.Setenv("SLACK_MCP_ALLOWED_CHANNEL_TYPES", "public_channel,mpim")
+		policy := allowedChannelTypes()
+
+		items := filterActivityByPolicy([]ActivityItem{
+			{ChannelID: "C1"}, {ChannelID: "D1"}, {ChannelID: "G2"}, {ChannelID: "C9"},
+		}, policy, channels)
+		var ids []string
+		for _, item := range items {
+			ids = append(ids, item.ChannelID)
+		}
+		assert.Equal(t, []string{"C1", "G2"}, ids)
+
+		assert.Equal(t, []string{"mpim"}, dmTypesAllowed(policy))
+		assert.Equal(t, []string{"im", "mpim"}, dmTypesAllowed(nil))
+		assert.Empty(t, dmTypesAllowed(channelTypePolicy{provider.PubChanType: true}))
+	})
+}
+
+func TestUnitValidateChannelName(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{"valid name", "project-apollo", "project-apollo", ""},
+		{"hash prefix is stripped", "#team_x", "team_x", ""},
+		{"surrounding spaces are trimmed", "  ops  ", "ops", ""},
+		{"empty", "", "", "name is required"},
+		{"only hash", "#", "", "name is required"},
+		{"uppercase suggests lowercase", "Project", "", `e.g. "project"`},
+		{"spaces suggest hyphens", "my channel", "", `e.g. "my-channel"`},
+		{"periods suggest hyphens", "v1.2", "", `e.g. "v1-2"`},
+		{"too long", strings.Repeat("a", 81), "", "too long"},
+		{"max length", strings.Repeat("a", 80), strings.Repeat("a", 80), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := validateChannelName(tt.input)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestUnitAllowsNewChannels(t *testing.T) {
+	assert.True(t, allowsNewChannels("true"))
+	assert.True(t, allowsNewChannels("1"))
+	assert.True(t, allowsNewChannels(" !C1234567890, !C0987654321"), "an exclusion list cannot exclude a new channel")
+	assert.False(t, allowsNewChannels("C1234567890,C0987654321"), "an allowlist names existing channels only")
+	assert.False(t, allowsNewChannels(""))
+}
+
+func TestUnitChannelAdminToolConfig(t *testing.T) {
+	t.Setenv("SLACK_MCP_CHANNEL_ADMIN_TOOL", "")
+	t.Setenv("SLACK_MCP_ENABLED_TOOLS", "channels_unarchive, channels_create")
+
+	assert.Equal(t, "true", channelAdminToolConfig("channels_unarchive"))
+	assert.Equal(t, "true", channelAdminToolConfig("channels_create"))
+	assert.Empty(t, channelAdminToolConfig("channels_archive"), "channels_unarchive must not enable channels_archive")
+
+	t.Setenv("SLACK_MCP_CHANNEL_ADMIN_TOOL", "C1234567890")
+	assert.Equal(t, "C1234567890", channelAdminToolConfig("channels_archive"))
+}
+
+func TestUnitRefreshChannelsAfterCreate(t *testing.T) {
+	tests := []struct {
+		name       string
+		refreshErr error
+		wantNote   string
+	}{
+		{"refresh succeeds", nil, ""},
+		{"refresh rate-limited", provider.ErrRefreshRateLimited, "rate-limited"},
+		{"refresh fails", fmt.Errorf("boom"), "failed to refresh channels cache: boom"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			note := refreshChannelsAfterCreate(context.Background(), func(ctx context.Context) error {
+				calls++
+				return tt.refreshErr
+			}, zap.NewNop())
+
+			assert.Equal(t, 1, calls, "cache refresh should be called once")
+			if tt.wantNote == "" {
+				assert.Empty(t, note)
+			} else {
+				assert.Contains(t, note, tt.wantNote)
+			}
+		})
+	}
+}
+
+func TestUnitRequireConfirm(t *testing.T) {
+	t.Run("confirmed", func(t *testing.T) {
+		assert.NoError(t, requireConfirm("archive", "C1", true))
+	})
+
+	t.Run("not confirmed", func(t *testing.T) {
+		err := requireConfirm("archive", "C1", false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `refusing to archive channel "C1"`)
+		assert.Contains(t, err.Error(), "confirm=true")
+	})
+}
+
+func TestUnitTeamSettings(t *testing.T) {
+	info := &slack.TeamInfo{ID: "T1", Name: "Acme", Domain: "acme"}
+	boot := &edge.ClientUserBootResponse{}
+	boot.Team.Prefs.DefaultChannels = []string{"C1", "C9"}
+	boot.Team.Prefs.CanCreateSlackConnectChannelInvite = true
+	boot.Team.Prefs.RetentionType = 1
+	boot.Team.Prefs.RetentionDuration = 90
+	channels := map[string]provider.Channel{"C1": {ID: "C1", Name: "#general"}}
+
+	settingsMap := func(settings []TeamSetting) map[string]string {
+		m := make(map[string]string, len(settings))
+		for _, s := range settings {
+			m[s.Setting] = s.Value
+		}
+		return m
+	}
+
+	t.Run("all sources available", func(t *testing.T) {
+		settings, omitted := teamSettings(info, boot, channels, nil)
+		assert.Empty(t, omitted)
+		assert.Equal(t, map[string]string{
+			"team_id":               "T1",
+			"name":                  "Acme",
+			"domain":                "acme",
+			"default_channels":      "#general C9",
+			"slack_connect_enabled": "true",
+			"message_retention":     "90 days",
+			"file_retention":        "keep all",
+		}, settingsMap(settings))
+	})
+
+	t.Run("team preferences missing scope", func(t *testing.T) {
+		settings, omitted := teamSettings(info, nil, channels, nil)
+		m := settingsMap(settings)
+		assert.Equal(t, "Acme", m["name"])
+		for _, key := range []string{"default_channels", "slack_connect_enabled", "message_retention", "file_retention"} {
+			_, ok := m[key]
+			assert.False(t, ok, key)
+			assert.Contains(t, omitted, key)
+		}
+	})
+
+	t.Run("team info missing scope", func(t *testing.T) {
+		settings, omitted := teamSettings(nil, boot, channels, nil)
+		m := settingsMap(settings)
+		_, ok := m["team_id"]
+		assert.False(t, ok)
+		assert.Equal(t, []string{"team_id", "name", "domain"}, omitted)
+		assert.Equal(t, "true", m["slack_connect_enabled"])
+	})
+
+	t.Run("default channels follow the channel type policy", func(t *testing.T) {
+		private := map[string]provider.Channel{"C1": {ID: "C1", Name: "#general", IsPrivate: true}}
+		settings, _ := teamSettings(info, boot, private, channelTypePolicy{provider.PubChanType: true})
+		assert.Equal(t, "C9", settingsMap(settings)["default_channels"])
+	})
+}
+
+func TestUnitDefaultChannels(t *testing.T) {
+	channels := map[string]provider.Channel{
+		"C1": {ID: "C1", Name: "#general", Topic: "Company-wide", MemberCount: 120},
+		"C2": {ID: "C2", Name: "#onboarding", IsPrivate: true, MemberCount: 15},
+	}
+
+	t.Run("boot unavailable degrades to a note", func(t *testing.T) {
+		list, note := defaultChannels(nil, errors.New("not_allowed_token_type"), channels, nil)
+		assert.Empty(t, list)
+		assert.Contains(t, note, "not available to this token")
+		assert.Contains(t, note, "not_allowed_token_type")
+	})
+
+	boot := &edge.ClientUserBootResponse{}
+	boot.Team.Prefs.DefaultChannels = []string{"C1", "C2", "C9"}
+
+	t.Run("defaults are resolved from the cache", func(t *testing.T) {
+		list, note := defaultChannels(boot, nil, channels, nil)
+		assert.Empty(t, note)
+		assert.Equal(t, []Channel{
+			{ID: "C1", Name: "#general", Topic: "Company-wide", MemberCount: 120},
+			{ID: "C2", Name: "#onboarding", MemberCount: 15},
+			{ID: "C9"},
+		}, list)
+	})
+
+	t.Run("channel type policy applies", func(t *testing.T) {
+		list, _ := defaultChannels(boot, nil, channels, channelTypePolicy{provider.PubChanType: true})
+		require.Len(t, list, 2)
+		assert.Equal(t, "C1", list[0].ID)
+		assert.Equal(t, "C9", list[1].ID)
+	})
+
+	t.Run("no defaults configured", func(t *testing.T) {
+		list, note := defaultChannels(&edge.ClientUserBootResponse{}, nil, channels, nil)
+		assert.Empty(t, list)
+		assert.Equal(t, "The workspace has no default channels configured", note)
+	})
+}
+
+func TestUnitChannelResources(t *testing.T) {
+	canvas := slack.File{ID: "F1", Title: "Runbook", Filetype: "quip", PrettyType: "Canvas", Permalink: "https://example.slack.com/docs/T1/F1", Created: 1700000000, Timestamp: 1700000500}
+	post := slack.File{ID: "F2", Name: "retro.post", Filetype: "space", PrettyType: "Post", Created: 1700000100}
+	image := slack.File{ID: "F3", Title: "screenshot.png", Filetype: "png", PrettyType: "PNG"}
+	pdf := slack.File{ID: "F4", Title: "Contract", Filetype: "pdf", PrettyType: "PDF", Created: 1700000200}
+
+	pins := []slack.Item{
+		{Type: "file", File: &pdf},
+		{Type: "message", Message: &slack.Message{}},
+		{Type: "file", File: &canvas},
+		{Type: "file", File: &pdf},
+	}
+	files := []slack.File{canvas, post, image, pdf}
+
+	resources := channelResources(pins, files, true)
+	assert.Equal(t, []ChannelResource{
+		{ID: "F4", Title: "Contract", Type: "PDF", Updated: formatJSONTime(1700000200), Pinned: true},
+		{ID: "F1", Title: "Runbook", Type: "Canvas", Permalink: "https://example.slack.com/docs/T1/F1", Updated: formatJSONTime(1700000500), Pinned: true},
+		{ID: "F2", Title: "retro.post", Type: "Post", Updated: formatJSONTime(1700000100)},
+	}, resources, "pinned files first, then unpinned canvases and posts, each once")
+
+	t.Run("later pages leave out pinned files", func(t *testing.T) {
+		resources := channelResources(pins, files, false)
+		require.Len(t, resources, 1)
+		assert.Equal(t, "F2", resources[0].ID)
+	})
+}
+
+func TestUnitFilterChannelsActiveSince(t *testing.T) {
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+	since := now.AddDate(0, 0, -7)
+
+	counts := edge.ClientCountsResponse{
+		Channels: []edge.ChannelSnapshot{
+			{ID: "C1", Latest: fasttime.Time(now.Add(-time.Hour))},
+			{ID: "C2", Latest: fasttime.Time(now.AddDate(0, -3, 0))},
+			{ID: "C3", Latest: fasttime.Time(since)},
+			{ID: "C4"},
+		},
+		MPIMs: []edge.ChannelSnapshot{{ID: "G1", Latest: fasttime.Time(now.AddDate(0, 0, -2))}},
+		IMs:   []edge.ChannelSnapshot{{ID: "D1", Latest: fasttime.Time(now.AddDate(-1, 0, 0))}},
+	}
+	channels := []provider.Channel{
+		{ID: "C1", Name: "#incidents"},
+		{ID: "C2", Name: "#old-project"},
+		{ID: "C3", Name: "#weekly"},
+		{ID: "C4", Name: "#never-used"},
+		{ID: "C5", Name: "#not-a-member"},
+		{ID: "G1", Name: "@mpdm-a--b"},
+		{ID: "D1", Name: "@someone"},
+	}
+
+	kept, unknown := filterChannelsActiveSince(channels, latestActivity(counts), since)
+
+	var ids []string
+	for _, c := range kept {
+		ids = append(ids, c.ID)
+	}
+	assert.Equal(t, []string{"C1", "C3", "G1"}, ids, "channels with stale or no latest message are excluded")
+	assert.Equal(t, 1, unknown, "channels missing from client.counts are counted")
+}
+
+func TestUnitPaginateChannelsByActivity(t *testing.T) {
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	counts := edge.ClientCountsResponse{
+		Channels: []edge.ChannelSnapshot{
+			{ID: "C1", Latest: fasttime.Time(now.AddDate(0, -1, 0))},
+			{ID: "C2", Latest: fasttime.Time(now.Add(-time.Hour))},
+			{ID: "C4"},
+		},
+		MPIMs: []edge.ChannelSnapshot{{ID: "G1", Latest: fasttime.Time(now.AddDate(0, 0, -2))}},
+	}
+	channels := []provider.Channel{
+		{ID: "C1", Name: "#quiet", MemberCount: 500},
+		{ID: "C3", Name: "#not-a-member", MemberCount: 900},
+		{ID: "C4", Name: "#never-used", MemberCount: 3},
+		{ID: "G1", Name: "@mpdm-a--b", MemberCount: 3},
+		{ID: "C2", Name: "#incidents", MemberCount: 20},
+	}
+	ids := func(channels []provider.Channel) []string {
+		var ids []string
+		for _, c := range channels {
+			ids = append(ids, c.ID)
+		}
+		return ids
+	}
+
+	page, next := paginateChannelsByActivity(channels, latestActivity(counts), "", 10)
+	assert.Equal(t, []string{"C2", "G1", "C1", "C3", "C4"}, ids(page), "most recent first, channels without activity at the end")
+	assert.Empty(t, next)
+
+	t.Run("pages continue the activity order", func(t *testing.T) {
+		latest := latestActivity(counts)
+		var got []string
+		cursor := ""
+		for range 5 {
+			page, next := paginateChannelsByActivity(channels, latest, cursor, 2)
+			got = append(got, ids(page)...)
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+		assert.Equal(t, []string{"C2", "G1", "C1", "C3", "C4"}, got, "the most active channel is on the first page, not wherever its ID falls")
+	})
+}
@@ -4,14 +4,20 @@ import (
 	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math/rand/v2"
 	"net/url"
 	"os"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gocarina/gocsv"
@@ -24,12 +30,17 @@ import (
 	"github.com/slack-go/slack"
 	slackGoUtil "github.com/takara2314/slack-go-util"
 	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
 )
 
 const (
 	defaultConversationsNumericLimit    = 50
 	defaultConversationsExpressionLimit = "1d"
 	maxFileSizeBytes                    = 5 * 1024 * 1024 // 5MB limit
+	defaultRecentActivityDays           = 7
+	maxSearchThreadRoots                = 10
+	maxSearchExpandThreads              = 3
+	searchThreadMaxMessages             = 50
 )
 
 var validFilterKeys = map[string]struct{}{
@@ -57,9 +68,27 @@ type Message struct {
 	FileCount     int    `json:"fileCount,omitempty"`
 	AttachmentIDs string `json:"attachmentIDs,omitempty"`
 	HasMedia      bool   `json:"hasMedia,omitempty"`
+	ClientMsgID   string `json:"clientMsgID,omitempty"`
+	Subtype       string `json:"subtype,omitempty"`
+	Subscribed    *bool  `json:"subscribed,omitempty"`
+	IsUnread      bool   `json:"isUnread,omitempty"`
+	AvatarURL     string `json:"avatarURL,omitempty"`
+	Team          string `json:"team,omitempty"`
+	IsExternal    bool   `json:"isExternal,omitempty"`
+	IsContext     bool   `json:"isContext,omitempty"`
 	Cursor        string `json:"cursor"`
 }
 
+type MessageLink struct {
+	URL      string `json:"url"`
+	MsgID    string `json:"msgID"`
+	UserID   string `json:"userID"`
+	UserName string `json:"userUser"`
+	Channel  string `json:"channelID"`
+	Time     string `json:"time"`
+	Cursor   string `json:"cursor"`
+}
+
 type User struct {
 	UserID   string `json:"userID"`
 	UserName string `json:"userName"`
@@ -77,18 +106,77 @@ type UserSearchResult struct {
 }
 
 type conversationParams struct {
-	channel  string
-	limit    int
-	oldest   string
-	latest   string
-	cursor   string
-	activity bool
+	channel        string
+	limit          int
+	oldest         string
+	latest         string
+	cursor         string
+	activity       bool
+	order          string
+	reactionUsers  bool
+	clientMsgID    bool
+	subtype        bool
+	avatars        bool
+	teams          bool
+	unreadBoundary bool
+	calls          bool
+	responseFormat string
+	linksOnly      bool
+	authors        []string
+	dailyAnchors   bool
+}
+
+// messageColumns lists the optional Message columns the params enable
+func (p *conversationParams) messageColumns() []string {
+	var columns []string
+	if p.clientMsgID {
+		columns = append(columns, colClientMsgID)
+	}
+	if p.subtype {
+		columns = append(columns, colSubtype)
+	}
+	if p.unreadBoundary {
+		columns = append(columns, colIsUnread)
+	}
+	if p.avatars {
+		columns = append(columns, colAvatarURL)
+	}
+	if p.teams {
+		columns = append(columns, colTeam, colIsExternal)
+	}
+	return columns
+}
+
+// messageColumns lists the optional Message columns the search params enable
+func (p *searchParams) messageColumns() []string {
+	var columns []string
+	if p.avatars {
+		columns = append(columns, colAvatarURL)
+	}
+	if p.includeThreadRoot {
+		columns = append(columns, colIsContext)
+	}
+	return columns
 }
 
 type searchParams struct {
-	query string
-	limit int
-	page  int
+	query             string
+	limit             int
+	page              int
+	includeThreadRoot bool
+	expandThreads     int
+	myChannelsOnly    bool
+	resolveChannels   bool
+	reactions         bool
+	avatars           bool
+	deepSearch        bool
+	countOnly         bool
+	groupByChannel    bool
+	maxResults        int
+	sort              string
+	sortDir           string
+	freeText          []string
+	filters           map[string][]string
 }
 
 type addMessageParams struct {
@@ -96,6 +184,9 @@ type addMessageParams struct {
 	threadTs    string
 	text        string
 	contentType string
+	reactions   []string
+	autoJoin    bool
+	markRead    *bool // nil when mark_read was not given
 }
 
 type addReactionParams struct {
@@ -104,6 +195,11 @@ type addReactionParams struct {
 	emoji     string
 }
 
+type savedAddParams struct {
+	channel string
+	item    slack.ItemRef
+}
+
 type filesGetParams struct {
 	fileID string
 }
@@ -120,6 +216,8 @@ type unreadsParams struct {
 	maxMessagesPerChannel int
 	mentionsOnly          bool
 	includeMuted          bool
+	groupByChannel        bool
+	countsOnly            bool
 	mutedChannels         map[string]bool // populated at runtime from Slack prefs
 	mutedUnavailable      bool            // true when muted channels could not be fetched (e.g. xoxp token)
 }
@@ -131,12 +229,18 @@ type markParams struct {
 type ConversationsHandler struct {
 	apiProvider *provider.ApiProvider
 	logger      *zap.Logger
+	timeFormat  text.TimeFormat
 }
 
 func NewConversationsHandler(apiProvider *provider.ApiProvider, logger *zap.Logger) *ConversationsHandler {
+	timeFormat, err := text.ParseTimeFormat(os.Getenv("SLACK_MCP_DATE_FORMAT"), os.Getenv("SLACK_MCP_TIMEZONE"))
+	if err != nil {
+		logger.Warn("Invalid SLACK_MCP_TIMEZONE, showing times in UTC", zap.Error(err))
+	}
 	return &ConversationsHandler{
 		apiProvider: apiProvider,
 		logger:      logger,
+		timeFormat:  timeFormat,
 	}
 }
 
@@ -216,24 +320,30 @@ func (ch *ConversationsHandler) ConversationsAddMessageHandler(ctx context.Conte
 		return nil, err
 	}
 
+	// With auto_join a missing membership is fixed by joining, so there is
+	// nothing to check up front.
+	if !params.autoJoin && envBool("SLACK_MCP_PRECHECK_MEMBERSHIP") {
+		if err := checkMembership(ctx, params.channel, ch.apiProvider.ProvideChannelsMaps().Channels, ch.conversationInfo, ch.apiProvider.SetChannelMember, ch.logger); err != nil {
+			return nil, err
+		}
+	}
+
 	var options []slack.MsgOption
 	if params.threadTs != "" {
 		options = append(options, slack.MsgOptionTS(params.threadTs))
 	}
 
+	prefix, suffix := os.Getenv("SLACK_MCP_MESSAGE_PREFIX"), os.Getenv("SLACK_MCP_MESSAGE_SUFFIX")
 	switch params.contentType {
 	case "text/plain":
 		options = append(options, slack.MsgOptionDisableMarkdown())
-		options = append(options, slack.MsgOptionText(params.text, false))
+		options = append(options, slack.MsgOptionText(wrapMessageText(params.text, prefix, suffix), false))
 	case "text/markdown":
-		blocks, err := slackGoUtil.ConvertMarkdownTextToBlocks(params.text)
+		markdown, err := markdownOptions(params.text, prefix, suffix, os.Getenv("SLACK_MCP_MARKDOWN_FALLBACK"), slackGoUtil.ConvertMarkdownTextToBlocks, ch.logger)
 		if err != nil {
-			ch.logger.Warn("Markdown parsing error", zap.Error(err))
-			options = append(options, slack.MsgOptionDisableMarkdown())
-			options = append(options, slack.MsgOptionText(params.text, false))
-		} else {
-			options = append(options, slack.MsgOptionBlocks(blocks...))
+			return nil, err
 		}
+		options = append(options, markdown...)
 	default:
 		return nil, errors.New("content_type must be either 'text/plain' or 'text/markdown'")
 	}
@@ -251,14 +361,36 @@ func (ch *ConversationsHandler) ConversationsAddMessageHandler(ctx context.Conte
 		zap.String("thread_ts", params.threadTs),
 		zap.String("content_type", params.contentType),
 	)
-	respChannel, respTimestamp, err := ch.apiProvider.Slack().PostMessageContext(ctx, params.channel, options...)
+	post := func() (string, string, error) {
+		return ch.apiProvider.Slack().PostMessageContext(ctx, params.channel, options...)
+	}
+	var join func() error
+	if params.autoJoin {
+		join = func() error {
+			ch.logger.Info("Not in channel, joining before retrying post", zap.String("channel", params.channel))
+			_, _, _, err := ch.apiProvider.Slack().JoinConversationContext(ctx, params.channel)
+			return err
+		}
+	}
+	respChannel, respTimestamp, joined, err := postWithAutoJoin(post, join)
 	if err != nil {
 		ch.logger.Error("Slack PostMessageContext failed", zap.Error(err))
 		return nil, err
 	}
 
-	toolConfig := os.Getenv("SLACK_MCP_ADD_MESSAGE_MARK")
-	if toolConfig == "1" || toolConfig == "true" || toolConfig == "yes" {
+	if len(params.reactions) > 0 {
+		ch.logger.Debug("Adding reactions to posted message",
+			zap.String("channel", respChannel),
+			zap.String("timestamp", respTimestamp),
+			zap.Strings("reactions", params.reactions),
+		)
+		if err := addReactions(ctx, ch.apiProvider.Slack().AddReactionContext, respChannel, respTimestamp, params.reactions); err != nil {
+			ch.logger.Error("Slack AddReactionContext failed", zap.Error(err))
+			return nil, fmt.Errorf("message %s was posted, but adding reactions failed: %w", respTimestamp, err)
+		}
+	}
+
+	if markAfterPost(params.markRead) {
 		err := ch.apiProvider.Slack().MarkConversationContext(ctx, params.channel, respTimestamp)
 		if err != nil {
 			ch.logger.Error("Slack MarkConversationContext failed", zap.Error(err))
@@ -281,8 +413,70 @@ func (ch *ConversationsHandler) ConversationsAddMessageHandler(ctx context.Conte
 	}
 	ch.logger.Debug("Fetched conversation history", zap.Int("message_count", len(history.Messages)))
 
-	messages := ch.convertMessagesFromHistory(history.Messages, historyParams.ChannelID, false)
-	return marshalMessagesToCSV(messages)
+	messages := ch.convertMessagesFromHistory(ctx, history.Messages, historyParams.ChannelID, false)
+	result, err := marshalMessagesToCSV(messages)
+	if err != nil {
+		return nil, err
+	}
+	if joined {
+		result.Content = append(result.Content, mcp.NewTextContent(fmt.Sprintf("Joined channel %s before posting the message.", respChannel)))
+	}
+	return result, nil
+}
+
+// postWithAutoJoin posts via post and, if Slack reports not_in_channel and join
+// is set, joins the channel once and retries the post.
+func postWithAutoJoin(post func() (string, string, error), join func() error) (channel, ts string, joined bool, err error) {
+	channel, ts, err = post()
+	if err == nil || join == nil || !isNotInChannelError(err) {
+		return channel, ts, false, err
+	}
+	if joinErr := join(); joinErr != nil {
+		return "", "", false, fmt.Errorf("failed to join channel after not_in_channel: %w", joinErr)
+	}
+	channel, ts, err = post()
+	return channel, ts, true, err
+}
+
+// conversationInfo fetches channel through the Tier 3 limiter
+func (ch *ConversationsHandler) conversationInfo(ctx context.Context, channel string) (*slack.Channel, error) {
+	return limiter.CallWithRetry(ctx, limiter.Tier3.Limiter(), 2, provider.SlackRetryAfter, func() (*slack.Channel, error) {
+		return ch.apiProvider.Slack().GetConversationInfoContext(ctx, &slack.GetConversationInfoInput{ChannelID: channel})
+	})
+}
+
+// checkMembership rejects a post to a channel the token is not a member of,
+// before Slack does it with a bare not_in_channel. A cached membership is
+// trusted; otherwise conversations.info decides, since the cache may predate a
+// join, and its answer is passed to remember for the next post. When info
+// fails the post is let through and Slack has the final say.
+func checkMembership(ctx context.Context, channel string, channels map[string]provider.Channel, info func(ctx context.Context, channel string) (*slack.Channel, error), remember func(channel string, member bool) bool, logger *zap.Logger) error {
+	if member, _ := channels[channel].Member(); member {
+		return nil
+	}
+	c, err := info(ctx, channel)
+	if err != nil {
+		logger.Warn("Membership pre-check failed, posting anyway", zap.String("channel", channel), zap.Error(err))
+		return nil
+	}
+	member := c.IsMember || c.IsIM || c.IsMpIM
+	if !remember(channel, member) {
+		logger.Debug("Channel not in cache, membership not recorded", zap.String("channel", channel))
+	}
+	if member {
+		return nil
+	}
+	logger.Warn("Not a member of channel, refusing to post", zap.String("channel", channel))
+	return fmt.Errorf("not a member of channel %s: invite the app or user to the channel, "+
+		"or pass auto_join=true with SLACK_MCP_AUTO_JOIN=true to join it before posting", channel)
+}
+
+func isNotInChannelError(err error) bool {
+	var slackErr slack.SlackErrorResponse
+	if errors.As(err, &slackErr) {
+		return slackErr.Err == "not_in_channel"
+	}
+	return err.Error() == "not_in_channel"
 }
 
 // ReactionsAddHandler adds an emoji reaction to a message
@@ -300,6 +494,10 @@ func (ch *ConversationsHandler) ReactionsAddHandler(ctx context.Context, request
 		ch.logger.Error("Failed to parse add-reaction params", zap.Error(err))
 		return nil, err
 	}
+	params.emoji, err = pickReactionEmoji(params.emoji, rand.IntN)
+	if err != nil {
+		return nil, err
+	}
 
 	itemRef := slack.ItemRef{
 		Channel:   params.channel,
@@ -342,6 +540,28 @@ func (ch *ConversationsHandler) ReactionsRemoveHandler(ctx context.Context, requ
 		Timestamp: params.timestamp,
 	}
 
+	if envBool("SLACK_MCP_REACTION_SAFE_REMOVE") {
+		ar, err := ch.apiProvider.Slack().AuthTest()
+		if err != nil {
+			ch.logger.Error("Slack AuthTest failed", zap.Error(err))
+			return nil, err
+		}
+		reactions, err := limiter.CallWithRetry(ctx, limiter.Tier3.Limiter(), 2, provider.SlackRetryAfter, func() ([]slack.ItemReaction, error) {
+			return ch.apiProvider.Slack().GetReactionsContext(ctx, itemRef, slack.GetReactionsParameters{Full: true})
+		})
+		if err != nil {
+			ch.logger.Error("Slack GetReactionsContext failed", zap.Error(err))
+			return nil, fmt.Errorf("failed to check reactions before removing :%s:: %w", params.emoji, err)
+		}
+		if err := checkOwnReaction(reactions, params.emoji, ar.UserID); err != nil {
+			ch.logger.Warn("Refusing to remove a reaction the user did not add",
+				zap.String("channel", params.channel),
+				zap.String("timestamp", params.timestamp),
+				zap.String("emoji", params.emoji))
+			return nil, err
+		}
+	}
+
 	ch.logger.Debug("Removing reaction from Slack message",
 		zap.String("channel", params.channel),
 		zap.String("timestamp", params.timestamp),
@@ -357,6 +577,21 @@ func (ch *ConversationsHandler) ReactionsRemoveHandler(ctx context.Context, requ
 	return mcp.NewToolResultText(fmt.Sprintf("Successfully removed :%s: reaction from message %s in channel %s", params.emoji, params.timestamp, params.channel)), nil
 }
 
+// checkOwnReaction makes sure userID is among the users who reacted with emoji,
+// since Slack only lets a user remove their own reactions.
+func checkOwnReaction(reactions []slack.ItemReaction, emoji, userID string) error {
+	for _, r := range reactions {
+		if r.Name != emoji {
+			continue
+		}
+		if slices.Contains(r.Users, userID) {
+			return nil
+		}
+		return fmt.Errorf("you haven't reacted with :%s: on this message, only your own reactions can be removed; it has %d reaction(s) from other users", emoji, r.Count)
+	}
+	return fmt.Errorf("you haven't reacted with :%s: on this message, it has no :%s: reaction at all", emoji, emoji)
+}
+
 func (ch *ConversationsHandler) UsersSearchHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	ch.logger.Debug("UsersSearchHandler called", zap.Any("params", request.Params))
 
@@ -409,17 +644,13 @@ func (ch *ConversationsHandler) UsersSearchHandler(ctx context.Context, request
 		})
 	}
 
-	if len(results) == 0 {
-		return mcp.NewToolResultText("No users found matching the query."), nil
-	}
-
 	csvBytes, err := gocsv.MarshalBytes(&results)
 	if err != nil {
 		ch.logger.Error("Failed to marshal users to CSV", zap.Error(err))
 		return nil, err
 	}
 
-	return mcp.NewToolResultText(string(csvBytes)), nil
+	return withEmptyResultNote(mcp.NewToolResultText(string(csvBytes)), len(results), "No users found matching the query."), nil
 }
 
 func (ch *ConversationsHandler) FilesGetHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -523,6 +754,27 @@ func (ch *ConversationsHandler) ConversationsHistoryHandler(ctx context.Context,
 		zap.Bool("include_activity", params.activity),
 	)
 
+	if raw := request.GetString("newer_than", ""); raw != "" {
+		newerThan, err := parseSinceToTs(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid newer_than %q: must be an RFC3339 time like 2025-01-02T15:04:05Z or a Slack ts like 1234567890.123456", raw)
+		}
+		if params.cursor != "" {
+			return nil, errors.New("newer_than pages forward and cannot be combined with cursor, which pages backward")
+		}
+		if request.GetString("limit", "") == "" {
+			// The default limit is a time range, which newer_than replaces
+			params.limit = defaultConversationsNumericLimit
+		} else if params.oldest != "" || params.latest != "" {
+			return nil, errors.New("newer_than requires a numeric limit, not a time range such as 1d")
+		}
+		if request.GetString("order", "") == "" {
+			// Following a channel forward reads oldest first unless asked otherwise
+			params.order = "oldest"
+		}
+		return ch.historyNewerThan(ctx, params, newerThan)
+	}
+
 	historyParams := slack.GetConversationHistoryParameters{
 		ChannelID: params.channel,
 		Limit:     params.limit,
@@ -539,140 +791,2438 @@ func (ch *ConversationsHandler) ConversationsHistoryHandler(ctx context.Context,
 
 	ch.logger.Debug("Fetched conversation history", zap.Int("message_count", len(history.Messages)))
 
-	messages := ch.convertMessagesFromHistory(history.Messages, params.channel, params.activity)
+	slackMessages := history.Messages
+	if params.linksOnly {
+		slackMessages = filterMessagesWithLinks(slackMessages)
+		ch.logger.Debug("Filtered history to messages with links", zap.Int("message_count", len(slackMessages)))
+	}
+	if len(params.authors) > 0 {
+		slackMessages = filterMessagesByAuthors(slackMessages, params.authors)
+		ch.logger.Debug("Filtered history to messages by users", zap.Strings("users", params.authors), zap.Int("message_count", len(slackMessages)))
+	}
 
+	messages, unreadNote := ch.enrichHistory(ctx, params, slackMessages)
+
+	// The cursor always pages back in time, whatever the display order
 	if len(messages) > 0 && history.HasMore {
 		messages[len(messages)-1].Cursor = history.ResponseMetaData.NextCursor
 	}
-	return marshalMessagesToCSV(messages)
+	if len(messages) == 0 && (params.linksOnly || len(params.authors) > 0) && history.HasMore {
+		// No row is left to carry the cursor, so hand it back separately
+		what := "with links"
+		if len(params.authors) > 0 {
+			what = "from the given users"
+			if params.linksOnly {
+				what += " with links"
+			}
+		}
+		return mcp.NewToolResultText(fmt.Sprintf(
+			"No messages %s on this page, continue with cursor %q", what, history.ResponseMetaData.NextCursor,
+		)), nil
+	}
+	result, err := marshalMessages(messages, params.responseFormat, params.messageColumns()...)
+	if err != nil {
+		return nil, err
+	}
+	result = withEmptyResultNote(result, len(messages),
+		fmt.Sprintf("No messages matched in %s for the given window", ch.channelLabel(params.channel)))
+	if unreadNote != "" {
+		result.Content = append(result.Content, mcp.NewTextContent(unreadNote))
+	}
+	return result, nil
 }
 
-// ConversationsRepliesHandler streams thread replies as CSV
-func (ch *ConversationsHandler) ConversationsRepliesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	ch.logger.Debug("ConversationsRepliesHandler called", zap.Any("params", request.Params))
+const (
+	// newerThanPageSize is the conversations.history page size used to walk
+	// back to newer_than
+	newerThanPageSize = 200
+	// maxNewerThanPages caps that walk for channels far behind
+	maxNewerThanPages = 10
+)
 
-	params, err := ch.parseParamsToolConversations(ctx, request)
+// historyNewerThan returns up to params.limit messages posted after newerThan,
+// oldest first by default, with the same options as a page of history. The
+// note for the next call names the newest ts, whatever the order.
+func (ch *ConversationsHandler) historyNewerThan(ctx context.Context, params *conversationParams, newerThan string) (*mcp.CallToolResult, error) {
+	slackMessages, complete, err := fetchNewerThan(ctx, newerThan, maxNewerThanPages, func(ctx context.Context, cursor string) ([]slack.Message, string, error) {
+		history, err := ch.apiProvider.Slack().GetConversationHistoryContext(ctx, &slack.GetConversationHistoryParameters{
+			ChannelID: params.channel,
+			Limit:     newerThanPageSize,
+			Oldest:    newerThan,
+			Cursor:    cursor,
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		next := ""
+		if history.HasMore {
+			next = history.ResponseMetaData.NextCursor
+		}
+		return history.Messages, next, nil
+	})
 	if err != nil {
-		ch.logger.Error("Failed to parse replies params", zap.Error(err))
+		ch.logger.Error("GetConversationHistoryContext failed", zap.Error(err))
 		return nil, err
 	}
-	threadTs := request.GetString("thread_ts", "")
-	if threadTs == "" {
-		ch.logger.Error("thread_ts not provided for replies", zap.String("thread_ts", threadTs))
-		return nil, errors.New("thread_ts must be a string")
+
+	if params.linksOnly {
+		slackMessages = filterMessagesWithLinks(slackMessages)
+	}
+	if len(params.authors) > 0 {
+		slackMessages = filterMessagesByAuthors(slackMessages, params.authors)
+	}
+	more := len(slackMessages) > params.limit
+	if more {
+		slackMessages = slackMessages[:params.limit]
 	}
 
-	repliesParams := slack.GetConversationRepliesParameters{
+	// enrichHistory expects Slack's newest first order
+	newestFirst := slices.Clone(slackMessages)
+	slices.Reverse(newestFirst)
+	messages, unreadNote := ch.enrichHistory(ctx, params, newestFirst)
+
+	result, err := marshalMessages(messages, params.responseFormat, params.messageColumns()...)
+	if err != nil {
+		return nil, err
+	}
+	result = withEmptyResultNote(result, len(messages),
+		fmt.Sprintf("No messages in %s are newer than %s", ch.channelLabel(params.channel), newerThan))
+	if unreadNote != "" {
+		result.Content = append(result.Content, mcp.NewTextContent(unreadNote))
+	}
+	if !complete {
+		result.Content = append(result.Content, mcp.NewTextContent(fmt.Sprintf(
+			"More than %d messages are newer than %s; only the newest of them were scanned, so messages right after %s are missing",
+			newerThanPageSize*maxNewerThanPages, newerThan, newerThan,
+		)))
+	}
+	if more {
+		result.Content = append(result.Content, mcp.NewTextContent(fmt.Sprintf(
+			"More messages follow, continue with newer_than=%s", slackMessages[len(slackMessages)-1].Timestamp,
+		)))
+	}
+	return result, nil
+}
+
+// fetchNewerThan collects the messages posted after newerThan, oldest first.
+// Slack pages history newest first, so every page is walked back to newerThan,
+// up to maxPages. complete is false when pages were left unread.
+func fetchNewerThan(
+	ctx context.Context,
+	newerThan string,
+	maxPages int,
+	fetch func(ctx context.Context, cursor string) ([]slack.Message, string, error),
+) ([]slack.Message, bool, error) {
+	var collected []slack.Message
+	cursor := ""
+	complete := false
+	for page := 0; page < maxPages; page++ {
+		msgs, next, err := fetch(ctx, cursor)
+		if err != nil {
+			return nil, false, err
+		}
+		collected = append(collected, msgs...)
+		if next == "" {
+			complete = true
+			break
+		}
+		cursor = next
+	}
+
+	collected = filterMessagesAfter(collected, newerThan)
+	sort.SliceStable(collected, func(i, j int) bool {
+		return compareSlackTs(collected[i].Timestamp, collected[j].Timestamp) < 0
+	})
+	return collected, complete, nil
+}
+
+// enrichHistory converts a page of conversations.history, newest first, to rows
+// and applies the options of params in the same way for every history path.
+// It returns the rows in params.order and the note of mark_unread_boundary.
+func (ch *ConversationsHandler) enrichHistory(ctx context.Context, params *conversationParams, slackMessages []slack.Message) ([]Message, string) {
+	messages := ch.convertMessagesFromHistory(ctx, slackMessages, params.channel, params.activity)
+	if params.calls {
+		messages = ch.withCallSummaries(ctx, messages, slackMessages, params.channel)
+	}
+	if params.reactionUsers {
+		messages = withReactionUsers(messages, slackMessages, ch.apiProvider.ProvideUsersMap().Users)
+	}
+	if params.clientMsgID {
+		messages = withClientMsgIDs(messages, slackMessages)
+	}
+	if params.subtype {
+		messages = withSubtypes(messages, slackMessages)
+	}
+	if params.avatars {
+		messages = withAvatars(messages, slackMessages, ch.apiProvider.ProvideUsersMap().Users)
+	}
+	if params.teams {
+		messages = ch.withAuthorTeams(messages, slackMessages)
+	}
+	unreadNote := ""
+	if params.unreadBoundary {
+		messages, unreadNote = ch.withUnreadBoundary(ctx, params.channel, messages)
+	}
+	if params.dailyAnchors {
+		messages = dailyAnchors(messages, ch.timeFormat)
+		ch.logger.Debug("Reduced history to daily anchors", zap.Int("message_count", len(messages)))
+	}
+	return orderMessages(messages, params.order), unreadNote
+}
+
+// orderMessages returns messages, which Slack delivers newest first, in the
+// requested order. "oldest" reverses them so a conversation reads top to bottom.
+func orderMessages(messages []Message, order string) []Message {
+	if order != "oldest" {
+		return messages
+	}
+	reversed := make([]Message, len(messages))
+	for i, m := range messages {
+		reversed[len(messages)-1-i] = m
+	}
+	return reversed
+}
+
+// ConversationsExtractLinksHandler returns the de-duplicated links shared in a channel as CSV
+func (ch *ConversationsHandler) ConversationsExtractLinksHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("ConversationsExtractLinksHandler called", zap.Any("params", request.Params))
+
+	params, err := ch.parseParamsToolConversations(ctx, request)
+	if err != nil {
+		ch.logger.Error("Failed to parse extract-links params", zap.Error(err))
+		return nil, err
+	}
+
+	historyParams := slack.GetConversationHistoryParameters{
 		ChannelID: params.channel,
-		Timestamp: threadTs,
 		Limit:     params.limit,
 		Oldest:    params.oldest,
 		Latest:    params.latest,
 		Cursor:    params.cursor,
 		Inclusive: false,
 	}
-	replies, hasMore, nextCursor, err := ch.apiProvider.Slack().GetConversationRepliesContext(ctx, &repliesParams)
+	history, err := ch.apiProvider.Slack().GetConversationHistoryContext(ctx, &historyParams)
 	if err != nil {
-		ch.logger.Error("GetConversationRepliesContext failed", zap.Error(err))
+		ch.logger.Error("GetConversationHistoryContext failed", zap.Error(err))
 		return nil, err
 	}
-	ch.logger.Debug("Fetched conversation replies", zap.Int("count", len(replies)))
+	ch.logger.Debug("Fetched conversation history", zap.Int("message_count", len(history.Messages)))
 
-	messages := ch.convertMessagesFromHistory(replies, params.channel, params.activity)
-	if len(messages) > 0 && hasMore {
-		messages[len(messages)-1].Cursor = nextCursor
-	}
-	return marshalMessagesToCSV(messages)
-}
+	messages := ch.convertMessagesFromHistory(ctx, history.Messages, params.channel, false)
+	links := collectMessageLinks(history.Messages, messages)
 
-func (ch *ConversationsHandler) ConversationsSearchHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	ch.logger.Debug("ConversationsSearchHandler called", zap.Any("params", request.Params))
+	if len(links) > 0 && history.HasMore {
+		links[len(links)-1].Cursor = history.ResponseMetaData.NextCursor
+	}
 
-	params, err := ch.parseParamsToolSearch(request)
+	csvBytes, err := gocsv.MarshalBytes(&links)
 	if err != nil {
-		ch.logger.Error("Failed to parse search params", zap.Error(err))
+		ch.logger.Error("Failed to marshal links to CSV", zap.Error(err))
 		return nil, err
 	}
-	ch.logger.Debug("Search params parsed", zap.String("query", params.query), zap.Int("limit", params.limit), zap.Int("page", params.page))
+	return withEmptyResultNote(mcp.NewToolResultText(string(csvBytes)), len(links),
+		fmt.Sprintf("No links found in %s for the given window", ch.channelLabel(params.channel))), nil
+}
 
-	searchParams := slack.SearchParameters{
-		Sort:          slack.DEFAULT_SEARCH_SORT,
-		SortDirection: slack.DEFAULT_SEARCH_SORT_DIR,
-		Highlight:     false,
-		Count:         params.limit,
-		Page:          params.page,
-	}
-	messagesRes, _, err := ch.apiProvider.Slack().SearchContext(ctx, params.query, searchParams)
-	if err != nil {
-		ch.logger.Error("Slack SearchContext failed", zap.Error(err))
-		return nil, err
+// collectMessageLinks extracts links from the raw text of slackMessages and
+// attributes each URL to the first converted message it appears in. Links are
+// parsed from the raw text since converted messages are already rewritten.
+func collectMessageLinks(slackMessages []slack.Message, messages []Message) []MessageLink {
+	rawText := make(map[string]string, len(slackMessages))
+	for _, msg := range slackMessages {
+		rawText[msg.Timestamp] = msg.Text
 	}
-	ch.logger.Debug("Search completed", zap.Int("matches", len(messagesRes.Matches)))
 
-	messages := ch.convertMessagesFromSearch(messagesRes.Matches)
-	if len(messages) > 0 && messagesRes.Pagination.Page < messagesRes.Pagination.PageCount {
-		nextCursor := fmt.Sprintf("page:%d", messagesRes.Pagination.Page+1)
-		messages[len(messages)-1].Cursor = base64.StdEncoding.EncodeToString([]byte(nextCursor))
+	var links []MessageLink
+	seen := make(map[string]bool)
+	for _, msg := range messages {
+		for _, url := range text.ExtractLinks(rawText[msg.MsgID]) {
+			if seen[url] {
+				continue
+			}
+			seen[url] = true
+			links = append(links, MessageLink{
+				URL:      url,
+				MsgID:    msg.MsgID,
+				UserID:   msg.UserID,
+				UserName: msg.UserName,
+				Channel:  msg.Channel,
+				Time:     msg.Time,
+			})
+		}
 	}
-	return marshalMessagesToCSV(messages)
+	return links
 }
 
-// UnreadChannel represents a channel with unread messages
-type UnreadChannel struct {
-	ChannelID   string `json:"channelID"`
-	ChannelName string `json:"channelName"`
-	ChannelType string `json:"channelType"` // "dm", "group_dm", "partner", "internal"
-	UnreadCount int    `json:"unreadCount"`
-	LastRead    string `json:"lastRead"`
-	Latest      string `json:"latest"`
+// AuditEntry is a result row of conversations_audit
+type AuditEntry struct {
+	MsgID    string `json:"msgID"`
+	UserID   string `json:"userID"`
+	UserName string `json:"userUser"`
+	Channel  string `json:"channelID"`
+	Time     string `json:"time"`
+	Action   string `json:"action"` // "edited" or "deleted"
+	EditedAt string `json:"editedAt"`
+	EditedBy string `json:"editedBy"`
+	Text     string `json:"text"`
+	Cursor   string `json:"cursor"`
 }
 
-// UnreadMessage extends Message with channel context
-type UnreadMessage struct {
-	Message
-	ChannelType string `json:"channelType"`
-}
+// ConversationsAuditHandler lists the edited and deleted messages found in a
+// page of channel history. It is best effort: Slack keeps neither deleted
+// content nor the text before an edit.
+func (ch *ConversationsHandler) ConversationsAuditHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("ConversationsAuditHandler called", zap.Any("params", request.Params))
 
-// ConversationsUnreadsHandler returns unread messages across all channels
-func (ch *ConversationsHandler) ConversationsUnreadsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	ch.logger.Debug("ConversationsUnreadsHandler called", zap.Any("params", request.Params))
+	params, err := ch.parseParamsToolConversations(ctx, request)
+	if err != nil {
+		ch.logger.Error("Failed to parse audit params", zap.Error(err))
+		return nil, err
+	}
 
-	params := ch.parseParamsToolUnreads(request)
+	users := ch.apiProvider.ProvideUsersMap().Users
+	return historyPageCSV(ctx, ch, params, func(history []slack.Message) []AuditEntry {
+		// Tombstones are skipped like activity messages unless included
+		messages := ch.convertMessagesFromHistory(ctx, history, params.channel, true)
+		return collectAuditEntries(history, messages, users, ch.timeFormat)
+	}, func(e *AuditEntry) *string { return &e.Cursor },
+		fmt.Sprintf("No edited or deleted messages found in %s for the given window", ch.channelLabel(params.channel)))
+}
 
-	// Fetch muted channels unless the caller wants them included
-	if !params.includeMuted {
-		mutedChannels, err := ch.apiProvider.Slack().GetMutedChannels(ctx)
-		if err != nil {
-			ch.logger.Warn("Failed to fetch muted channels, proceeding without mute filter", zap.Error(err))
-			params.mutedUnavailable = true
-		} else if len(mutedChannels) > 0 {
-			params.mutedChannels = mutedChannels
-			ch.logger.Debug("Loaded muted channels", zap.Int("count", len(mutedChannels)))
-		}
+// collectAuditEntries returns the edited messages and the tombstones left by
+// deleted thread parents among messages, which were converted from
+// slackMessages. Text is the current text of edited messages; the text before
+// an edit is not available from history.
+func collectAuditEntries(slackMessages []slack.Message, messages []Message, users map[string]slack.User, format text.TimeFormat) []AuditEntry {
+	raw := make(map[string]slack.Message, len(slackMessages))
+	for _, msg := range slackMessages {
+		raw[msg.Timestamp] = msg
 	}
 
-	// Route based on token type:
-	// - xoxc/xoxd (browser session): use fast client.counts API
-	// - xoxp (OAuth user): fall back to conversations.info/history approach
-	// - xoxb (bot): not supported — unreads is a user-level concept
-	if ch.apiProvider.IsOAuth() {
-		if ch.apiProvider.IsBotToken() {
-			return nil, fmt.Errorf(
-				"conversations_unreads requires a user token (xoxp) or browser session tokens (xoxc/xoxd); " +
-					"bot tokens (xoxb) do not support unread tracking",
-			)
+	var entries []AuditEntry
+	for _, m := range messages {
+		msg, ok := raw[m.MsgID]
+		if !ok {
+			continue
 		}
-		ch.logger.Info("OAuth token detected, using conversations.info fallback for unreads")
-		return ch.getUnreadsViaConversationsInfo(ctx, params)
+		entry := AuditEntry{
+			MsgID:    m.MsgID,
+			UserID:   m.UserID,
+			UserName: m.UserName,
+			Channel:  m.Channel,
+			Time:     m.Time,
+		}
+		switch {
+		case msg.SubType == "tombstone":
+			entry.Action = "deleted"
+		case msg.Edited != nil:
+			entry.Action = "edited"
+			entry.EditedAt, _ = format.FormatTimestamp(msg.Edited.Timestamp)
+			entry.EditedBy, _, _ = getUserInfo(msg.Edited.User, users)
+			entry.Text = m.Text
+		default:
+			continue
+		}
+		entries = append(entries, entry)
 	}
+	return entries
+}
 
-	counts, err := ch.apiProvider.Slack().ClientCounts(ctx)
+// BotMessage is a result row of conversations_bot_messages
+type BotMessage struct {
+	App     string `json:"app"`
+	BotID   string `json:"botID"`
+	MsgID   string `json:"msgID"`
+	Channel string `json:"channelID"`
+	Time    string `json:"time"`
+	Text    string `json:"text"`
+	Cursor  string `json:"cursor"`
+}
+
+// ConversationsBotMessagesHandler returns the messages posted by bots and apps
+// in a page of channel history, grouped by app, as a feed of automated events
+func (ch *ConversationsHandler) ConversationsBotMessagesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("ConversationsBotMessagesHandler called", zap.Any("params", request.Params))
+
+	params, err := ch.parseParamsToolConversations(ctx, request)
 	if err != nil {
-		ch.logger.Error("ClientCounts failed", zap.Error(err))
-		return nil, fmt.Errorf("failed to get client counts: %v", err)
+		ch.logger.Error("Failed to parse bot-messages params", zap.Error(err))
+		return nil, err
 	}
 
-	return ch.processClientCountsResponse(ctx, params, counts)
+	return historyPageCSV(ctx, ch, params, func(history []slack.Message) []BotMessage {
+		messages := ch.convertMessagesFromHistory(ctx, history, params.channel, false)
+		lookup := ch.botNameLookup(ctx)
+		return groupBotMessages(history, messages, func(msg slack.Message) string {
+			return botAppName(msg, lookup)
+		})
+	}, func(m *BotMessage) *string { return &m.Cursor },
+		fmt.Sprintf("No bot or app messages found in %s for the given window", ch.channelLabel(params.channel)))
+}
+
+// botAppName names the app that posted msg from its bot profile, a lookup of
+// its bot ID or the username it posted as, in that order
+func botAppName(msg slack.Message, lookup func(botID string) string) string {
+	if msg.BotProfile != nil && msg.BotProfile.Name != "" {
+		return msg.BotProfile.Name
+	}
+	if msg.BotID != "" {
+		if name := lookup(msg.BotID); name != "" {
+			return name
+		}
+	}
+	if msg.Username != "" {
+		return msg.Username
+	}
+	return msg.BotID
+}
+
+// isBotMessage reports whether msg was posted by a bot or app
+func isBotMessage(msg slack.Message) bool {
+	return msg.BotID != "" || msg.SubType == "bot_message"
+}
+
+// groupBotMessages keeps the bot messages among messages, which were converted
+// from slackMessages, and groups them by the app named by appName. Apps are
+// ordered by their first message and each keeps its messages in history order.
+func groupBotMessages(slackMessages []slack.Message, messages []Message, appName func(slack.Message) string) []BotMessage {
+	raw := make(map[string]slack.Message, len(slackMessages))
+	for _, msg := range slackMessages {
+		raw[msg.Timestamp] = msg
+	}
+
+	var apps []string
+	byApp := make(map[string][]BotMessage)
+	for _, m := range messages {
+		msg, ok := raw[m.MsgID]
+		if !ok || !isBotMessage(msg) {
+			continue
+		}
+		app := appName(msg)
+		if _, seen := byApp[app]; !seen {
+			apps = append(apps, app)
+		}
+		byApp[app] = append(byApp[app], BotMessage{
+			App:     app,
+			BotID:   msg.BotID,
+			MsgID:   m.MsgID,
+			Channel: m.Channel,
+			Time:    m.Time,
+			Text:    m.Text,
+		})
+	}
+
+	rows := make([]BotMessage, 0, len(messages))
+	for _, app := range apps {
+		rows = append(rows, byApp[app]...)
+	}
+	return rows
+}
+
+// ThreadInfo is a result row of conversations_threads_list
+type ThreadInfo struct {
+	MsgID            string `json:"msgID"`
+	UserID           string `json:"userID"`
+	UserName         string `json:"userUser"`
+	Channel          string `json:"channelID"`
+	Time             string `json:"time"`
+	ThreadTs         string `json:"threadTs"`
+	ReplyCount       int    `json:"replyCount"`
+	LastReply        string `json:"lastReply"`
+	ParticipantCount int    `json:"participantCount"`
+	Text             string `json:"text"`
+	Cursor           string `json:"cursor"`
+}
+
+// ConversationsThreadsListHandler lists the messages of a page of channel
+// history that started a thread, with their reply counts, to triage a busy
+// channel without reading every thread
+func (ch *ConversationsHandler) ConversationsThreadsListHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("ConversationsThreadsListHandler called", zap.Any("params", request.Params))
+
+	params, err := ch.parseParamsToolConversations(ctx, request)
+	if err != nil {
+		ch.logger.Error("Failed to parse threads-list params", zap.Error(err))
+		return nil, err
+	}
+
+	return historyPageCSV(ctx, ch, params, func(history []slack.Message) []ThreadInfo {
+		roots := threadRoots(history)
+		// Threads on file shares and other subtypes count as well
+		messages := ch.convertMessagesFromHistory(ctx, roots, params.channel, true)
+		return threadInfos(roots, messages, ch.timeFormat, ch.logger)
+	}, func(t *ThreadInfo) *string { return &t.Cursor },
+		fmt.Sprintf("No threads found in %s for the given window", ch.channelLabel(params.channel)))
+}
+
+// historyPageCSV fetches the page of channel history selected by params and
+// renders the rows toRows derives from it as CSV. The cursor of the next page
+// goes in the last row, or in a note when the page yields no rows, so paging
+// can go on through history without matches.
+func historyPageCSV[T any](
+	ctx context.Context,
+	ch *ConversationsHandler,
+	params *conversationParams,
+	toRows func(history []slack.Message) []T,
+	cursorOf func(row *T) *string,
+	emptyNote string,
+) (*mcp.CallToolResult, error) {
+	historyParams := slack.GetConversationHistoryParameters{
+		ChannelID: params.channel,
+		Limit:     params.limit,
+		Oldest:    params.oldest,
+		Latest:    params.latest,
+		Cursor:    params.cursor,
+		Inclusive: false,
+	}
+	history, err := ch.apiProvider.Slack().GetConversationHistoryContext(ctx, &historyParams)
+	if err != nil {
+		ch.logger.Error("GetConversationHistoryContext failed", zap.Error(err))
+		return nil, err
+	}
+	ch.logger.Debug("Fetched conversation history", zap.Int("message_count", len(history.Messages)))
+
+	rows := toRows(history.Messages)
+	if len(rows) > 0 && history.HasMore {
+		*cursorOf(&rows[len(rows)-1]) = history.ResponseMetaData.NextCursor
+	}
+
+	csvBytes, err := gocsv.MarshalBytes(&rows)
+	if err != nil {
+		ch.logger.Error("Failed to marshal history rows to CSV", zap.Error(err))
+		return nil, err
+	}
+	result := withEmptyResultNote(mcp.NewToolResultText(string(csvBytes)), len(rows), emptyNote)
+	if len(rows) == 0 && history.HasMore {
+		result.Content = append(result.Content, mcp.NewTextContent(
+			fmt.Sprintf("More history is available, continue with cursor %q", history.ResponseMetaData.NextCursor),
+		))
+	}
+	return result, nil
+}
+
+// threadRoots keeps the messages that started a thread with at least one
+// reply. Replies broadcast to the channel belong to another thread and are
+// left out.
+func threadRoots(messages []slack.Message) []slack.Message {
+	var roots []slack.Message
+	for _, msg := range messages {
+		if msg.ReplyCount > 0 && (msg.ThreadTimestamp == "" || msg.ThreadTimestamp == msg.Timestamp) {
+			roots = append(roots, msg)
+		}
+	}
+	return roots
+}
+
+// threadInfos builds a row per thread root in roots, in history order, taking
+// names and text from messages, which were converted from roots. The
+// participant count is the root author plus the distinct users Slack lists as
+// having replied.
+func threadInfos(roots []slack.Message, messages []Message, tf text.TimeFormat, logger *zap.Logger) []ThreadInfo {
+	converted := make(map[string]Message, len(messages))
+	for _, m := range messages {
+		converted[m.MsgID] = m
+	}
+
+	rows := make([]ThreadInfo, 0, len(roots))
+	for _, msg := range roots {
+		m, ok := converted[msg.Timestamp]
+		if !ok {
+			continue
+		}
+		participants := make(map[string]bool, len(msg.ReplyUsers)+1)
+		if msg.User != "" {
+			participants[msg.User] = true
+		}
+		for _, u := range msg.ReplyUsers {
+			participants[u] = true
+		}
+		lastReply := ""
+		if msg.LatestReply != "" {
+			lastReply = messageTime(msg.LatestReply, tf, logger)
+		}
+		rows = append(rows, ThreadInfo{
+			MsgID:            m.MsgID,
+			UserID:           m.UserID,
+			UserName:         m.UserName,
+			Channel:          m.Channel,
+			Time:             m.Time,
+			ThreadTs:         msg.Timestamp,
+			ReplyCount:       msg.ReplyCount,
+			LastReply:        lastReply,
+			ParticipantCount: len(participants),
+			Text:             m.Text,
+		})
+	}
+	return rows
+}
+
+const (
+	// exportPageSize is the conversations.history and conversations.replies
+	// page size used by conversations_export
+	exportPageSize = 200
+
+	defaultExportMaxMessages = 5000
+	maxExportMaxMessages     = 50000
+)
+
+type exportParams struct {
+	channel        string
+	includeThreads bool
+	maxMessages    int
+	format         string
+}
+
+// ConversationsExportHandler exports the whole history of a channel, optionally
+// with its threads, as one transcript or CSV. With SLACK_MCP_EXPORT_DIR set the
+// export is written to a file there and only its path is returned.
+func (ch *ConversationsHandler) ConversationsExportHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("ConversationsExportHandler called", zap.Any("params", request.Params))
+
+	if err := checkExportToolEnabled(os.Getenv("SLACK_MCP_EXPORT_TOOL"), os.Getenv("SLACK_MCP_ENABLED_TOOLS")); err != nil {
+		ch.logger.Error("Export tool disabled", zap.Error(err))
+		return nil, err
+	}
+
+	if ready, err := ch.apiProvider.IsReady(); !ready {
+		ch.logger.Error("API provider not ready", zap.Error(err))
+		return nil, err
+	}
+
+	params, err := ch.parseParamsToolExport(ctx, request)
+	if err != nil {
+		ch.logger.Error("Failed to parse export params", zap.Error(err))
+		return nil, err
+	}
+
+	rl := limiter.Tier3.Limiter()
+	slackMessages, complete, err := exportHistory(ctx, params.maxMessages, func(ctx context.Context, cursor string, limit int) ([]slack.Message, string, error) {
+		history, err := limiter.CallWithRetry(ctx, rl, 2, provider.SlackRetryAfter, func() (*slack.GetConversationHistoryResponse, error) {
+			return ch.apiProvider.Slack().GetConversationHistoryContext(ctx, &slack.GetConversationHistoryParameters{
+				ChannelID: params.channel,
+				Limit:     limit,
+				Cursor:    cursor,
+			})
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		next := ""
+		if history.HasMore {
+			next = history.ResponseMetaData.NextCursor
+		}
+		return history.Messages, next, nil
+	})
+	if err != nil {
+		ch.logger.Error("Failed to export conversation history", zap.String("channel", params.channel), zap.Error(err))
+		return nil, fmt.Errorf("failed to export %s: %w", params.channel, err)
+	}
+
+	failedThreads := 0
+	if params.includeThreads {
+		var threadsComplete bool
+		slackMessages, threadsComplete, failedThreads = expandExportThreads(ctx, slackMessages, params.maxMessages, func(ctx context.Context, threadTs string, maxMessages int) ([]slack.Message, string, error) {
+			return fetchThread(ctx, maxMessages, func(ctx context.Context, cursor string, limit int) (repliesPage, error) {
+				return limiter.CallWithRetry(ctx, rl, 2, provider.SlackRetryAfter, func() (repliesPage, error) {
+					msgs, hasMore, next, err := ch.apiProvider.Slack().GetConversationRepliesContext(ctx, &slack.GetConversationRepliesParameters{
+						ChannelID: params.channel,
+						Timestamp: threadTs,
+						Cursor:    cursor,
+						Limit:     min(limit, exportPageSize),
+						Inclusive: true,
+					})
+					if !hasMore {
+						next = ""
+					}
+					return repliesPage{messages: msgs, next: next}, err
+				})
+			})
+		}, ch.logger)
+		complete = complete && threadsComplete
+	}
+	ch.logger.Debug("Exported conversation",
+		zap.String("channel", params.channel),
+		zap.Int("message_count", len(slackMessages)),
+		zap.Bool("complete", complete))
+
+	messages := ch.convertMessagesFromHistory(ctx, slackMessages, params.channel, false)
+	var data string
+	if params.format == "csv" {
+		csvBytes, err := messagesCSV(&messages, nil)
+		if err != nil {
+			return nil, err
+		}
+		data = string(csvBytes)
+	} else {
+		data = formatTranscript(messages)
+	}
+
+	var notes []string
+	if !complete {
+		notes = append(notes, fmt.Sprintf("export capped at max_messages=%d, older messages or thread replies are missing", params.maxMessages))
+	}
+	if failedThreads > 0 {
+		notes = append(notes, fmt.Sprintf("%d thread(s) could not be fetched and only their parent message is included", failedThreads))
+	}
+
+	label := ch.channelLabel(params.channel)
+	var result *mcp.CallToolResult
+	if dir := os.Getenv("SLACK_MCP_EXPORT_DIR"); dir != "" {
+		path, err := writeExportFile(dir, params.channel, params.format, data, time.Now())
+		if err != nil {
+			ch.logger.Error("Failed to write export file", zap.String("dir", dir), zap.Error(err))
+			return nil, err
+		}
+		ch.logger.Info("Wrote conversation export", zap.String("channel", params.channel), zap.String("path", path))
+		result = mcp.NewToolResultText(fmt.Sprintf("Exported %d message(s) of %s to %s", len(messages), label, path))
+	} else {
+		result = withEmptyResultNote(mcp.NewToolResultText(data), len(messages), fmt.Sprintf("No messages found in %s", label))
+	}
+	for _, note := range notes {
+		result.Content = append(result.Content, mcp.NewTextContent(note))
+	}
+	return result, nil
+}
+
+func (ch *ConversationsHandler) parseParamsToolExport(ctx context.Context, request mcp.CallToolRequest) (*exportParams, error) {
+	channel := strings.TrimSpace(request.GetString("channel_id", ""))
+	if channel == "" {
+		return nil, errors.New("channel_id is required")
+	}
+	channel, err := ch.resolveAllowedChannel(ctx, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	maxMessages := request.GetInt("max_messages", defaultExportMaxMessages)
+	if maxMessages < 1 || maxMessages > maxExportMaxMessages {
+		return nil, fmt.Errorf("max_messages must be between 1 and %d", maxExportMaxMessages)
+	}
+	format := request.GetString("format", "transcript")
+	if format != "transcript" && format != "csv" {
+		return nil, errors.New("format must be either 'transcript' or 'csv'")
+	}
+
+	return &exportParams{
+		channel:        channel,
+		includeThreads: request.GetBool("include_threads", false),
+		maxMessages:    maxMessages,
+		format:         format,
+	}, nil
+}
+
+// checkExportToolEnabled applies the SLACK_MCP_EXPORT_TOOL gate, since an
+// export walks the whole history of a channel and costs many API calls.
+func checkExportToolEnabled(toolConfig, enabledTools string) error {
+	if toolConfig == "" {
+		if strings.Contains(enabledTools, "conversations_export") {
+			return nil
+		}
+		return errors.New(
+			"by default, the conversations_export tool is disabled since exports make many API calls. " +
+				"To enable it, set the SLACK_MCP_EXPORT_TOOL environment variable to true or 1, " +
+				"e.g. 'SLACK_MCP_EXPORT_TOOL=true'",
+		)
+	}
+	if toolConfig != "1" && toolConfig != "true" && toolConfig != "yes" {
+		return errors.New(
+			"the conversations_export tool is disabled. " +
+				"To enable it, set the SLACK_MCP_EXPORT_TOOL environment variable to true or 1",
+		)
+	}
+	return nil
+}
+
+// exportHistory pages through the history of a channel until it is exhausted
+// or maxMessages were collected, and returns the messages oldest first.
+// complete is false when the cap stopped the walk.
+func exportHistory(ctx context.Context, maxMessages int, fetch func(ctx context.Context, cursor string, limit int) ([]slack.Message, string, error)) ([]slack.Message, bool, error) {
+	var collected []slack.Message
+	cursor := ""
+	complete := false
+	for len(collected) < maxMessages {
+		msgs, next, err := fetch(ctx, cursor, min(exportPageSize, maxMessages-len(collected)))
+		if err != nil {
+			return nil, false, err
+		}
+		collected = append(collected, msgs...)
+		if next == "" {
+			complete = true
+			break
+		}
+		cursor = next
+	}
+	if len(collected) > maxMessages {
+		collected = collected[:maxMessages]
+		complete = false
+	}
+	// Slack pages history newest first
+	slices.Reverse(collected)
+	return collected, complete, nil
+}
+
+// expandExportThreads inserts the replies of every thread parent right after
+// it, without exceeding maxMessages in total. It returns false when threads
+// were cut or skipped for the cap, and the number of threads that failed; those
+// keep only their parent.
+func expandExportThreads(
+	ctx context.Context,
+	messages []slack.Message,
+	maxMessages int,
+	fetch func(ctx context.Context, threadTs string, maxMessages int) ([]slack.Message, string, error),
+	logger *zap.Logger,
+) ([]slack.Message, bool, int) {
+	total := len(messages)
+	out := make([]slack.Message, 0, total)
+	complete, failed := true, 0
+	for _, msg := range messages {
+		out = append(out, msg)
+		if msg.ReplyCount == 0 || msg.ThreadTimestamp != msg.Timestamp {
+			continue
+		}
+		remaining := maxMessages - total
+		if remaining <= 0 {
+			complete = false
+			continue
+		}
+		// The parent is returned as well and already counted
+		replies, next, err := fetch(ctx, msg.Timestamp, remaining+1)
+		if err != nil {
+			logger.Warn("Failed to fetch thread for export", zap.String("thread_ts", msg.Timestamp), zap.Error(err))
+			failed++
+			continue
+		}
+		replies = filterMessagesAfter(replies, msg.Timestamp)
+		if len(replies) > remaining {
+			replies = replies[:remaining]
+			complete = false
+		}
+		if next != "" {
+			complete = false
+		}
+		out = append(out, replies...)
+		total += len(replies)
+	}
+	return out, complete, failed
+}
+
+// writeExportFile writes an export to dir, named after the channel and the
+// time of the export, and returns its path.
+func writeExportFile(dir, channel, format, data string, now time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create export directory: %w", err)
+	}
+	ext := ".txt"
+	if format == "csv" {
+		ext = ".csv"
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s%s", channel, now.UTC().Format("20060102-150405"), ext))
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		return "", fmt.Errorf("failed to write export file: %w", err)
+	}
+	return path, nil
+}
+
+// ConversationsRepliesHandler streams thread replies as CSV
+func (ch *ConversationsHandler) ConversationsRepliesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("ConversationsRepliesHandler called", zap.Any("params", request.Params))
+
+	params, err := ch.parseParamsToolConversations(ctx, request)
+	if err != nil {
+		ch.logger.Error("Failed to parse replies params", zap.Error(err))
+		return nil, err
+	}
+	threadTs := request.GetString("thread_ts", "")
+	if threadTs == "" {
+		ch.logger.Error("thread_ts not provided for replies", zap.String("thread_ts", threadTs))
+		return nil, errors.New("thread_ts must be a string")
+	}
+
+	if request.GetBool("participants_only", false) {
+		maxReplies := request.GetInt("max_replies", defaultParticipantsMaxReplies)
+		if maxReplies < 1 || maxReplies > maxParticipantsMaxReplies {
+			return nil, fmt.Errorf("max_replies must be an integer between 1 and %d", maxParticipantsMaxReplies)
+		}
+		return ch.repliesParticipants(ctx, params.channel, threadTs, maxReplies)
+	}
+
+	since, err := parseSinceToTs(request.GetString("since", ""))
+	if err != nil {
+		ch.logger.Error("Invalid since", zap.Error(err))
+		return nil, err
+	}
+
+	repliesParams := slack.GetConversationRepliesParameters{
+		ChannelID: params.channel,
+		Timestamp: threadTs,
+		Limit:     params.limit,
+		Oldest:    params.oldest,
+		Latest:    params.latest,
+		Cursor:    params.cursor,
+		Inclusive: false,
+	}
+	if since != "" {
+		repliesParams.Oldest = since
+	}
+	replies, hasMore, nextCursor, err := ch.apiProvider.Slack().GetConversationRepliesContext(ctx, &repliesParams)
+	if err != nil {
+		ch.logger.Error("GetConversationRepliesContext failed", zap.Error(err))
+		return nil, err
+	}
+	ch.logger.Debug("Fetched conversation replies", zap.Int("count", len(replies)))
+
+	if since != "" {
+		// Slack always returns the thread parent, drop it and anything else not newer than since
+		replies = filterMessagesAfter(replies, since)
+	}
+
+	messages := ch.convertMessagesFromHistory(ctx, replies, params.channel, params.activity)
+	if params.reactionUsers {
+		messages = withReactionUsers(messages, replies, ch.apiProvider.ProvideUsersMap().Users)
+	}
+	if params.clientMsgID {
+		messages = withClientMsgIDs(messages, replies)
+	}
+	if params.subtype {
+		messages = withSubtypes(messages, replies)
+	}
+	if params.avatars {
+		messages = withAvatars(messages, replies, ch.apiProvider.ProvideUsersMap().Users)
+	}
+	if params.teams {
+		messages = ch.withAuthorTeams(messages, replies)
+	}
+	columns := params.messageColumns()
+	if !ch.apiProvider.IsBotToken() {
+		// Slack only reports thread subscriptions to user tokens
+		messages = withThreadSubscription(messages, replies, threadTs)
+		columns = append(columns, colSubscribed)
+	}
+	if len(messages) > 0 && hasMore {
+		messages[len(messages)-1].Cursor = nextCursor
+	}
+	result, err := marshalMessages(messages, params.responseFormat, columns...)
+	if err != nil {
+		return nil, err
+	}
+	return withEmptyResultNote(result, len(messages),
+		fmt.Sprintf("No replies matched in thread %s of %s for the given window", threadTs, ch.channelLabel(params.channel))), nil
+}
+
+const (
+	defaultParticipantsMaxReplies = 1000
+	maxParticipantsMaxReplies     = 10000
+)
+
+// repliesParticipants lists the unique authors of a thread instead of its
+// messages, scanning at most maxReplies replies
+func (ch *ConversationsHandler) repliesParticipants(ctx context.Context, channel, threadTs string, maxReplies int) (*mcp.CallToolResult, error) {
+	rl := limiter.Tier3.Limiter()
+	fetch := func(ctx context.Context, cursor string, limit int) (repliesPage, error) {
+		return limiter.CallWithRetry(ctx, rl, 2, provider.SlackRetryAfter, func() (repliesPage, error) {
+			msgs, hasMore, next, err := ch.apiProvider.Slack().GetConversationRepliesContext(ctx, &slack.GetConversationRepliesParameters{
+				ChannelID: channel,
+				Timestamp: threadTs,
+				Cursor:    cursor,
+				Limit:     limit,
+				Inclusive: true,
+			})
+			if !hasMore {
+				next = ""
+			}
+			return repliesPage{messages: msgs, next: next}, err
+		})
+	}
+	// The thread parent comes first and does not count towards maxReplies
+	replies, nextCursor, err := fetchThread(ctx, maxReplies+1, fetch)
+	if err != nil {
+		ch.logger.Error("Failed to fetch thread", zap.String("channel", channel), zap.String("thread_ts", threadTs), zap.Error(err))
+		return nil, err
+	}
+
+	ids, scanned := threadParticipantIDs(replies)
+	participants := resolveParticipants(ids, ch.apiProvider.ProvideUsersMap().Users)
+	csvBytes, err := gocsv.MarshalBytes(&participants)
+	if err != nil {
+		ch.logger.Error("Failed to marshal participants to CSV", zap.Error(err))
+		return nil, err
+	}
+
+	result := withEmptyResultNote(mcp.NewToolResultText(string(csvBytes)), len(participants),
+		fmt.Sprintf("No messages found in thread %s of %s", threadTs, ch.channelLabel(channel)))
+	if len(replies) > 0 {
+		result.Content = append(result.Content, mcp.NewTextContent(
+			fmt.Sprintf("%d participant(s) across %d scanned replies; the thread has %d replies", len(participants), scanned, replies[0].ReplyCount),
+		))
+	}
+	if nextCursor != "" {
+		result.Content = append(result.Content, mcp.NewTextContent(
+			fmt.Sprintf("warning: the thread has more than max_replies=%d replies, later participants may be missing", maxReplies),
+		))
+	}
+	return result, nil
+}
+
+// threadParticipantIDs returns the unique authors of a thread, as returned by
+// conversations.replies, in order of their first message, together with the
+// number of replies scanned. Bot posts without a user count by bot ID.
+func threadParticipantIDs(messages []slack.Message) ([]string, int) {
+	var ids []string
+	seen := make(map[string]bool)
+	scanned := 0
+	for _, msg := range messages {
+		if msg.ThreadTimestamp != "" && msg.Timestamp != msg.ThreadTimestamp {
+			scanned++
+		}
+		id := msg.User
+		if id == "" {
+			id = msg.BotID
+		}
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids, scanned
+}
+
+const (
+	defaultThreadByLinkMaxMessages = 500
+	maxThreadByLinkMaxMessages     = 1000
+	threadByLinkPageSize           = 200
+)
+
+// ConversationsThreadByLinkHandler returns the whole thread a message permalink
+// points to. Permalinks to a message outside of a thread return that message only.
+func (ch *ConversationsHandler) ConversationsThreadByLinkHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("ConversationsThreadByLinkHandler called", zap.Any("params", request.Params))
+
+	if ready, err := ch.apiProvider.IsReady(); !ready {
+		ch.logger.Error("API provider not ready", zap.Error(err))
+		return nil, err
+	}
+
+	permalink := strings.TrimSpace(request.GetString("permalink", ""))
+	if permalink == "" {
+		return nil, errors.New("permalink is required")
+	}
+	channel, rootTs, err := threadFromPermalink(permalink)
+	if err != nil {
+		ch.logger.Error("Invalid permalink", zap.String("permalink", permalink), zap.Error(err))
+		return nil, err
+	}
+	maxMessages := request.GetInt("max_messages", defaultThreadByLinkMaxMessages)
+	if maxMessages < 1 || maxMessages > maxThreadByLinkMaxMessages {
+		return nil, fmt.Errorf("max_messages must be an integer between 1 and %d", maxThreadByLinkMaxMessages)
+	}
+	channel, err = ch.resolveAllowedChannel(ctx, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	rl := limiter.Tier3.Limiter()
+	fetch := func(ctx context.Context, cursor string, limit int) (repliesPage, error) {
+		return limiter.CallWithRetry(ctx, rl, 2, provider.SlackRetryAfter, func() (repliesPage, error) {
+			msgs, hasMore, next, err := ch.apiProvider.Slack().GetConversationRepliesContext(ctx, &slack.GetConversationRepliesParameters{
+				ChannelID: channel,
+				Timestamp: rootTs,
+				Cursor:    cursor,
+				Limit:     limit,
+				Inclusive: true,
+			})
+			if !hasMore {
+				next = ""
+			}
+			return repliesPage{messages: msgs, next: next}, err
+		})
+	}
+	replies, nextCursor, err := fetchThread(ctx, maxMessages, fetch)
+	if err != nil {
+		ch.logger.Error("Failed to fetch thread", zap.String("channel", channel), zap.String("thread_ts", rootTs), zap.Error(err))
+		return nil, err
+	}
+	ch.logger.Debug("Fetched thread by permalink", zap.String("channel", channel), zap.String("thread_ts", rootTs), zap.Int("count", len(replies)))
+
+	messages := ch.convertMessagesFromHistory(ctx, replies, channel, false)
+	if len(messages) > 0 && nextCursor != "" {
+		messages[len(messages)-1].Cursor = nextCursor
+	}
+	result, err := marshalMessagesToCSV(messages)
+	if err != nil {
+		return nil, err
+	}
+	if !isThread(replies) {
+		result.Content = append(result.Content, mcp.NewTextContent(
+			"the permalink points to a message that is not part of a thread; only that message is returned",
+		))
+	}
+	if nextCursor != "" {
+		result.Content = append(result.Content, mcp.NewTextContent(
+			fmt.Sprintf("thread capped at max_messages=%d; pass the cursor to conversations_replies to fetch the rest", maxMessages),
+		))
+	}
+	return result, nil
+}
+
+// repliesPage is one page of conversations.replies
+type repliesPage struct {
+	messages []slack.Message
+	next     string
+}
+
+// threadFromPermalink returns the channel and thread root ts a message
+// permalink refers to: thread_ts for reply permalinks, the message ts otherwise.
+func threadFromPermalink(permalink string) (channel, rootTs string, err error) {
+	channel, ts, threadTs, err := parseMessagePermalink(permalink)
+	if err != nil {
+		return "", "", err
+	}
+	if threadTs != "" {
+		return channel, threadTs, nil
+	}
+	return channel, ts, nil
+}
+
+// fetchThread pages through a thread until it is exhausted or maxMessages have
+// been read. Pages are sized so the cap never splits one, which keeps the
+// returned cursor valid for continuing with conversations_replies.
+func fetchThread(ctx context.Context, maxMessages int, fetch func(ctx context.Context, cursor string, limit int) (repliesPage, error)) ([]slack.Message, string, error) {
+	var messages []slack.Message
+	cursor := ""
+	for {
+		limit := min(threadByLinkPageSize, maxMessages-len(messages))
+		page, err := fetch(ctx, cursor, limit)
+		if err != nil {
+			return nil, "", err
+		}
+		messages = append(messages, page.messages...)
+		if page.next == "" {
+			return messages, "", nil
+		}
+		if len(messages) >= maxMessages {
+			return messages, page.next, nil
+		}
+		cursor = page.next
+	}
+}
+
+// isThread reports whether messages, as returned by conversations.replies, form
+// a thread rather than a single standalone message.
+func isThread(messages []slack.Message) bool {
+	if len(messages) != 1 {
+		return len(messages) > 1
+	}
+	return messages[0].ReplyCount > 0 || (messages[0].ThreadTimestamp != "" && messages[0].ThreadTimestamp != messages[0].Timestamp)
+}
+
+func (ch *ConversationsHandler) ConversationsSearchHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("ConversationsSearchHandler called", zap.Any("params", request.Params))
+
+	params, err := ch.parseParamsToolSearch(request)
+	if err != nil {
+		ch.logger.Error("Failed to parse search params", zap.Error(err))
+		return nil, err
+	}
+	ch.logger.Debug("Search params parsed", zap.String("query", params.query), zap.Int("limit", params.limit), zap.Int("page", params.page))
+
+	searchParams := slack.SearchParameters{
+		Sort:          params.sort,
+		SortDirection: params.sortDir,
+		Highlight:     false,
+		Count:         params.limit,
+		Page:          params.page,
+	}
+	if params.deepSearch {
+		return ch.deepSearchHandler(ctx, params)
+	}
+	if params.countOnly {
+		return searchCount(ctx, params.query, func(ctx context.Context, query string, sp slack.SearchParameters) (*slack.SearchMessages, error) {
+			res, _, err := ch.apiProvider.Slack().SearchContext(ctx, query, sp)
+			return res, err
+		})
+	}
+
+	messagesRes, _, err := ch.apiProvider.Slack().SearchContext(ctx, params.query, searchParams)
+	if err != nil {
+		ch.logger.Error("Slack SearchContext failed", zap.Error(err))
+		return nil, err
+	}
+	ch.logger.Debug("Search completed", zap.Int("matches", len(messagesRes.Matches)))
+
+	matches := filterMatchesByPolicy(messagesRes.Matches, allowedChannelTypes(), ch.apiProvider.ProvideChannelsMaps().Channels)
+	omitted := 0
+	if params.myChannelsOnly {
+		matches, omitted = filterMatchesToMyChannels(ctx, matches, ch.apiProvider.ProvideChannelsMaps().Channels, ch.conversationInfo, ch.logger)
+		ch.logger.Debug("Filtered search matches to member channels",
+			zap.Int("kept", len(matches)),
+			zap.Int("omitted", omitted))
+	}
+
+	ch.resolveSearchChannelNames(ctx, matches, params.resolveChannels)
+	if params.groupByChannel {
+		result, err := marshalSearchChannels(groupMatchesByChannel(matches))
+		if err != nil {
+			return nil, err
+		}
+		if p := messagesRes.Pagination; p.Page < p.PageCount {
+			result.Content = append(result.Content, mcp.NewTextContent(
+				fmt.Sprintf("counts cover page %d of %d of the matches; use deep_search=true to count all of them", p.Page, p.PageCount),
+			))
+		}
+		return withOmittedNote(result, omitted), nil
+	}
+	messages := ch.convertMessagesFromSearch(ctx, matches)
+	reactionsFailed := 0
+	if params.reactions {
+		messages, reactionsFailed = ch.withSearchReactions(ctx, messages, matches)
+	}
+	if params.includeThreadRoot {
+		messages = ch.prependThreadRoots(ctx, matches, messages)
+	}
+	threadsFailed, threadsCapped := 0, 0
+	if params.expandThreads > 0 {
+		messages, threadsFailed, threadsCapped = ch.withSearchThreads(ctx, messages, matches, params.expandThreads)
+	}
+	if params.avatars {
+		messages = withAvatars(messages, nil, ch.apiProvider.ProvideUsersMap().Users)
+	}
+	if len(messages) > 0 && messagesRes.Pagination.Page < messagesRes.Pagination.PageCount {
+		nextCursor := fmt.Sprintf("page:%d", messagesRes.Pagination.Page+1)
+		messages[len(messages)-1].Cursor = base64.StdEncoding.EncodeToString([]byte(nextCursor))
+	}
+
+	result, err := marshalMessagesToCSV(messages, params.messageColumns()...)
+	if err != nil {
+		return nil, err
+	}
+	result = withEmptyResultNote(result, len(messages), "No messages matched the search query")
+	result = withOmittedNote(result, omitted)
+	if reactionsFailed > 0 {
+		result.Content = append(result.Content, mcp.NewTextContent(
+			fmt.Sprintf("reactions could not be fetched for %d message(s), their reactions column is empty", reactionsFailed),
+		))
+	}
+	if threadsFailed > 0 {
+		result.Content = append(result.Content, mcp.NewTextContent(
+			fmt.Sprintf("the threads of %d match(es) could not be fetched and are not expanded", threadsFailed),
+		))
+	}
+	if threadsCapped > 0 {
+		result.Content = append(result.Content, mcp.NewTextContent(
+			fmt.Sprintf("%d expanded thread(s) were cut at %d messages; use conversations_replies to read the rest", threadsCapped, searchThreadMaxMessages),
+		))
+	}
+	return result, nil
+}
+
+// reactionsFetchWorkers is the number of concurrent reactions.get calls used
+// to enrich a page of messages
+const reactionsFetchWorkers = 4
+
+// withSearchReactions fills the reactions column of messages converted from
+// matches, which search.messages does not include. It returns the number of
+// messages whose reactions could not be fetched.
+func (ch *ConversationsHandler) withSearchReactions(ctx context.Context, messages []Message, matches []slack.SearchMessage) ([]Message, int) {
+	items := make([]slack.ItemRef, 0, len(matches))
+	for _, m := range matches {
+		items = append(items, slack.ItemRef{Channel: m.Channel.ID, Timestamp: m.Timestamp})
+	}
+
+	rl := limiter.Tier3.Limiter()
+	reactions, failed := fetchReactions(ctx, items, reactionsFetchWorkers, func(ctx context.Context, item slack.ItemRef) ([]slack.ItemReaction, error) {
+		return limiter.CallWithRetry(ctx, rl, 2, provider.SlackRetryAfter, func() ([]slack.ItemReaction, error) {
+			return ch.apiProvider.Slack().GetReactionsContext(ctx, item, slack.NewGetReactionsParameters())
+		})
+	}, ch.logger)
+
+	return applySearchReactions(messages, matches, reactions, ch.apiProvider.ProvideUsersMap().Users), failed
+}
+
+// fetchReactions looks up the reactions of items with at most workers calls in
+// flight. Failed lookups are logged and left out of the result, so callers get
+// whatever could be fetched along with the number of failures.
+func fetchReactions(
+	ctx context.Context,
+	items []slack.ItemRef,
+	workers int,
+	fetch func(ctx context.Context, item slack.ItemRef) ([]slack.ItemReaction, error),
+	logger *zap.Logger,
+) (map[slack.ItemRef][]slack.ItemReaction, int) {
+	var (
+		mu        sync.Mutex
+		reactions = make(map[slack.ItemRef][]slack.ItemReaction, len(items))
+		failed    int
+	)
+
+	var eg errgroup.Group
+	eg.SetLimit(workers)
+	for _, item := range items {
+		eg.Go(func() error {
+			r, err := fetch(ctx, item)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				logger.Warn("Failed to fetch reactions",
+					zap.String("channel", item.Channel),
+					zap.String("ts", item.Timestamp),
+					zap.Error(err))
+				failed++
+				return nil
+			}
+			reactions[item] = r
+			return nil
+		})
+	}
+	_ = eg.Wait()
+
+	return reactions, failed
+}
+
+// applySearchReactions renders fetched reactions into the rows converted from
+// matches. Rows are matched by channel and ts, since conversion may skip matches.
+func applySearchReactions(messages []Message, matches []slack.SearchMessage, reactions map[slack.ItemRef][]slack.ItemReaction, users map[string]slack.User) []Message {
+	refs := make(map[string]slack.ItemRef, len(matches))
+	for _, m := range matches {
+		refs[searchChannelLabel(m.Channel)+"/"+m.Timestamp] = slack.ItemRef{Channel: m.Channel.ID, Timestamp: m.Timestamp}
+	}
+	for i := range messages {
+		ref, ok := refs[messages[i].Channel+"/"+messages[i].MsgID]
+		if !ok {
+			continue
+		}
+		if r := reactions[ref]; len(r) > 0 {
+			messages[i].Reactions = formatReactions(r, users, false)
+		}
+	}
+	return messages
+}
+
+// SearchCount is the result row of conversations_search_messages with count_only
+type SearchCount struct {
+	Query string `json:"query"`
+	Total int    `json:"total"`
+}
+
+// searchCount asks Slack for a single match and returns only the total match
+// count of query, so no message rows are converted or sent to the model.
+func searchCount(ctx context.Context, query string, search func(ctx context.Context, query string, params slack.SearchParameters) (*slack.SearchMessages, error)) (*mcp.CallToolResult, error) {
+	res, err := search(ctx, query, slack.SearchParameters{
+		Sort:          slack.DEFAULT_SEARCH_SORT,
+		SortDirection: slack.DEFAULT_SEARCH_SORT_DIR,
+		Count:         1,
+		Page:          1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	total := res.Total
+	if total == 0 {
+		total = res.Pagination.TotalCount
+	}
+	rows := []SearchCount{{Query: query, Total: total}}
+	csvBytes, err := gocsv.MarshalBytes(&rows)
+	if err != nil {
+		return nil, err
+	}
+	return mcp.NewToolResultText(string(csvBytes)), nil
+}
+
+// SearchChannel is a result row of conversations_search_messages with
+// group_by_channel: a channel with matches and how many of them it has
+type SearchChannel struct {
+	ChannelID   string `json:"channelID"`
+	ChannelName string `json:"channelName"`
+	Matches     int    `json:"matches"`
+}
+
+// groupMatchesByChannel collapses search matches into one row per channel,
+// most matches first and ties ordered by channel ID.
+func groupMatchesByChannel(matches []slack.SearchMessage) []SearchChannel {
+	index := make(map[string]int)
+	var rows []SearchChannel
+	for _, m := range matches {
+		i, ok := index[m.Channel.ID]
+		if !ok {
+			i = len(rows)
+			index[m.Channel.ID] = i
+			rows = append(rows, SearchChannel{ChannelID: m.Channel.ID})
+		}
+		if rows[i].ChannelName == "" && m.Channel.Name != "" {
+			rows[i].ChannelName = searchChannelLabel(m.Channel)
+		}
+		rows[i].Matches++
+	}
+	sort.SliceStable(rows, func(a, b int) bool {
+		if rows[a].Matches != rows[b].Matches {
+			return rows[a].Matches > rows[b].Matches
+		}
+		return rows[a].ChannelID < rows[b].ChannelID
+	})
+	return rows
+}
+
+func marshalSearchChannels(rows []SearchChannel) (*mcp.CallToolResult, error) {
+	csvBytes, err := gocsv.MarshalBytes(&rows)
+	if err != nil {
+		return nil, err
+	}
+	return withEmptyResultNote(mcp.NewToolResultText(string(csvBytes)), len(rows), "No messages matched the search query"), nil
+}
+
+// withOmittedNote tells how many matches my_channels_only dropped, if any
+func withOmittedNote(result *mcp.CallToolResult, omitted int) *mcp.CallToolResult {
+	if omitted > 0 {
+		result.Content = append(result.Content, mcp.NewTextContent(
+			fmt.Sprintf("%d match(es) from channels you are not a member of were omitted", omitted),
+		))
+	}
+	return result
+}
+
+const (
+	// searchPageLimit is the number of pages Slack serves for a single query
+	searchPageLimit = 100
+	// deepSearchPageSize is the largest page size search.messages accepts
+	deepSearchPageSize = 100
+
+	defaultDeepSearchMaxResults = 1000
+	maxDeepSearchMaxResults     = 10000
+)
+
+// deepSearchHandler runs a search with deep_search=true: every page is fetched,
+// and the date window is split whenever a query has more pages than Slack serves
+func (ch *ConversationsHandler) deepSearchHandler(ctx context.Context, params *searchParams) (*mcp.CallToolResult, error) {
+	rl := limiter.Tier2.Limiter()
+	fetch := func(ctx context.Context, query string, page int) (*slack.SearchMessages, error) {
+		return limiter.CallWithRetry(ctx, rl, 2, provider.SlackRetryAfter, func() (*slack.SearchMessages, error) {
+			res, _, err := ch.apiProvider.Slack().SearchContext(ctx, query, slack.SearchParameters{
+				Sort:          "timestamp",
+				SortDirection: "desc",
+				Count:         deepSearchPageSize,
+				Page:          page,
+			})
+			return res, err
+		})
+	}
+
+	window, sliceable := initialSearchWindow(params.filters, time.Now())
+	matches, capped, err := deepSearch(ctx, fetch, params.freeText, params.filters, window, sliceable, params.maxResults)
+	if err != nil {
+		ch.logger.Error("Deep search failed", zap.Error(err))
+		return nil, err
+	}
+	ch.logger.Debug("Deep search completed", zap.Int("matches", len(matches)), zap.Bool("capped", capped))
+	matches = filterMatchesByPolicy(matches, allowedChannelTypes(), ch.apiProvider.ProvideChannelsMaps().Channels)
+
+	omitted := 0
+	if params.myChannelsOnly {
+		matches, omitted = filterMatchesToMyChannels(ctx, matches, ch.apiProvider.ProvideChannelsMaps().Channels, ch.conversationInfo, ch.logger)
+	}
+
+	ch.resolveSearchChannelNames(ctx, matches, params.resolveChannels)
+	if params.groupByChannel {
+		result, err := marshalSearchChannels(groupMatchesByChannel(matches))
+		if err != nil {
+			return nil, err
+		}
+		if capped {
+			result.Content = append(result.Content, mcp.NewTextContent(
+				fmt.Sprintf("counts cover the first max_results=%d matches; narrow the query or raise max_results", params.maxResults),
+			))
+		}
+		return withOmittedNote(result, omitted), nil
+	}
+	messages := ch.convertMessagesFromSearch(ctx, matches)
+	if params.includeThreadRoot {
+		messages = ch.prependThreadRoots(ctx, matches, messages)
+	}
+	if params.avatars {
+		messages = withAvatars(messages, nil, ch.apiProvider.ProvideUsersMap().Users)
+	}
+
+	result, err := marshalMessagesToCSV(messages, params.messageColumns()...)
+	if err != nil {
+		return nil, err
+	}
+	result = withEmptyResultNote(result, len(messages), "No messages matched the search query")
+	if capped {
+		result.Content = append(result.Content, mcp.NewTextContent(
+			fmt.Sprintf("results capped at max_results=%d; narrow the query or raise max_results", params.maxResults),
+		))
+	}
+	result = withOmittedNote(result, omitted)
+	return result, nil
+}
+
+// searchWindow is a date range of a search as Slack's exclusive after: and
+// before: filters, so it covers the days after "after" up to the day before "before"
+type searchWindow struct {
+	after  time.Time
+	before time.Time
+}
+
+// days is the number of whole days covered by the window
+func (w searchWindow) days() int {
+	return int(w.before.Sub(w.after).Hours()/24) - 1
+}
+
+// split halves the window into a newer and an older part that together cover
+// the same days. Windows of a single day cannot be split.
+func (w searchWindow) split() (newer, older searchWindow, ok bool) {
+	if w.days() < 2 {
+		return w, w, false
+	}
+	diff := w.days() + 1
+	mid := w.after.AddDate(0, 0, (diff+1)/2)
+	older = searchWindow{after: w.after, before: mid}
+	newer = searchWindow{after: mid.AddDate(0, 0, -1), before: w.before}
+	return newer, older, true
+}
+
+// searchEpoch is the lower bound of a deep search without an after: filter
+var searchEpoch = time.Date(2013, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// initialSearchWindow derives the date window of a deep search from the
+// after: and before: filters, defaulting to all messages up to today. Queries
+// with on: or during: filters cannot be sliced.
+func initialSearchWindow(filters map[string][]string, now time.Time) (searchWindow, bool) {
+	if len(filters["on"]) > 0 || len(filters["during"]) > 0 {
+		return searchWindow{}, false
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	w := searchWindow{after: searchEpoch, before: today.AddDate(0, 0, 1)}
+	if vals := filters["after"]; len(vals) == 1 {
+		t, err := time.Parse("2006-01-02", vals[0])
+		if err != nil {
+			return searchWindow{}, false
+		}
+		w.after = t
+	} else if len(vals) > 1 {
+		return searchWindow{}, false
+	}
+	if vals := filters["before"]; len(vals) == 1 {
+		t, err := time.Parse("2006-01-02", vals[0])
+		if err != nil {
+			return searchWindow{}, false
+		}
+		w.before = t
+	} else if len(vals) > 1 {
+		return searchWindow{}, false
+	}
+	return w, !w.before.Before(w.after)
+}
+
+// windowFilters returns a copy of filters with the date range replaced by w
+func windowFilters(filters map[string][]string, w searchWindow) map[string][]string {
+	out := make(map[string][]string, len(filters)+2)
+	for k, v := range filters {
+		out[k] = v
+	}
+	out["after"] = []string{w.after.Format("2006-01-02")}
+	out["before"] = []string{w.before.Format("2006-01-02")}
+	return out
+}
+
+// deepSearch pages through all results of a query up to maxResults. When a
+// window has more pages than Slack serves, it is split into a newer and an
+// older half which are searched in turn, newest first. Matches seen in more
+// than one slice are returned once. The second return value reports whether
+// maxResults cut the results short.
+func deepSearch(
+	ctx context.Context,
+	fetch func(ctx context.Context, query string, page int) (*slack.SearchMessages, error),
+	freeText []string,
+	filters map[string][]string,
+	window searchWindow,
+	sliceable bool,
+	maxResults int,
+) ([]slack.SearchMessage, bool, error) {
+	var (
+		results []slack.SearchMessage
+		seen    = make(map[string]struct{})
+		pending = []searchWindow{window}
+	)
+
+	for len(pending) > 0 {
+		w := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		query := buildQuery(freeText, filters)
+		if sliceable {
+			query = buildQuery(freeText, windowFilters(filters, w))
+		}
+
+		for page := 1; ; page++ {
+			res, err := fetch(ctx, query, page)
+			if err != nil {
+				return nil, false, err
+			}
+
+			if page == 1 && sliceable && res.Pagination.PageCount > searchPageLimit {
+				if newer, older, ok := w.split(); ok {
+					// LIFO: push older first so the newer half is searched next
+					pending = append(pending, older, newer)
+					break
+				}
+			}
+
+			for _, m := range res.Matches {
+				key := m.Channel.ID + "/" + m.Timestamp
+				if _, dup := seen[key]; dup {
+					continue
+				}
+				seen[key] = struct{}{}
+				results = append(results, m)
+				if len(results) >= maxResults {
+					return results, true, nil
+				}
+			}
+
+			if page >= res.Pagination.PageCount || page >= searchPageLimit || len(res.Matches) == 0 {
+				break
+			}
+		}
+
+		if !sliceable {
+			break
+		}
+	}
+	return results, false, nil
+}
+
+// filterMatchesByPolicy drops search matches from channels whose type the
+// policy does not allow. Uncached channels are typed from the match itself.
+func filterMatchesByPolicy(matches []slack.SearchMessage, policy channelTypePolicy, channels map[string]provider.Channel) []slack.SearchMessage {
+	if policy == nil {
+		return matches
+	}
+	kept := make([]slack.SearchMessage, 0, len(matches))
+	for _, m := range matches {
+		t := channelTypeByID(m.Channel.ID, channels)
+		if t == "" {
+			switch {
+			case m.Channel.IsMPIM:
+				t = "mpim"
+			case m.Channel.IsPrivate:
+				t = provider.PrivateChanType
+			default:
+				t = provider.PubChanType
+			}
+		}
+		if policy.allows(t) {
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
+
+// filterMatchesToMyChannels keeps search matches from channels the user is a
+// member of. The channels cache answers where it knows the membership, info
+// decides once per channel where it does not, e.g. for channels missing from
+// the cache. A failed lookup omits the match, so unknown channels are never
+// surfaced. Message search goes through search.messages for every token type,
+// which has no equivalent of the edge SearchOnlyMyChannels flag: that one is
+// only honoured by the channel browser search.
+func filterMatchesToMyChannels(ctx context.Context, matches []slack.SearchMessage, channels map[string]provider.Channel, info func(ctx context.Context, channel string) (*slack.Channel, error), logger *zap.Logger) ([]slack.SearchMessage, int) {
+	members := make(map[string]bool)
+	isMember := func(channel string) bool {
+		if member, ok := members[channel]; ok {
+			return member
+		}
+		member, known := channels[channel].Member()
+		if !known {
+			c, err := info(ctx, channel)
+			if err != nil {
+				logger.Debug("Membership lookup failed, omitting matches", zap.String("channel", channel), zap.Error(err))
+			} else {
+				member = c.IsMember || c.IsIM || c.IsMpIM
+			}
+		}
+		members[channel] = member
+		return member
+	}
+
+	kept := make([]slack.SearchMessage, 0, len(matches))
+	for _, m := range matches {
+		if isMember(m.Channel.ID) {
+			kept = append(kept, m)
+		}
+	}
+	return kept, len(matches) - len(kept)
+}
+
+// threadRootRef identifies the root message of a thread that a search match replied to
+type threadRootRef struct {
+	channelID   string
+	channelName string
+	threadTs    string
+}
+
+// threadRootsToFetch returns the distinct thread roots of matches that are
+// thread replies (thread_ts differs from ts), skipping roots that are already
+// part of the matches. At most limit roots are returned.
+func threadRootsToFetch(matches []slack.SearchMessage, limit int) []threadRootRef {
+	present := make(map[string]struct{}, len(matches))
+	for _, m := range matches {
+		present[m.Channel.ID+"/"+m.Timestamp] = struct{}{}
+	}
+
+	var refs []threadRootRef
+	seen := make(map[string]struct{})
+	for _, m := range matches {
+		if len(refs) >= limit {
+			break
+		}
+		threadTs, _ := extractThreadTS(m.Permalink)
+		if threadTs == "" || threadTs == m.Timestamp {
+			continue
+		}
+		key := m.Channel.ID + "/" + threadTs
+		if _, ok := present[key]; ok {
+			continue
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		refs = append(refs, threadRootRef{
+			channelID:   m.Channel.ID,
+			channelName: searchChannelLabel(m.Channel),
+			threadTs:    threadTs,
+		})
+	}
+	return refs
+}
+
+// prependThreadRoots fetches the root message of replied-to threads and
+// inserts each root right before the first reply of its thread.
+func (ch *ConversationsHandler) prependThreadRoots(ctx context.Context, matches []slack.SearchMessage, messages []Message) []Message {
+	refs := threadRootsToFetch(matches, maxSearchThreadRoots)
+	if len(refs) == 0 {
+		return messages
+	}
+
+	rl := limiter.Tier3.Limiter()
+	roots := make(map[string]Message, len(refs))
+	for _, ref := range refs {
+		params := slack.GetConversationRepliesParameters{
+			ChannelID: ref.channelID,
+			Timestamp: ref.threadTs,
+			Limit:     1,
+			Inclusive: true,
+		}
+		replies, err := limiter.CallWithRetry(ctx, rl, 2, provider.SlackRetryAfter, func() ([]slack.Message, error) {
+			msgs, _, _, err := ch.apiProvider.Slack().GetConversationRepliesContext(ctx, &params)
+			return msgs, err
+		})
+		if err != nil {
+			ch.logger.Warn("Failed to fetch thread root",
+				zap.String("channel", ref.channelID),
+				zap.String("thread_ts", ref.threadTs),
+				zap.Error(err))
+			continue
+		}
+		if len(replies) == 0 {
+			continue
+		}
+		converted := ch.convertMessagesFromHistory(ctx, replies[:1], ref.channelName, true)
+		if len(converted) > 0 {
+			roots[ref.channelName+"/"+ref.threadTs] = converted[0]
+		}
+	}
+	ch.logger.Debug("Fetched thread roots for search results", zap.Int("requested", len(refs)), zap.Int("fetched", len(roots)))
+
+	return insertThreadRoots(messages, roots)
+}
+
+// insertThreadRoots inserts each root, keyed by channel and thread ts, right
+// before the first message of its thread. Roots are flagged with IsContext so
+// they can be told apart from the search matches.
+func insertThreadRoots(messages []Message, roots map[string]Message) []Message {
+	result := make([]Message, 0, len(messages)+len(roots))
+	for _, m := range messages {
+		key := m.Channel + "/" + m.ThreadTs
+		if root, ok := roots[key]; ok {
+			root.IsContext = true
+			result = append(result, root)
+			delete(roots, key)
+		}
+		result = append(result, m)
+	}
+	return result
+}
+
+// threadsToExpand returns the distinct threads of the first matches, up to
+// limit. A match that is not a reply is taken as the root of its own thread.
+func threadsToExpand(matches []slack.SearchMessage, limit int) []threadRootRef {
+	var refs []threadRootRef
+	seen := make(map[string]struct{})
+	for _, m := range matches {
+		if len(refs) >= limit {
+			break
+		}
+		threadTs, _ := extractThreadTS(m.Permalink)
+		if threadTs == "" {
+			threadTs = m.Timestamp
+		}
+		key := m.Channel.ID + "/" + threadTs
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		refs = append(refs, threadRootRef{
+			channelID:   m.Channel.ID,
+			channelName: searchChannelLabel(m.Channel),
+			threadTs:    threadTs,
+		})
+	}
+	return refs
+}
+
+// withSearchThreads fetches the threads of the top expand matches and groups
+// each right under its match. It returns the number of threads that could not
+// be fetched and the number that were cut at searchThreadMaxMessages.
+func (ch *ConversationsHandler) withSearchThreads(ctx context.Context, messages []Message, matches []slack.SearchMessage, expand int) ([]Message, int, int) {
+	refs := threadsToExpand(matches, expand)
+
+	rl := limiter.Tier3.Limiter()
+	threads := make(map[string][]Message, len(refs))
+	failed, capped := 0, 0
+	for _, ref := range refs {
+		fetch := func(ctx context.Context, cursor string, limit int) (repliesPage, error) {
+			return limiter.CallWithRetry(ctx, rl, 2, provider.SlackRetryAfter, func() (repliesPage, error) {
+				msgs, hasMore, next, err := ch.apiProvider.Slack().GetConversationRepliesContext(ctx, &slack.GetConversationRepliesParameters{
+					ChannelID: ref.channelID,
+					Timestamp: ref.threadTs,
+					Cursor:    cursor,
+					Limit:     limit,
+					Inclusive: true,
+				})
+				if !hasMore {
+					next = ""
+				}
+				return repliesPage{messages: msgs, next: next}, err
+			})
+		}
+		replies, next, err := fetchThread(ctx, searchThreadMaxMessages, fetch)
+		if err != nil {
+			ch.logger.Warn("Failed to fetch thread of search match",
+				zap.String("channel", ref.channelID),
+				zap.String("thread_ts", ref.threadTs),
+				zap.Error(err))
+			failed++
+			continue
+		}
+		if next != "" {
+			capped++
+		}
+		threads[ref.channelName+"/"+ref.threadTs] = ch.convertMessagesFromHistory(ctx, replies, ref.channelName, true)
+	}
+	ch.logger.Debug("Fetched threads of search matches", zap.Int("requested", len(refs)), zap.Int("fetched", len(threads)))
+
+	return groupThreadsUnderMatches(messages, threads), failed, capped
+}
+
+// groupThreadsUnderMatches inserts each thread, keyed by channel and thread
+// ts, right after the first match belonging to it. Later matches already
+// listed as part of an inserted thread are dropped.
+func groupThreadsUnderMatches(messages []Message, threads map[string][]Message) []Message {
+	result := make([]Message, 0, len(messages))
+	listed := make(map[string]struct{})
+	for _, m := range messages {
+		id := m.Channel + "/" + m.MsgID
+		if _, ok := listed[id]; ok {
+			continue
+		}
+		result = append(result, m)
+		listed[id] = struct{}{}
+
+		threadTs := m.ThreadTs
+		if threadTs == "" {
+			threadTs = m.MsgID
+		}
+		key := m.Channel + "/" + threadTs
+		thread, ok := threads[key]
+		if !ok {
+			continue
+		}
+		delete(threads, key)
+		for _, r := range thread {
+			rid := r.Channel + "/" + r.MsgID
+			if _, ok := listed[rid]; ok {
+				continue
+			}
+			result = append(result, r)
+			listed[rid] = struct{}{}
+		}
+	}
+	return result
+}
+
+// ProfileField is a result row of users_profile
+type ProfileField struct {
+	UserID  string `json:"userID"`
+	FieldID string `json:"fieldID"`
+	Label   string `json:"label"`
+	Value   string `json:"value"`
+	Alt     string `json:"alt"`
+}
+
+// UsersProfileHandler returns the custom profile fields of a user, such as
+// department, location or manager, labelled by the workspace field definitions
+func (ch *ConversationsHandler) UsersProfileHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("UsersProfileHandler called", zap.Any("params", request.Params))
+
+	if ready, err := ch.apiProvider.IsReady(); !ready {
+		ch.logger.Error("API provider not ready", zap.Error(err))
+		return nil, err
+	}
+
+	raw := strings.TrimSpace(request.GetString("user", ""))
+	if raw == "" {
+		return nil, errors.New("user must be a string")
+	}
+	mention, err := ch.paramFormatUser(raw)
+	if err != nil {
+		return nil, err
+	}
+	userID := strings.TrimSuffix(strings.TrimPrefix(mention, "<@"), ">")
+
+	profile, err := limiter.CallWithRetry(ctx, limiter.Tier3.Limiter(), 2, provider.SlackRetryAfter, func() (*slack.UserProfile, error) {
+		return ch.apiProvider.Slack().GetUserProfileContext(ctx, &slack.GetUserProfileParameters{UserID: userID, IncludeLabels: true})
+	})
+	if err != nil {
+		var slackErr slack.SlackErrorResponse
+		if !errors.As(err, &slackErr) || slackErr.Err != "missing_scope" {
+			ch.logger.Error("Failed to fetch user profile", zap.String("user", userID), zap.Error(err))
+			return nil, fmt.Errorf("failed to fetch profile of %s: %w", userID, err)
+		}
+		ch.logger.Warn("Token lacks the scope to read user profiles", zap.Error(err))
+		profile = nil
+	}
+
+	var rows []ProfileField
+	if profile != nil {
+		rows = profileFieldRows(userID, profile.FieldsMap(), ch.apiProvider.ProvideProfileFields(ctx))
+	}
+	csvBytes, err := gocsv.MarshalBytes(&rows)
+	if err != nil {
+		ch.logger.Error("Failed to marshal profile fields to CSV", zap.Error(err))
+		return nil, err
+	}
+
+	result := mcp.NewToolResultText(string(csvBytes))
+	if profile == nil {
+		result.Content = append(result.Content, mcp.NewTextContent(
+			"Custom profile fields are not available to this token: the users.profile:read scope is missing",
+		))
+		return result, nil
+	}
+	return withEmptyResultNote(result, len(rows), fmt.Sprintf("%s has no custom profile fields filled in", userID)), nil
+}
+
+// profileFieldRows turns the custom fields of a profile into rows labelled by
+// the workspace field definitions, in the order the workspace defines. Fields
+// without a definition keep the label returned with the profile, or their ID,
+// and come last. Empty fields and fields hidden by the workspace are skipped.
+func profileFieldRows(userID string, fields map[string]slack.UserProfileCustomField, defs map[string]slack.TeamProfileField) []ProfileField {
+	rows := make([]ProfileField, 0, len(fields))
+	for id, f := range fields {
+		if f.Value == "" {
+			continue
+		}
+		label := f.Label
+		if def, ok := defs[id]; ok {
+			if def.IsHidden {
+				continue
+			}
+			if def.Label != "" {
+				label = def.Label
+			}
+		}
+		if label == "" {
+			label = id
+		}
+		rows = append(rows, ProfileField{UserID: userID, FieldID: id, Label: label, Value: f.Value, Alt: f.Alt})
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		di, iok := defs[rows[i].FieldID]
+		dj, jok := defs[rows[j].FieldID]
+		if iok != jok {
+			return iok
+		}
+		if iok && di.Ordering != dj.Ordering {
+			return di.Ordering < dj.Ordering
+		}
+		return rows[i].FieldID < rows[j].FieldID
+	})
+	return rows
+}
+
+// UserByEmail is a result row of users_by_email
+type UserByEmail struct {
+	Email    string `json:"email"`
+	Found    bool   `json:"found"`
+	UserID   string `json:"userID"`
+	UserName string `json:"userName"`
+	RealName string `json:"realName"`
+	Error    string `json:"error"`
+}
+
+// maxUsersByEmail caps the number of emails resolved by a single users_by_email call
+const maxUsersByEmail = 100
+
+// UsersByEmailHandler resolves a comma-separated list of emails to users,
+// preferring the users cache and asking Slack only for the misses
+func (ch *ConversationsHandler) UsersByEmailHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("UsersByEmailHandler called", zap.Any("params", request.Params))
+
+	if ready, err := ch.apiProvider.IsReady(); !ready {
+		ch.logger.Error("API provider not ready", zap.Error(err))
+		return nil, err
+	}
+
+	emails := parseCommaSeparatedList(request.GetString("emails", ""))
+	if len(emails) == 0 {
+		return nil, errors.New("emails must be a comma-separated list of email addresses")
+	}
+	if len(emails) > maxUsersByEmail {
+		return nil, fmt.Errorf("at most %d emails can be resolved at once, got %d", maxUsersByEmail, len(emails))
+	}
+
+	fetch := func(ctx context.Context, email string) (*slack.User, error) {
+		return limiter.CallWithRetry(ctx, limiter.Tier3.Limiter(), 2, provider.SlackRetryAfter, func() (*slack.User, error) {
+			return ch.apiProvider.Slack().GetUserByEmailContext(ctx, email)
+		})
+	}
+	rows, fetched := lookupUsersByEmail(ctx, emails, ch.apiProvider.ProvideUsersMap().Users, fetch, ch.logger)
+	ch.logger.Debug("Resolved users by email", zap.Int("emails", len(rows)), zap.Int("api_lookups", fetched))
+
+	csvBytes, err := gocsv.MarshalBytes(&rows)
+	if err != nil {
+		ch.logger.Error("Failed to marshal users to CSV", zap.Error(err))
+		return nil, err
+	}
+	return mcp.NewToolResultText(string(csvBytes)), nil
+}
+
+// lookupUsersByEmail resolves each email, ignoring case and duplicates, first
+// against the cached users and then with fetch for the misses. Emails Slack
+// does not know are reported with found=false; other lookup failures also
+// carry the error, so they are not mistaken for a missing user. Neither fails
+// the batch. It also returns the number of fetch calls made.
+func lookupUsersByEmail(
+	ctx context.Context,
+	emails []string,
+	users map[string]slack.User,
+	fetch func(ctx context.Context, email string) (*slack.User, error),
+	logger *zap.Logger,
+) ([]UserByEmail, int) {
+	cached := make(map[string]slack.User, len(users))
+	for _, u := range users {
+		key := strings.ToLower(u.Profile.Email)
+		if key == "" {
+			continue
+		}
+		if prev, ok := cached[key]; ok && !prev.Deleted {
+			continue
+		}
+		cached[key] = u
+	}
+
+	rows := make([]UserByEmail, 0, len(emails))
+	seen := make(map[string]bool, len(emails))
+	fetched := 0
+	for _, email := range emails {
+		key := strings.ToLower(email)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		row := UserByEmail{Email: email}
+		u, ok := cached[key]
+		if !ok {
+			fetched++
+			user, err := fetch(ctx, email)
+			if err != nil && !strings.Contains(err.Error(), "users_not_found") {
+				logger.Warn("Failed to look up user by email", zap.String("email", email), zap.Error(err))
+				row.Error = err.Error()
+			}
+			if err != nil || user == nil {
+				rows = append(rows, row)
+				continue
+			}
+			u = *user
+		}
+		row.Found = true
+		row.UserID = u.ID
+		row.UserName = u.Name
+		row.RealName = u.RealName
+		rows = append(rows, row)
+	}
+	return rows, fetched
+}
+
+// UsersRecentActivityHandler returns recent messages posted by a single user across channels, newest first
+func (ch *ConversationsHandler) UsersRecentActivityHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("UsersRecentActivityHandler called", zap.Any("params", request.Params))
+
+	params, err := ch.parseParamsToolRecentActivity(request)
+	if err != nil {
+		ch.logger.Error("Failed to parse recent activity params", zap.Error(err))
+		return nil, err
+	}
+	ch.logger.Debug("Recent activity params parsed", zap.String("query", params.query), zap.Int("limit", params.limit))
+
+	searchParams := slack.SearchParameters{
+		Sort:          "timestamp",
+		SortDirection: "desc",
+		Highlight:     false,
+		Count:         params.limit,
+		Page:          1,
+	}
+	messagesRes, _, err := ch.apiProvider.Slack().SearchContext(ctx, params.query, searchParams)
+	if err != nil {
+		ch.logger.Error("Slack SearchContext failed", zap.Error(err))
+		return nil, err
+	}
+	ch.logger.Debug("Recent activity search completed", zap.Int("matches", len(messagesRes.Matches)))
+
+	matches := filterMatchesByPolicy(messagesRes.Matches, allowedChannelTypes(), ch.apiProvider.ProvideChannelsMaps().Channels)
+	ch.resolveSearchChannelNames(ctx, matches, false)
+	messages := ch.convertMessagesFromSearch(ctx, matches)
+	result, err := marshalMessagesToCSV(messages)
+	if err != nil {
+		return nil, err
+	}
+	return withEmptyResultNote(result, len(messages), "No recent activity found for the user in the given window"), nil
+}
+
+// ActivityItem is a result row of activity_feed
+type ActivityItem struct {
+	Type        string `json:"type"` // "mention", "reaction" or "thread"
+	Time        string `json:"time"`
+	ChannelID   string `json:"channelID"`
+	ChannelName string `json:"channelName"`
+	MsgID       string `json:"msgID"`
+	ThreadTs    string `json:"threadTs"`
+	UserID      string `json:"userID"`
+	UserName    string `json:"userName"`
+	Reaction    string `json:"reaction"`
+	Unread      bool   `json:"unread"`
+	Cursor      string `json:"cursor"`
+}
+
+// activityKinds maps the kinds accepted by activity_feed to the item types of
+// the edge activity.feed API
+var activityKinds = map[string][]string{
+	"mentions":  {edge.ActivityAtUser, edge.ActivityAtUserGroup, edge.ActivityAtChannel, edge.ActivityAtEveryone, edge.ActivityKeyword},
+	"reactions": {edge.ActivityMessageReaction},
+	"threads":   {edge.ActivityThread},
+}
+
+// ActivityFeedHandler returns the user's recent mentions, reactions to their
+// messages and thread activity. Browser session tokens read the activity feed
+// directly; other tokens fall back to searching for mentions.
+func (ch *ConversationsHandler) ActivityFeedHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("ActivityFeedHandler called", zap.Any("params", request.Params))
+
+	if ready, err := ch.apiProvider.IsReady(); !ready {
+		ch.logger.Error("API provider not ready", zap.Error(err))
+		return nil, err
+	}
+
+	kinds, err := parseActivityKinds(request.GetString("types", "mentions,reactions,threads"))
+	if err != nil {
+		return nil, err
+	}
+	limit := request.GetInt("limit", 20)
+	if limit < 1 || limit > 50 {
+		return nil, fmt.Errorf("limit must be between 1 and 50, got %d", limit)
+	}
+	cursor := request.GetString("cursor", "")
+
+	if ch.apiProvider.IsOAuth() {
+		return ch.activityFeedFromSearch(ctx, kinds, limit, cursor)
+	}
+
+	var types []string
+	for _, kind := range kinds {
+		types = append(types, activityKinds[kind]...)
+	}
+	feed, err := limiter.CallWithRetry(ctx, limiter.Tier2.Limiter(), 2, provider.SlackRetryAfter, func() (edge.ActivityFeedResponse, error) {
+		return ch.apiProvider.Slack().ActivityFeed(ctx, types, limit, cursor)
+	})
+	if err != nil {
+		ch.logger.Error("ActivityFeed failed", zap.Error(err))
+		return nil, fmt.Errorf("failed to get activity feed: %w", err)
+	}
+
+	channels := ch.apiProvider.ProvideChannelsMaps().Channels
+	items := filterActivityByPolicy(activityItems(feed.Items, channels, ch.apiProvider.ProvideUsersMap().Users, ch.timeFormat), allowedChannelTypes(), channels)
+	if len(items) > 0 && feed.ResponseMetadata.NextCursor != "" {
+		items[len(items)-1].Cursor = feed.ResponseMetadata.NextCursor
+	}
+	csvBytes, err := gocsv.MarshalBytes(&items)
+	if err != nil {
+		ch.logger.Error("Failed to marshal activity feed to CSV", zap.Error(err))
+		return nil, err
+	}
+	return withEmptyResultNote(mcp.NewToolResultText(string(csvBytes)), len(items), "No recent activity in your feed"), nil
+}
+
+// activityFeedFromSearch lists mentions of the current user found with
+// search.messages, for tokens that cannot read the activity feed
+func (ch *ConversationsHandler) activityFeedFromSearch(ctx context.Context, kinds []string, limit int, cursor string) (*mcp.CallToolResult, error) {
+	page, err := parsePageCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	ar, err := ch.apiProvider.Slack().AuthTest()
+	if err != nil {
+		ch.logger.Error("Slack AuthTest failed", zap.Error(err))
+		return nil, err
+	}
+
+	var items []ActivityItem
+	var paging slack.Paging
+	if slices.Contains(kinds, "mentions") {
+		res, _, err := ch.apiProvider.Slack().SearchContext(ctx, fmt.Sprintf("<@%s>", ar.UserID), slack.SearchParameters{
+			Sort:          "timestamp",
+			SortDirection: "desc",
+			Count:         limit,
+			Page:          page,
+		})
+		if err != nil {
+			ch.logger.Error("Slack SearchContext failed", zap.Error(err))
+			return nil, err
+		}
+		channels := ch.apiProvider.ProvideChannelsMaps().Channels
+		matches := filterMatchesByPolicy(res.Matches, allowedChannelTypes(), channels)
+		items = mentionItemsFromSearch(matches, channels, ch.apiProvider.ProvideUsersMap().Users, ch.timeFormat)
+		paging = res.Paging
+	}
+	if len(items) > 0 && paging.Page < paging.Pages {
+		items[len(items)-1].Cursor = base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("page:%d", paging.Page+1)))
+	}
+
+	csvBytes, err := gocsv.MarshalBytes(&items)
+	if err != nil {
+		ch.logger.Error("Failed to marshal activity feed to CSV", zap.Error(err))
+		return nil, err
+	}
+	result := withEmptyResultNote(mcp.NewToolResultText(string(csvBytes)), len(items), "No recent mentions found")
+	if slices.Contains(kinds, "reactions") || slices.Contains(kinds, "threads") {
+		result.Content = append(result.Content, mcp.NewTextContent(
+			"reactions and thread activity require browser session tokens (xoxc/xoxd), which can read the activity feed; only mentions found by search are returned",
+		))
+	}
+	return result, nil
+}
+
+// parseActivityKinds validates a comma-separated list of activity_feed kinds
+func parseActivityKinds(raw string) ([]string, error) {
+	var kinds []string
+	for _, kind := range strings.Split(raw, ",") {
+		kind = strings.ToLower(strings.TrimSpace(kind))
+		if kind == "" || slices.Contains(kinds, kind) {
+			continue
+		}
+		if _, ok := activityKinds[kind]; !ok {
+			return nil, fmt.Errorf("invalid types value %q, allowed values are mentions, reactions and threads", kind)
+		}
+		kinds = append(kinds, kind)
+	}
+	if len(kinds) == 0 {
+		return nil, errors.New("types must list at least one of mentions, reactions and threads")
+	}
+	return kinds, nil
+}
+
+// activityItems maps activity feed entries to rows, resolving channel and user
+// names from the caches. UserID is the author of a mention, the user who
+// reacted, or empty for thread activity. Unknown item types are skipped.
+func activityItems(feed []edge.ActivityItem, channels map[string]provider.Channel, users map[string]slack.User, tf text.TimeFormat) []ActivityItem {
+	items := make([]ActivityItem, 0, len(feed))
+	for _, entry := range feed {
+		item := ActivityItem{Unread: entry.IsUnread}
+		ts := entry.FeedTs
+		switch entry.Item.Type {
+		case edge.ActivityAtUser, edge.ActivityAtUserGroup, edge.ActivityAtChannel, edge.ActivityAtEveryone, edge.ActivityKeyword:
+			msg := entry.Item.Message
+			if msg == nil {
+				continue
+			}
+			item.Type = "mention"
+			item.ChannelID, item.MsgID, item.ThreadTs, item.UserID = msg.Channel, msg.Ts, msg.ThreadTs, msg.AuthorUserID
+		case edge.ActivityMessageReaction:
+			msg := entry.Item.Message
+			if msg == nil {
+				continue
+			}
+			item.Type = "reaction"
+			item.ChannelID, item.MsgID, item.ThreadTs = msg.Channel, msg.Ts, msg.ThreadTs
+			if r := entry.Item.Reaction; r != nil {
+				item.UserID, item.Reaction = r.User, r.Name
+			}
+		case edge.ActivityThread:
+			if entry.Item.BundleInfo == nil {
+				continue
+			}
+			thread := entry.Item.BundleInfo.Payload.ThreadEntry
+			item.Type = "thread"
+			item.ChannelID, item.MsgID, item.ThreadTs = thread.ChannelID, thread.LatestTs, thread.ThreadTs
+		default:
+			continue
+		}
+		if ts == "" {
+			ts = item.MsgID
+		}
+		item.Time, _ = tf.FormatTimestamp(ts)
+		if c, ok := channels[item.ChannelID]; ok {
+			item.ChannelName = c.Name
+		}
+		if u, ok := users[item.UserID]; ok {
+			item.UserName = u.Name
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// filterActivityByPolicy drops activity rows from channels whose type the
+// policy does not allow
+func filterActivityByPolicy(items []ActivityItem, policy channelTypePolicy, channels map[string]provider.Channel) []ActivityItem {
+	if policy == nil {
+		return items
+	}
+	kept := make([]ActivityItem, 0, len(items))
+	for _, item := range items {
+		if policy.allows(channelTypeByID(item.ChannelID, channels)) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// mentionItemsFromSearch maps search matches for the current user's mentions
+// to activity_feed rows. Search does not tell read from unread messages.
+func mentionItemsFromSearch(matches []slack.SearchMessage, channels map[string]provider.Channel, users map[string]slack.User, tf text.TimeFormat) []ActivityItem {
+	items := make([]ActivityItem, 0, len(matches))
+	for _, m := range matches {
+		item := ActivityItem{
+			Type:        "mention",
+			ChannelID:   m.Channel.ID,
+			ChannelName: m.Channel.Name,
+			MsgID:       m.Timestamp,
+			UserID:      m.User,
+			UserName:    m.Username,
+		}
+		item.Time, _ = tf.FormatTimestamp(m.Timestamp)
+		if c, ok := channels[m.Channel.ID]; ok {
+			item.ChannelName = c.Name
+		}
+		if u, ok := users[m.User]; ok {
+			item.UserName = u.Name
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// UnreadChannel represents a channel with unread messages
+type UnreadChannel struct {
+	ChannelID   string `json:"channelID"`
+	ChannelName string `json:"channelName"`
+	ChannelType string `json:"channelType"` // "dm", "group_dm", "partner", "internal"
+	UnreadCount int    `json:"unreadCount"`
+	LastRead    string `json:"lastRead"`
+	Latest      string `json:"latest"`
+}
+
+// UnreadTotal is a result row of conversations_unreads with counts_only,
+// summing the conversations of one type that have unreads
+type UnreadTotal struct {
+	Type     string `json:"type"` // "dm", "group_dm", "partner", "internal" or "total"
+	Channels int    `json:"channels"`
+	Mentions int    `json:"mentions"`
+}
+
+// UnreadMessage extends Message with channel context
+type UnreadMessage struct {
+	Message
+	ChannelType string `json:"channelType"`
+}
+
+// UnreadChannelMessages nests the unread messages of a channel under it, used
+// when unreads are requested with group_by_channel=true
+type UnreadChannelMessages struct {
+	UnreadChannel
+	Messages []Message `json:"messages"`
+}
+
+// ConversationsUnreadsHandler returns unread messages across all channels
+func (ch *ConversationsHandler) ConversationsUnreadsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("ConversationsUnreadsHandler called", zap.Any("params", request.Params))
+
+	params := ch.parseParamsToolUnreads(request)
+
+	// Fetch muted channels unless the caller wants them included
+	if !params.includeMuted {
+		mutedChannels, err := ch.apiProvider.Slack().GetMutedChannels(ctx)
+		if err != nil {
+			ch.logger.Warn("Failed to fetch muted channels, proceeding without mute filter", zap.Error(err))
+			params.mutedUnavailable = true
+		} else if len(mutedChannels) > 0 {
+			params.mutedChannels = mutedChannels
+			ch.logger.Debug("Loaded muted channels", zap.Int("count", len(mutedChannels)))
+		}
+	}
+
+	// Route based on token type:
+	// - xoxc/xoxd (browser session): use fast client.counts API
+	// - xoxp (OAuth user): fall back to conversations.info/history approach
+	// - xoxb (bot): not supported — unreads is a user-level concept
+	if ch.apiProvider.IsOAuth() {
+		if ch.apiProvider.IsBotToken() {
+			return nil, fmt.Errorf(
+				"conversations_unreads requires a user token (xoxp) or browser session tokens (xoxc/xoxd); " +
+					"bot tokens (xoxb) do not support unread tracking",
+			)
+		}
+		if params.countsOnly {
+			return nil, errors.New("counts_only requires browser session tokens (xoxc/xoxd), which provide client.counts")
+		}
+		ch.logger.Info("OAuth token detected, using conversations.info fallback for unreads")
+		return ch.getUnreadsViaConversationsInfo(ctx, params)
+	}
+
+	counts, err := fetchClientCounts(ctx, ch.apiProvider.Slack().ClientCounts)
+	if err != nil {
+		ch.logger.Error("ClientCounts failed", zap.Error(err))
+		return nil, fmt.Errorf("failed to get client counts: %w", err)
+	}
+
+	if params.countsOnly {
+		totals := unreadTotals(params, counts, ch.apiProvider.ProvideChannelsMaps().Channels)
+		csvBytes, err := gocsv.MarshalBytes(&totals)
+		if err != nil {
+			ch.logger.Error("Failed to marshal unread totals to CSV", zap.Error(err))
+			return nil, err
+		}
+		result := mcp.NewToolResultText(string(csvBytes))
+		result.Content = append(result.Content, mcp.NewTextContent(
+			"counts are approximate: mentions count @mentions and DM messages, channels with unreads but no mentions only add to the channels column",
+		))
+		return result, nil
+	}
+
+	return ch.processClientCountsResponse(ctx, params, counts)
 }
 
 func (ch *ConversationsHandler) processClientCountsResponse(ctx context.Context, params *unreadsParams, counts edge.ClientCountsResponse) (*mcp.CallToolResult, error) {
@@ -687,6 +3237,7 @@ func (ch *ConversationsHandler) processClientCountsResponse(ctx context.Context,
 
 	// Collect channels with unreads
 	var unreadChannels []UnreadChannel
+	policy := allowedChannelTypes()
 
 	// Process regular channels (public, private)
 	for _, snap := range counts.Channels {
@@ -704,6 +3255,10 @@ func (ch *ConversationsHandler) processClientCountsResponse(ctx context.Context,
 			continue
 		}
 
+		if !policy.allows(channelTypeByID(snap.ID, channelsMaps.Channels)) {
+			continue
+		}
+
 		// Get channel info from cache to determine type and name
 		channelName := snap.ID
 		channelType := "internal"
@@ -756,6 +3311,9 @@ func (ch *ConversationsHandler) processClientCountsResponse(ctx context.Context,
 		if params.channelTypes != "all" && params.channelTypes != "group_dm" {
 			continue
 		}
+		if !policy.allows("mpim") {
+			continue
+		}
 
 		channelName := snap.ID
 		if cached, ok := channelsMaps.Channels[snap.ID]; ok {
@@ -792,6 +3350,9 @@ func (ch *ConversationsHandler) processClientCountsResponse(ctx context.Context,
 		if params.channelTypes != "all" && params.channelTypes != "dm" {
 			continue
 		}
+		if !policy.allows("im") {
+			continue
+		}
 
 		// Get display name for DM from channel cache or users
 		channelName := snap.ID
@@ -835,6 +3396,7 @@ func (ch *ConversationsHandler) processClientCountsResponse(ctx context.Context,
 	// matters less than surfacing that unreads exist.
 	const backfillLimit = 20
 	backfilled := 0
+	var errs channelErrors
 	for i := range unreadChannels {
 		if unreadChannels[i].UnreadCount > 0 {
 			continue // MentionCount was positive, good enough
@@ -857,6 +3419,7 @@ func (ch *ConversationsHandler) processClientCountsResponse(ctx context.Context,
 			ch.logger.Debug("Failed to backfill unread count",
 				zap.String("channel", unreadChannels[i].ChannelID),
 				zap.Error(err))
+			errs.Add(unreadChannels[i].ChannelID, err)
 			continue
 		}
 		if len(history.Messages) > 0 {
@@ -871,11 +3434,16 @@ func (ch *ConversationsHandler) processClientCountsResponse(ctx context.Context,
 
 	// If not including messages, just return channel summary
 	if !params.includeMessages {
-		return ch.marshalUnreadChannelsToCSV(unreadChannels)
+		result, err := ch.marshalUnreadChannelsToCSV(unreadChannels)
+		if err != nil {
+			return nil, err
+		}
+		return errs.AppendTo(result), nil
 	}
 
 	// Fetch messages for each unread channel
 	var allMessages []Message
+	var grouped []UnreadChannelMessages
 
 	for i := range unreadChannels {
 		historyParams := slack.GetConversationHistoryParameters{
@@ -890,6 +3458,7 @@ func (ch *ConversationsHandler) processClientCountsResponse(ctx context.Context,
 			ch.logger.Warn("Failed to get history for channel",
 				zap.String("channel", unreadChannels[i].ChannelID),
 				zap.Error(err))
+			errs.Add(unreadChannels[i].ChannelID, err)
 			continue
 		}
 
@@ -897,13 +3466,77 @@ func (ch *ConversationsHandler) processClientCountsResponse(ctx context.Context,
 		unreadChannels[i].UnreadCount = len(history.Messages)
 
 		// Convert messages
-		channelMessages := ch.convertMessagesFromHistory(history.Messages, unreadChannels[i].ChannelName, false)
+		channelMessages := ch.convertMessagesFromHistory(ctx, history.Messages, unreadChannels[i].ChannelName, false)
 		allMessages = append(allMessages, channelMessages...)
+		grouped = append(grouped, groupUnreadMessages(unreadChannels[i], channelMessages))
+	}
+
+	ch.logger.Debug("Fetched unread messages", zap.Int("total", len(allMessages)))
+
+	var (
+		result *mcp.CallToolResult
+		err    error
+	)
+	if params.groupByChannel {
+		result, err = marshalGroupedUnreadsToJSON(grouped)
+	} else {
+		result, err = marshalMessagesToCSV(allMessages)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return errs.AppendTo(result), nil
+}
+
+// unreadTotals sums client.counts per conversation type without any further
+// API calls, applying the same mute, mention, policy and type filters as the
+// full listing. Channels with unreads but no mentions are counted in Channels
+// only, since their unread count is not known without reading history.
+func unreadTotals(params *unreadsParams, counts edge.ClientCountsResponse, channels map[string]provider.Channel) []UnreadTotal {
+	order := []string{"dm", "group_dm", "partner", "internal"}
+	byType := make(map[string]*UnreadTotal, len(order))
+	for _, t := range order {
+		byType[t] = &UnreadTotal{Type: t}
+	}
+	policy := allowedChannelTypes()
+
+	add := func(snaps []edge.ChannelSnapshot, policyType, channelType func(id string) string) {
+		for _, snap := range snaps {
+			if !snap.HasUnreads || params.mutedChannels[snap.ID] {
+				continue
+			}
+			if params.mentionsOnly && snap.MentionCount == 0 {
+				continue
+			}
+			if !policy.allows(policyType(snap.ID)) {
+				continue
+			}
+			t := channelType(snap.ID)
+			if params.channelTypes != "all" && params.channelTypes != t {
+				continue
+			}
+			byType[t].Channels++
+			byType[t].Mentions += snap.MentionCount
+		}
 	}
+	fixed := func(t string) func(string) string { return func(string) string { return t } }
+	add(counts.Channels, func(id string) string { return channelTypeByID(id, channels) }, func(id string) string {
+		if c, ok := channels[id]; ok && c.IsExtShared {
+			return "partner"
+		}
+		return "internal"
+	})
+	add(counts.MPIMs, fixed("mpim"), fixed("group_dm"))
+	add(counts.IMs, fixed("im"), fixed("dm"))
 
-	ch.logger.Debug("Fetched unread messages", zap.Int("total", len(allMessages)))
-
-	return marshalMessagesToCSV(allMessages)
+	totals := make([]UnreadTotal, 0, len(order)+1)
+	sum := UnreadTotal{Type: "total"}
+	for _, t := range order {
+		totals = append(totals, *byType[t])
+		sum.Channels += byType[t].Channels
+		sum.Mentions += byType[t].Mentions
+	}
+	return append(totals, sum)
 }
 
 func (ch *ConversationsHandler) getUnreadsViaConversationsInfo(ctx context.Context, params *unreadsParams) (*mcp.CallToolResult, error) {
@@ -962,96 +3595,672 @@ func (ch *ConversationsHandler) getUnreadsViaConversationsInfo(ctx context.Conte
 		totalRateLimited += rateLimited
 	}
 
-	ch.sortChannelsByPriority(unreadChannels)
+	ch.sortChannelsByPriority(unreadChannels)
+
+	ch.logger.Info("Found unread channels via xoxp fallback",
+		zap.Int("count", len(unreadChannels)),
+		zap.Int("scanned", totalScanned),
+		zap.Int("apiCalls", totalAPIcalls),
+		zap.Int("rateLimited", totalRateLimited))
+
+	// Prepend a note about xoxp limitations so the LLM understands
+	// these results may be partial.
+	mutedNote := ""
+	if params.mutedUnavailable && !params.includeMuted {
+		mutedNote = "Muted channel filtering is unavailable with xoxp tokens; results may include muted channels. "
+	}
+	rateLimitNote := ""
+	if totalRateLimited > 0 {
+		rateLimitNote = fmt.Sprintf("WARNING: %d channels were skipped due to Slack rate limiting (even after retries) — results are degraded. Try again after a brief cooldown. ", totalRateLimited)
+	}
+	xoxpNote := fmt.Sprintf(
+		"[xoxp token: scanned %d channels (%d API calls), found %d with unreads. %s%s"+
+			"Results may be incomplete — increase max_channels for broader coverage, "+
+			"or use xoxc/xoxd browser tokens for complete results.]\n\n",
+		totalScanned, totalAPIcalls, len(unreadChannels), rateLimitNote, mutedNote,
+	)
+
+	if !params.includeMessages {
+		result, err := ch.marshalUnreadChannelsToCSV(unreadChannels)
+		if err != nil {
+			return nil, err
+		}
+		// Prepend the xoxp note to the CSV output
+		if len(result.Content) > 0 {
+			if tc, ok := result.Content[0].(mcp.TextContent); ok {
+				tc.Text = xoxpNote + tc.Text
+				result.Content[0] = tc
+			}
+		}
+		return result, nil
+	}
+
+	// Fetch actual unread messages for each discovered channel
+	rl := limiter.Tier3.Limiter()
+	var allMessages []Message
+	var grouped []UnreadChannelMessages
+	var errs channelErrors
+	for _, uc := range unreadChannels {
+		historyParams := slack.GetConversationHistoryParameters{
+			ChannelID: uc.ChannelID,
+			Oldest:    uc.LastRead,
+			Limit:     params.maxMessagesPerChannel,
+			Inclusive: false,
+		}
+
+		history, err := limiter.CallWithRetry(ctx, rl, 2, provider.SlackRetryAfter, func() (*slack.GetConversationHistoryResponse, error) {
+			return ch.apiProvider.Slack().GetConversationHistoryContext(ctx, &historyParams)
+		})
+		if err != nil {
+			ch.logger.Warn("Failed to get history for channel",
+				zap.String("channel", uc.ChannelID),
+				zap.Error(err))
+			errs.Add(uc.ChannelID, err)
+			continue
+		}
+
+		channelMessages := ch.convertMessagesFromHistory(ctx, history.Messages, uc.ChannelName, false)
+		allMessages = append(allMessages, channelMessages...)
+		grouped = append(grouped, groupUnreadMessages(uc, channelMessages))
+	}
+
+	ch.logger.Debug("Fetched unread messages via fallback", zap.Int("total", len(allMessages)))
+
+	var (
+		result *mcp.CallToolResult
+		err    error
+	)
+	if params.groupByChannel {
+		result, err = marshalGroupedUnreadsToJSON(grouped)
+	} else {
+		result, err = marshalMessagesToCSV(allMessages)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Prepend the xoxp note to the messages CSV output
+	if len(result.Content) > 0 {
+		if tc, ok := result.Content[0].(mcp.TextContent); ok {
+			tc.Text = xoxpNote + tc.Text
+			result.Content[0] = tc
+		}
+	}
+	return errs.AppendTo(result), nil
+}
+
+// maxLatestChannels caps the channels accepted by conversations_latest, each
+// one costs a conversations.history call
+const maxLatestChannels = 50
+
+// ConversationsLatestHandler returns the latest message of each of several
+// channels, for a glanceable view of watched channels
+func (ch *ConversationsHandler) ConversationsLatestHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("ConversationsLatestHandler called", zap.Any("params", request.Params))
+
+	if ready, err := ch.apiProvider.IsReady(); !ready {
+		ch.logger.Error("API provider not ready", zap.Error(err))
+		return nil, err
+	}
+
+	var raws []string
+	for _, raw := range strings.Split(request.GetString("channel_ids", ""), ",") {
+		if raw = strings.TrimSpace(raw); raw != "" && !slices.Contains(raws, raw) {
+			raws = append(raws, raw)
+		}
+	}
+	if len(raws) == 0 {
+		return nil, errors.New("channel_ids is required")
+	}
+	if len(raws) > maxLatestChannels {
+		return nil, fmt.Errorf("channel_ids accepts at most %d channels, got %d", maxLatestChannels, len(raws))
+	}
+
+	var (
+		errs     channelErrors
+		channels []string
+	)
+	for _, raw := range raws {
+		id, err := ch.resolveAllowedChannel(ctx, raw)
+		if err != nil {
+			errs.Add(raw, err)
+			continue
+		}
+		if !slices.Contains(channels, id) {
+			channels = append(channels, id)
+		}
+	}
+
+	rl := limiter.Tier3.Limiter()
+	latest := latestPerChannel(ctx, channels, func(ctx context.Context, channel string) ([]slack.Message, error) {
+		history, err := limiter.CallWithRetry(ctx, rl, 2, provider.SlackRetryAfter, func() (*slack.GetConversationHistoryResponse, error) {
+			return ch.apiProvider.Slack().GetConversationHistoryContext(ctx, &slack.GetConversationHistoryParameters{
+				ChannelID: channel,
+				Limit:     1,
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+		return history.Messages, nil
+	}, &errs, ch.logger)
+
+	var (
+		messages []Message
+		empty    []string
+	)
+	for _, l := range latest {
+		if l.message == nil {
+			empty = append(empty, ch.channelLabel(l.channel))
+			continue
+		}
+		// The latest message is returned whatever its kind, e.g. a channel join
+		messages = append(messages, ch.convertMessagesFromHistory(ctx, []slack.Message{*l.message}, l.channel, true)...)
+	}
+
+	result, err := marshalMessagesToCSV(messages)
+	if err != nil {
+		return nil, err
+	}
+	if len(empty) > 0 {
+		result.Content = append(result.Content, mcp.NewTextContent("no messages in: "+strings.Join(empty, ", ")))
+	}
+	return errs.AppendTo(result), nil
+}
+
+// channelLatest is the latest message of a channel, nil when it has none
+type channelLatest struct {
+	channel string
+	message *slack.Message
+}
+
+// latestPerChannel fetches the latest message of each channel in turn, in the
+// order given. A failing channel is added to errs and left out of the results.
+func latestPerChannel(ctx context.Context, channels []string, fetch func(ctx context.Context, channel string) ([]slack.Message, error), errs *channelErrors, logger *zap.Logger) []channelLatest {
+	latest := make([]channelLatest, 0, len(channels))
+	for _, channel := range channels {
+		if err := ctx.Err(); err != nil {
+			errs.Add(channel, err)
+			continue
+		}
+		msgs, err := fetch(ctx, channel)
+		if err != nil {
+			logger.Warn("Failed to get latest message of channel", zap.String("channel", channel), zap.Error(err))
+			errs.Add(channel, err)
+			continue
+		}
+		l := channelLatest{channel: channel}
+		if len(msgs) > 0 {
+			l.message = &msgs[0]
+		}
+		latest = append(latest, l)
+	}
+	return latest
+}
+
+// MyDM is a direct message or group DM of the authed user with its latest message
+type MyDM struct {
+	ChannelID    string `json:"channelID"`
+	ChannelType  string `json:"channelType"` // "dm" or "group_dm"
+	Participants string `json:"participants"`
+	LatestTs     string `json:"latestTs"`
+	LatestTime   string `json:"latestTime"`
+	LatestUser   string `json:"latestUser"`
+	Preview      string `json:"preview"`
+}
+
+const (
+	// myDMsMaxScan caps how many DMs are fetched per call, each one costs a
+	// conversations.history call
+	myDMsMaxScan = 100
+	// myDMPreviewLen is the maximum length of a message preview in runes
+	myDMPreviewLen = 200
+)
+
+// ConversationsMyDMsHandler lists the DMs and group DMs of the authed user
+// sorted by latest activity, with a preview of the latest message of each
+func (ch *ConversationsHandler) ConversationsMyDMsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("ConversationsMyDMsHandler called", zap.Any("params", request.Params))
+
+	if ready, err := ch.apiProvider.IsReady(); !ready {
+		ch.logger.Error("API provider not ready", zap.Error(err))
+		return nil, err
+	}
+
+	limit := request.GetInt("limit", 20)
+	if limit < 1 || limit > 100 {
+		return nil, errors.New("limit must be between 1 and 100")
+	}
+
+	types := dmTypesAllowed(allowedChannelTypes())
+	if len(types) == 0 {
+		return nil, errors.New("conversations_my_dms lists DMs and group DMs, neither of which SLACK_MCP_ALLOWED_CHANNEL_TYPES allows")
+	}
+
+	// client.counts knows the latest activity of every DM, so the scan can
+	// start with the most recent ones instead of Slack's listing order
+	var latest map[string]time.Time
+	if !ch.apiProvider.IsOAuth() {
+		counts, err := fetchClientCounts(ctx, ch.apiProvider.Slack().ClientCounts)
+		if err != nil {
+			ch.logger.Warn("ClientCounts failed, scanning DMs in listing order", zap.Error(err))
+		} else {
+			latest = latestActivity(counts)
+		}
+	}
+
+	usersMap := ch.apiProvider.ProvideUsersMap().Users
+	channelsMaps := ch.apiProvider.ProvideChannelsMaps()
+	rl := limiter.Tier3.Limiter()
+
+	var (
+		candidates []slack.Channel
+		cursor     string
+	)
+	// Without activity data only the first myDMsMaxScan DMs are listed,
+	// with it all of them are, to be ordered before the scan
+	for latest != nil || len(candidates) < myDMsMaxScan {
+		page, err := limiter.CallWithRetry(ctx, rl, 2, provider.SlackRetryAfter, func() (dmPage, error) {
+			channels, next, err := ch.apiProvider.Slack().GetConversationsForUserContext(ctx, &slack.GetConversationsForUserParameters{
+				Types:           types,
+				Limit:           200,
+				ExcludeArchived: true,
+				Cursor:          cursor,
+			})
+			return dmPage{channels: channels, next: next}, err
+		})
+		if err != nil {
+			ch.logger.Error("Failed to list DMs", zap.Error(err))
+			return nil, fmt.Errorf("failed to list DMs: %w", err)
+		}
+		candidates = append(candidates, page.channels...)
+
+		if page.next == "" || len(page.channels) == 0 {
+			break
+		}
+		cursor = page.next
+	}
+	if latest != nil {
+		orderDMsByActivity(candidates, latest)
+	}
+	if len(candidates) > myDMsMaxScan {
+		candidates = candidates[:myDMsMaxScan]
+	}
+
+	var (
+		dms  []MyDM
+		errs channelErrors
+	)
+	for _, c := range candidates {
+		history, err := limiter.CallWithRetry(ctx, rl, 2, provider.SlackRetryAfter, func() (*slack.GetConversationHistoryResponse, error) {
+			return ch.apiProvider.Slack().GetConversationHistoryContext(ctx, &slack.GetConversationHistoryParameters{
+				ChannelID: c.ID,
+				Limit:     1,
+			})
+		})
+		if err != nil {
+			ch.logger.Warn("Failed to get latest DM message", zap.String("channel", c.ID), zap.Error(err))
+			errs.Add(c.ID, err)
+			continue
+		}
+
+		cached, found := channelsMaps.Channels[c.ID]
+		var latestMsg *Message
+		if msgs := ch.convertMessagesFromHistory(ctx, history.Messages, c.ID, true); len(msgs) > 0 {
+			latestMsg = &msgs[0]
+		}
+		dms = append(dms, newMyDM(c, dmParticipants(c, cached, found, usersMap), latestMsg))
+	}
+
+	sortDMsByLatest(dms)
+	if len(dms) > limit {
+		dms = dms[:limit]
+	}
+
+	ch.logger.Debug("Collected DMs", zap.Int("scanned", len(candidates)), zap.Int("returned", len(dms)))
+
+	csvBytes, err := gocsv.MarshalBytes(&dms)
+	if err != nil {
+		ch.logger.Error("Failed to marshal DMs to CSV", zap.Error(err))
+		return nil, err
+	}
+	return errs.AppendTo(mcp.NewToolResultText(string(csvBytes))), nil
+}
+
+// orderDMsByActivity sorts DMs by their latest activity, most recent first.
+// DMs missing from latest keep their listing order after the others.
+func orderDMsByActivity(dms []slack.Channel, latest map[string]time.Time) {
+	sort.SliceStable(dms, func(i, j int) bool {
+		ti, oki := latest[dms[i].ID]
+		tj, okj := latest[dms[j].ID]
+		if oki != okj {
+			return oki
+		}
+		return ti.After(tj)
+	})
+}
+
+// dmTypesAllowed returns the DM conversation types the policy allows
+func dmTypesAllowed(policy channelTypePolicy) []string {
+	var types []string
+	for _, t := range []string{"im", "mpim"} {
+		if policy.allows(t) {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// dmPage is a page of users.conversations results, so a page can be fetched
+// through limiter.CallWithRetry
+type dmPage struct {
+	channels []slack.Channel
+	next     string
+}
+
+// dmParticipants describes the other participants of a DM or group DM using the
+// users and channels caches, falling back to user IDs when they are unknown
+func dmParticipants(c slack.Channel, cached provider.Channel, found bool, users map[string]slack.User) string {
+	if c.IsIM {
+		userID := c.User
+		if userID == "" && found {
+			userID = cached.User
+		}
+		if u, ok := users[userID]; ok {
+			return "@" + u.Name + " (" + u.RealName + ")"
+		}
+		return userID
+	}
+
+	if found && strings.HasPrefix(cached.Purpose, "Group DM with ") {
+		return strings.TrimPrefix(cached.Purpose, "Group DM with ")
+	}
+	members := c.Members
+	if len(members) == 0 && found {
+		members = cached.Members
+	}
+	if len(members) == 0 {
+		return c.Name
+	}
+	names := make([]string, 0, len(members))
+	for _, id := range members {
+		if u, ok := users[id]; ok {
+			names = append(names, u.RealName)
+		} else {
+			names = append(names, id)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// newMyDM builds the summary row of a DM from its latest message, which is nil
+// for DMs without messages
+func newMyDM(c slack.Channel, participants string, latest *Message) MyDM {
+	dm := MyDM{
+		ChannelID:    c.ID,
+		ChannelType:  "dm",
+		Participants: participants,
+	}
+	if c.IsMpIM {
+		dm.ChannelType = "group_dm"
+	}
+	if latest != nil {
+		dm.LatestTs = latest.MsgID
+		dm.LatestTime = latest.Time
+		dm.LatestUser = latest.UserName
+		dm.Preview = previewText(latest.Text, myDMPreviewLen)
+	}
+	return dm
+}
+
+// previewText shortens s to at most n runes, marking cut text with "..."
+func previewText(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return strings.TrimSpace(string(runes[:n])) + "..."
+}
+
+// sortDMsByLatest orders DMs by their latest message, newest first. DMs
+// without messages go last.
+func sortDMsByLatest(dms []MyDM) {
+	sort.SliceStable(dms, func(i, j int) bool {
+		if dms[i].LatestTs == "" || dms[j].LatestTs == "" {
+			return dms[j].LatestTs == "" && dms[i].LatestTs != ""
+		}
+		return compareSlackTs(dms[i].LatestTs, dms[j].LatestTs) > 0
+	})
+}
+
+// Participant is a result row of conversations_participants
+type Participant struct {
+	UserID   string `json:"userID"`
+	UserName string `json:"userName"`
+	RealName string `json:"realName"`
+}
+
+// ConversationsParticipantsHandler lists who is in a DM or group DM
+func (ch *ConversationsHandler) ConversationsParticipantsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("ConversationsParticipantsHandler called", zap.Any("params", request.Params))
+
+	if ready, err := ch.apiProvider.IsReady(); !ready {
+		ch.logger.Error("API provider not ready", zap.Error(err))
+		return nil, err
+	}
+
+	channel := strings.TrimSpace(request.GetString("channel_id", ""))
+	if channel == "" {
+		return nil, errors.New("channel_id is required")
+	}
+	channel, err := ch.resolveAllowedChannel(ctx, channel)
+	if err != nil {
+		return nil, err
+	}
+	channelsMaps := ch.apiProvider.ProvideChannelsMaps()
+
+	rl := limiter.Tier3.Limiter()
+	info, err := limiter.CallWithRetry(ctx, rl, 2, provider.SlackRetryAfter, func() (*slack.Channel, error) {
+		return ch.apiProvider.Slack().GetConversationInfoContext(ctx, &slack.GetConversationInfoInput{ChannelID: channel})
+	})
+	if err != nil {
+		// The cache still knows the type and members of most DMs
+		ch.logger.Warn("Failed to fetch conversation info, falling back to cache", zap.String("channel", channel), zap.Error(err))
+		info = nil
+	}
+	cached, found := channelsMaps.Channels[channel]
+
+	ids, err := conversationParticipants(ctx, channel, info, cached, found, func(ctx context.Context, id string) ([]string, error) {
+		var members []string
+		cursor := ""
+		for {
+			page, err := limiter.CallWithRetry(ctx, rl, 2, provider.SlackRetryAfter, func() (memberPage, error) {
+				ids, next, err := ch.apiProvider.Slack().GetUsersInConversationContext(ctx, &slack.GetUsersInConversationParameters{
+					ChannelID: id,
+					Cursor:    cursor,
+					Limit:     200,
+				})
+				return memberPage{members: ids, next: next}, err
+			})
+			if err != nil {
+				return nil, err
+			}
+			members = append(members, page.members...)
+			if page.next == "" {
+				return members, nil
+			}
+			cursor = page.next
+		}
+	})
+	if err != nil {
+		ch.logger.Error("Failed to resolve participants", zap.String("channel", channel), zap.Error(err))
+		return nil, err
+	}
+
+	participants := resolveParticipants(ids, ch.apiProvider.ProvideUsersMap().Users)
+	csvBytes, err := gocsv.MarshalBytes(&participants)
+	if err != nil {
+		ch.logger.Error("Failed to marshal participants to CSV", zap.Error(err))
+		return nil, err
+	}
+	return mcp.NewToolResultText(string(csvBytes)), nil
+}
 
-	ch.logger.Info("Found unread channels via xoxp fallback",
-		zap.Int("count", len(unreadChannels)),
-		zap.Int("scanned", totalScanned),
-		zap.Int("apiCalls", totalAPIcalls),
-		zap.Int("rateLimited", totalRateLimited))
+// memberPage is one page of conversations.members
+type memberPage struct {
+	members []string
+	next    string
+}
 
-	// Prepend a note about xoxp limitations so the LLM understands
-	// these results may be partial.
-	mutedNote := ""
-	if params.mutedUnavailable && !params.includeMuted {
-		mutedNote = "Muted channel filtering is unavailable with xoxp tokens; results may include muted channels. "
+// conversationParticipants returns the user IDs in a DM or group DM: the other
+// user of an IM, or the members of an mpim. info may be nil when
+// conversations.info failed, in which case the cached channel is used.
+func conversationParticipants(
+	ctx context.Context,
+	channel string,
+	info *slack.Channel,
+	cached provider.Channel,
+	found bool,
+	listMembers func(ctx context.Context, id string) ([]string, error),
+) ([]string, error) {
+	isIM, isMpIM, user := cached.IsIM, cached.IsMpIM, cached.User
+	if info != nil {
+		isIM, isMpIM = info.IsIM, info.IsMpIM
+		if info.User != "" {
+			user = info.User
+		}
 	}
-	rateLimitNote := ""
-	if totalRateLimited > 0 {
-		rateLimitNote = fmt.Sprintf("WARNING: %d channels were skipped due to Slack rate limiting (even after retries) — results are degraded. Try again after a brief cooldown. ", totalRateLimited)
+	if info == nil && !found {
+		return nil, fmt.Errorf("channel %q not found: it is not cached and conversations.info failed", channel)
 	}
-	xoxpNote := fmt.Sprintf(
-		"[xoxp token: scanned %d channels (%d API calls), found %d with unreads. %s%s"+
-			"Results may be incomplete — increase max_channels for broader coverage, "+
-			"or use xoxc/xoxd browser tokens for complete results.]\n\n",
-		totalScanned, totalAPIcalls, len(unreadChannels), rateLimitNote, mutedNote,
-	)
 
-	if !params.includeMessages {
-		result, err := ch.marshalUnreadChannelsToCSV(unreadChannels)
-		if err != nil {
-			return nil, err
+	switch {
+	case isIM:
+		if user == "" {
+			return nil, fmt.Errorf("could not determine the other user of DM %q", channel)
 		}
-		// Prepend the xoxp note to the CSV output
-		if len(result.Content) > 0 {
-			if tc, ok := result.Content[0].(mcp.TextContent); ok {
-				tc.Text = xoxpNote + tc.Text
-				result.Content[0] = tc
-			}
+		return []string{user}, nil
+	case isMpIM:
+		members, err := listMembers(ctx, channel)
+		if err == nil {
+			return members, nil
 		}
-		return result, nil
+		if found && len(cached.Members) > 0 {
+			return cached.Members, nil
+		}
+		return nil, fmt.Errorf("failed to list members of group DM %q: %w", channel, err)
+	default:
+		return nil, fmt.Errorf("channel %q is not a DM or group DM", channel)
 	}
+}
 
-	// Fetch actual unread messages for each discovered channel
-	rl := limiter.Tier3.Limiter()
-	var allMessages []Message
-	for _, uc := range unreadChannels {
-		historyParams := slack.GetConversationHistoryParameters{
-			ChannelID: uc.ChannelID,
-			Oldest:    uc.LastRead,
-			Limit:     params.maxMessagesPerChannel,
-			Inclusive: false,
+// resolveParticipants maps user IDs to handles, keeping the bare ID for users
+// missing from the cache
+func resolveParticipants(ids []string, users map[string]slack.User) []Participant {
+	participants := make([]Participant, 0, len(ids))
+	for _, id := range ids {
+		p := Participant{UserID: id}
+		if u, ok := users[id]; ok {
+			p.UserName = u.Name
+			p.RealName = u.RealName
 		}
+		participants = append(participants, p)
+	}
+	return participants
+}
 
-		history, err := limiter.CallWithRetry(ctx, rl, 2, slackRetryAfter, func() (*slack.GetConversationHistoryResponse, error) {
-			return ch.apiProvider.Slack().GetConversationHistoryContext(ctx, &historyParams)
-		})
-		if err != nil {
-			ch.logger.Warn("Failed to get history for channel",
-				zap.String("channel", uc.ChannelID),
-				zap.Error(err))
+// ChannelError describes why a single channel failed in a multi-channel tool.
+type ChannelError struct {
+	ChannelID string `json:"channelID"`
+	Reason    string `json:"reason"`
+}
+
+// channelErrors accumulates per-channel failures of tools that loop over many
+// channels, so a failing channel neither aborts the call nor goes unreported.
+type channelErrors struct {
+	errs []ChannelError
+}
+
+// Add records err for channelID. A channel that fails more than once, e.g. in
+// its count and again in its messages, is reported once with every distinct
+// reason.
+func (ce *channelErrors) Add(channelID string, err error) {
+	reason := err.Error()
+	for i := range ce.errs {
+		if ce.errs[i].ChannelID != channelID {
 			continue
 		}
+		if !strings.Contains(ce.errs[i].Reason, reason) {
+			ce.errs[i].Reason += ", " + reason
+		}
+		return
+	}
+	ce.errs = append(ce.errs, ChannelError{ChannelID: channelID, Reason: reason})
+}
 
-		channelMessages := ch.convertMessagesFromHistory(history.Messages, uc.ChannelName, false)
-		allMessages = append(allMessages, channelMessages...)
+func (ce *channelErrors) Len() int {
+	return len(ce.errs)
+}
+
+// Summary renders the failures as "errors: C1: reason; C2: reason", or an
+// empty string when every channel succeeded.
+func (ce *channelErrors) Summary() string {
+	if len(ce.errs) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(ce.errs))
+	for _, e := range ce.errs {
+		parts = append(parts, e.ChannelID+": "+e.Reason)
 	}
+	return fmt.Sprintf("errors: %d channel(s) could not be fully read, so their results are missing or incomplete: %s", len(ce.errs), strings.Join(parts, "; "))
+}
 
-	ch.logger.Debug("Fetched unread messages via fallback", zap.Int("total", len(allMessages)))
+// AppendTo adds the errors summary to result as a separate text content, so the
+// main CSV or JSON content stays parseable.
+func (ce *channelErrors) AppendTo(result *mcp.CallToolResult) *mcp.CallToolResult {
+	if result == nil || len(ce.errs) == 0 {
+		return result
+	}
+	result.Content = append(result.Content, mcp.NewTextContent(ce.Summary()))
+	return result
+}
 
-	result, err := marshalMessagesToCSV(allMessages)
+// groupUnreadMessages attaches the converted messages to their channel. An
+// empty slice is used instead of nil so every channel serializes with a
+// messages array.
+func groupUnreadMessages(channel UnreadChannel, messages []Message) UnreadChannelMessages {
+	if messages == nil {
+		messages = []Message{}
+	}
+	return UnreadChannelMessages{
+		UnreadChannel: channel,
+		Messages:      messages,
+	}
+}
+
+func marshalGroupedUnreadsToJSON(groups []UnreadChannelMessages) (*mcp.CallToolResult, error) {
+	if groups == nil {
+		groups = []UnreadChannelMessages{}
+	}
+	data, err := json.Marshal(groups)
 	if err != nil {
 		return nil, err
 	}
-	// Prepend the xoxp note to the messages CSV output
-	if len(result.Content) > 0 {
-		if tc, ok := result.Content[0].(mcp.TextContent); ok {
-			tc.Text = xoxpNote + tc.Text
-			result.Content[0] = tc
-		}
-	}
-	return result, nil
+	return mcp.NewToolResultText(string(data)), nil
 }
 
-// slackRetryAfter checks if an error is a Slack rate limit error and returns
-// the retry-after duration. Returns 0 for non-rate-limit errors.
-// Used as the retryAfter callback for limiter.CallWithRetry.
-func slackRetryAfter(err error) time.Duration {
+// fetchClientCounts calls client.counts through the Tier 2 limiter, retrying
+// when Slack throttles it. Once the retries are used up, the error tells the
+// caller to wait before trying again.
+func fetchClientCounts(ctx context.Context, fetch func(ctx context.Context) (edge.ClientCountsResponse, error)) (edge.ClientCountsResponse, error) {
+	counts, err := limiter.CallWithRetry(ctx, limiter.Tier2.Limiter(), 2, provider.SlackRetryAfter, func() (edge.ClientCountsResponse, error) {
+		return fetch(ctx)
+	})
 	var rle *slack.RateLimitedError
 	if errors.As(err, &rle) {
-		return rle.RetryAfter
+		return counts, fmt.Errorf("client.counts is still rate limited after retrying, wait %s before trying again: %w", rle.RetryAfter, err)
 	}
-	return 0
+	return counts, err
 }
 
 // scanTypeGroupForUnreads fetches channels of the given Slack types via users.conversations
@@ -1159,7 +4368,7 @@ func (ch *ConversationsHandler) scanTypeGroupForUnreads(
 			// Uses rate limiting + retry to avoid cascading 429 errors
 			// that silently skip channels (see: slack-go does NOT auto-retry
 			// on *RateLimitedError for standard client methods).
-			info, err := limiter.CallWithRetry(ctx, rl, 2, slackRetryAfter, func() (*slack.Channel, error) {
+			info, err := limiter.CallWithRetry(ctx, rl, 2, provider.SlackRetryAfter, func() (*slack.Channel, error) {
 				return ch.apiProvider.Slack().GetConversationInfoContext(ctx, &slack.GetConversationInfoInput{
 					ChannelID: channel.ID,
 				})
@@ -1220,7 +4429,7 @@ func (ch *ConversationsHandler) scanTypeGroupForUnreads(
 					Limit:     params.maxMessagesPerChannel,
 					Inclusive: false,
 				}
-				history, err := limiter.CallWithRetry(ctx, rl, 2, slackRetryAfter, func() (*slack.GetConversationHistoryResponse, error) {
+				history, err := limiter.CallWithRetry(ctx, rl, 2, provider.SlackRetryAfter, func() (*slack.GetConversationHistoryResponse, error) {
 					return ch.apiProvider.Slack().GetConversationHistoryContext(ctx, &historyParams)
 				})
 				apiCalls++
@@ -1318,11 +4527,211 @@ func (ch *ConversationsHandler) getChannelDisplayName(info *slack.Channel, chann
 	}
 }
 
+// SavedItem is a saved (starred) message or file of the authed user
+type SavedItem struct {
+	Type     string `json:"type"` // "message" or "file"
+	Channel  string `json:"channelID"`
+	MsgID    string `json:"msgID"`
+	UserName string `json:"userUser"`
+	Text     string `json:"text"`
+	Time     string `json:"time"`
+	FileID   string `json:"fileID"`
+	FileName string `json:"fileName"`
+	Cursor   string `json:"cursor"`
+}
+
+// SavedAddHandler saves a message or file for later
+func (ch *ConversationsHandler) SavedAddHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("SavedAddHandler called", zap.Any("params", request.Params))
+
+	if err := rejectBotToken("saved_add", ch.apiProvider.IsBotToken()); err != nil {
+		ch.logger.Error("Saved items requested with bot token", zap.Error(err))
+		return nil, err
+	}
+
+	params, err := ch.parseParamsToolSavedAdd(ctx, request)
+	if err != nil {
+		ch.logger.Error("Failed to parse saved_add params", zap.Error(err))
+		return nil, err
+	}
+
+	if err := ch.apiProvider.Slack().AddStarContext(ctx, params.channel, params.item); err != nil {
+		ch.logger.Error("Slack AddStarContext failed", zap.Error(err))
+		return nil, err
+	}
+
+	if params.item.File != "" {
+		return mcp.NewToolResultText(fmt.Sprintf("Successfully saved file %s", params.item.File)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Successfully saved message %s in channel %s", params.item.Timestamp, params.channel)), nil
+}
+
+// SavedListHandler lists the saved items of the authed user
+func (ch *ConversationsHandler) SavedListHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("SavedListHandler called", zap.Any("params", request.Params))
+
+	if err := rejectBotToken("saved_list", ch.apiProvider.IsBotToken()); err != nil {
+		ch.logger.Error("Saved items requested with bot token", zap.Error(err))
+		return nil, err
+	}
+
+	limit := request.GetInt("limit", 20)
+	if limit < 1 || limit > 100 {
+		return nil, errors.New("limit must be between 1 and 100")
+	}
+	page, err := parsePageCursor(request.GetString("cursor", ""))
+	if err != nil {
+		ch.logger.Error("Invalid cursor", zap.Error(err))
+		return nil, err
+	}
+
+	starsParams := slack.NewStarsParameters()
+	starsParams.Count = limit
+	starsParams.Page = page
+	items, paging, err := ch.apiProvider.Slack().ListStarsContext(ctx, starsParams)
+	if err != nil {
+		ch.logger.Error("Slack ListStarsContext failed", zap.Error(err))
+		return nil, err
+	}
+
+	saved := convertSavedItems(items, ch.apiProvider.ProvideUsersMap().Users, ch.timeFormat)
+	if len(saved) > 0 && paging != nil && paging.Page < paging.Pages {
+		saved[len(saved)-1].Cursor = base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("page:%d", paging.Page+1)))
+	}
+
+	csvBytes, err := gocsv.MarshalBytes(&saved)
+	if err != nil {
+		ch.logger.Error("Failed to marshal saved items to CSV", zap.Error(err))
+		return nil, err
+	}
+	return mcp.NewToolResultText(string(csvBytes)), nil
+}
+
+// rejectBotToken fails user-scoped tools for bot tokens, which have no saved items
+func rejectBotToken(tool string, isBotToken bool) error {
+	if !isBotToken {
+		return nil
+	}
+	return fmt.Errorf("%s requires a user token (xoxp) or browser session tokens (xoxc/xoxd); saved items are user-scoped and bot tokens (xoxb) have none", tool)
+}
+
+// parsePageCursor decodes a "page:N" cursor, an empty cursor is the first page
+func parsePageCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 1, nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cursor: %v", err)
+	}
+	_, raw, ok := strings.Cut(string(decoded), "page:")
+	if !ok {
+		return 0, fmt.Errorf("invalid cursor: %v", cursor)
+	}
+	page, err := strconv.Atoi(raw)
+	if err != nil || page < 1 {
+		return 0, fmt.Errorf("invalid cursor page: %v", cursor)
+	}
+	return page, nil
+}
+
+func (ch *ConversationsHandler) parseParamsToolSavedAdd(ctx context.Context, request mcp.CallToolRequest) (*savedAddParams, error) {
+	if err := checkSavedToolEnabled(os.Getenv("SLACK_MCP_SAVED_TOOL"), os.Getenv("SLACK_MCP_ENABLED_TOOLS")); err != nil {
+		return nil, err
+	}
+
+	channel := strings.TrimSpace(request.GetString("channel_id", ""))
+	if channel != "" {
+		resolved, err := ch.resolveAllowedChannel(ctx, channel)
+		if err != nil {
+			return nil, err
+		}
+		channel = resolved
+	}
+
+	item, err := savedItemRef(channel, request.GetString("timestamp", ""), request.GetString("file_id", ""))
+	if err != nil {
+		return nil, err
+	}
+	return &savedAddParams{channel: channel, item: item}, nil
+}
+
+// checkSavedToolEnabled reports an error unless SLACK_MCP_SAVED_TOOL enables
+// saved_add, or the tool is explicitly listed in SLACK_MCP_ENABLED_TOOLS
+func checkSavedToolEnabled(toolConfig, enabledTools string) error {
+	if toolConfig == "" && strings.Contains(enabledTools, "saved_add") {
+		return nil
+	}
+	if toolConfig != "1" && toolConfig != "true" && toolConfig != "yes" {
+		return errors.New(
+			"by default, the saved_add tool is disabled. " +
+				"To enable it, set the SLACK_MCP_SAVED_TOOL environment variable to true or 1, " +
+				"e.g. 'SLACK_MCP_SAVED_TOOL=true'",
+		)
+	}
+	return nil
+}
+
+// savedItemRef builds the item to save: either a message given by channel and
+// timestamp, or a file given by its ID
+func savedItemRef(channel, timestamp, fileID string) (slack.ItemRef, error) {
+	timestamp = strings.TrimSpace(timestamp)
+	fileID = strings.TrimSpace(fileID)
+
+	switch {
+	case fileID != "" && (channel != "" || timestamp != ""):
+		return slack.ItemRef{}, errors.New("file_id cannot be combined with channel_id and timestamp")
+	case fileID != "":
+		return slack.NewRefToFile(fileID), nil
+	case channel == "" && timestamp == "":
+		return slack.ItemRef{}, errors.New("either channel_id and timestamp, or file_id is required")
+	case channel == "":
+		return slack.ItemRef{}, errors.New("channel_id is required with timestamp")
+	case timestamp == "":
+		return slack.ItemRef{}, errors.New("timestamp is required with channel_id")
+	}
+	return slack.NewRefToMessage(channel, timestamp), nil
+}
+
+// convertSavedItems turns saved messages and files into rows, other item types
+// such as saved channels are skipped
+func convertSavedItems(items []slack.Item, users map[string]slack.User, format text.TimeFormat) []SavedItem {
+	saved := make([]SavedItem, 0, len(items))
+	for _, item := range items {
+		switch item.Type {
+		case slack.TYPE_MESSAGE:
+			if item.Message == nil {
+				continue
+			}
+			userName, _, _ := getUserInfo(item.Message.User, users)
+			timestamp, _ := format.FormatTimestamp(item.Message.Timestamp)
+			saved = append(saved, SavedItem{
+				Type:     item.Type,
+				Channel:  item.Channel,
+				MsgID:    item.Message.Timestamp,
+				UserName: userName,
+				Text:     text.ProcessText(item.Message.Text),
+				Time:     timestamp,
+			})
+		case slack.TYPE_FILE:
+			if item.File == nil {
+				continue
+			}
+			saved = append(saved, SavedItem{
+				Type:     item.Type,
+				FileID:   item.File.ID,
+				FileName: item.File.Name,
+			})
+		}
+	}
+	return saved
+}
+
 // ConversationsMarkHandler marks a channel as read up to a specific timestamp
 func (ch *ConversationsHandler) ConversationsMarkHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	ch.logger.Debug("ConversationsMarkHandler called", zap.Any("params", request.Params))
 
-	params, err := ch.parseParamsToolMark(request)
+	params, err := ch.parseParamsToolMark(ctx, request)
 	if err != nil {
 		ch.logger.Error("Failed to parse mark params", zap.Error(err))
 		return nil, err
@@ -1332,19 +4741,12 @@ func (ch *ConversationsHandler) ConversationsMarkHandler(ctx context.Context, re
 	ts := params.ts
 
 	if ts == "" {
-		// Fetch the latest message to get its timestamp
-		historyParams := slack.GetConversationHistoryParameters{
-			ChannelID: channel,
-			Limit:     1,
-		}
-		history, err := ch.apiProvider.Slack().GetConversationHistoryContext(ctx, &historyParams)
+		ts, err = ch.latestMessageTs(ctx, channel, "")
 		if err != nil {
 			ch.logger.Error("Failed to get latest message", zap.Error(err))
 			return nil, fmt.Errorf("failed to get latest message: %v", err)
 		}
-		if len(history.Messages) > 0 {
-			ts = history.Messages[0].Timestamp
-		} else {
+		if ts == "" {
 			// No messages in channel, nothing to mark
 			return mcp.NewToolResultText("No messages to mark as read"), nil
 		}
@@ -1364,6 +4766,166 @@ func (ch *ConversationsHandler) ConversationsMarkHandler(ctx context.Context, re
 	return mcp.NewToolResultText(fmt.Sprintf("Marked %s as read up to %s", channel, ts)), nil
 }
 
+// messageTargetLatest is the target shortcut for the newest message of a
+// channel or thread, used instead of an explicit message ts
+const messageTargetLatest = "latest"
+
+// parseMessageTarget checks the ts/target pair of a tool acting on a message and
+// reports whether the newest message has to be looked up. With latestByDefault
+// an omitted ts also means the newest message.
+func parseMessageTarget(ts, target string, latestByDefault bool) (bool, error) {
+	if target != "" && target != messageTargetLatest {
+		return false, fmt.Errorf("invalid target %q: only %q is supported", target, messageTargetLatest)
+	}
+	if ts != "" {
+		if target != "" {
+			return false, errors.New("target cannot be combined with an explicit message timestamp")
+		}
+		return false, nil
+	}
+	if target == "" && !latestByDefault {
+		return false, fmt.Errorf("timestamp is required unless target is %q", messageTargetLatest)
+	}
+	return true, nil
+}
+
+// latestMessageTs returns the ts of the newest message in channel, or of the
+// newest reply when threadTs is set. It returns "" if there is no message.
+func (ch *ConversationsHandler) latestMessageTs(ctx context.Context, channel, threadTs string) (string, error) {
+	newest := func(ctx context.Context) ([]slack.Message, error) {
+		history, err := ch.apiProvider.Slack().GetConversationHistoryContext(ctx, &slack.GetConversationHistoryParameters{
+			ChannelID: channel,
+			Limit:     1,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return history.Messages, nil
+	}
+	rl := limiter.Tier3.Limiter()
+	replies := func(ctx context.Context, cursor string) (repliesPage, error) {
+		return limiter.CallWithRetry(ctx, rl, 2, provider.SlackRetryAfter, func() (repliesPage, error) {
+			msgs, hasMore, next, err := ch.apiProvider.Slack().GetConversationRepliesContext(ctx, &slack.GetConversationRepliesParameters{
+				ChannelID: channel,
+				Timestamp: threadTs,
+				Cursor:    cursor,
+				Limit:     threadByLinkPageSize,
+			})
+			if !hasMore {
+				next = ""
+			}
+			return repliesPage{messages: msgs, next: next}, err
+		})
+	}
+	return latestMessageTsFrom(ctx, threadTs, newest, replies)
+}
+
+// latestMessageTsFrom implements latestMessageTs. Replies come oldest first,
+// so a thread is paged through to its last message.
+func latestMessageTsFrom(
+	ctx context.Context,
+	threadTs string,
+	newest func(ctx context.Context) ([]slack.Message, error),
+	replies func(ctx context.Context, cursor string) (repliesPage, error),
+) (string, error) {
+	if threadTs == "" {
+		msgs, err := newest(ctx)
+		if err != nil || len(msgs) == 0 {
+			return "", err
+		}
+		return msgs[0].Timestamp, nil
+	}
+
+	latest := ""
+	cursor := ""
+	for {
+		page, err := replies(ctx, cursor)
+		if err != nil {
+			return "", err
+		}
+		if n := len(page.messages); n > 0 {
+			latest = page.messages[n-1].Timestamp
+		}
+		if page.next == "" {
+			return latest, nil
+		}
+		cursor = page.next
+	}
+}
+
+// ConversationsCloseHandler closes a DM or group DM, hiding it from the sidebar
+func (ch *ConversationsHandler) ConversationsCloseHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ch.logger.Debug("ConversationsCloseHandler called", zap.Any("params", request.Params))
+
+	if err := checkMembershipToolEnabled(os.Getenv("SLACK_MCP_MEMBERSHIP_TOOL"), os.Getenv("SLACK_MCP_ENABLED_TOOLS")); err != nil {
+		ch.logger.Error("Membership tool disabled", zap.Error(err))
+		return nil, err
+	}
+
+	channel := request.GetString("channel_id", "")
+	if channel == "" {
+		ch.logger.Error("channel_id missing in close params")
+		return nil, errors.New("channel_id is required")
+	}
+	channel, err := ch.resolveAllowedChannel(ctx, channel)
+	if err != nil {
+		ch.logger.Error("Channel not found", zap.String("channel", channel), zap.Error(err))
+		return nil, err
+	}
+
+	cached, found := ch.apiProvider.ProvideChannelsMaps().Channels[channel]
+	if !isClosableConversation(channel, cached, found) {
+		return nil, fmt.Errorf("channel %q is not a DM or group DM, conversations_close only applies to direct messages", channel)
+	}
+
+	noOp, alreadyClosed, err := ch.apiProvider.Slack().CloseConversationContext(ctx, channel)
+	if err != nil {
+		ch.logger.Error("Slack CloseConversationContext failed", zap.Error(err))
+		return nil, fmt.Errorf("failed to close conversation: %v", err)
+	}
+
+	ch.logger.Info("Closed conversation",
+		zap.String("channel", channel),
+		zap.Bool("already_closed", alreadyClosed || noOp))
+
+	if alreadyClosed || noOp {
+		return mcp.NewToolResultText(fmt.Sprintf("Conversation %s was already closed", channel)), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Closed conversation %s", channel)), nil
+}
+
+// checkMembershipToolEnabled applies the SLACK_MCP_MEMBERSHIP_TOOL gate, which
+// guards tools that change which conversations the user is part of or sees.
+func checkMembershipToolEnabled(toolConfig, enabledTools string) error {
+	if toolConfig == "" {
+		if strings.Contains(enabledTools, "conversations_close") {
+			return nil
+		}
+		return errors.New(
+			"by default, the membership tools are disabled to prevent accidental changes to your conversations. " +
+				"To enable them, set the SLACK_MCP_MEMBERSHIP_TOOL environment variable to true or 1, " +
+				"e.g. 'SLACK_MCP_MEMBERSHIP_TOOL=true'",
+		)
+	}
+	if toolConfig != "1" && toolConfig != "true" && toolConfig != "yes" {
+		return errors.New(
+			"the membership tools are disabled. " +
+				"To enable them, set the SLACK_MCP_MEMBERSHIP_TOOL environment variable to true or 1",
+		)
+	}
+	return nil
+}
+
+// isClosableConversation reports whether channelID is a DM or group DM. The
+// channels cache is authoritative when the channel is known, otherwise only
+// D-prefixed IDs are accepted.
+func isClosableConversation(channelID string, cached provider.Channel, found bool) bool {
+	if found {
+		return cached.IsIM || cached.IsMpIM
+	}
+	return strings.HasPrefix(channelID, "D")
+}
+
 // sortChannelsByPriority sorts channels: DMs > group_dm > partner > internal
 func (ch *ConversationsHandler) sortChannelsByPriority(channels []UnreadChannel) {
 	priority := map[string]int{
@@ -1407,34 +4969,68 @@ func isChannelAllowedForConfig(channel, config string) bool {
 			}
 		}
 	}
-	return isNegated
+	return isNegated
+}
+
+// withDefaultChannel returns channel, or the SLACK_MCP_DEFAULT_CHANNEL value
+// defaultChannel when channel_id was omitted. The result is still checked
+// against the SLACK_MCP_ADD_MESSAGE_TOOL policy by the caller.
+func withDefaultChannel(channel, defaultChannel string) string {
+	if channel != "" {
+		return channel
+	}
+	return strings.TrimSpace(defaultChannel)
 }
 
 func isChannelAllowed(channel string) bool {
 	return isChannelAllowedForConfig(channel, os.Getenv("SLACK_MCP_ADD_MESSAGE_TOOL"))
 }
 
-func (ch *ConversationsHandler) resolveChannelID(ctx context.Context, channel string) (string, error) {
+func (ch *ConversationsHandler) resolveAllowedChannel(ctx context.Context, channel string) (string, error) {
+	return resolveAllowedChannel(ctx, channel, ch.apiProvider.ProvideChannelsMaps, ch.apiProvider.ForceRefreshChannels, ch.logger)
+}
+
+// resolveAllowedChannel resolves channel as resolveChannelID does and refuses
+// it when SLACK_MCP_ALLOWED_CHANNEL_TYPES does not allow its type. Every tool
+// reading from or writing to a single channel goes through it.
+func resolveAllowedChannel(ctx context.Context, channel string, maps func() *provider.ChannelsCache, refresh func(ctx context.Context) error, logger *zap.Logger) (string, error) {
+	id, err := resolveChannelID(ctx, channel, maps, refresh, logger)
+	if err != nil {
+		return "", err
+	}
+	if err := allowedChannelTypes().check(id, maps().Channels); err != nil {
+		logger.Warn("Channel type not allowed", zap.String("channel", id), zap.Error(err))
+		return "", err
+	}
+	return id, nil
+}
+
+// resolveChannelID is the single place where a "#channel" or "@user" reference
+// is turned into a channel ID. Every tool goes through it, so a channel that is
+// missing from the cache (e.g. it was created after the last sync) triggers one
+// forced refresh and a second lookup no matter which tool is resolving it.
+// Anything that does not look like a name is returned unchanged.
+func resolveChannelID(ctx context.Context, channel string, maps func() *provider.ChannelsCache, refresh func(ctx context.Context) error, logger *zap.Logger) (string, error) {
 	if !strings.HasPrefix(channel, "#") && !strings.HasPrefix(channel, "@") {
 		return channel, nil
 	}
 
 	// First attempt: try to resolve from current cache
-	channelsMaps := ch.apiProvider.ProvideChannelsMaps()
+	channelsMaps := maps()
 	chn, ok := channelsMaps.ChannelsInv[channel]
 	if ok {
 		return channelsMaps.Channels[chn].ID, nil
 	}
 
 	// Channel not found - try refreshing cache and retry once
-	ch.logger.Debug("Channel not found in cache, attempting refresh",
+	logger.Debug("Channel not found in cache, attempting refresh",
 		zap.String("channel", channel))
 
-	refreshErr := ch.apiProvider.ForceRefreshChannels(ctx)
+	refreshErr := refresh(ctx)
 	wasRateLimited := errors.Is(refreshErr, provider.ErrRefreshRateLimited)
 
 	if refreshErr != nil && !wasRateLimited {
-		ch.logger.Error("Failed to refresh channels cache",
+		logger.Error("Failed to refresh channels cache",
 			zap.String("channel", channel),
 			zap.Error(refreshErr))
 		return "", fmt.Errorf("channel %q not found and cache refresh failed: %w", channel, refreshErr)
@@ -1442,29 +5038,91 @@ func (ch *ConversationsHandler) resolveChannelID(ctx context.Context, channel st
 
 	// If rate-limited, cache wasn't refreshed - no point in a second lookup
 	if wasRateLimited {
-		ch.logger.Warn("Channel not found; cache refresh was rate-limited",
+		logger.Warn("Channel not found; cache refresh was rate-limited",
 			zap.String("channel", channel))
 		return "", fmt.Errorf("channel %q not found (cache refresh was rate-limited, try again later)", channel)
 	}
 
 	// Second attempt after successful refresh
-	channelsMaps = ch.apiProvider.ProvideChannelsMaps()
+	channelsMaps = maps()
 	chn, ok = channelsMaps.ChannelsInv[channel]
 	if !ok {
-		ch.logger.Error("Channel not found even after cache refresh",
+		logger.Error("Channel not found even after cache refresh",
 			zap.String("channel", channel))
 		return "", fmt.Errorf("channel %q not found", channel)
 	}
 
-	ch.logger.Debug("Channel found after cache refresh",
+	logger.Debug("Channel found after cache refresh",
 		zap.String("channel", channel),
 		zap.String("channel_id", channelsMaps.Channels[chn].ID))
 
 	return channelsMaps.Channels[chn].ID, nil
 }
 
-func (ch *ConversationsHandler) convertMessagesFromHistory(slackMessages []slack.Message, channel string, includeActivity bool) []Message {
+// syncWarningInterval is the minimum time between two repeated "sync not
+// ready" warnings, which would otherwise be logged on every tool call while
+// the caches are warming up.
+const syncWarningInterval = 30 * time.Second
+
+const (
+	usersNotReadyExplanation    = "WARNING: Slack users sync is not ready yet, you may experience some limited functionality and see UIDs instead of resolved names as well as unable to query users by their @handles. Users sync is part of channels sync and operations on channels depend on users collection (IM, MPIM). Please wait until users are synced and try again"
+	channelsNotReadyExplanation = "WARNING: Slack channels sync is not ready yet, you may experience some limited functionality and be able to request conversation only by Channel ID, not by its name. Please wait until channels are synced and try again."
+)
+
+var (
+	usersNotReadyWarning    = newThrottledWarning("Slack users sync not ready; you may see raw UIDs instead of names.", syncWarningInterval)
+	channelsNotReadyWarning = newThrottledWarning("Slack channels sync not ready; channels can only be referenced by ID.", syncWarningInterval)
+)
+
+// LogSyncNotReady explains once, when the server starts serving before the
+// caches are synced, what does not work until they are. Only the caches that
+// are still syncing are reported. Tool calls in the meantime only log a short
+// throttled reminder.
+func LogSyncNotReady(logger *zap.Logger, usersReady, channelsReady bool) {
+	if !usersReady {
+		logger.Warn(usersNotReadyExplanation, zap.String("context", "console"))
+	}
+	if !channelsReady {
+		logger.Warn(channelsNotReadyExplanation, zap.String("context", "console"))
+	}
+}
+
+// throttledWarning logs a message at most once per interval.
+type throttledWarning struct {
+	msg      string
+	interval time.Duration
+	now      func() time.Time
+
+	mu     sync.Mutex
+	logged bool
+	last   time.Time
+}
+
+func newThrottledWarning(msg string, interval time.Duration) *throttledWarning {
+	return &throttledWarning{
+		msg:      msg,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+func (w *throttledWarning) Warn(logger *zap.Logger, fields ...zap.Field) {
+	w.mu.Lock()
+	now := w.now()
+	if w.logged && now.Sub(w.last) < w.interval {
+		w.mu.Unlock()
+		return
+	}
+	w.logged, w.last = true, now
+	w.mu.Unlock()
+
+	logger.Warn(w.msg, fields...)
+}
+
+func (ch *ConversationsHandler) convertMessagesFromHistory(ctx context.Context, slackMessages []slack.Message, channel string, includeActivity bool) []Message {
 	usersMap := ch.apiProvider.ProvideUsersMap()
+	normalizeEmoji := ch.emojiNormalizer(ctx)
+	lookupBot := ch.botNameLookup(ctx)
 	var messages []Message
 	warn := false
 
@@ -1474,30 +5132,27 @@ func (ch *ConversationsHandler) convertMessagesFromHistory(slackMessages []slack
 		}
 
 		userName, realName, ok := getUserInfo(msg.User, usersMap.Users)
+		resolvedBot := resolveBotName(msg, lookupBot)
 
 		if !ok && msg.SubType == "bot_message" {
-			userName, realName, ok = getBotInfo(msg.Username)
+			if resolvedBot != "" {
+				userName, realName, ok = resolvedBot, resolvedBot, true
+			} else {
+				userName, realName, ok = getBotInfo(msg.Username)
+			}
 		}
 
 		if !ok {
 			warn = true
 		}
 
-		timestamp, err := text.TimestampToIsoRFC3339(msg.Timestamp)
-		if err != nil {
-			ch.logger.Error("Failed to convert timestamp to RFC3339", zap.Error(err))
-			continue
-		}
+		timestamp := messageTime(msg.Timestamp, ch.timeFormat, ch.logger)
 
 		msgText := msg.Text + text.AttachmentsTo2CSV(msg.Text, msg.Attachments)
 
-		var reactionParts []string
-		for _, r := range msg.Reactions {
-			reactionParts = append(reactionParts, fmt.Sprintf("%s:%d", r.Name, r.Count))
-		}
-		reactionsString := strings.Join(reactionParts, "|")
+		reactionsString := formatReactions(msg.Reactions, nil, false)
 
-		botName := ""
+		botName := resolvedBot
 		if msg.BotProfile != nil && msg.BotProfile.Name != "" {
 			botName = msg.BotProfile.Name
 		}
@@ -1516,7 +5171,7 @@ func (ch *ConversationsHandler) convertMessagesFromHistory(slackMessages []slack
 			UserID:        msg.User,
 			UserName:      userName,
 			RealName:      realName,
-			Text:          text.ProcessText(msgText),
+			Text:          ch.withBlockActions(normalizeEmoji(text.ProcessText(msgText)), msg.Blocks),
 			Channel:       channel,
 			ThreadTs:      msg.ThreadTimestamp,
 			Time:          timestamp,
@@ -1530,17 +5185,28 @@ func (ch *ConversationsHandler) convertMessagesFromHistory(slackMessages []slack
 
 	if ready, err := ch.apiProvider.IsReady(); !ready {
 		if warn && errors.Is(err, provider.ErrUsersNotReady) {
-			ch.logger.Warn(
-				"WARNING: Slack users sync is not ready yet, you may experience some limited functionality and see UIDs instead of resolved names as well as unable to query users by their @handles. Users sync is part of channels sync and operations on channels depend on users collection (IM, MPIM). Please wait until users are synced and try again",
-				zap.Error(err),
-			)
+			usersNotReadyWarning.Warn(ch.logger, zap.Error(err))
 		}
 	}
 	return messages
 }
 
-func (ch *ConversationsHandler) convertMessagesFromSearch(slackMessages []slack.SearchMessage) []Message {
+// messageTime formats a message ts with format, RFC3339 in UTC by default. A
+// malformed ts yields an empty time rather than an error, so the message is
+// still returned with its raw ts in MsgID.
+func messageTime(ts string, format text.TimeFormat, logger *zap.Logger) string {
+	timestamp, err := format.FormatTimestamp(ts)
+	if err != nil {
+		logger.Debug("Failed to convert timestamp, leaving time empty",
+			zap.String("ts", ts), zap.Error(err))
+		return ""
+	}
+	return timestamp
+}
+
+func (ch *ConversationsHandler) convertMessagesFromSearch(ctx context.Context, slackMessages []slack.SearchMessage) []Message {
 	usersMap := ch.apiProvider.ProvideUsersMap()
+	normalizeEmoji := ch.emojiNormalizer(ctx)
 	var messages []Message
 	warn := false
 
@@ -1555,9 +5221,9 @@ func (ch *ConversationsHandler) convertMessagesFromSearch(slackMessages []slack.
 
 		threadTs, _ := extractThreadTS(msg.Permalink)
 
-		timestamp, err := text.TimestampToIsoRFC3339(msg.Timestamp)
+		timestamp, err := ch.timeFormat.FormatTimestamp(msg.Timestamp)
 		if err != nil {
-			ch.logger.Error("Failed to convert timestamp to RFC3339", zap.Error(err))
+			ch.logger.Error("Failed to convert timestamp", zap.Error(err))
 			continue
 		}
 
@@ -1570,8 +5236,8 @@ func (ch *ConversationsHandler) convertMessagesFromSearch(slackMessages []slack.
 			UserID:    msg.User,
 			UserName:  userName,
 			RealName:  realName,
-			Text:      text.ProcessText(msgText),
-			Channel:   fmt.Sprintf("#%s", msg.Channel.Name),
+			Text:      normalizeEmoji(text.ProcessText(msgText)),
+			Channel:   searchChannelLabel(msg.Channel),
 			ThreadTs:  threadTs,
 			Time:      timestamp,
 			Reactions: "",
@@ -1581,15 +5247,379 @@ func (ch *ConversationsHandler) convertMessagesFromSearch(slackMessages []slack.
 
 	if ready, err := ch.apiProvider.IsReady(); !ready {
 		if warn && errors.Is(err, provider.ErrUsersNotReady) {
-			ch.logger.Warn(
-				"Slack users sync not ready; you may see raw UIDs instead of names and lose some functionality.",
-				zap.Error(err),
-			)
+			usersNotReadyWarning.Warn(ch.logger, zap.Error(err))
+		}
+	}
+	return messages
+}
+
+// searchChannelLabel renders a search match's channel name for the Channel
+// column. Names taken from the channels cache already carry a '#' or '@' prefix.
+// Channels whose name could not be resolved are labelled with their ID.
+func searchChannelLabel(channel slack.CtxChannel) string {
+	name := channel.Name
+	if name == "" {
+		return channel.ID
+	}
+	if strings.HasPrefix(name, "#") || strings.HasPrefix(name, "@") {
+		return name
+	}
+	return "#" + name
+}
+
+// resolveSearchChannelNames fills in channel names missing from search matches
+// in one pass over the channels cache. With forceRefresh, channels absent from
+// the cache trigger a single forced cache refresh followed by a second pass.
+func (ch *ConversationsHandler) resolveSearchChannelNames(ctx context.Context, matches []slack.SearchMessage, forceRefresh bool) {
+	var refresh func(ctx context.Context) error
+	if forceRefresh {
+		refresh = ch.apiProvider.ForceRefreshChannels
+	}
+	channels := func() map[string]provider.Channel {
+		return ch.apiProvider.ProvideChannelsMaps().Channels
+	}
+	resolveSearchChannelNames(ctx, matches, channels, refresh, ch.logger)
+}
+
+func resolveSearchChannelNames(ctx context.Context, matches []slack.SearchMessage, channels func() map[string]provider.Channel, refresh func(ctx context.Context) error, logger *zap.Logger) {
+	missing := fillSearchChannelNames(matches, channels())
+	if missing == 0 || refresh == nil {
+		return
+	}
+
+	logger.Debug("Search matches reference uncached channels, refreshing channels cache", zap.Int("missing", missing))
+	if err := refresh(ctx); err != nil {
+		if errors.Is(err, provider.ErrRefreshRateLimited) {
+			logger.Warn("Channels cache refresh for search results was rate-limited")
+		} else {
+			logger.Warn("Failed to refresh channels cache for search results", zap.Error(err))
+		}
+		return
+	}
+	fillSearchChannelNames(matches, channels())
+}
+
+// fillSearchChannelNames sets the name of every match whose channel has only
+// an ID from channels, and returns how many could not be resolved.
+func fillSearchChannelNames(matches []slack.SearchMessage, channels map[string]provider.Channel) int {
+	missing := 0
+	for i := range matches {
+		c := &matches[i].Channel
+		if c.Name != "" || c.ID == "" {
+			continue
+		}
+		if cached, ok := channels[c.ID]; ok && cached.Name != "" {
+			c.Name = cached.Name
+			continue
+		}
+		missing++
+	}
+	return missing
+}
+
+// formatReactions renders reactions as "name:count|name:count". With
+// includeUsers the users who reacted are appended as handles, e.g.
+// "thumbsup:2[@alice,@bob]"; users missing from the cache are kept as IDs.
+func formatReactions(reactions []slack.ItemReaction, users map[string]slack.User, includeUsers bool) string {
+	parts := make([]string, 0, len(reactions))
+	for _, r := range reactions {
+		part := fmt.Sprintf("%s:%d", r.Name, r.Count)
+		if includeUsers && len(r.Users) > 0 {
+			handles := make([]string, 0, len(r.Users))
+			for _, id := range r.Users {
+				if u, ok := users[id]; ok {
+					handles = append(handles, "@"+u.Name)
+				} else {
+					handles = append(handles, id)
+				}
+			}
+			part += "[" + strings.Join(handles, ",") + "]"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "|")
+}
+
+// withReactionUsers rewrites the reactions column of messages to include the
+// users who reacted, taken from the original Slack messages matched by ts
+func withReactionUsers(messages []Message, slackMessages []slack.Message, users map[string]slack.User) []Message {
+	byTs := make(map[string][]slack.ItemReaction, len(slackMessages))
+	for _, m := range slackMessages {
+		if len(m.Reactions) > 0 {
+			byTs[m.Timestamp] = m.Reactions
+		}
+	}
+	for i := range messages {
+		if reactions, ok := byTs[messages[i].MsgID]; ok {
+			messages[i].Reactions = formatReactions(reactions, users, true)
+		}
+	}
+	return messages
+}
+
+// withClientMsgIDs fills the ClientMsgID column from the client_msg_id Slack
+// assigns to messages posted from its clients. It survives edits, so it can be
+// used for de-duplication; bot and integration posts usually have none.
+func withClientMsgIDs(messages []Message, slackMessages []slack.Message) []Message {
+	byTs := make(map[string]string, len(slackMessages))
+	for _, m := range slackMessages {
+		if m.ClientMsgID != "" {
+			byTs[m.Timestamp] = m.ClientMsgID
+		}
+	}
+	for i := range messages {
+		messages[i].ClientMsgID = byTs[messages[i].MsgID]
+	}
+	return messages
+}
+
+// withSubtypes fills the Subtype column from the Slack message subtype, such
+// as bot_message, file_share or, with activity messages, channel_join. Plain
+// user messages have none and leave the column empty.
+func withSubtypes(messages []Message, slackMessages []slack.Message) []Message {
+	byTs := make(map[string]string, len(slackMessages))
+	for _, m := range slackMessages {
+		if m.SubType != "" {
+			byTs[m.Timestamp] = m.SubType
+		}
+	}
+	for i := range messages {
+		messages[i].Subtype = byTs[messages[i].MsgID]
+	}
+	return messages
+}
+
+// withThreadSubscription fills the Subscribed column of the thread root with
+// whether the current user follows the thread, as reported on the root of a
+// conversations.replies page. Replies, and pages without the root, leave the
+// column empty.
+func withThreadSubscription(messages []Message, replies []slack.Message, threadTs string) []Message {
+	for _, r := range replies {
+		if r.Timestamp != threadTs {
+			continue
+		}
+		for i := range messages {
+			if messages[i].MsgID == threadTs {
+				subscribed := r.Subscribed
+				messages[i].Subscribed = &subscribed
+			}
+		}
+		break
+	}
+	return messages
+}
+
+// withAvatars fills the AvatarURL column from the users cache. Bot posts
+// without a cached user fall back to the icon of their bot profile or the
+// per-message icon_url, when slackMessages carries them; anything else is
+// left empty. Search results pass no slackMessages, as matches carry no icons.
+func withAvatars(messages []Message, slackMessages []slack.Message, users map[string]slack.User) []Message {
+	icons := make(map[string]string, len(slackMessages))
+	for _, m := range slackMessages {
+		if icon := botIconURL(m); icon != "" {
+			icons[m.Timestamp] = icon
+		}
+	}
+	for i := range messages {
+		if u, ok := users[messages[i].UserID]; ok {
+			messages[i].AvatarURL = u.Profile.Image72
+		}
+		if messages[i].AvatarURL == "" {
+			messages[i].AvatarURL = icons[messages[i].MsgID]
+		}
+	}
+	return messages
+}
+
+func botIconURL(msg slack.Message) string {
+	if msg.BotProfile != nil && msg.BotProfile.Icons != nil && msg.BotProfile.Icons.Image72 != "" {
+		return msg.BotProfile.Icons.Image72
+	}
+	if msg.Icons != nil {
+		return msg.Icons.IconURL
+	}
+	return ""
+}
+
+// withAuthorTeams fills the Team and IsExternal columns, comparing authors
+// against the workspace of the token. When auth.test fails only the Team
+// column is filled.
+func (ch *ConversationsHandler) withAuthorTeams(messages []Message, slackMessages []slack.Message) []Message {
+	homeTeam := ""
+	if ar, err := ch.apiProvider.Slack().AuthTest(); err != nil {
+		ch.logger.Warn("Slack AuthTest failed, external authors are not flagged", zap.Error(err))
+	} else {
+		homeTeam = ar.TeamID
+	}
+	return withTeams(messages, slackMessages, ch.apiProvider.ProvideUsersMap().Users, homeTeam)
+}
+
+// withTeams fills the Team column with the team of each author, as reported on
+// the message or else by the users cache. In Slack Connect channels authors
+// from a team other than homeTeam are flagged as external, telling partner
+// voices apart from internal ones. Authors without a known team are not flagged.
+func withTeams(messages []Message, slackMessages []slack.Message, users map[string]slack.User, homeTeam string) []Message {
+	teams := make(map[string]string, len(slackMessages))
+	for _, m := range slackMessages {
+		if m.Team != "" {
+			teams[m.Timestamp] = m.Team
+		}
+	}
+	for i := range messages {
+		team := teams[messages[i].MsgID]
+		if team == "" {
+			team = users[messages[i].UserID].TeamID
 		}
+		messages[i].Team = team
+		messages[i].IsExternal = team != "" && homeTeam != "" && team != homeTeam
+	}
+	return messages
+}
+
+// withUnreadBoundary flags the messages posted after the channel's last_read,
+// fetched with conversations.info. When last_read is not available the
+// messages are returned unflagged along with a note explaining why.
+func (ch *ConversationsHandler) withUnreadBoundary(ctx context.Context, channel string, messages []Message) ([]Message, string) {
+	info, err := limiter.CallWithRetry(ctx, limiter.Tier3.Limiter(), 2, provider.SlackRetryAfter, func() (*slack.Channel, error) {
+		return ch.apiProvider.Slack().GetConversationInfoContext(ctx, &slack.GetConversationInfoInput{ChannelID: channel})
+	})
+	if err != nil {
+		ch.logger.Warn("Failed to fetch last_read for unread boundary", zap.String("channel", channel), zap.Error(err))
+		return messages, fmt.Sprintf("the unread boundary could not be determined, isUnread is not set: %v", err)
+	}
+	if info.LastRead == "" {
+		return messages, "Slack did not return last_read for this conversation, isUnread is not set"
+	}
+	return flagUnreadMessages(messages, info.LastRead), ""
+}
+
+// flagUnreadMessages sets IsUnread on the messages posted after lastRead
+func flagUnreadMessages(messages []Message, lastRead string) []Message {
+	for i := range messages {
+		messages[i].IsUnread = compareSlackTs(messages[i].MsgID, lastRead) > 0
 	}
 	return messages
 }
 
+// maxCallLookups caps the calls.info lookups made for one page of history
+const maxCallLookups = 10
+
+// withCallSummaries turns the call and huddle messages of slackMessages, which
+// carry little or no text, into rows summarizing who started them and, for
+// calls, their title, duration and participants from calls.info. Huddle
+// messages are skipped as activity messages unless included, so their rows are
+// added here. messages must still be in the order of slackMessages.
+func (ch *ConversationsHandler) withCallSummaries(ctx context.Context, messages []Message, slackMessages []slack.Message, channel string) []Message {
+	users := ch.apiProvider.ProvideUsersMap().Users
+	converted := make(map[string]Message, len(messages))
+	for _, m := range messages {
+		converted[m.MsgID] = m
+	}
+
+	rl := limiter.Tier3.Limiter()
+	lookups := 0
+	result := make([]Message, 0, len(messages))
+	for _, msg := range slackMessages {
+		row, ok := converted[msg.Timestamp]
+		if !isCallMessage(msg) {
+			if ok {
+				result = append(result, row)
+			}
+			continue
+		}
+
+		var call *slack.Call
+		if id := callIDOf(msg); id != "" && lookups < maxCallLookups {
+			lookups++
+			c, err := limiter.CallWithRetry(ctx, rl, 2, provider.SlackRetryAfter, func() (slack.Call, error) {
+				return ch.apiProvider.Slack().GetCallContext(ctx, id)
+			})
+			if err != nil {
+				ch.logger.Debug("Failed to fetch call info", zap.String("call_id", id), zap.Error(err))
+			} else {
+				call = &c
+			}
+		}
+
+		if !ok {
+			userName, realName, _ := getUserInfo(msg.User, users)
+			row = Message{
+				MsgID:    msg.Timestamp,
+				UserID:   msg.User,
+				UserName: userName,
+				RealName: realName,
+				Channel:  channel,
+				ThreadTs: msg.ThreadTimestamp,
+				Time:     messageTime(msg.Timestamp, ch.timeFormat, ch.logger),
+			}
+		}
+		row.Text = callSummary(msg, call, users)
+		result = append(result, row)
+	}
+	return result
+}
+
+// isCallMessage reports whether msg announces a huddle or a call
+func isCallMessage(msg slack.Message) bool {
+	return msg.SubType == "huddle_thread" || callIDOf(msg) != ""
+}
+
+// callIDOf returns the ID of the call posted in msg through a call block
+func callIDOf(msg slack.Message) string {
+	for _, b := range msg.Blocks.BlockSet {
+		if cb, ok := b.(*slack.CallBlock); ok && cb.CallID != "" {
+			return cb.CallID
+		}
+	}
+	return ""
+}
+
+// callSummary describes a huddle or call message in one line, adding the
+// title, duration and participants of call when it could be fetched
+func callSummary(msg slack.Message, call *slack.Call, users map[string]slack.User) string {
+	kind := "call"
+	if msg.SubType == "huddle_thread" {
+		kind = "huddle"
+	}
+	starter := msg.User
+	if u, ok := users[msg.User]; ok {
+		starter = "@" + u.Name
+	}
+	parts := []string{fmt.Sprintf("[%s] started by %s", kind, starter)}
+	if call == nil {
+		return strings.Join(parts, "; ")
+	}
+
+	if call.Title != "" {
+		parts = append(parts, "title: "+call.Title)
+	}
+	switch {
+	case call.DateStart != 0 && call.DateEnd != 0:
+		parts = append(parts, "duration: "+call.DateEnd.Time().Sub(call.DateStart.Time()).String())
+	case call.DateStart != 0:
+		parts = append(parts, "ongoing")
+	}
+	var participants []string
+	for _, p := range call.Participants {
+		switch {
+		case p.SlackID != "":
+			if u, ok := users[p.SlackID]; ok {
+				participants = append(participants, "@"+u.Name)
+			} else {
+				participants = append(participants, p.SlackID)
+			}
+		case p.DisplayName != "":
+			participants = append(participants, p.DisplayName)
+		case p.ExternalID != "":
+			participants = append(participants, p.ExternalID)
+		}
+	}
+	if len(participants) > 0 {
+		parts = append(parts, "participants: "+strings.Join(participants, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
 func (ch *ConversationsHandler) parseParamsToolConversations(ctx context.Context, request mcp.CallToolRequest) (*conversationParams, error) {
 	channel := request.GetString("channel_id", "")
 	if channel == "" {
@@ -1600,58 +5630,69 @@ func (ch *ConversationsHandler) parseParamsToolConversations(ctx context.Context
 	limit := request.GetString("limit", "")
 	cursor := request.GetString("cursor", "")
 	activity := request.GetBool("include_activity_messages", false)
+	order := request.GetString("order", "newest")
+	if order != "newest" && order != "oldest" {
+		ch.logger.Error("Invalid order", zap.String("order", order))
+		return nil, errors.New("order must be either 'newest' or 'oldest'")
+	}
+	responseFormat := request.GetString("response_format", "csv")
+	if responseFormat != "csv" && responseFormat != "transcript" {
+		ch.logger.Error("Invalid response format", zap.String("response_format", responseFormat))
+		return nil, errors.New("response_format must be either 'csv' or 'transcript'")
+	}
+	unreadBoundary := request.GetBool("mark_unread_boundary", false)
+	if unreadBoundary && responseFormat == "transcript" {
+		return nil, errors.New("mark_unread_boundary fills the isUnread column and requires response_format 'csv'")
+	}
 
-	var (
-		paramLimit  int
-		paramOldest string
-		paramLatest string
-		err         error
-	)
-	if strings.HasSuffix(limit, "d") || strings.HasSuffix(limit, "w") || strings.HasSuffix(limit, "m") {
-		paramLimit, paramOldest, paramLatest, err = limitByExpression(limit, defaultConversationsExpressionLimit)
-		if err != nil {
-			ch.logger.Error("Invalid duration limit", zap.String("limit", limit), zap.Error(err))
-			return nil, err
-		}
-	} else if cursor == "" {
-		paramLimit, err = limitByNumeric(limit, defaultConversationsNumericLimit)
-		if err != nil {
-			ch.logger.Error("Invalid numeric limit", zap.String("limit", limit), zap.Error(err))
-			return nil, err
-		}
+	paramLimit, paramOldest, paramLatest, err := limitByNumericOrExpression(limit, cursor, defaultConversationsNumericLimit, defaultConversationsExpressionLimit)
+	if err != nil {
+		ch.logger.Error("Invalid limit", zap.String("limit", limit), zap.Error(err))
+		return nil, err
 	}
 
 	if strings.HasPrefix(channel, "#") || strings.HasPrefix(channel, "@") {
 		if ready, err := ch.apiProvider.IsReady(); !ready {
 			if errors.Is(err, provider.ErrUsersNotReady) {
-				ch.logger.Warn(
-					"WARNING: Slack users sync is not ready yet, you may experience some limited functionality and see UIDs instead of resolved names as well as unable to query users by their @handles. Users sync is part of channels sync and operations on channels depend on users collection (IM, MPIM). Please wait until users are synced and try again",
-					zap.Error(err),
-				)
+				usersNotReadyWarning.Warn(ch.logger, zap.Error(err))
 			}
 			if errors.Is(err, provider.ErrChannelsNotReady) {
-				ch.logger.Warn(
-					"WARNING: Slack channels sync is not ready yet, you may experience some limited functionality and be able to request conversation only by Channel ID, not by its name. Please wait until channels are synced and try again.",
-					zap.Error(err),
-				)
+				channelsNotReadyWarning.Warn(ch.logger, zap.Error(err))
 			}
 			return nil, fmt.Errorf("channel %q not found in empty cache", channel)
 		}
-		// Use resolveChannelID which includes refresh-on-error logic
-		resolvedChannel, err := ch.resolveChannelID(ctx, channel)
-		if err != nil {
-			return nil, err
-		}
-		channel = resolvedChannel
+	}
+	// resolveChannelID underneath includes the refresh-on-error logic
+	channel, err = ch.resolveAllowedChannel(ctx, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	authors, err := resolveUserList(request.GetString("users", ""), ch.apiProvider.ProvideUsersMap())
+	if err != nil {
+		ch.logger.Error("Invalid users filter", zap.Error(err))
+		return nil, err
 	}
 
 	return &conversationParams{
-		channel:  channel,
-		limit:    paramLimit,
-		oldest:   paramOldest,
-		latest:   paramLatest,
-		cursor:   cursor,
-		activity: activity,
+		channel:        channel,
+		limit:          paramLimit,
+		oldest:         paramOldest,
+		latest:         paramLatest,
+		cursor:         cursor,
+		activity:       activity,
+		order:          order,
+		reactionUsers:  request.GetBool("include_reaction_users", false),
+		clientMsgID:    request.GetBool("include_client_msg_id", false),
+		subtype:        request.GetBool("include_subtype", false),
+		avatars:        request.GetBool("include_avatars", false),
+		teams:          request.GetBool("include_team", false),
+		unreadBoundary: unreadBoundary,
+		calls:          request.GetBool("include_calls", false),
+		responseFormat: responseFormat,
+		linksOnly:      request.GetBool("links_only", false),
+		authors:        authors,
+		dailyAnchors:   request.GetBool("daily_anchors", false),
 	}, nil
 }
 
@@ -1673,48 +5714,228 @@ func (ch *ConversationsHandler) parseParamsToolAddMessage(ctx context.Context, r
 	}
 
 	channel := request.GetString("channel_id", "")
+	threadTs := request.GetString("thread_ts", "")
+
+	if permalink := request.GetString("reply_to_permalink", ""); permalink != "" {
+		if channel != "" || threadTs != "" {
+			ch.logger.Error("reply_to_permalink combined with channel_id or thread_ts")
+			return nil, errors.New("reply_to_permalink cannot be combined with channel_id or thread_ts")
+		}
+		linkChannel, linkTs, linkThreadTs, err := parseMessagePermalink(permalink)
+		if err != nil {
+			ch.logger.Error("Invalid reply_to_permalink", zap.String("permalink", permalink), zap.Error(err))
+			return nil, err
+		}
+		channel = linkChannel
+		// Replies to a reply go to the same thread, so prefer the parent's ts.
+		threadTs = linkTs
+		if linkThreadTs != "" {
+			threadTs = linkThreadTs
+		}
+	}
+
+	channel = withDefaultChannel(channel, os.Getenv("SLACK_MCP_DEFAULT_CHANNEL"))
 	if channel == "" {
 		ch.logger.Error("channel_id missing in add-message params")
-		return nil, errors.New("channel_id must be a string")
+		return nil, errors.New("channel_id must be a string, or set SLACK_MCP_DEFAULT_CHANNEL to post to a default channel")
+	}
+	channel, err := ch.resolveAllowedChannel(ctx, channel)
+	if err != nil {
+		ch.logger.Error("Channel not found", zap.String("channel", channel), zap.Error(err))
+		return nil, err
+	}
+	if !isChannelAllowed(channel) {
+		ch.logger.Warn("Add-message tool not allowed for channel", zap.String("channel", channel), zap.String("policy", toolConfig))
+		return nil, fmt.Errorf("conversations_add_message tool is not allowed for channel %q, applied policy: %s", channel, toolConfig)
+	}
+
+	if threadTs != "" && !strings.Contains(threadTs, ".") {
+		ch.logger.Error("Invalid thread_ts format", zap.String("thread_ts", threadTs))
+		return nil, errors.New("thread_ts must be a valid timestamp in format 1234567890.123456")
+	}
+
+	msgText := request.GetString("text", "")
+	if msgText == "" {
+		// Backward compatibility with "payload" parameter
+		msgText = request.GetString("payload", "")
+	}
+	if msgText == "" {
+		ch.logger.Error("Message text missing")
+		return nil, errors.New("text must be a string")
+	}
+	if request.GetBool("escape_mentions", false) {
+		msgText = text.EscapeMentions(msgText)
+	}
+
+	contentType := request.GetString("content_type", "text/markdown")
+	if contentType != "text/plain" && contentType != "text/markdown" {
+		ch.logger.Error("Invalid content_type", zap.String("content_type", contentType))
+		return nil, errors.New("content_type must be either 'text/plain' or 'text/markdown'")
+	}
+
+	autoJoin := request.GetBool("auto_join", false)
+	if autoJoin && !envBool("SLACK_MCP_AUTO_JOIN") {
+		ch.logger.Warn("auto_join requested but SLACK_MCP_AUTO_JOIN is not enabled")
+		return nil, errors.New("auto_join is disabled, set SLACK_MCP_AUTO_JOIN=true to let conversations_add_message join channels")
+	}
+
+	reactions := parseReactionList(request.GetString("reactions", ""))
+	if len(reactions) > 0 {
+		if err := checkReactionsAllowed(channel, os.Getenv("SLACK_MCP_REACTION_TOOL"), enabledTools); err != nil {
+			ch.logger.Warn("Reactions not allowed for add-message", zap.String("channel", channel), zap.Error(err))
+			return nil, err
+		}
+	}
+
+	var markRead *bool
+	if _, ok := request.GetArguments()["mark_read"]; ok {
+		v := request.GetBool("mark_read", false)
+		markRead = &v
+	}
+
+	return &addMessageParams{
+		channel:     channel,
+		threadTs:    threadTs,
+		text:        msgText,
+		contentType: contentType,
+		reactions:   reactions,
+		autoJoin:    autoJoin,
+		markRead:    markRead,
+	}, nil
+}
+
+// wrapMessageText surrounds text with the configured prefix and suffix, each
+// on its own line. Blank values are ignored.
+func wrapMessageText(text, prefix, suffix string) string {
+	lines := make([]string, 0, 3)
+	if p := strings.TrimSpace(prefix); p != "" {
+		lines = append(lines, p)
+	}
+	lines = append(lines, text)
+	if s := strings.TrimSpace(suffix); s != "" {
+		lines = append(lines, s)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// markdownOptions posts markdown text as the blocks convert turns it into.
+// When the conversion fails, fallback, the SLACK_MCP_MARKDOWN_FALLBACK value,
+// decides what happens: "error" fails the call, "raw_mrkdwn" sends the text as
+// is for Slack to render as mrkdwn, and "plain", the default, sends it as
+// plain text.
+func markdownOptions(msgText, prefix, suffix, fallback string, convert func(string) ([]slack.Block, error), logger *zap.Logger) ([]slack.MsgOption, error) {
+	blocks, err := convert(msgText)
+	if err == nil {
+		return []slack.MsgOption{slack.MsgOptionBlocks(wrapMessageBlocks(blocks, prefix, suffix)...)}, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(fallback)) {
+	case "error":
+		logger.Error("Markdown parsing error", zap.Error(err))
+		return nil, fmt.Errorf("failed to convert markdown to Slack blocks, the message was not sent: %w", err)
+	case "raw_mrkdwn":
+		logger.Warn("Markdown parsing error, sending the text as mrkdwn", zap.Error(err))
+		return []slack.MsgOption{slack.MsgOptionText(wrapMessageText(msgText, prefix, suffix), false)}, nil
+	default:
+		logger.Warn("Markdown parsing error, sending the text as plain text", zap.Error(err))
+		return []slack.MsgOption{
+			slack.MsgOptionDisableMarkdown(),
+			slack.MsgOptionText(wrapMessageText(msgText, prefix, suffix), false),
+		}, nil
+	}
+}
+
+// wrapMessageBlocks surrounds blocks converted from markdown with section
+// blocks holding the configured prefix and suffix. They are added after the
+// conversion, so their own mrkdwn formatting reaches Slack as written.
+func wrapMessageBlocks(blocks []slack.Block, prefix, suffix string) []slack.Block {
+	wrapped := make([]slack.Block, 0, len(blocks)+2)
+	if p := strings.TrimSpace(prefix); p != "" {
+		wrapped = append(wrapped, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, p, false, false), nil, nil))
 	}
-	channel, err := ch.resolveChannelID(ctx, channel)
-	if err != nil {
-		ch.logger.Error("Channel not found", zap.String("channel", channel), zap.Error(err))
-		return nil, err
+	wrapped = append(wrapped, blocks...)
+	if s := strings.TrimSpace(suffix); s != "" {
+		wrapped = append(wrapped, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, s, false, false), nil, nil))
 	}
-	if !isChannelAllowed(channel) {
-		ch.logger.Warn("Add-message tool not allowed for channel", zap.String("channel", channel), zap.String("policy", toolConfig))
-		return nil, fmt.Errorf("conversations_add_message tool is not allowed for channel %q, applied policy: %s", channel, toolConfig)
+	return wrapped
+}
+
+// markAfterPost reports whether the conversation is marked read after a post:
+// the per-call mark_read when given, otherwise SLACK_MCP_ADD_MESSAGE_MARK.
+func markAfterPost(markRead *bool) bool {
+	if markRead != nil {
+		return *markRead
 	}
+	return envBool("SLACK_MCP_ADD_MESSAGE_MARK")
+}
 
-	threadTs := request.GetString("thread_ts", "")
-	if threadTs != "" && !strings.Contains(threadTs, ".") {
-		ch.logger.Error("Invalid thread_ts format", zap.String("thread_ts", threadTs))
-		return nil, errors.New("thread_ts must be a valid timestamp in format 1234567890.123456")
+// envBool reports whether the boolean environment flag name is enabled, i.e.
+// set to "true", "1" or "yes".
+func envBool(name string) bool {
+	v := os.Getenv(name)
+	return v == "true" || v == "1" || v == "yes"
+}
+
+// parseReactionList splits a comma-separated list of emoji names, stripping
+// surrounding colons and dropping empty and duplicate entries.
+func parseReactionList(raw string) []string {
+	var reactions []string
+	seen := make(map[string]bool)
+	for _, r := range strings.Split(raw, ",") {
+		r = strings.Trim(strings.TrimSpace(r), ":")
+		if r == "" || seen[r] {
+			continue
+		}
+		seen[r] = true
+		reactions = append(reactions, r)
 	}
+	return reactions
+}
 
-	msgText := request.GetString("text", "")
-	if msgText == "" {
-		// Backward compatibility with "payload" parameter
-		msgText = request.GetString("payload", "")
+// checkReactionsAllowed applies the reactions tools policy to reactions added
+// along with a new message, so conversations_add_message cannot bypass it.
+func checkReactionsAllowed(channel, reactionConfig, enabledTools string) error {
+	if reactionConfig == "" {
+		if !strings.Contains(enabledTools, "reactions_add") {
+			return errors.New("reactions cannot be added because the reactions tools are disabled, set SLACK_MCP_REACTION_TOOL to enable them")
+		}
+		reactionConfig = "true"
 	}
-	if msgText == "" {
-		ch.logger.Error("Message text missing")
-		return nil, errors.New("text must be a string")
+	if !isChannelAllowedForConfig(channel, reactionConfig) {
+		return fmt.Errorf("reactions tools are not allowed for channel %q, applied policy: %s", channel, reactionConfig)
 	}
+	return nil
+}
 
-	contentType := request.GetString("content_type", "text/markdown")
-	if contentType != "text/plain" && contentType != "text/markdown" {
-		ch.logger.Error("Invalid content_type", zap.String("content_type", contentType))
-		return nil, errors.New("content_type must be either 'text/plain' or 'text/markdown'")
+// addReactions adds each emoji to the message identified by channel and ts,
+// stopping at the first failure.
+func addReactions(ctx context.Context, add func(ctx context.Context, name string, item slack.ItemRef) error, channel, ts string, emojis []string) error {
+	item := slack.NewRefToMessage(channel, ts)
+	for _, emoji := range emojis {
+		if err := add(ctx, emoji, item); err != nil {
+			return fmt.Errorf("failed to add :%s: reaction: %w", emoji, err)
+		}
 	}
+	return nil
+}
 
-	return &addMessageParams{
-		channel:     channel,
-		threadTs:    threadTs,
-		text:        msgText,
-		contentType: contentType,
-	}, nil
+// pickReactionEmoji resolves a "random:wave,tada,sparkles" emoji to one of the
+// listed names, chosen with pick, which returns a number in [0, n). Any other
+// emoji is returned unchanged.
+func pickReactionEmoji(emoji string, pick func(n int) int) (string, error) {
+	spec, ok := strings.CutPrefix(emoji, "random:")
+	if !ok {
+		return emoji, nil
+	}
+	var candidates []string
+	for _, name := range strings.Split(spec, ",") {
+		if name = strings.Trim(strings.TrimSpace(name), ":"); name != "" {
+			candidates = append(candidates, name)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", errors.New("random emoji needs at least one name to pick from, e.g. random:wave,tada,sparkles")
+	}
+	return candidates[pick(len(candidates))], nil
 }
 
 func (ch *ConversationsHandler) parseParamsToolReaction(ctx context.Context, request mcp.CallToolRequest) (*addReactionParams, error) {
@@ -1738,7 +5959,7 @@ func (ch *ConversationsHandler) parseParamsToolReaction(ctx context.Context, req
 	if channel == "" {
 		return nil, errors.New("channel_id is required")
 	}
-	channel, err := ch.resolveChannelID(ctx, channel)
+	channel, err := ch.resolveAllowedChannel(ctx, channel)
 	if err != nil {
 		ch.logger.Error("Channel not found", zap.String("channel", channel), zap.Error(err))
 		return nil, err
@@ -1748,16 +5969,29 @@ func (ch *ConversationsHandler) parseParamsToolReaction(ctx context.Context, req
 		return nil, fmt.Errorf("reactions tools are not allowed for channel %q, applied policy: %s", channel, toolConfig)
 	}
 
-	timestamp := request.GetString("timestamp", "")
-	if timestamp == "" {
-		return nil, errors.New("timestamp is required")
-	}
-
 	emoji := strings.Trim(request.GetString("emoji", ""), ":")
 	if emoji == "" {
 		return nil, errors.New("emoji is required")
 	}
 
+	timestamp := request.GetString("timestamp", "")
+	useLatest, err := parseMessageTarget(timestamp, request.GetString("target", ""), false)
+	if err != nil {
+		return nil, err
+	}
+	if useLatest {
+		threadTs := request.GetString("thread_ts", "")
+		timestamp, err = ch.latestMessageTs(ctx, channel, threadTs)
+		if err != nil {
+			ch.logger.Error("Failed to get latest message", zap.String("channel", channel), zap.String("thread_ts", threadTs), zap.Error(err))
+			return nil, fmt.Errorf("failed to get latest message: %v", err)
+		}
+		if timestamp == "" {
+			return nil, fmt.Errorf("no message found in channel %q to react to", channel)
+		}
+		ch.logger.Debug("Resolved latest message", zap.String("channel", channel), zap.String("timestamp", timestamp))
+	}
+
 	return &addReactionParams{
 		channel:   channel,
 		timestamp: timestamp,
@@ -1822,10 +6056,12 @@ func (ch *ConversationsHandler) parseParamsToolUnreads(request mcp.CallToolReque
 		maxMessagesPerChannel: request.GetInt("max_messages_per_channel", 10),
 		mentionsOnly:          request.GetBool("mentions_only", false),
 		includeMuted:          request.GetBool("include_muted", false),
+		groupByChannel:        request.GetBool("group_by_channel", false),
+		countsOnly:            request.GetBool("counts_only", false),
 	}
 }
 
-func (ch *ConversationsHandler) parseParamsToolMark(request mcp.CallToolRequest) (*markParams, error) {
+func (ch *ConversationsHandler) parseParamsToolMark(ctx context.Context, request mcp.CallToolRequest) (*markParams, error) {
 	toolConfig := os.Getenv("SLACK_MCP_MARK_TOOL")
 	if toolConfig == "" {
 		ch.logger.Error("Mark tool disabled by default")
@@ -1849,18 +6085,15 @@ func (ch *ConversationsHandler) parseParamsToolMark(request mcp.CallToolRequest)
 		return nil, errors.New("channel_id is required")
 	}
 
-	// Resolve channel name to ID if needed
-	if strings.HasPrefix(channel, "#") || strings.HasPrefix(channel, "@") {
-		channelsMaps := ch.apiProvider.ProvideChannelsMaps()
-		chn, ok := channelsMaps.ChannelsInv[channel]
-		if !ok {
-			ch.logger.Error("Channel not found", zap.String("channel", channel))
-			return nil, fmt.Errorf("channel %q not found", channel)
-		}
-		channel = channelsMaps.Channels[chn].ID
+	channel, err := ch.resolveAllowedChannel(ctx, channel)
+	if err != nil {
+		return nil, err
 	}
 
 	ts := request.GetString("ts", "")
+	if _, err := parseMessageTarget(ts, request.GetString("target", ""), true); err != nil {
+		return nil, err
+	}
 
 	return &markParams{
 		channel: channel,
@@ -1882,7 +6115,7 @@ func (ch *ConversationsHandler) parseParamsToolSearch(req mcp.CallToolRequest) (
 		}
 		addFilter(filters, "in", f)
 	} else if im := req.GetString("filter_in_im_or_mpim", ""); im != "" {
-		f, err := ch.paramFormatUser(im)
+		f, err := formatIMFilter(im, ch.apiProvider.ProvideChannelsMaps(), ch.apiProvider.ProvideUsersMap())
 		if err != nil {
 			ch.logger.Error("Invalid IM/MPIM filter", zap.String("filter", im), zap.Error(err))
 			return nil, err
@@ -1906,12 +6139,24 @@ func (ch *ConversationsHandler) parseParamsToolSearch(req mcp.CallToolRequest) (
 		addFilter(filters, "from", f)
 	}
 
-	dateMap, err := buildDateFilters(
-		req.GetString("filter_date_before", ""),
-		req.GetString("filter_date_after", ""),
-		req.GetString("filter_date_on", ""),
-		req.GetString("filter_date_during", ""),
-	)
+	before := req.GetString("filter_date_before", "")
+	after := req.GetString("filter_date_after", "")
+	on := req.GetString("filter_date_on", "")
+	during := req.GetString("filter_date_during", "")
+	if dateRange := req.GetString("filter_date_range", ""); dateRange != "" {
+		if before != "" || after != "" || on != "" || during != "" {
+			ch.logger.Error("Date range combined with other date filters", zap.String("range", dateRange))
+			return nil, errors.New("'filter_date_range' cannot be combined with other date filters")
+		}
+		var err error
+		after, before, err = parseDateRange(dateRange)
+		if err != nil {
+			ch.logger.Error("Invalid date range", zap.String("range", dateRange), zap.Error(err))
+			return nil, err
+		}
+	}
+
+	dateMap, err := buildDateFilters(before, after, on, during)
 	if err != nil {
 		ch.logger.Error("Invalid date filters", zap.Error(err))
 		return nil, err
@@ -1924,6 +6169,69 @@ func (ch *ConversationsHandler) parseParamsToolSearch(req mcp.CallToolRequest) (
 	limit := req.GetInt("limit", 100)
 	cursor := req.GetString("cursor", "")
 
+	deepSearch := req.GetBool("deep_search", false)
+	maxResults := req.GetInt("max_results", defaultDeepSearchMaxResults)
+	if deepSearch {
+		if cursor != "" {
+			return nil, errors.New("cursor cannot be used with deep_search, which fetches all pages itself")
+		}
+		if maxResults < 1 || maxResults > maxDeepSearchMaxResults {
+			return nil, fmt.Errorf("max_results must be between 1 and %d", maxDeepSearchMaxResults)
+		}
+	}
+
+	rawSort, rawSortDir := req.GetString("sort", ""), req.GetString("sort_dir", "")
+	if deepSearch && (rawSort != "" || rawSortDir != "") {
+		return nil, errors.New("sort and sort_dir cannot be combined with deep_search, which always returns the newest matches first")
+	}
+	sortBy, sortDir, err := parseSearchSort(rawSort, rawSortDir)
+	if err != nil {
+		ch.logger.Error("Invalid search sort", zap.String("sort", rawSort), zap.String("sort_dir", rawSortDir), zap.Error(err))
+		return nil, err
+	}
+
+	includeReactions := req.GetBool("include_reactions", false)
+	if includeReactions && deepSearch {
+		return nil, errors.New("include_reactions cannot be combined with deep_search, which may return thousands of matches")
+	}
+
+	expandThreads := req.GetInt("expand_threads", 0)
+	if expandThreads < 0 || expandThreads > maxSearchExpandThreads {
+		return nil, fmt.Errorf("expand_threads must be between 0 and %d", maxSearchExpandThreads)
+	}
+	if expandThreads > 0 {
+		switch {
+		case deepSearch:
+			return nil, errors.New("expand_threads cannot be combined with deep_search")
+		case req.GetBool("count_only", false):
+			return nil, errors.New("expand_threads cannot be combined with count_only")
+		case req.GetBool("include_thread_root", false):
+			return nil, errors.New("expand_threads cannot be combined with include_thread_root, expanded threads already include their root")
+		}
+	}
+
+	countOnly := req.GetBool("count_only", false)
+	if countOnly {
+		switch {
+		case deepSearch:
+			return nil, errors.New("count_only cannot be combined with deep_search")
+		case req.GetBool("my_channels_only", false):
+			return nil, errors.New("count_only cannot be combined with my_channels_only, Slack's total is not filtered by membership")
+		case allowedChannelTypes() != nil:
+			return nil, errors.New("count_only is not available while SLACK_MCP_ALLOWED_CHANNEL_TYPES is set, Slack's total would include excluded channel types")
+		}
+	}
+
+	groupByChannel := req.GetBool("group_by_channel", false)
+	if groupByChannel {
+		switch {
+		case countOnly:
+			return nil, errors.New("group_by_channel cannot be combined with count_only")
+		case includeReactions, expandThreads > 0, req.GetBool("include_thread_root", false):
+			return nil, errors.New("group_by_channel returns no message rows, so it cannot be combined with include_reactions, expand_threads or include_thread_root")
+		}
+	}
+
 	var (
 		page          int
 		decodedCursor []byte
@@ -1954,19 +6262,98 @@ func (ch *ConversationsHandler) parseParamsToolSearch(req mcp.CallToolRequest) (
 		zap.Int("page", page),
 	)
 	return &searchParams{
-		query: finalQuery,
+		query:             finalQuery,
+		limit:             limit,
+		page:              page,
+		includeThreadRoot: req.GetBool("include_thread_root", false),
+		expandThreads:     expandThreads,
+		myChannelsOnly:    req.GetBool("my_channels_only", false),
+		resolveChannels:   req.GetBool("resolve_channel_names", false),
+		reactions:         includeReactions,
+		avatars:           req.GetBool("include_avatars", false),
+		deepSearch:        deepSearch,
+		countOnly:         countOnly,
+		groupByChannel:    groupByChannel,
+		maxResults:        maxResults,
+		sort:              sortBy,
+		sortDir:           sortDir,
+		freeText:          freeText,
+		filters:           filters,
+	}, nil
+}
+
+// parseSearchSort validates the sort and sort_dir search parameters. Empty
+// values fall back to Slack's defaults, relevance score, highest first.
+func parseSearchSort(sortBy, sortDir string) (string, string, error) {
+	sortBy = strings.ToLower(strings.TrimSpace(sortBy))
+	switch sortBy {
+	case "":
+		sortBy = slack.DEFAULT_SEARCH_SORT
+	case "score", "timestamp":
+	default:
+		return "", "", fmt.Errorf("invalid sort %q: must be 'score' or 'timestamp'", sortBy)
+	}
+	sortDir = strings.ToLower(strings.TrimSpace(sortDir))
+	switch sortDir {
+	case "":
+		sortDir = slack.DEFAULT_SEARCH_SORT_DIR
+	case "asc", "desc":
+	default:
+		return "", "", fmt.Errorf("invalid sort_dir %q: must be 'asc' or 'desc'", sortDir)
+	}
+	return sortBy, sortDir, nil
+}
+
+func (ch *ConversationsHandler) parseParamsToolRecentActivity(req mcp.CallToolRequest) (*searchParams, error) {
+	user := strings.TrimSpace(req.GetString("user", ""))
+	if user == "" {
+		return nil, errors.New("user must be a string")
+	}
+	userFilter, err := ch.paramFormatUser(user)
+	if err != nil {
+		ch.logger.Error("Invalid user", zap.String("user", user), zap.Error(err))
+		return nil, err
+	}
+
+	days := req.GetInt("days", defaultRecentActivityDays)
+	if days < 1 {
+		return nil, fmt.Errorf("days must be a positive integer, got %d", days)
+	}
+	limit := req.GetInt("limit", 20)
+	if limit < 1 || limit > 100 {
+		return nil, fmt.Errorf("limit must be between 1 and 100, got %d", limit)
+	}
+
+	return &searchParams{
+		query: buildRecentActivityQuery(userFilter, days, time.Now().UTC()),
 		limit: limit,
-		page:  page,
+		page:  1,
 	}, nil
 }
 
+// buildRecentActivityQuery builds a search query matching messages from the
+// given user (already formatted as <@UXXXX>) posted within the last days days.
+func buildRecentActivityQuery(userFilter string, days int, now time.Time) string {
+	filters := make(map[string][]string)
+	addFilter(filters, "from", userFilter)
+	// "after:" is exclusive in Slack search, so step back one more day.
+	after := now.AddDate(0, 0, -days-1).Format("2006-01-02")
+	addFilter(filters, "after", after)
+	return buildQuery(nil, filters)
+}
+
 // Slack user IDs may begin with U or W: https://docs.slack.dev/changelog/2016/08/11/user-id-format-changes
 func isSlackUserIDPrefix(s string) bool {
 	return strings.HasPrefix(s, "U") || strings.HasPrefix(s, "W")
 }
 
 func (ch *ConversationsHandler) paramFormatUser(raw string) (string, error) {
-	users := ch.apiProvider.ProvideUsersMap()
+	return formatUserFilter(raw, ch.apiProvider.ProvideUsersMap())
+}
+
+// formatUserFilter turns a user ID or @handle into the <@U123> form search
+// filters expect.
+func formatUserFilter(raw string, users *provider.UsersCache) (string, error) {
 	raw = strings.TrimSpace(raw)
 	if isSlackUserIDPrefix(raw) {
 		u, ok := users.Users[raw]
@@ -1988,6 +6375,41 @@ func (ch *ConversationsHandler) paramFormatUser(raw string) (string, error) {
 	return fmt.Sprintf("<@%s>", uid), nil
 }
 
+// formatIMFilter builds the in: filter of filter_in_im_or_mpim. Slack scopes
+// a search to a DM by naming the other user, in:<@U123>, and to a group DM by
+// its conversation name, in:mpdm-alice--bob--carol-1. DM and group DM IDs and
+// their cached "@" names are mapped to these; anything else is taken as a user,
+// meaning the DM with them.
+func formatIMFilter(raw string, channels *provider.ChannelsCache, users *provider.UsersCache) (string, error) {
+	raw = strings.TrimSpace(raw)
+	id, ok := channels.ChannelsInv[raw]
+	if !ok {
+		id = raw
+	}
+	if c, ok := channels.Channels[id]; ok {
+		switch {
+		case c.IsIM:
+			if c.User == "" {
+				return "", fmt.Errorf("DM %q has no known counterpart to search with", raw)
+			}
+			return fmt.Sprintf("<@%s>", c.User), nil
+		case c.IsMpIM:
+			name := strings.TrimPrefix(c.Name, "@")
+			if name == "" {
+				return "", fmt.Errorf("group DM %q has no known name to search with", raw)
+			}
+			return name, nil
+		default:
+			return "", fmt.Errorf("%q is not a DM or group DM, use filter_in_channel instead", raw)
+		}
+	}
+	f, err := formatUserFilter(raw, users)
+	if err != nil {
+		return "", fmt.Errorf("%q is neither a cached DM or group DM nor a known user", raw)
+	}
+	return f, nil
+}
+
 func (ch *ConversationsHandler) paramFormatChannel(raw string) (string, error) {
 	raw = strings.TrimSpace(raw)
 	cms := ch.apiProvider.ProvideChannelsMaps()
@@ -2007,14 +6429,195 @@ func (ch *ConversationsHandler) paramFormatChannel(raw string) (string, error) {
 	return "", fmt.Errorf("invalid channel format: %q", raw)
 }
 
-func marshalMessagesToCSV(messages []Message) (*mcp.CallToolResult, error) {
-	csvBytes, err := gocsv.MarshalBytes(&messages)
+// marshalMessages renders messages as CSV or, for format "transcript", as a
+// plain transcript followed by the pagination cursor, if any
+func marshalMessages(messages []Message, format string, columns ...string) (*mcp.CallToolResult, error) {
+	if format != "transcript" {
+		return marshalMessagesToCSV(messages, columns...)
+	}
+	contents := []mcp.Content{mcp.NewTextContent(formatTranscript(messages))}
+	if len(messages) > 0 && messages[len(messages)-1].Cursor != "" {
+		contents = append(contents, mcp.NewTextContent("next_cursor: "+messages[len(messages)-1].Cursor))
+	}
+	return &mcp.CallToolResult{Content: contents}, nil
+}
+
+// formatTranscript renders one "[time] @author: text" line per message. Line
+// breaks inside a message are folded so every message stays on its own line.
+func formatTranscript(messages []Message) string {
+	var sb strings.Builder
+	for _, m := range messages {
+		author := m.UserName
+		if author == "" {
+			author = m.BotName
+		}
+		if author == "" {
+			author = m.UserID
+		}
+		body := strings.Join(strings.Fields(m.Text), " ")
+		fmt.Fprintf(&sb, "[%s] @%s: %s\n", m.Time, author, body)
+	}
+	return sb.String()
+}
+
+// marshalMessagesToCSV renders messages as CSV. Optional columns are only
+// included when listed in columns.
+func marshalMessagesToCSV(messages []Message, columns ...string) (*mcp.CallToolResult, error) {
+	csvBytes, err := messagesCSV(&messages, columns)
 	if err != nil {
 		return nil, err
 	}
 	return mcp.NewToolResultText(string(csvBytes)), nil
 }
 
+// Optional Message columns. gocsv ignores omitempty, so these are removed from
+// the CSV unless the request enabled the option that fills them.
+const (
+	colClientMsgID = "ClientMsgID"
+	colSubtype     = "Subtype"
+	colSubscribed  = "Subscribed"
+	colIsUnread    = "IsUnread"
+	colAvatarURL   = "AvatarURL"
+	colTeam        = "Team"
+	colIsExternal  = "IsExternal"
+	colIsContext   = "IsContext"
+)
+
+var optionalMessageColumns = []string{colClientMsgID, colSubtype, colSubscribed, colIsUnread, colAvatarURL, colTeam, colIsExternal, colIsContext}
+
+// messagesCSV marshals rows, a pointer to a slice of Message or of a struct
+// embedding it, and drops the optional columns not listed in columns.
+func messagesCSV(rows any, columns []string) ([]byte, error) {
+	csvBytes, err := gocsv.MarshalBytes(rows)
+	if err != nil {
+		return nil, err
+	}
+	return dropCSVColumns(csvBytes, func(name string) bool {
+		return slices.Contains(optionalMessageColumns, name) && !slices.Contains(columns, name)
+	})
+}
+
+// dropCSVColumns rewrites data without the columns whose header matches drop
+func dropCSVColumns(data []byte, drop func(name string) bool) ([]byte, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil || len(records) == 0 {
+		return data, err
+	}
+	keep := make([]int, 0, len(records[0]))
+	for i, name := range records[0] {
+		if !drop(name) {
+			keep = append(keep, i)
+		}
+	}
+	if len(keep) == len(records[0]) {
+		return data, nil
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	row := make([]string, len(keep))
+	for _, record := range records {
+		for j, i := range keep {
+			row[j] = record[i]
+		}
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// withEmptyResultNote appends note to result when count is zero. The
+// headers-only CSV is kept for parsers; the note tells the model that nothing
+// matched, which it otherwise tends to read as a failed call.
+func withEmptyResultNote(result *mcp.CallToolResult, count int, note string) *mcp.CallToolResult {
+	if result == nil || count > 0 {
+		return result
+	}
+	result.Content = append(result.Content, mcp.NewTextContent(note))
+	return result
+}
+
+// channelLabel returns the cached name of a channel, or its ID if unknown
+func (ch *ConversationsHandler) channelLabel(channelID string) string {
+	if c, ok := ch.apiProvider.ProvideChannelsMaps().Channels[channelID]; ok && c.Name != "" {
+		return c.Name
+	}
+	return channelID
+}
+
+// emojiNormalizer returns a func applying SLACK_MCP_NORMALIZE_EMOJI to processed
+// message text. It runs after text.ProcessText, which would strip unicode emoji
+// and brackets. The custom emoji catalog is looked up with ctx at most once per
+// normalizer, on the first text that may contain a shortcode, so create one per
+// conversion rather than per message.
+func (ch *ConversationsHandler) emojiNormalizer(ctx context.Context) func(s string) string {
+	mode := text.ParseEmojiMode(os.Getenv("SLACK_MCP_NORMALIZE_EMOJI"))
+	if !mode.Enabled() {
+		return func(s string) string { return s }
+	}
+	var catalog map[string]string
+	return func(s string) string {
+		if !strings.Contains(s, ":") {
+			return s
+		}
+		if catalog == nil {
+			catalog = ch.apiProvider.ProvideEmojiCatalog(ctx)
+		}
+		return text.NormalizeEmoji(s, catalog, mode)
+	}
+}
+
+// withBlockActions appends the labels of interactive block elements to s when
+// SLACK_MCP_INCLUDE_BLOCK_ACTIONS is enabled. The labels are added after text
+// processing, which would strip their brackets.
+func (ch *ConversationsHandler) withBlockActions(s string, blocks slack.Blocks) string {
+	if !envBool("SLACK_MCP_INCLUDE_BLOCK_ACTIONS") {
+		return s
+	}
+	actions := text.BlockActionsToText(blocks)
+	if actions == "" {
+		return s
+	}
+	if s == "" {
+		return actions
+	}
+	return s + " " + actions
+}
+
+// resolveBotName returns the app name of the bot that posted msg when
+// SLACK_MCP_RESOLVE_BOTS is enabled, preferring the bot profile embedded in the
+// message over a lookup of its bot ID
+func resolveBotName(msg slack.Message, lookup func(botID string) string) string {
+	if !envBool("SLACK_MCP_RESOLVE_BOTS") {
+		return ""
+	}
+	if msg.BotProfile != nil && msg.BotProfile.Name != "" {
+		return msg.BotProfile.Name
+	}
+	if msg.BotID == "" {
+		return ""
+	}
+	return lookup(msg.BotID)
+}
+
+// botNameLookup returns a func resolving bot IDs via the (cached) bots.info
+// lookup of the provider. Each bot ID is looked up at most once per returned
+// func, even when the lookup fails, so create one per conversion to keep a
+// page of messages from one bot down to a single Tier 2 call.
+func (ch *ConversationsHandler) botNameLookup(ctx context.Context) func(botID string) string {
+	names := make(map[string]string)
+	return func(botID string) string {
+		if name, ok := names[botID]; ok {
+			return name
+		}
+		name, _ := ch.apiProvider.ProvideBotName(ctx, botID)
+		names[botID] = name
+		return name
+	}
+}
+
 func getUserInfo(userID string, usersMap map[string]slack.User) (userName, realName string, ok bool) {
 	if u, ok := usersMap[userID]; ok {
 		return u.Name, u.RealName, true
@@ -2026,6 +6629,31 @@ func getBotInfo(botID string) (userName, realName string, ok bool) {
 	return botID, botID, true
 }
 
+// isExpressionLimit reports whether limit is a time window such as "30min", "2h",
+// "7d", "2w" or "1m" rather than a message count.
+func isExpressionLimit(limit string) bool {
+	for _, unit := range []string{"min", "h", "d", "w", "m"} {
+		if strings.HasSuffix(limit, unit) {
+			return true
+		}
+	}
+	return false
+}
+
+// limitByNumericOrExpression parses a limit that is either a count ("50") or a
+// time window ("7d"). A time window yields oldest/latest timestamps for APIs that
+// support them. A numeric limit is ignored when paginating with a cursor.
+func limitByNumericOrExpression(limit, cursor string, defaultNumeric int, defaultExpression string) (slackLimit int, oldest, latest string, err error) {
+	if isExpressionLimit(limit) {
+		return limitByExpression(limit, defaultExpression)
+	}
+	if cursor != "" {
+		return 0, "", "", nil
+	}
+	slackLimit, err = limitByNumeric(limit, defaultNumeric)
+	return slackLimit, "", "", err
+}
+
 func limitByNumeric(limit string, defaultLimit int) (int, error) {
 	if limit == "" {
 		return defaultLimit, nil
@@ -2044,11 +6672,14 @@ func limitByExpression(limit, defaultLimit string) (slackLimit int, oldest, late
 	if len(limit) < 2 {
 		return 0, "", "", fmt.Errorf("invalid duration limit %q: too short", limit)
 	}
-	suffix := limit[len(limit)-1]
-	numStr := limit[:len(limit)-1]
+	// "m" means months, minutes are spelled out as "min"
+	suffix, numStr := "min", strings.TrimSuffix(limit, "min")
+	if numStr == limit {
+		suffix, numStr = limit[len(limit)-1:], limit[:len(limit)-1]
+	}
 	n, err := strconv.Atoi(numStr)
 	if err != nil || n <= 0 {
-		return 0, "", "", fmt.Errorf("invalid duration limit %q: must be a positive integer followed by 'd', 'w', or 'm'", limit)
+		return 0, "", "", fmt.Errorf("invalid duration limit %q: must be a positive integer followed by 'min', 'h', 'd', 'w', or 'm'", limit)
 	}
 	now := time.Now()
 	loc := now.Location()
@@ -2056,20 +6687,160 @@ func limitByExpression(limit, defaultLimit string) (slackLimit int, oldest, late
 
 	var oldestTime time.Time
 	switch suffix {
-	case 'd':
+	case "min":
+		oldestTime = now.Add(-time.Duration(n) * time.Minute)
+	case "h":
+		oldestTime = now.Add(-time.Duration(n) * time.Hour)
+	case "d":
 		oldestTime = startOfToday.AddDate(0, 0, -n+1)
-	case 'w':
+	case "w":
 		oldestTime = startOfToday.AddDate(0, 0, -n*7+1)
-	case 'm':
+	case "m":
 		oldestTime = startOfToday.AddDate(0, -n, 0)
 	default:
-		return 0, "", "", fmt.Errorf("invalid duration limit %q: must end in 'd', 'w', or 'm'", limit)
+		return 0, "", "", fmt.Errorf("invalid duration limit %q: must end in 'min', 'h', 'd', 'w', or 'm'", limit)
 	}
 	latest = fmt.Sprintf("%d.000000", now.Unix())
 	oldest = fmt.Sprintf("%d.000000", oldestTime.Unix())
 	return 100, oldest, latest, nil
 }
 
+var slackTsRe = regexp.MustCompile(`^\d+(\.\d+)?$`)
+
+// parseSinceToTs normalizes an RFC3339 time or a Slack ts to a Slack ts.
+// An empty input returns an empty ts.
+func parseSinceToTs(since string) (string, error) {
+	since = strings.TrimSpace(since)
+	if since == "" {
+		return "", nil
+	}
+	if slackTsRe.MatchString(since) {
+		if !strings.Contains(since, ".") {
+			since += ".000000"
+		}
+		return since, nil
+	}
+	t, err := time.Parse(time.RFC3339, since)
+	if err != nil {
+		return "", fmt.Errorf("invalid since %q: must be an RFC3339 time like 2025-01-02T15:04:05Z or a Slack ts like 1234567890.123456", since)
+	}
+	return fmt.Sprintf("%d.%06d", t.Unix(), t.Nanosecond()/1000), nil
+}
+
+// compareSlackTs compares two Slack timestamps numerically, returning -1, 0 or 1.
+// Malformed parts compare as zero.
+func compareSlackTs(a, b string) int {
+	aSec, aFrac, _ := strings.Cut(a, ".")
+	bSec, bFrac, _ := strings.Cut(b, ".")
+	as, _ := strconv.ParseInt(aSec, 10, 64)
+	bs, _ := strconv.ParseInt(bSec, 10, 64)
+	if as != bs {
+		if as < bs {
+			return -1
+		}
+		return 1
+	}
+	af, _ := strconv.ParseInt((aFrac + "000000")[:6], 10, 64)
+	bf, _ := strconv.ParseInt((bFrac + "000000")[:6], 10, 64)
+	switch {
+	case af < bf:
+		return -1
+	case af > bf:
+		return 1
+	}
+	return 0
+}
+
+// filterMessagesWithLinks keeps messages whose text contains at least one URL,
+// detected the same way as for conversations_extract_links.
+func filterMessagesWithLinks(messages []slack.Message) []slack.Message {
+	result := make([]slack.Message, 0, len(messages))
+	for _, m := range messages {
+		if len(text.ExtractLinks(m.Text)) > 0 {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// dailyAnchors keeps the first message of each calendar day, in the time zone
+// of tf, and drops the rest. The relative order of the kept messages is
+// unchanged.
+func dailyAnchors(messages []Message, tf text.TimeFormat) []Message {
+	dayFormat := text.TimeFormat{Layout: "2006-01-02", Location: tf.Location}
+	days := make([]string, len(messages))
+	first := make(map[string]string)
+	for i, m := range messages {
+		day, err := dayFormat.FormatTimestamp(m.MsgID)
+		if err != nil {
+			continue
+		}
+		days[i] = day
+		if ts, ok := first[day]; !ok || m.MsgID < ts {
+			first[day] = m.MsgID
+		}
+	}
+	result := make([]Message, 0, len(first))
+	for i, m := range messages {
+		if days[i] != "" && first[days[i]] == m.MsgID {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// filterMessagesByAuthors keeps messages posted by one of the given user IDs
+func filterMessagesByAuthors(messages []slack.Message, userIDs []string) []slack.Message {
+	result := make([]slack.Message, 0, len(messages))
+	for _, m := range messages {
+		if slices.Contains(userIDs, m.User) {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// resolveUserList resolves a comma-separated list of user IDs and @handles to
+// user IDs through the users cache, dropping duplicates. Unknown users are an
+// error rather than silently matching nothing.
+func resolveUserList(raw string, users *provider.UsersCache) ([]string, error) {
+	var ids, unknown []string
+	for _, part := range strings.Split(raw, ",") {
+		name := strings.TrimSpace(part)
+		if name == "" {
+			continue
+		}
+		id := ""
+		if _, ok := users.Users[name]; ok && isSlackUserIDPrefix(name) {
+			id = name
+		} else if uid, ok := users.UsersInv[strings.TrimPrefix(name, "@")]; ok {
+			id = uid
+		}
+		if id == "" {
+			unknown = append(unknown, name)
+			continue
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown users: %s", strings.Join(unknown, ", "))
+	}
+	return ids, nil
+}
+
+// filterMessagesAfter keeps messages whose ts is strictly after ts.
+func filterMessagesAfter(messages []slack.Message, ts string) []slack.Message {
+	result := make([]slack.Message, 0, len(messages))
+	for _, m := range messages {
+		if compareSlackTs(m.Timestamp, ts) > 0 {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
 func extractThreadTS(rawurl string) (string, error) {
 	u, err := url.Parse(rawurl)
 	if err != nil {
@@ -2078,6 +6849,30 @@ func extractThreadTS(rawurl string) (string, error) {
 	return u.Query().Get("thread_ts"), nil
 }
 
+var permalinkPathRe = regexp.MustCompile(`^/archives/([CDG][A-Z0-9]+)/p(\d{7,})$`)
+
+// parseMessagePermalink extracts the channel ID and message ts from a Slack
+// message permalink such as https://example.slack.com/archives/C1234567890/p1234567890123456.
+// threadTs is set when the permalink points to a thread reply.
+func parseMessagePermalink(permalink string) (channelID, ts, threadTs string, err error) {
+	u, err := url.Parse(strings.TrimSpace(permalink))
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return "", "", "", fmt.Errorf("invalid permalink %q: expected a Slack message URL like https://example.slack.com/archives/C1234567890/p1234567890123456", permalink)
+	}
+	m := permalinkPathRe.FindStringSubmatch(strings.TrimSuffix(u.Path, "/"))
+	if m == nil {
+		return "", "", "", fmt.Errorf("invalid permalink %q: expected a path like /archives/C1234567890/p1234567890123456", permalink)
+	}
+	digits := m[2]
+	ts = digits[:len(digits)-6] + "." + digits[len(digits)-6:]
+
+	threadTs = u.Query().Get("thread_ts")
+	if threadTs != "" && !strings.Contains(threadTs, ".") {
+		return "", "", "", fmt.Errorf("invalid permalink %q: malformed thread_ts %q", permalink, threadTs)
+	}
+	return m[1], ts, threadTs, nil
+}
+
 func parseFlexibleDate(dateStr string) (time.Time, string, error) {
 	dateStr = strings.TrimSpace(dateStr)
 	standardFormats := []string{
@@ -2244,6 +7039,29 @@ func buildDateFilters(before, after, on, during string) (map[string]string, erro
 	return out, nil
 }
 
+// parseDateRange splits an inclusive "start..end" expression into normalized
+// after/before dates, each side accepting any format supported by
+// parseFlexibleDate. Slack's after: and before: exclude the given day, so the
+// bounds are widened by one day each; a same-day range thus finds that day.
+func parseDateRange(dateRange string) (after, before string, err error) {
+	parts := strings.SplitN(dateRange, "..", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+		return "", "", fmt.Errorf("invalid filter_date_range %q: expected format 'start..end'", dateRange)
+	}
+	start, _, err := parseFlexibleDate(parts[0])
+	if err != nil {
+		return "", "", fmt.Errorf("invalid filter_date_range start: %v", err)
+	}
+	end, _, err := parseFlexibleDate(parts[1])
+	if err != nil {
+		return "", "", fmt.Errorf("invalid filter_date_range end: %v", err)
+	}
+	if start.After(end) {
+		return "", "", fmt.Errorf("invalid filter_date_range %q: start is after end", dateRange)
+	}
+	return start.AddDate(0, 0, -1).Format("2006-01-02"), end.AddDate(0, 0, 1).Format("2006-01-02"), nil
+}
+
 func isFilterKey(key string) bool {
 	_, ok := validFilterKeys[strings.ToLower(key)]
 	return ok
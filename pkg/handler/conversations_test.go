@@ -2,23 +2,40 @@ package handler
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand/v2"
+	"net/url"
 	"os"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/gocarina/gocsv"
 	"github.com/google/uuid"
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/korotovsky/slack-mcp-server/pkg/provider/edge"
+	"github.com/korotovsky/slack-mcp-server/pkg/provider/edge/fasttime"
 	"github.com/korotovsky/slack-mcp-server/pkg/test/util"
+	"github.com/korotovsky/slack-mcp-server/pkg/text"
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
 	"github.com/openai/openai-go/packages/param"
 	"github.com/openai/openai-go/responses"
+	"github.com/slack-go/slack"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestIntegrationConversations(t *testing.T) {
@@ -535,6 +552,9 @@ func TestUnitLimitByExpression_Valid(t *testing.T) {
 		{"2 weeks", "2w", 13 * 86400, 14 * 86400},
 		{"1 month", "1m", oneMonthSpan - tolerance, oneMonthSpan + tolerance},
 		{"2 months", "2m", twoMonthSpan - tolerance, twoMonthSpan + tolerance},
+		{"30 minutes", "30min", 30 * 60, 30*60 + 1},
+		{"2 hours", "2h", 2 * 3600, 2*3600 + 1},
+		{"36 hours", "36h", 36 * 3600, 36*3600 + 1},
 	}
 
 	for _, tt := range tests {
@@ -579,6 +599,13 @@ func TestUnitLimitByExpression_Invalid(t *testing.T) {
 		"1x",  // bad suffix
 		"1",   // missing suffix
 		"01",  // no suffix + zero value
+		"0h",  // zero hours
+		"xh",  // not a number
+		"h",   // too short
+		"min", // missing number
+		"0min",
+		"1.5h",
+		"30mins",
 	}
 
 	for _, input := range invalid {
@@ -591,6 +618,48 @@ func TestUnitLimitByExpression_Invalid(t *testing.T) {
 	}
 }
 
+func TestUnitLimitByNumericOrExpression(t *testing.T) {
+	tests := []struct {
+		name       string
+		limit      string
+		cursor     string
+		wantLimit  int
+		wantWindow bool
+		wantErr    bool
+	}{
+		{name: "default numeric", limit: "", wantLimit: 50},
+		{name: "explicit numeric", limit: "20", wantLimit: 20},
+		{name: "numeric with cursor", limit: "20", cursor: "abc", wantLimit: 0},
+		{name: "days window", limit: "7d", wantLimit: 100, wantWindow: true},
+		{name: "weeks window", limit: "2w", wantLimit: 100, wantWindow: true},
+		{name: "hours window", limit: "2h", wantLimit: 100, wantWindow: true},
+		{name: "minutes window", limit: "30min", wantLimit: 100, wantWindow: true},
+		{name: "window with cursor", limit: "2w", cursor: "abc", wantLimit: 100, wantWindow: true},
+		{name: "invalid numeric", limit: "lots", wantErr: true},
+		{name: "invalid window", limit: "0d", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slackLimit, oldest, latest, err := limitByNumericOrExpression(tt.limit, tt.cursor, 50, "1d")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantLimit, slackLimit)
+			if tt.wantWindow {
+				assert.NotEmpty(t, oldest)
+				assert.NotEmpty(t, latest)
+				assert.Less(t, oldest, latest)
+			} else {
+				assert.Empty(t, oldest)
+				assert.Empty(t, latest)
+			}
+		})
+	}
+}
+
 func TestUnitIsChannelAllowedForConfig(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -633,6 +702,33 @@ func TestUnitIsChannelAllowedForConfig(t *testing.T) {
 	}
 }
 
+func TestUnitWithDefaultChannel(t *testing.T) {
+	tests := []struct {
+		name           string
+		channel        string
+		defaultChannel string
+		policy         string
+		want           string
+		allowed        bool
+	}{
+		{"explicit channel without default", "C123", "", "true", "C123", true},
+		{"explicit channel overrides default", "C456", "C123", "C123,C456", "C456", true},
+		{"default applied when channel_id is absent", "", "C123", "C123", "C123", true},
+		{"default is trimmed", "", " #status ", "true", "#status", true},
+		{"no channel and no default", "", "", "true", "", true},
+		{"default outside the allowlist is refused", "", "C789", "C123,C456", "C789", false},
+		{"default in the blocklist is refused", "", "C123", "!C123", "C123", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := withDefaultChannel(tt.channel, tt.defaultChannel)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.allowed, isChannelAllowedForConfig(got, tt.policy), "the allowlist governs the default channel too")
+		})
+	}
+}
+
 func TestUnitIsSlackUserIDPrefix(t *testing.T) {
 	tests := []struct {
 		name string
@@ -653,3 +749,2994 @@ func TestUnitIsSlackUserIDPrefix(t *testing.T) {
 		})
 	}
 }
+
+func TestUnitFormatIMFilter(t *testing.T) {
+	users := &provider.UsersCache{
+		Users: map[string]slack.User{
+			"U1": {ID: "U1", Name: "alice"},
+			"U2": {ID: "U2", Name: "bob"},
+		},
+		UsersInv: map[string]string{"alice": "U1", "bob": "U2"},
+	}
+	channels := &provider.ChannelsCache{
+		Channels: map[string]provider.Channel{
+			"D1": {ID: "D1", Name: "@alice", IsIM: true, User: "U1"},
+			"G1": {ID: "G1", Name: "@mpdm-alice--bob--carol-1", IsMpIM: true, Members: []string{"U1", "U2", "U3"}},
+			"C1": {ID: "C1", Name: "#general"},
+		},
+		ChannelsInv: map[string]string{"@alice": "D1", "@mpdm-alice--bob--carol-1": "G1", "#general": "C1"},
+	}
+
+	tests := []struct {
+		name      string
+		raw       string
+		wantQuery string
+		wantErr   string
+	}{
+		{name: "DM by ID", raw: "D1", wantQuery: "deploy in:<@U1>"},
+		{name: "DM by cached name", raw: "@alice", wantQuery: "deploy in:<@U1>"},
+		{name: "DM by user ID", raw: "U1", wantQuery: "deploy in:<@U1>"},
+		{name: "DM with user without cached DM", raw: "@bob", wantQuery: "deploy in:<@U2>"},
+		{name: "group DM by ID", raw: "G1", wantQuery: "deploy in:mpdm-alice--bob--carol-1"},
+		{name: "group DM by cached name", raw: " @mpdm-alice--bob--carol-1 ", wantQuery: "deploy in:mpdm-alice--bob--carol-1"},
+		{name: "channels are rejected", raw: "C1", wantErr: `"C1" is not a DM or group DM, use filter_in_channel instead`},
+		{name: "unknown conversation", raw: "D9", wantErr: `"D9" is neither a cached DM or group DM nor a known user`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := formatIMFilter(tt.raw, channels, users)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			filters := map[string][]string{}
+			addFilter(filters, "in", f)
+			assert.Equal(t, tt.wantQuery, buildQuery([]string{"deploy"}, filters))
+		})
+	}
+
+	t.Run("in and with filters are kept apart", func(t *testing.T) {
+		in, err := formatIMFilter("G1", channels, users)
+		require.NoError(t, err)
+		with, err := formatUserFilter("@bob", users)
+		require.NoError(t, err)
+		filters := map[string][]string{}
+		addFilter(filters, "in", in)
+		addFilter(filters, "with", with)
+		assert.Equal(t, "deploy in:mpdm-alice--bob--carol-1 with:<@U2>", buildQuery([]string{"deploy"}, filters))
+	})
+}
+
+func TestUnitActivityItems(t *testing.T) {
+	const payload = `{
+		"ok": true,
+		"items": [
+			{"is_unread": true, "feed_ts": "1700000300.000100", "item": {"type": "at_user", "message": {"ts": "1700000300.000100", "channel": "C1", "author_user_id": "U2"}}},
+			{"is_unread": false, "feed_ts": "1700000200.000100", "item": {"type": "message_reaction", "message": {"ts": "1700000000.000100", "channel": "C1", "thread_ts": "1699990000.000100", "author_user_id": "U1"}, "reaction": {"user": "U3", "name": "tada"}}},
+			{"is_unread": true, "feed_ts": "1700000100.000100", "item": {"type": "thread_v2", "bundle_info": {"payload": {"thread_entry": {"channel_id": "C9", "thread_ts": "1699000000.000100", "latest_ts": "1700000100.000100", "unread_msg_count": 3}}}}},
+			{"is_unread": false, "feed_ts": "1700000050.000100", "item": {"type": "at_channel", "message": {"ts": "1700000050.000100", "channel": "C1", "author_user_id": "U9"}}},
+			{"is_unread": false, "feed_ts": "1700000010.000100", "item": {"type": "list_record_assigned"}}
+		],
+		"response_metadata": {"next_cursor": "bmV4dA=="}
+	}`
+	var feed edge.ActivityFeedResponse
+	require.NoError(t, json.Unmarshal([]byte(payload), &feed))
+	assert.Equal(t, "bmV4dA==", feed.ResponseMetadata.NextCursor)
+
+	channels := map[string]provider.Channel{"C1": {ID: "C1", Name: "#general"}}
+	users := map[string]slack.User{
+		"U2": {ID: "U2", Name: "alice"},
+		"U3": {ID: "U3", Name: "bob"},
+	}
+
+	items := activityItems(feed.Items, channels, users, text.TimeFormat{})
+	assert.Equal(t, []ActivityItem{
+		{Type: "mention", Time: "2023-11-14T22:18:20Z", ChannelID: "C1", ChannelName: "#general", MsgID: "1700000300.000100", UserID: "U2", UserName: "alice", Unread: true},
+		{Type: "reaction", Time: "2023-11-14T22:16:40Z", ChannelID: "C1", ChannelName: "#general", MsgID: "1700000000.000100", ThreadTs: "1699990000.000100", UserID: "U3", UserName: "bob", Reaction: "tada"},
+		{Type: "thread", Time: "2023-11-14T22:15:00Z", ChannelID: "C9", MsgID: "1700000100.000100", ThreadTs: "1699000000.000100", Unread: true},
+		{Type: "mention", Time: "2023-11-14T22:14:10Z", ChannelID: "C1", ChannelName: "#general", MsgID: "1700000050.000100", UserID: "U9"},
+	}, items, "the reaction row names who reacted, unknown item types are skipped")
+}
+
+func TestUnitParseActivityKinds(t *testing.T) {
+	kinds, err := parseActivityKinds(" Mentions, threads,mentions ")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mentions", "threads"}, kinds)
+
+	_, err = parseActivityKinds("mentions,stars")
+	assert.EqualError(t, err, `invalid types value "stars", allowed values are mentions, reactions and threads`)
+
+	_, err = parseActivityKinds(" , ")
+	assert.Error(t, err)
+}
+
+func TestUnitBuildRecentActivityQuery(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		userFilter string
+		days       int
+		want       string
+	}{
+		{"one week", "<@U0123ABCD>", 7, "from:<@U0123ABCD> after:2024-03-07"},
+		{"single day", "<@W0123ABCD>", 1, "from:<@W0123ABCD> after:2024-03-13"},
+		{"across month boundary", "<@U0123ABCD>", 30, "from:<@U0123ABCD> after:2024-02-13"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildRecentActivityQuery(tt.userFilter, tt.days, now)
+			assert.Equal(t, tt.want, got)
+			assert.Contains(t, got, "from:"+tt.userFilter)
+		})
+	}
+
+	t.Run("handles are resolved as paramFormatUser does", func(t *testing.T) {
+		users := &provider.UsersCache{
+			Users:    map[string]slack.User{"U0123ABCD": {ID: "U0123ABCD", Name: "alice"}},
+			UsersInv: map[string]string{"alice": "U0123ABCD"},
+		}
+		for _, raw := range []string{"@alice", "alice", " U0123ABCD "} {
+			userFilter, err := formatUserFilter(raw, users)
+			require.NoError(t, err, raw)
+			assert.Equal(t, "from:<@U0123ABCD> after:2024-03-07", buildRecentActivityQuery(userFilter, 7, now), raw)
+		}
+
+		_, err := formatUserFilter("@nobody", users)
+		assert.EqualError(t, err, `user "nobody" not found`)
+	})
+}
+
+func TestUnitThrottledWarning(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+	logger := zap.New(core)
+
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	w := newThrottledWarning("short", 30*time.Second)
+	w.now = func() time.Time { return now }
+
+	for i := 0; i < 5; i++ {
+		w.Warn(logger)
+	}
+	require.Equal(t, 1, logs.Len(), "repeated calls within the window must log once")
+	assert.Equal(t, "short", logs.All()[0].Message)
+
+	now = now.Add(10 * time.Second)
+	w.Warn(logger)
+	assert.Equal(t, 1, logs.Len(), "call inside the window must be suppressed")
+
+	now = now.Add(30 * time.Second)
+	w.Warn(logger)
+	w.Warn(logger)
+	require.Equal(t, 2, logs.Len(), "only one call per window after it elapses")
+	assert.Equal(t, "short", logs.All()[1].Message)
+}
+
+func TestUnitLogSyncNotReady(t *testing.T) {
+	tests := []struct {
+		name          string
+		usersReady    bool
+		channelsReady bool
+		want          []string
+	}{
+		{"both syncing", false, false, []string{"users sync is not ready", "channels sync is not ready"}},
+		{"only channels syncing", true, false, []string{"channels sync is not ready"}},
+		{"only users syncing", false, true, []string{"users sync is not ready"}},
+		{"both ready", true, true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zap.WarnLevel)
+			LogSyncNotReady(zap.New(core), tt.usersReady, tt.channelsReady)
+
+			require.Equal(t, len(tt.want), logs.Len())
+			for i, want := range tt.want {
+				assert.Contains(t, logs.All()[i].Message, want)
+			}
+		})
+	}
+}
+
+func TestUnitMarshalGroupedUnreadsToJSON(t *testing.T) {
+	groups := []UnreadChannelMessages{
+		groupUnreadMessages(
+			UnreadChannel{ChannelID: "D123", ChannelName: "@alice", ChannelType: "dm", UnreadCount: 2},
+			[]Message{
+				{MsgID: "1700000000.000100", Channel: "@alice", Text: "hi"},
+				{MsgID: "1700000000.000200", Channel: "@alice", Text: "are you there?"},
+			},
+		),
+		groupUnreadMessages(
+			UnreadChannel{ChannelID: "C456", ChannelName: "#general", ChannelType: "internal", UnreadCount: 1},
+			nil,
+		),
+	}
+
+	result, err := marshalGroupedUnreadsToJSON(groups)
+	require.NoError(t, err)
+	require.Len(t, result.Content, 1)
+	tc, ok := result.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+
+	var decoded []struct {
+		ChannelID   string `json:"channelID"`
+		ChannelName string `json:"channelName"`
+		ChannelType string `json:"channelType"`
+		Messages    []struct {
+			MsgID string `json:"msgID"`
+			Text  string `json:"text"`
+		} `json:"messages"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(tc.Text), &decoded))
+	require.Len(t, decoded, 2)
+
+	assert.Equal(t, "D123", decoded[0].ChannelID)
+	assert.Equal(t, "dm", decoded[0].ChannelType)
+	require.Len(t, decoded[0].Messages, 2)
+	assert.Equal(t, "1700000000.000100", decoded[0].Messages[0].MsgID)
+	assert.Equal(t, "are you there?", decoded[0].Messages[1].Text)
+
+	assert.Equal(t, "#general", decoded[1].ChannelName)
+	assert.NotNil(t, decoded[1].Messages)
+	assert.Empty(t, decoded[1].Messages)
+	assert.Contains(t, tc.Text, `"messages":[]`)
+}
+
+func TestUnitParseDateRange(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantAfter  string
+		wantBefore string
+		wantErr    bool
+	}{
+		{"ISO dates", "2023-01-01..2023-01-15", "2022-12-31", "2023-01-16", false},
+		{"mixed formats", "Jan 1, 2023..2023/01/15", "2022-12-31", "2023-01-16", false},
+		{"surrounding spaces", " 2023-01-01 .. 2023-01-15 ", "2022-12-31", "2023-01-16", false},
+		{"same day is inclusive", "2023-03-01..2023-03-01", "2023-02-28", "2023-03-02", false},
+		{"start after end", "2023-01-15..2023-01-01", "", "", true},
+		{"missing separator", "2023-01-01", "", "", true},
+		{"missing end", "2023-01-01..", "", "", true},
+		{"missing start", "..2023-01-15", "", "", true},
+		{"invalid start", "not-a-date..2023-01-15", "", "", true},
+		{"invalid end", "2023-01-01..not-a-date", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			after, before, err := parseDateRange(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAfter, after)
+			assert.Equal(t, tt.wantBefore, before)
+
+			filters, err := buildDateFilters(before, after, "", "")
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{"after": tt.wantAfter, "before": tt.wantBefore}, filters)
+		})
+	}
+}
+
+func TestUnitThreadRootsToFetch(t *testing.T) {
+	match := func(channelID, ts, threadTs string) slack.SearchMessage {
+		m := slack.SearchMessage{
+			Timestamp: ts,
+			Channel:   slack.CtxChannel{ID: channelID, Name: "general"},
+			Permalink: "https://example.slack.com/archives/" + channelID + "/p" + strings.ReplaceAll(ts, ".", ""),
+		}
+		if threadTs != "" {
+			m.Permalink += "?thread_ts=" + threadTs
+		}
+		return m
+	}
+
+	t.Run("only replies need their root", func(t *testing.T) {
+		refs := threadRootsToFetch([]slack.SearchMessage{
+			match("C1", "1700000000.000100", ""),                  // not threaded
+			match("C1", "1700000000.000200", "1700000000.000200"), // thread root itself
+			match("C1", "1700000000.000300", "1700000000.000050"), // reply
+		}, maxSearchThreadRoots)
+		require.Len(t, refs, 1)
+		assert.Equal(t, "C1", refs[0].channelID)
+		assert.Equal(t, "#general", refs[0].channelName)
+		assert.Equal(t, "1700000000.000050", refs[0].threadTs)
+	})
+
+	t.Run("roots already in matches are skipped", func(t *testing.T) {
+		refs := threadRootsToFetch([]slack.SearchMessage{
+			match("C1", "1700000000.000050", ""),
+			match("C1", "1700000000.000300", "1700000000.000050"),
+		}, maxSearchThreadRoots)
+		assert.Empty(t, refs)
+	})
+
+	t.Run("replies to the same thread are fetched once", func(t *testing.T) {
+		refs := threadRootsToFetch([]slack.SearchMessage{
+			match("C1", "1700000000.000300", "1700000000.000050"),
+			match("C1", "1700000000.000400", "1700000000.000050"),
+			match("C2", "1700000000.000500", "1700000000.000050"),
+		}, maxSearchThreadRoots)
+		assert.Len(t, refs, 2)
+	})
+
+	t.Run("capped", func(t *testing.T) {
+		var matches []slack.SearchMessage
+		for i := 0; i < 25; i++ {
+			matches = append(matches, match("C1", fmt.Sprintf("1700000100.%06d", i), fmt.Sprintf("1700000000.%06d", i)))
+		}
+		assert.Len(t, threadRootsToFetch(matches, 3), 3)
+		assert.Len(t, threadRootsToFetch(matches, maxSearchThreadRoots), maxSearchThreadRoots)
+	})
+
+	t.Run("names resolved from the cache keep their prefix", func(t *testing.T) {
+		reply := match("C1", "1700000000.000300", "1700000000.000050")
+		reply.Channel.Name = "#general"
+		dm := match("D1", "1700000000.000400", "1700000000.000060")
+		dm.Channel.Name = "@alice"
+
+		refs := threadRootsToFetch([]slack.SearchMessage{reply, dm}, maxSearchThreadRoots)
+		require.Len(t, refs, 2)
+		assert.Equal(t, "#general", refs[0].channelName, "the key matches the Channel column of the reply")
+		assert.Equal(t, "@alice", refs[1].channelName)
+
+		expand := threadsToExpand([]slack.SearchMessage{reply, dm}, maxSearchExpandThreads)
+		require.Len(t, expand, 2)
+		assert.Equal(t, "#general", expand[0].channelName)
+		assert.Equal(t, "@alice", expand[1].channelName)
+	})
+}
+
+func TestUnitInsertThreadRoots(t *testing.T) {
+	messages := []Message{
+		{MsgID: "1700000000.000002", Channel: "#general", ThreadTs: "1700000000.000001"},
+		{MsgID: "1700000000.000003", Channel: "#general", ThreadTs: "1700000000.000001"},
+		{MsgID: "1700000000.000005", Channel: "#random"},
+	}
+	roots := map[string]Message{
+		"#general/1700000000.000001": {MsgID: "1700000000.000001", Channel: "#general", Text: "root"},
+	}
+
+	got := insertThreadRoots(messages, roots)
+
+	require.Len(t, got, 4)
+	assert.Equal(t, "1700000000.000001", got[0].MsgID, "the root precedes the first reply")
+	assert.True(t, got[0].IsContext, "inserted roots are flagged as context")
+	for _, m := range got[1:] {
+		assert.False(t, m.IsContext, "search matches are not context rows")
+	}
+
+	csvBytes, err := messagesCSV(&got, []string{colIsContext})
+	require.NoError(t, err)
+	records, err := csv.NewReader(strings.NewReader(string(csvBytes))).ReadAll()
+	require.NoError(t, err)
+	idx := slices.Index(records[0], colIsContext)
+	require.NotEqual(t, -1, idx, "the IsContext column is kept when requested")
+	assert.Equal(t, "true", records[1][idx])
+	assert.Equal(t, "false", records[2][idx])
+}
+
+func TestUnitSearchExpandThreads(t *testing.T) {
+	match := func(ts, threadTs string) slack.SearchMessage {
+		m := slack.SearchMessage{
+			Timestamp: ts,
+			Channel:   slack.CtxChannel{ID: "C1", Name: "general"},
+			Permalink: "https://example.slack.com/archives/C1/p" + strings.ReplaceAll(ts, ".", ""),
+		}
+		if threadTs != "" {
+			m.Permalink += "?thread_ts=" + threadTs
+		}
+		return m
+	}
+	matches := []slack.SearchMessage{
+		match("1700000000.000300", "1700000000.000100"), // top match, a reply
+		match("1700000000.000900", ""),                  // standalone
+		match("1700000000.000200", "1700000000.000100"), // same thread as the top match
+	}
+
+	t.Run("the thread of the top match is expanded", func(t *testing.T) {
+		refs := threadsToExpand(matches, 1)
+		assert.Equal(t, []threadRootRef{{channelID: "C1", channelName: "#general", threadTs: "1700000000.000100"}}, refs)
+
+		refs = threadsToExpand(matches, maxSearchExpandThreads)
+		require.Len(t, refs, 2, "matches of one thread expand it once")
+		assert.Equal(t, "1700000000.000900", refs[1].threadTs, "a standalone match is its own root")
+	})
+
+	t.Run("threads are grouped under their match", func(t *testing.T) {
+		messages := []Message{
+			{MsgID: "1700000000.000300", Channel: "#general", ThreadTs: "1700000000.000100"},
+			{MsgID: "1700000000.000900", Channel: "#general"},
+			{MsgID: "1700000000.000200", Channel: "#general", ThreadTs: "1700000000.000100"},
+		}
+		threads := map[string][]Message{
+			"#general/1700000000.000100": {
+				{MsgID: "1700000000.000100", Channel: "#general", ThreadTs: "1700000000.000100"},
+				{MsgID: "1700000000.000200", Channel: "#general", ThreadTs: "1700000000.000100"},
+				{MsgID: "1700000000.000300", Channel: "#general", ThreadTs: "1700000000.000100"},
+			},
+		}
+
+		grouped := groupThreadsUnderMatches(messages, threads)
+		var ids []string
+		for _, m := range grouped {
+			ids = append(ids, m.MsgID)
+		}
+		assert.Equal(t, []string{
+			"1700000000.000300", // top match
+			"1700000000.000100", // its thread, without the match itself
+			"1700000000.000200",
+			"1700000000.000900", // later match already listed in the thread is dropped
+		}, ids)
+	})
+}
+
+func TestUnitParseMessagePermalink(t *testing.T) {
+	tests := []struct {
+		name         string
+		permalink    string
+		wantChannel  string
+		wantTs       string
+		wantThreadTs string
+		wantErr      bool
+	}{
+		{
+			name:        "channel message",
+			permalink:   "https://example.slack.com/archives/C1234567890/p1234567890123456",
+			wantChannel: "C1234567890",
+			wantTs:      "1234567890.123456",
+		},
+		{
+			name:         "thread reply",
+			permalink:    "https://example.slack.com/archives/C1234567890/p1234567899000100?thread_ts=1234567890.123456&cid=C1234567890",
+			wantChannel:  "C1234567890",
+			wantTs:       "1234567899.000100",
+			wantThreadTs: "1234567890.123456",
+		},
+		{
+			name:        "direct message",
+			permalink:   "https://example.slack.com/archives/D0987654321/p1700000000000100",
+			wantChannel: "D0987654321",
+			wantTs:      "1700000000.000100",
+		},
+		{name: "not a url", permalink: "C1234567890/p1234567890123456", wantErr: true},
+		{name: "missing message part", permalink: "https://example.slack.com/archives/C1234567890", wantErr: true},
+		{name: "bad message id", permalink: "https://example.slack.com/archives/C1234567890/p12ab", wantErr: true},
+		{name: "bad channel id", permalink: "https://example.slack.com/archives/U1234567890/p1234567890123456", wantErr: true},
+		{name: "bad thread_ts", permalink: "https://example.slack.com/archives/C1234567890/p1234567890123456?thread_ts=abc", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			channel, ts, threadTs, err := parseMessagePermalink(tt.permalink)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantChannel, channel)
+			assert.Equal(t, tt.wantTs, ts)
+			assert.Equal(t, tt.wantThreadTs, threadTs)
+		})
+	}
+}
+
+func TestUnitParseReactionList(t *testing.T) {
+	assert.Nil(t, parseReactionList(""))
+	assert.Equal(t, []string{"thumbsup", "thumbsdown", "eyes"}, parseReactionList(":thumbsup:, thumbsdown,,eyes,thumbsup"))
+}
+
+func TestUnitCheckReactionsAllowed(t *testing.T) {
+	tests := []struct {
+		name         string
+		channel      string
+		config       string
+		enabledTools string
+		wantErr      bool
+	}{
+		{name: "disabled by default", channel: "C123", wantErr: true},
+		{name: "enabled via enabled tools", channel: "C123", enabledTools: "conversations_add_message,reactions_add"},
+		{name: "enabled for all", channel: "C123", config: "true"},
+		{name: "channel in allowlist", channel: "C123", config: "C123,C456"},
+		{name: "channel not in allowlist", channel: "C789", config: "C123,C456", wantErr: true},
+		{name: "channel negated", channel: "C123", config: "!C123", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkReactionsAllowed(tt.channel, tt.config, tt.enabledTools)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestUnitCheckOwnReaction(t *testing.T) {
+	reactions := []slack.ItemReaction{
+		{Name: "thumbsup", Count: 2, Users: []string{"U1", "U2"}},
+		{Name: "x", Count: 1, Users: []string{"U2"}},
+	}
+
+	assert.NoError(t, checkOwnReaction(reactions, "thumbsup", "U1"))
+
+	t.Run("removal is skipped when the user is not among the reactors", func(t *testing.T) {
+		err := checkOwnReaction(reactions, "x", "U1")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "you haven't reacted with :x: on this message")
+		assert.Contains(t, err.Error(), "only your own reactions can be removed")
+	})
+
+	t.Run("missing emoji", func(t *testing.T) {
+		err := checkOwnReaction(reactions, "rocket", "U1")
+		assert.EqualError(t, err, "you haven't reacted with :rocket: on this message, it has no :rocket: reaction at all")
+	})
+}
+
+func TestUnitAddReactions(t *testing.T) {
+	type call struct {
+		name string
+		item slack.ItemRef
+	}
+
+	t.Run("adds each reaction to the posted message", func(t *testing.T) {
+		var calls []call
+		add := func(_ context.Context, name string, item slack.ItemRef) error {
+			calls = append(calls, call{name: name, item: item})
+			return nil
+		}
+
+		err := addReactions(context.Background(), add, "C123", "1700000000.000100", []string{"thumbsup", "thumbsdown"})
+		require.NoError(t, err)
+		require.Len(t, calls, 2)
+		for i, emoji := range []string{"thumbsup", "thumbsdown"} {
+			assert.Equal(t, emoji, calls[i].name)
+			assert.Equal(t, "C123", calls[i].item.Channel)
+			assert.Equal(t, "1700000000.000100", calls[i].item.Timestamp)
+		}
+	})
+
+	t.Run("stops at the first failure", func(t *testing.T) {
+		var calls int
+		add := func(_ context.Context, name string, _ slack.ItemRef) error {
+			calls++
+			return fmt.Errorf("invalid_name")
+		}
+
+		err := addReactions(context.Background(), add, "C123", "1700000000.000100", []string{"nope", "thumbsup"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), ":nope:")
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestUnitPickReactionEmoji(t *testing.T) {
+	candidates := []string{"wave", "tada", "sparkles"}
+
+	t.Run("picks one of the listed emoji and applies it", func(t *testing.T) {
+		emoji, err := pickReactionEmoji("random:wave, :tada:,sparkles,", rand.IntN)
+		require.NoError(t, err)
+		assert.Contains(t, candidates, emoji)
+
+		var added []string
+		add := func(_ context.Context, name string, _ slack.ItemRef) error {
+			added = append(added, name)
+			return nil
+		}
+		require.NoError(t, addReactions(context.Background(), add, "C123", "1700000000.000100", []string{emoji}))
+		assert.Equal(t, []string{emoji}, added)
+	})
+
+	t.Run("pick chooses among the candidates in order", func(t *testing.T) {
+		var n int
+		emoji, err := pickReactionEmoji("random:wave,tada,sparkles", func(size int) int {
+			n = size
+			return 2
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, n)
+		assert.Equal(t, "sparkles", emoji)
+	})
+
+	t.Run("a single emoji is unchanged", func(t *testing.T) {
+		emoji, err := pickReactionEmoji("thumbsup", func(int) int {
+			t.Fatal("pick must not be called for a single emoji")
+			return 0
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "thumbsup", emoji)
+	})
+
+	t.Run("no candidates", func(t *testing.T) {
+		for _, emoji := range []string{"random:", "random: , ::"} {
+			_, err := pickReactionEmoji(emoji, rand.IntN)
+			assert.Error(t, err, emoji)
+		}
+	})
+}
+
+func TestUnitParseSinceToTs(t *testing.T) {
+	tests := []struct {
+		name    string
+		since   string
+		want    string
+		wantErr bool
+	}{
+		{name: "empty", since: "", want: ""},
+		{name: "slack ts", since: "1700000000.000100", want: "1700000000.000100"},
+		{name: "unix seconds", since: "1700000000", want: "1700000000.000000"},
+		{name: "rfc3339", since: "2023-11-14T22:13:20Z", want: "1700000000.000000"},
+		{name: "rfc3339 with offset and fraction", since: "2023-11-15T00:13:20.5+02:00", want: "1700000000.500000"},
+		{name: "invalid", since: "yesterday", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSinceToTs(tt.since)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestUnitFilterMessagesAfter(t *testing.T) {
+	msg := func(ts string) slack.Message {
+		return slack.Message{Msg: slack.Msg{Timestamp: ts}}
+	}
+	replies := []slack.Message{
+		msg("1700000000.000100"), // thread parent
+		msg("1700000500.000000"),
+		msg("1700001000.000000"),
+		msg("1700001000.000001"),
+		msg("1700002000.000000"),
+	}
+
+	got := filterMessagesAfter(replies, "1700001000.000000")
+
+	var ts []string
+	for _, m := range got {
+		ts = append(ts, m.Timestamp)
+	}
+	assert.Equal(t, []string{"1700001000.000001", "1700002000.000000"}, ts)
+}
+
+func TestUnitPostWithAutoJoin(t *testing.T) {
+	t.Run("not_in_channel joins and retries", func(t *testing.T) {
+		var posts, joins int
+		post := func() (string, string, error) {
+			posts++
+			if joins == 0 {
+				return "", "", slack.SlackErrorResponse{Err: "not_in_channel"}
+			}
+			return "C123", "1700000000.000100", nil
+		}
+		join := func() error {
+			joins++
+			return nil
+		}
+
+		channel, ts, joined, err := postWithAutoJoin(post, join)
+		require.NoError(t, err)
+		assert.True(t, joined)
+		assert.Equal(t, "C123", channel)
+		assert.Equal(t, "1700000000.000100", ts)
+		assert.Equal(t, 2, posts)
+		assert.Equal(t, 1, joins)
+	})
+
+	t.Run("without auto join the error is returned", func(t *testing.T) {
+		post := func() (string, string, error) {
+			return "", "", slack.SlackErrorResponse{Err: "not_in_channel"}
+		}
+
+		_, _, joined, err := postWithAutoJoin(post, nil)
+		require.Error(t, err)
+		assert.False(t, joined)
+	})
+
+	t.Run("other errors do not trigger a join", func(t *testing.T) {
+		var joins int
+		post := func() (string, string, error) {
+			return "", "", slack.SlackErrorResponse{Err: "channel_not_found"}
+		}
+		join := func() error {
+			joins++
+			return nil
+		}
+
+		_, _, joined, err := postWithAutoJoin(post, join)
+		require.Error(t, err)
+		assert.False(t, joined)
+		assert.Equal(t, 0, joins)
+	})
+
+	t.Run("join failure is reported", func(t *testing.T) {
+		post := func() (string, string, error) {
+			return "", "", slack.SlackErrorResponse{Err: "not_in_channel"}
+		}
+		join := func() error {
+			return slack.SlackErrorResponse{Err: "is_archived"}
+		}
+
+		_, _, joined, err := postWithAutoJoin(post, join)
+		require.Error(t, err)
+		assert.False(t, joined)
+		assert.Contains(t, err.Error(), "is_archived")
+	})
+}
+
+func TestUnitCheckMembership(t *testing.T) {
+	logger := zap.NewNop()
+	member := true
+	channels := map[string]provider.Channel{
+		"C1": {ID: "C1", Name: "#general", IsMember: &member},
+		"C2": {ID: "C2", Name: "#random"},
+		"D1": {ID: "D1", Name: "@alice", IsIM: true},
+	}
+
+	remembered := map[string]bool{}
+	remember := func(channel string, member bool) bool {
+		remembered[channel] = member
+		_, cached := channels[channel]
+		return cached
+	}
+
+	t.Run("non-member is rejected before posting", func(t *testing.T) {
+		var infos, posts int
+		info := func(ctx context.Context, channel string) (*slack.Channel, error) {
+			infos++
+			return &slack.Channel{}, nil
+		}
+		post := func() (string, string, error) {
+			posts++
+			return "C2", "1700000000.000100", nil
+		}
+
+		err := checkMembership(context.Background(), "C2", channels, info, remember, logger)
+		if err == nil {
+			_, _, _, err = postWithAutoJoin(post, nil)
+		}
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not a member of channel C2")
+		assert.Contains(t, err.Error(), "auto_join=true")
+		assert.Equal(t, 1, infos)
+		assert.Equal(t, 0, posts)
+		assert.Equal(t, map[string]bool{"C2": false}, remembered, "the looked up membership is recorded")
+	})
+
+	t.Run("cached membership skips conversations.info", func(t *testing.T) {
+		info := func(ctx context.Context, channel string) (*slack.Channel, error) {
+			t.Fatalf("unexpected conversations.info call for %s", channel)
+			return nil, nil
+		}
+		assert.NoError(t, checkMembership(context.Background(), "C1", channels, info, remember, logger))
+		assert.NoError(t, checkMembership(context.Background(), "D1", channels, info, remember, logger))
+	})
+
+	t.Run("fresh membership from conversations.info is accepted", func(t *testing.T) {
+		info := func(ctx context.Context, channel string) (*slack.Channel, error) {
+			return &slack.Channel{IsMember: true}, nil
+		}
+		assert.NoError(t, checkMembership(context.Background(), "C9", channels, info, remember, logger))
+		assert.True(t, remembered["C9"])
+	})
+
+	t.Run("failing conversations.info does not block the post", func(t *testing.T) {
+		info := func(ctx context.Context, channel string) (*slack.Channel, error) {
+			return nil, errors.New("ratelimited")
+		}
+		delete(remembered, "C2")
+		assert.NoError(t, checkMembership(context.Background(), "C2", channels, info, remember, logger))
+		assert.NotContains(t, remembered, "C2", "a failed lookup records nothing")
+	})
+}
+
+func TestUnitExportHistory(t *testing.T) {
+	// Three pages of history, newest first as Slack returns them
+	pages := map[string]struct {
+		msgs []slack.Message
+		next string
+	}{
+		"":   {msgs: []slack.Message{{Msg: slack.Msg{Timestamp: "6.0"}}, {Msg: slack.Msg{Timestamp: "5.0"}}}, next: "p2"},
+		"p2": {msgs: []slack.Message{{Msg: slack.Msg{Timestamp: "4.0"}}, {Msg: slack.Msg{Timestamp: "3.0"}}}, next: "p3"},
+		"p3": {msgs: []slack.Message{{Msg: slack.Msg{Timestamp: "2.0"}}, {Msg: slack.Msg{Timestamp: "1.0"}}}},
+	}
+	var cursors []string
+	var limits []int
+	fetch := func(ctx context.Context, cursor string, limit int) ([]slack.Message, string, error) {
+		cursors = append(cursors, cursor)
+		limits = append(limits, limit)
+		p := pages[cursor]
+		msgs := p.msgs
+		if len(msgs) > limit {
+			msgs = msgs[:limit]
+		}
+		return msgs, p.next, nil
+	}
+	timestamps := func(msgs []slack.Message) []string {
+		var out []string
+		for _, m := range msgs {
+			out = append(out, m.Timestamp)
+		}
+		return out
+	}
+
+	msgs, complete, err := exportHistory(context.Background(), 100, fetch)
+	require.NoError(t, err)
+	assert.True(t, complete)
+	assert.Equal(t, []string{"", "p2", "p3"}, cursors, "every page is followed")
+	assert.Equal(t, []string{"1.0", "2.0", "3.0", "4.0", "5.0", "6.0"}, timestamps(msgs), "oldest first")
+
+	t.Run("cap stops the walk", func(t *testing.T) {
+		cursors, limits = nil, nil
+		msgs, complete, err := exportHistory(context.Background(), 3, fetch)
+		require.NoError(t, err)
+		assert.False(t, complete)
+		assert.Equal(t, []string{"", "p2"}, cursors)
+		assert.Equal(t, []int{3, 1}, limits, "the last page only asks for what is left")
+		assert.Equal(t, []string{"4.0", "5.0", "6.0"}, timestamps(msgs), "the newest messages are kept")
+	})
+
+	t.Run("cap reached on the last page is complete", func(t *testing.T) {
+		msgs, complete, err := exportHistory(context.Background(), 6, fetch)
+		require.NoError(t, err)
+		assert.True(t, complete)
+		assert.Len(t, msgs, 6)
+	})
+
+	t.Run("fetch errors are returned", func(t *testing.T) {
+		_, _, err := exportHistory(context.Background(), 100, func(ctx context.Context, cursor string, limit int) ([]slack.Message, string, error) {
+			return nil, "", errors.New("channel_not_found")
+		})
+		assert.EqualError(t, err, "channel_not_found")
+	})
+}
+
+func TestUnitExpandExportThreads(t *testing.T) {
+	logger := zap.NewNop()
+	messages := []slack.Message{
+		{Msg: slack.Msg{Timestamp: "1.0", ThreadTimestamp: "1.0", ReplyCount: 2}},
+		{Msg: slack.Msg{Timestamp: "2.0"}},
+		{Msg: slack.Msg{Timestamp: "3.0", ThreadTimestamp: "3.0", ReplyCount: 1}},
+	}
+	threads := map[string][]slack.Message{
+		"1.0": {{Msg: slack.Msg{Timestamp: "1.0"}}, {Msg: slack.Msg{Timestamp: "1.1"}}, {Msg: slack.Msg{Timestamp: "1.2"}}},
+		"3.0": {{Msg: slack.Msg{Timestamp: "3.0"}}, {Msg: slack.Msg{Timestamp: "3.1"}}},
+	}
+	fetch := func(ctx context.Context, threadTs string, maxMessages int) ([]slack.Message, string, error) {
+		replies := threads[threadTs]
+		if len(replies) > maxMessages {
+			return replies[:maxMessages], "more", nil
+		}
+		return replies, "", nil
+	}
+	timestamps := func(msgs []slack.Message) []string {
+		var out []string
+		for _, m := range msgs {
+			out = append(out, m.Timestamp)
+		}
+		return out
+	}
+
+	out, complete, failed := expandExportThreads(context.Background(), messages, 100, fetch, logger)
+	assert.True(t, complete)
+	assert.Equal(t, 0, failed)
+	assert.Equal(t, []string{"1.0", "1.1", "1.2", "2.0", "3.0", "3.1"}, timestamps(out), "replies follow their parent")
+
+	t.Run("cap is enforced across threads", func(t *testing.T) {
+		out, complete, _ := expandExportThreads(context.Background(), messages, 4, fetch, logger)
+		assert.False(t, complete)
+		assert.Equal(t, []string{"1.0", "1.1", "2.0", "3.0"}, timestamps(out))
+	})
+
+	t.Run("failed threads keep their parent", func(t *testing.T) {
+		out, complete, failed := expandExportThreads(context.Background(), messages, 100, func(ctx context.Context, threadTs string, maxMessages int) ([]slack.Message, string, error) {
+			if threadTs == "1.0" {
+				return nil, "", errors.New("ratelimited")
+			}
+			return fetch(ctx, threadTs, maxMessages)
+		}, logger)
+		assert.True(t, complete)
+		assert.Equal(t, 1, failed)
+		assert.Equal(t, []string{"1.0", "2.0", "3.0", "3.1"}, timestamps(out))
+	})
+}
+
+func TestUnitCheckExportToolEnabled(t *testing.T) {
+	assert.Error(t, checkExportToolEnabled("", ""))
+	assert.Error(t, checkExportToolEnabled("false", ""))
+	assert.NoError(t, checkExportToolEnabled("true", ""))
+	assert.NoError(t, checkExportToolEnabled("", "conversations_history,conversations_export"))
+}
+
+func TestUnitWriteExportFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "exports")
+	path, err := writeExportFile(dir, "C1", "csv", "MsgID\n1.0\n", time.Date(2024, 3, 15, 12, 30, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "C1-20240315-123000.csv"), path)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "MsgID\n1.0\n", string(data))
+}
+
+func TestUnitCollectMessageLinks(t *testing.T) {
+	raw := []slack.Message{
+		{Msg: slack.Msg{Timestamp: "1700000300.000000", User: "U2", Text: "again <https://example.com/a|the doc>"}},
+		{Msg: slack.Msg{Timestamp: "1700000200.000000", User: "U1", Text: "no links here"}},
+		{Msg: slack.Msg{Timestamp: "1700000100.000000", User: "U1", Text: "<https://example.com/a|doc> and https://example.com/b"}},
+	}
+	messages := []Message{
+		{MsgID: "1700000300.000000", UserID: "U2", UserName: "bob", Channel: "C1", Time: "t3", Text: "again https://example.com/a - the doc"},
+		{MsgID: "1700000200.000000", UserID: "U1", UserName: "alice", Channel: "C1", Time: "t2", Text: "no links here"},
+		{MsgID: "1700000100.000000", UserID: "U1", UserName: "alice", Channel: "C1", Time: "t1", Text: "https://example.com/a - doc, and https://example.com/b"},
+	}
+
+	links := collectMessageLinks(raw, messages)
+
+	require.Len(t, links, 2)
+	assert.Equal(t, MessageLink{URL: "https://example.com/a", MsgID: "1700000300.000000", UserID: "U2", UserName: "bob", Channel: "C1", Time: "t3"}, links[0])
+	assert.Equal(t, MessageLink{URL: "https://example.com/b", MsgID: "1700000100.000000", UserID: "U1", UserName: "alice", Channel: "C1", Time: "t1"}, links[1])
+}
+
+func TestUnitCollectAuditEntries(t *testing.T) {
+	users := map[string]slack.User{
+		"U1": {ID: "U1", Name: "alice"},
+		"U9": {ID: "U9", Name: "moderator"},
+	}
+	slackMessages := []slack.Message{
+		{Msg: slack.Msg{Timestamp: "1700000400.000100", User: "U1", Text: "fixed typo",
+			Edited: &slack.Edited{User: "U1", Timestamp: "1700000500.000000"}}},
+		{Msg: slack.Msg{Timestamp: "1700000300.000100", User: "U2", Text: "untouched"}},
+		{Msg: slack.Msg{Timestamp: "1700000200.000100", SubType: "tombstone", Text: "This message was deleted."}},
+		{Msg: slack.Msg{Timestamp: "1700000100.000100", User: "U2", Text: "cleaned up",
+			Edited: &slack.Edited{User: "U9", Timestamp: "1700000600.000000"}}},
+	}
+	messages := []Message{
+		{MsgID: "1700000400.000100", UserID: "U1", UserName: "alice", Channel: "C1", Time: "t4", Text: "fixed typo"},
+		{MsgID: "1700000300.000100", UserID: "U2", UserName: "U2", Channel: "C1", Time: "t3", Text: "untouched"},
+		{MsgID: "1700000200.000100", Channel: "C1", Time: "t2", Text: "This message was deleted."},
+		{MsgID: "1700000100.000100", UserID: "U2", UserName: "U2", Channel: "C1", Time: "t1", Text: "cleaned up"},
+	}
+
+	entries := collectAuditEntries(slackMessages, messages, users, text.TimeFormat{})
+	assert.Equal(t, []AuditEntry{
+		{MsgID: "1700000400.000100", UserID: "U1", UserName: "alice", Channel: "C1", Time: "t4",
+			Action: "edited", EditedAt: "2023-11-14T22:21:40Z", EditedBy: "alice", Text: "fixed typo"},
+		{MsgID: "1700000200.000100", Channel: "C1", Time: "t2", Action: "deleted"},
+		{MsgID: "1700000100.000100", UserID: "U2", UserName: "U2", Channel: "C1", Time: "t1",
+			Action: "edited", EditedAt: "2023-11-14T22:23:20Z", EditedBy: "moderator", Text: "cleaned up"},
+	}, entries, "edited messages and tombstones are listed, untouched messages are not")
+}
+func TestUnitBotAppName(t *testing.T) {
+	var looked []string
+	lookup := func(botID string) string {
+		looked = append(looked, botID)
+		if botID == "B1" {
+			return "Deployer"
+		}
+		return ""
+	}
+	profiled := slack.Message{Msg: slack.Msg{BotID: "B1", BotProfile: &slack.BotProfile{Name: "From Profile"}}}
+
+	assert.Equal(t, "From Profile", botAppName(profiled, lookup))
+	assert.Equal(t, "Deployer", botAppName(slack.Message{Msg: slack.Msg{BotID: "B1"}}, lookup))
+	assert.Equal(t, "alerts", botAppName(slack.Message{Msg: slack.Msg{BotID: "B2", Username: "alerts"}}, lookup))
+	assert.Equal(t, []string{"B1", "B2"}, looked, "the bot profile is used without a lookup")
+
+	t.Setenv("SLACK_MCP_RESOLVE_BOTS", "")
+	assert.Empty(t, resolveBotName(slack.Message{Msg: slack.Msg{BotID: "B1"}}, lookup), "disabled by default")
+	t.Setenv("SLACK_MCP_RESOLVE_BOTS", "true")
+	assert.Equal(t, "From Profile", resolveBotName(profiled, lookup))
+	assert.Equal(t, "Deployer", resolveBotName(slack.Message{Msg: slack.Msg{BotID: "B1"}}, lookup))
+	assert.Empty(t, resolveBotName(slack.Message{Msg: slack.Msg{User: "U1"}}, lookup))
+	assert.Equal(t, []string{"B1", "B2", "B1"}, looked)
+}
+
+func TestUnitGroupBotMessages(t *testing.T) {
+	slackMessages := []slack.Message{
+		{Msg: slack.Msg{Timestamp: "1700000500.000100", BotID: "B1", SubType: "bot_message", Text: "deploy finished"}},
+		{Msg: slack.Msg{Timestamp: "1700000400.000100", User: "U1", Text: "nice"}},
+		{Msg: slack.Msg{Timestamp: "1700000300.000100", BotID: "B2", Text: "alert: disk full"}},
+		{Msg: slack.Msg{Timestamp: "1700000200.000100", BotID: "B1", SubType: "bot_message", Text: "deploy started"}},
+		{Msg: slack.Msg{Timestamp: "1700000100.000100", User: "U2", Text: "on it"}},
+	}
+	messages := make([]Message, 0, len(slackMessages))
+	for _, m := range slackMessages {
+		messages = append(messages, Message{MsgID: m.Timestamp, Channel: "C1", Text: m.Text})
+	}
+	apps := map[string]string{"B1": "Deployer", "B2": "PagerBot"}
+
+	rows := groupBotMessages(slackMessages, messages, func(msg slack.Message) string { return apps[msg.BotID] })
+	var got [][2]string
+	for _, r := range rows {
+		got = append(got, [2]string{r.App, r.Text})
+	}
+	assert.Equal(t, [][2]string{
+		{"Deployer", "deploy finished"},
+		{"Deployer", "deploy started"},
+		{"PagerBot", "alert: disk full"},
+	}, got, "only bot messages are returned, grouped by app")
+}
+
+func TestUnitThreadInfos(t *testing.T) {
+	history := []slack.Message{
+		{Msg: slack.Msg{Timestamp: "5.1", User: "U1", Text: "no replies"}},
+		{Msg: slack.Msg{Timestamp: "4.1", ThreadTimestamp: "2.1", SubType: "thread_broadcast", User: "U2", Text: "also sent to channel"}},
+		{Msg: slack.Msg{Timestamp: "3.1", ThreadTimestamp: "3.1", User: "U1", Text: "deploy failed", ReplyCount: 4, ReplyUsers: []string{"U2", "U3", "U1"}, LatestReply: "1700000000.000100"}},
+		{Msg: slack.Msg{Timestamp: "2.1", ThreadTimestamp: "2.1", SubType: "file_share", User: "U2", Text: "logs", ReplyCount: 1, ReplyUsers: []string{"U2"}, LatestReply: "4.1"}},
+	}
+
+	roots := threadRoots(history)
+	require.Len(t, roots, 2, "only thread parents with replies are kept")
+
+	messages := []Message{
+		{MsgID: "3.1", UserID: "U1", UserName: "alice", Channel: "C1", Time: "t3", Text: "deploy failed"},
+		{MsgID: "2.1", UserID: "U2", UserName: "bob", Channel: "C1", Time: "t2", Text: "logs"},
+	}
+	rows := threadInfos(roots, messages, text.TimeFormat{}, zap.NewNop())
+	assert.Equal(t, []ThreadInfo{
+		{MsgID: "3.1", UserID: "U1", UserName: "alice", Channel: "C1", Time: "t3", ThreadTs: "3.1", ReplyCount: 4, LastReply: "2023-11-14T22:13:20Z", ParticipantCount: 3, Text: "deploy failed"},
+		{MsgID: "2.1", UserID: "U2", UserName: "bob", Channel: "C1", Time: "t2", ThreadTs: "2.1", ReplyCount: 1, LastReply: "1970-01-01T00:00:04Z", ParticipantCount: 1, Text: "logs"},
+	}, rows)
+}
+
+func TestUnitChannelErrors(t *testing.T) {
+	fetch := func(channelID string) ([]Message, error) {
+		if channelID == "C2" {
+			return nil, errors.New("not_in_channel")
+		}
+		return []Message{{MsgID: "1700000000.000100", Channel: channelID}}, nil
+	}
+
+	var (
+		errs     channelErrors
+		messages []Message
+	)
+	for _, id := range []string{"C1", "C2"} {
+		msgs, err := fetch(id)
+		if err != nil {
+			errs.Add(id, err)
+			continue
+		}
+		messages = append(messages, msgs...)
+	}
+
+	result, err := marshalMessagesToCSV(messages)
+	require.NoError(t, err)
+	result = errs.AppendTo(result)
+
+	assert.Equal(t, 1, errs.Len())
+	require.Len(t, result.Content, 2)
+	csvText := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, csvText, "C1")
+	assert.NotContains(t, csvText, "C2")
+	summary := result.Content[1].(mcp.TextContent).Text
+	assert.Equal(t, "errors: 1 channel(s) could not be fully read, so their results are missing or incomplete: C2: not_in_channel", summary)
+
+	t.Run("a channel failing twice is reported once", func(t *testing.T) {
+		var errs channelErrors
+		errs.Add("C2", errors.New("ratelimited"))
+		errs.Add("C2", errors.New("ratelimited"))
+		errs.Add("C2", errors.New("not_in_channel"))
+		assert.Equal(t, 1, errs.Len())
+		assert.Equal(t, "errors: 1 channel(s) could not be fully read, so their results are missing or incomplete: C2: ratelimited, not_in_channel", errs.Summary())
+	})
+
+	t.Run("no errors leaves result untouched", func(t *testing.T) {
+		var none channelErrors
+		res := mcp.NewToolResultText("ok")
+		assert.Len(t, none.AppendTo(res).Content, 1)
+		assert.Empty(t, none.Summary())
+	})
+}
+
+func TestUnitLatestPerChannel(t *testing.T) {
+	history := map[string][]slack.Message{
+		"C1": {{Msg: slack.Msg{Timestamp: "1700000300.000100", User: "U1", Text: "deploy done"}}},
+		"C3": {{Msg: slack.Msg{Timestamp: "1700000100.000100", User: "U2", Text: "hello"}}},
+		"C4": nil,
+	}
+	var fetched []string
+	fetch := func(ctx context.Context, channel string) ([]slack.Message, error) {
+		fetched = append(fetched, channel)
+		if channel == "C2" {
+			return nil, errors.New("not_in_channel")
+		}
+		return history[channel], nil
+	}
+
+	var errs channelErrors
+	errs.Add("#missing", errors.New("channel not found"))
+	latest := latestPerChannel(context.Background(), []string{"C1", "C2", "C3", "C4"}, fetch, &errs, zap.NewNop())
+
+	assert.Equal(t, []string{"C1", "C2", "C3", "C4"}, fetched, "one history call per channel")
+	require.Len(t, latest, 3)
+	assert.Equal(t, "C1", latest[0].channel)
+	require.NotNil(t, latest[0].message)
+	assert.Equal(t, "1700000300.000100", latest[0].message.Timestamp)
+	assert.Equal(t, "C3", latest[1].channel)
+	require.NotNil(t, latest[1].message)
+	assert.Equal(t, "1700000100.000100", latest[1].message.Timestamp)
+	assert.Equal(t, "C4", latest[2].channel)
+	assert.Nil(t, latest[2].message, "an empty channel has no latest message")
+
+	assert.Equal(t, 2, errs.Len())
+	assert.Equal(t, "errors: 2 channel(s) could not be fully read, so their results are missing or incomplete: #missing: channel not found; C2: not_in_channel", errs.Summary())
+
+	t.Run("cancelled context reports the remaining channels", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		var errs channelErrors
+		latest := latestPerChannel(ctx, []string{"C1"}, fetch, &errs, zap.NewNop())
+		assert.Empty(t, latest)
+		assert.Equal(t, 1, errs.Len())
+	})
+}
+
+func TestUnitCheckMembershipToolEnabled(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       string
+		enabledTools string
+		wantErr      bool
+	}{
+		{name: "disabled by default", wantErr: true},
+		{name: "enabled with true", config: "true"},
+		{name: "enabled with 1", config: "1"},
+		{name: "disabled with other value", config: "false", wantErr: true},
+		{name: "enabled via enabled tools", enabledTools: "conversations_history,conversations_close"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkMembershipToolEnabled(tt.config, tt.enabledTools)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestUnitIsClosableConversation(t *testing.T) {
+	tests := []struct {
+		name    string
+		channel string
+		cached  provider.Channel
+		found   bool
+		want    bool
+	}{
+		{name: "cached dm", channel: "D123", cached: provider.Channel{ID: "D123", IsIM: true}, found: true, want: true},
+		{name: "cached group dm", channel: "C456", cached: provider.Channel{ID: "C456", IsMpIM: true}, found: true, want: true},
+		{name: "cached public channel", channel: "C789", cached: provider.Channel{ID: "C789"}, found: true, want: false},
+		{name: "cached private channel", channel: "G789", cached: provider.Channel{ID: "G789", IsPrivate: true}, found: true, want: false},
+		{name: "uncached dm id", channel: "D999", want: true},
+		{name: "uncached channel id", channel: "C999", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isClosableConversation(tt.channel, tt.cached, tt.found))
+		})
+	}
+}
+
+func TestUnitOrderMessages(t *testing.T) {
+	newestFirst := []Message{{MsgID: "3"}, {MsgID: "2"}, {MsgID: "1"}}
+	ids := func(msgs []Message) []string {
+		var out []string
+		for _, m := range msgs {
+			out = append(out, m.MsgID)
+		}
+		return out
+	}
+
+	t.Run("newest keeps slack order", func(t *testing.T) {
+		assert.Equal(t, []string{"3", "2", "1"}, ids(orderMessages(newestFirst, "newest")))
+	})
+
+	t.Run("oldest reverses without mutating input", func(t *testing.T) {
+		assert.Equal(t, []string{"1", "2", "3"}, ids(orderMessages(newestFirst, "oldest")))
+		assert.Equal(t, []string{"3", "2", "1"}, ids(newestFirst))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		assert.Empty(t, orderMessages(nil, "oldest"))
+	})
+}
+
+func TestUnitFilterMessagesWithLinks(t *testing.T) {
+	messages := []slack.Message{
+		{Msg: slack.Msg{Timestamp: "1", Text: "no links here"}},
+		{Msg: slack.Msg{Timestamp: "2", Text: "see https://example.com/doc"}},
+		{Msg: slack.Msg{Timestamp: "3", Text: "<https://example.com/spec|the spec>"}},
+		{Msg: slack.Msg{Timestamp: "4", Text: "ftp or example.com without a scheme"}},
+		{Msg: slack.Msg{Timestamp: "5", Text: "[docs](https://docs.example.com)"}},
+	}
+
+	filtered := filterMessagesWithLinks(messages)
+
+	var ts []string
+	for _, m := range filtered {
+		ts = append(ts, m.Timestamp)
+	}
+	assert.Equal(t, []string{"2", "3", "5"}, ts)
+	assert.Empty(t, filterMessagesWithLinks(messages[:1]))
+}
+
+func TestUnitFilterMessagesByAuthors(t *testing.T) {
+	users := &provider.UsersCache{
+		Users: map[string]slack.User{
+			"U1": {ID: "U1", Name: "alice"},
+			"U2": {ID: "U2", Name: "bob"},
+			"U3": {ID: "U3", Name: "carol"},
+		},
+		UsersInv: map[string]string{"alice": "U1", "bob": "U2", "carol": "U3"},
+	}
+	messages := []slack.Message{
+		{Msg: slack.Msg{Timestamp: "1", User: "U1", Text: "can you review?"}},
+		{Msg: slack.Msg{Timestamp: "2", User: "U3", Text: "unrelated"}},
+		{Msg: slack.Msg{Timestamp: "3", User: "U2", Text: "on it"}},
+		{Msg: slack.Msg{Timestamp: "4", BotID: "B1", Text: "deploy done"}},
+		{Msg: slack.Msg{Timestamp: "5", User: "U1", Text: "thanks"}},
+	}
+
+	ids, err := resolveUserList(" @alice, U2 ,alice,", users)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"U1", "U2"}, ids)
+
+	var ts []string
+	for _, m := range filterMessagesByAuthors(messages, ids) {
+		ts = append(ts, m.Timestamp)
+	}
+	assert.Equal(t, []string{"1", "3", "5"}, ts, "only messages from alice and bob survive")
+
+	t.Run("unknown users are an error", func(t *testing.T) {
+		_, err := resolveUserList("@alice,@mallory,U9", users)
+		assert.EqualError(t, err, "unknown users: @mallory, U9")
+	})
+
+	t.Run("empty list means no filter", func(t *testing.T) {
+		ids, err := resolveUserList("", users)
+		require.NoError(t, err)
+		assert.Empty(t, ids)
+	})
+}
+
+func TestUnitConvertMessagesFromHistoryMalformedTs(t *testing.T) {
+	// Demo credentials give a provider with empty caches and no Slack client
+	t.Setenv("SLACK_MCP_XOXP_TOKEN", "demo")
+	t.Setenv("SLACK_MCP_USERS_CACHE", filepath.Join(t.TempDir(), "users_cache.json"))
+	t.Setenv("SLACK_MCP_CHANNELS_CACHE", filepath.Join(t.TempDir(), "channels_cache.json"))
+	t.Setenv("SLACK_MCP_DATE_FORMAT", "")
+	t.Setenv("SLACK_MCP_TIMEZONE", "")
+	logger := zap.NewNop()
+	ch := NewConversationsHandler(provider.New("stdio", logger), logger)
+
+	messages := ch.convertMessagesFromHistory(context.Background(), []slack.Message{
+		{Msg: slack.Msg{Timestamp: "not-a-ts", User: "U1", Text: "kept anyway"}},
+		{Msg: slack.Msg{Timestamp: "1700000000.000100", User: "U1", Text: "well formed"}},
+	}, "C1", false)
+
+	require.Len(t, messages, 2, "a malformed ts must not drop the message")
+	assert.Equal(t, "not-a-ts", messages[0].MsgID)
+	assert.Empty(t, messages[0].Time)
+	assert.Equal(t, "kept anyway", messages[0].Text)
+	assert.Equal(t, "C1", messages[0].Channel)
+	assert.Equal(t, "2023-11-14T22:13:20Z", messages[1].Time)
+}
+
+func TestUnitDailyAnchors(t *testing.T) {
+	// Newest first, as conversations.history returns them, over three UTC days
+	messages := []Message{
+		{MsgID: "1735866000.000100", Text: "Jan 3 01:00"},
+		{MsgID: "1735858800.000100", Text: "Jan 2 23:00"},
+		{MsgID: "1735822800.000100", Text: "Jan 2 13:00"},
+		{MsgID: "1735779600.000100", Text: "Jan 2 01:00"},
+		{MsgID: "1735740000.000100", Text: "Jan 1 14:00"},
+		{MsgID: "1735691400.000100", Text: "Jan 1 00:30"},
+	}
+	anchorIDs := func(tf text.TimeFormat) []string {
+		var ids []string
+		for _, m := range dailyAnchors(messages, tf) {
+			ids = append(ids, m.MsgID)
+		}
+		return ids
+	}
+
+	assert.Equal(t,
+		[]string{"1735866000.000100", "1735779600.000100", "1735691400.000100"},
+		anchorIDs(text.TimeFormat{}),
+		"one anchor per UTC day, the earliest message of each, in the original order")
+
+	// Five hours behind UTC the same messages span Dec 31 to Jan 2
+	assert.Equal(t,
+		[]string{"1735822800.000100", "1735740000.000100", "1735691400.000100"},
+		anchorIDs(text.TimeFormat{Location: time.FixedZone("UTC-5", -5*60*60)}),
+		"days follow the configured time zone")
+
+	assert.Empty(t, dailyAnchors(nil, text.TimeFormat{}))
+}
+
+func TestUnitFormatTranscript(t *testing.T) {
+	messages := []Message{
+		{MsgID: "1", UserID: "U1", UserName: "alice", Text: "Deploy is done", Time: "2025-01-02T15:04:05Z"},
+		{MsgID: "2", UserID: "U2", UserName: "bob", Text: "Thanks!\nChecking  now", Time: "2025-01-02T15:05:00Z"},
+		{MsgID: "3", BotName: "Deploy Bot", Text: "build #42 passed", Time: "2025-01-02T15:06:00Z"},
+		{MsgID: "4", UserID: "U9", Text: "hi", Time: "2025-01-02T15:07:00Z", Cursor: "next"},
+	}
+
+	want := "[2025-01-02T15:04:05Z] @alice: Deploy is done\n" +
+		"[2025-01-02T15:05:00Z] @bob: Thanks! Checking now\n" +
+		"[2025-01-02T15:06:00Z] @Deploy Bot: build #42 passed\n" +
+		"[2025-01-02T15:07:00Z] @U9: hi\n"
+	assert.Equal(t, want, formatTranscript(messages))
+
+	t.Run("cursor is returned separately", func(t *testing.T) {
+		result, err := marshalMessages(messages, "transcript")
+		require.NoError(t, err)
+		require.Len(t, result.Content, 2)
+		assert.Equal(t, want, result.Content[0].(mcp.TextContent).Text)
+		assert.Equal(t, "next_cursor: next", result.Content[1].(mcp.TextContent).Text)
+	})
+
+	t.Run("csv stays the default", func(t *testing.T) {
+		result, err := marshalMessages(messages[:1], "csv")
+		require.NoError(t, err)
+		require.Len(t, result.Content, 1)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "MsgID,UserID")
+	})
+}
+
+func TestUnitFilterMatchesToMyChannels(t *testing.T) {
+	match := func(channelID, ts string) slack.SearchMessage {
+		return slack.SearchMessage{Timestamp: ts, Channel: slack.CtxChannel{ID: channelID}}
+	}
+	member, nonMember := true, false
+	channels := map[string]provider.Channel{
+		"C1": {ID: "C1", Name: "#general", IsMember: &member},
+		"C2": {ID: "C2", Name: "#random", IsMember: &nonMember},
+		"C3": {ID: "C3", Name: "#from-an-old-cache"},
+		"D1": {ID: "D1", Name: "@alice", IsIM: true},
+	}
+	logger := zap.NewNop()
+
+	t.Run("cached membership decides, unknown membership is looked up", func(t *testing.T) {
+		var lookups []string
+		info := func(ctx context.Context, channel string) (*slack.Channel, error) {
+			lookups = append(lookups, channel)
+			if channel == "C9" {
+				return nil, errors.New("channel_not_found")
+			}
+			return &slack.Channel{IsMember: channel == "C3"}, nil
+		}
+		kept, omitted := filterMatchesToMyChannels(context.Background(), []slack.SearchMessage{
+			match("C1", "1.1"),
+			match("C2", "2.1"),
+			match("C3", "3.1"),
+			match("C3", "3.2"),
+			match("D1", "4.1"),
+			match("C9", "5.1"),
+		}, channels, info, logger)
+
+		var ts []string
+		for _, m := range kept {
+			ts = append(ts, m.Timestamp)
+		}
+		assert.Equal(t, []string{"1.1", "3.1", "3.2", "4.1"}, ts)
+		assert.Equal(t, 2, omitted, "non-member channels and failed lookups are omitted")
+		assert.Equal(t, []string{"C3", "C9"}, lookups, "each unknown channel is looked up once")
+	})
+
+	t.Run("no matches", func(t *testing.T) {
+		kept, omitted := filterMatchesToMyChannels(context.Background(), nil, channels, nil, logger)
+		assert.Empty(t, kept)
+		assert.Equal(t, 0, omitted)
+	})
+}
+
+func TestUnitMyDMsSortedWithPreviews(t *testing.T) {
+	im := func(id, user string) slack.Channel {
+		c := slack.Channel{}
+		c.ID = id
+		c.IsIM = true
+		c.User = user
+		return c
+	}
+	mpim := slack.Channel{}
+	mpim.ID = "G1"
+	mpim.IsMpIM = true
+	mpim.Members = []string{"U1", "U3"}
+
+	users := map[string]slack.User{
+		"U1": {ID: "U1", Name: "alice", RealName: "Alice A"},
+		"U2": {ID: "U2", Name: "bob", RealName: "Bob B"},
+	}
+
+	dms := []MyDM{
+		newMyDM(im("D1", "U1"), dmParticipants(im("D1", "U1"), provider.Channel{}, false, users),
+			&Message{MsgID: "1700000000.000100", UserName: "alice", Time: "2023-11-14T22:13:20Z", Text: "older"}),
+		newMyDM(im("D2", "U2"), dmParticipants(im("D2", "U2"), provider.Channel{}, false, users), nil),
+		newMyDM(mpim, dmParticipants(mpim, provider.Channel{}, false, users),
+			&Message{MsgID: "1700000500.000200", UserName: "bob", Time: "2023-11-14T22:21:40Z", Text: strings.Repeat("x", 250)}),
+		newMyDM(im("D3", "U9"), dmParticipants(im("D3", "U9"), provider.Channel{}, false, users),
+			&Message{MsgID: "1700000000.000900", UserName: "U9", Text: "multi\nline   text"}),
+	}
+	sortDMsByLatest(dms)
+
+	var order []string
+	for _, dm := range dms {
+		order = append(order, dm.ChannelID)
+	}
+	assert.Equal(t, []string{"G1", "D3", "D1", "D2"}, order, "newest first, DMs without messages last")
+
+	assert.Equal(t, "group_dm", dms[0].ChannelType)
+	assert.Equal(t, "Alice A, U3", dms[0].Participants)
+	assert.Equal(t, strings.Repeat("x", 200)+"...", dms[0].Preview)
+	assert.Equal(t, "bob", dms[0].LatestUser)
+
+	assert.Equal(t, "dm", dms[1].ChannelType)
+	assert.Equal(t, "U9", dms[1].Participants, "unknown users fall back to the ID")
+	assert.Equal(t, "multi line text", dms[1].Preview)
+
+	assert.Equal(t, "@alice (Alice A)", dms[2].Participants)
+	assert.Equal(t, "older", dms[2].Preview)
+	assert.Equal(t, "1700000000.000100", dms[2].LatestTs)
+
+	assert.Equal(t, "@bob (Bob B)", dms[3].Participants)
+	assert.Empty(t, dms[3].Preview)
+}
+
+func TestUnitOrderDMsByActivity(t *testing.T) {
+	dm := func(id string) slack.Channel {
+		c := slack.Channel{}
+		c.ID = id
+		return c
+	}
+	dms := []slack.Channel{dm("D1"), dm("D2"), dm("D3"), dm("D4"), dm("D5")}
+	latest := map[string]time.Time{
+		"D2": time.Unix(1700000100, 0),
+		"D4": time.Unix(1700000500, 0),
+		"D5": time.Unix(1700000300, 0),
+	}
+	orderDMsByActivity(dms, latest)
+
+	var order []string
+	for _, c := range dms {
+		order = append(order, c.ID)
+	}
+	assert.Equal(t, []string{"D4", "D5", "D2", "D1", "D3"}, order, "most recent first, DMs without activity keep their order at the end")
+}
+
+func TestUnitDMParticipantsFromCache(t *testing.T) {
+	mpim := slack.Channel{}
+	mpim.ID = "G1"
+	mpim.IsMpIM = true
+	cached := provider.Channel{ID: "G1", Name: "@mpdm-alice--bob-1", Purpose: "Group DM with Alice A, Bob B", IsMpIM: true}
+
+	assert.Equal(t, "Alice A, Bob B", dmParticipants(mpim, cached, true, nil))
+
+	im := slack.Channel{}
+	im.ID = "D1"
+	im.IsIM = true
+	users := map[string]slack.User{"U1": {ID: "U1", Name: "alice", RealName: "Alice A"}}
+	assert.Equal(t, "@alice (Alice A)", dmParticipants(im, provider.Channel{ID: "D1", User: "U1"}, true, users))
+}
+
+func TestUnitConversationParticipants(t *testing.T) {
+	users := map[string]slack.User{
+		"U1": {ID: "U1", Name: "alice", RealName: "Alice A"},
+		"U2": {ID: "U2", Name: "bob", RealName: "Bob B"},
+	}
+	mpim := &slack.Channel{}
+	mpim.ID = "G1"
+	mpim.IsMpIM = true
+
+	t.Run("mpim members are resolved to handles", func(t *testing.T) {
+		var listed string
+		ids, err := conversationParticipants(context.Background(), "G1", mpim, provider.Channel{}, false,
+			func(ctx context.Context, id string) ([]string, error) {
+				listed = id
+				return []string{"U1", "U2", "U3"}, nil
+			})
+		require.NoError(t, err)
+		assert.Equal(t, "G1", listed)
+		assert.Equal(t, []Participant{
+			{UserID: "U1", UserName: "alice", RealName: "Alice A"},
+			{UserID: "U2", UserName: "bob", RealName: "Bob B"},
+			{UserID: "U3"},
+		}, resolveParticipants(ids, users))
+	})
+
+	t.Run("mpim falls back to cached members", func(t *testing.T) {
+		cached := provider.Channel{ID: "G1", IsMpIM: true, Members: []string{"U1", "U2"}}
+		ids, err := conversationParticipants(context.Background(), "G1", nil, cached, true,
+			func(ctx context.Context, id string) ([]string, error) {
+				return nil, errors.New("missing_scope")
+			})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"U1", "U2"}, ids)
+	})
+
+	t.Run("im uses the other user", func(t *testing.T) {
+		im := &slack.Channel{}
+		im.ID = "D1"
+		im.IsIM = true
+		im.User = "U2"
+		ids, err := conversationParticipants(context.Background(), "D1", im, provider.Channel{}, false, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"U2"}, ids)
+	})
+
+	t.Run("unknown channel", func(t *testing.T) {
+		_, err := conversationParticipants(context.Background(), "D9", nil, provider.Channel{}, false, nil)
+		assert.EqualError(t, err, `channel "D9" not found: it is not cached and conversations.info failed`)
+	})
+
+	t.Run("regular channel is rejected", func(t *testing.T) {
+		public := &slack.Channel{}
+		public.ID = "C1"
+		_, err := conversationParticipants(context.Background(), "C1", public, provider.Channel{}, false, nil)
+		assert.EqualError(t, err, `channel "C1" is not a DM or group DM`)
+	})
+}
+
+func TestUnitFormatReactions(t *testing.T) {
+	users := map[string]slack.User{
+		"U1": {ID: "U1", Name: "alice"},
+		"U2": {ID: "U2", Name: "bob"},
+	}
+	reactions := []slack.ItemReaction{
+		{Name: "thumbsup", Count: 2, Users: []string{"U1", "U2"}},
+		{Name: "eyes", Count: 1, Users: []string{"U9"}},
+		{Name: "tada", Count: 3},
+	}
+
+	tests := []struct {
+		name         string
+		includeUsers bool
+		expected     string
+	}{
+		{"counts only", false, "thumbsup:2|eyes:1|tada:3"},
+		{"with users", true, "thumbsup:2[@alice,@bob]|eyes:1[U9]|tada:3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, formatReactions(reactions, users, tt.includeUsers))
+		})
+	}
+
+	t.Run("no reactions", func(t *testing.T) {
+		assert.Equal(t, "", formatReactions(nil, users, true))
+	})
+}
+
+func TestUnitWithReactionUsers(t *testing.T) {
+	users := map[string]slack.User{"U1": {ID: "U1", Name: "manager"}}
+	slackMessages := []slack.Message{
+		{Msg: slack.Msg{Timestamp: "1.1", Reactions: []slack.ItemReaction{{Name: "white_check_mark", Count: 1, Users: []string{"U1"}}}}},
+		{Msg: slack.Msg{Timestamp: "2.1"}},
+	}
+	messages := []Message{
+		{MsgID: "1.1", Reactions: "white_check_mark:1"},
+		{MsgID: "2.1"},
+	}
+
+	got := withReactionUsers(messages, slackMessages, users)
+	assert.Equal(t, "white_check_mark:1[@manager]", got[0].Reactions)
+	assert.Equal(t, "", got[1].Reactions)
+}
+
+func TestUnitWithClientMsgIDs(t *testing.T) {
+	slackMessages := []slack.Message{
+		{Msg: slack.Msg{Timestamp: "1.1", User: "U1", ClientMsgID: "6f1c9c4e-0b6a-4b8e-9d0e-3f2a1b4c5d6e"}},
+		{Msg: slack.Msg{Timestamp: "2.1", SubType: "bot_message", BotID: "B1"}},
+	}
+	messages := []Message{
+		{MsgID: "1.1", UserID: "U1"},
+		{MsgID: "2.1", BotName: "deploy"},
+	}
+
+	got := withClientMsgIDs(messages, slackMessages)
+	assert.Equal(t, "6f1c9c4e-0b6a-4b8e-9d0e-3f2a1b4c5d6e", got[0].ClientMsgID, "user messages carry client_msg_id")
+	assert.Equal(t, "", got[1].ClientMsgID, "bot messages have no client_msg_id")
+
+	result, err := marshalMessagesToCSV(got, colClientMsgID)
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "ClientMsgID")
+
+	t.Run("column is absent when the option is off", func(t *testing.T) {
+		result, err := marshalMessagesToCSV(got)
+		require.NoError(t, err)
+		csvText := result.Content[0].(mcp.TextContent).Text
+		assert.NotContains(t, csvText, "ClientMsgID")
+		assert.NotContains(t, csvText, "6f1c9c4e")
+		assert.True(t, strings.HasPrefix(csvText, "MsgID,UserID,"), csvText)
+	})
+}
+
+func TestUnitWithSubtypes(t *testing.T) {
+	slackMessages := []slack.Message{
+		{Msg: slack.Msg{Timestamp: "1.1", User: "U1"}},
+		{Msg: slack.Msg{Timestamp: "2.1", SubType: "channel_join", User: "U2"}},
+		{Msg: slack.Msg{Timestamp: "3.1", SubType: "file_share", User: "U1"}},
+		{Msg: slack.Msg{Timestamp: "4.1", SubType: "me_message", User: "U1"}},
+		{Msg: slack.Msg{Timestamp: "5.1", SubType: "bot_message", BotID: "B1"}},
+	}
+	messages := []Message{
+		{MsgID: "1.1", UserID: "U1"},
+		{MsgID: "2.1", UserID: "U2"},
+		{MsgID: "3.1", UserID: "U1"},
+		{MsgID: "4.1", UserID: "U1"},
+		{MsgID: "5.1", BotName: "deploy"},
+	}
+
+	got := withSubtypes(messages, slackMessages)
+	var subtypes []string
+	for _, m := range got {
+		subtypes = append(subtypes, m.Subtype)
+	}
+	assert.Equal(t, []string{"", "channel_join", "file_share", "me_message", "bot_message"}, subtypes)
+
+	result, err := marshalMessagesToCSV(got, (&conversationParams{subtype: true}).messageColumns()...)
+	require.NoError(t, err)
+	records, err := csv.NewReader(strings.NewReader(result.Content[0].(mcp.TextContent).Text)).ReadAll()
+	require.NoError(t, err)
+	col := slices.Index(records[0], "Subtype")
+	require.NotEqual(t, -1, col, "header has a Subtype column")
+	assert.Equal(t, "", records[1][col])
+	assert.Equal(t, "channel_join", records[2][col])
+	assert.Equal(t, "bot_message", records[5][col])
+
+	t.Run("column is absent without include_subtype", func(t *testing.T) {
+		result, err := marshalMessagesToCSV(got)
+		require.NoError(t, err)
+		records, err := csv.NewReader(strings.NewReader(result.Content[0].(mcp.TextContent).Text)).ReadAll()
+		require.NoError(t, err)
+		assert.Equal(t, -1, slices.Index(records[0], "Subtype"))
+	})
+}
+
+func TestUnitWithThreadSubscription(t *testing.T) {
+	replies := []slack.Message{
+		{Msg: slack.Msg{Timestamp: "1.1", ThreadTimestamp: "1.1", User: "U1", Subscribed: true}},
+		{Msg: slack.Msg{Timestamp: "2.1", ThreadTimestamp: "1.1", User: "U2"}},
+	}
+	messages := []Message{
+		{MsgID: "1.1", UserID: "U1", ThreadTs: "1.1"},
+		{MsgID: "2.1", UserID: "U2", ThreadTs: "1.1"},
+	}
+
+	got := withThreadSubscription(messages, replies, "1.1")
+	require.NotNil(t, got[0].Subscribed, "the root carries the subscription")
+	assert.True(t, *got[0].Subscribed)
+	assert.Nil(t, got[1].Subscribed, "replies leave the column empty")
+
+	result, err := marshalMessagesToCSV(got, colSubscribed)
+	require.NoError(t, err)
+	records, err := csv.NewReader(strings.NewReader(result.Content[0].(mcp.TextContent).Text)).ReadAll()
+	require.NoError(t, err)
+	col := slices.Index(records[0], "Subscribed")
+	require.NotEqual(t, -1, col, "header has a Subscribed column")
+	assert.Equal(t, []string{"true", ""}, []string{records[1][col], records[2][col]})
+
+	t.Run("not subscribed", func(t *testing.T) {
+		replies := []slack.Message{{Msg: slack.Msg{Timestamp: "1.1", ThreadTimestamp: "1.1"}}}
+		got := withThreadSubscription([]Message{{MsgID: "1.1"}}, replies, "1.1")
+		require.NotNil(t, got[0].Subscribed)
+		assert.False(t, *got[0].Subscribed)
+	})
+
+	t.Run("page without the root", func(t *testing.T) {
+		got := withThreadSubscription([]Message{{MsgID: "2.1"}}, replies[1:], "1.1")
+		assert.Nil(t, got[0].Subscribed)
+	})
+
+	t.Run("history has no Subscribed column", func(t *testing.T) {
+		result, err := marshalMessagesToCSV([]Message{{MsgID: "1.1"}}, (&conversationParams{}).messageColumns()...)
+		require.NoError(t, err)
+		records, err := csv.NewReader(strings.NewReader(result.Content[0].(mcp.TextContent).Text)).ReadAll()
+		require.NoError(t, err)
+		assert.Equal(t, -1, slices.Index(records[0], "Subscribed"))
+	})
+}
+
+func TestUnitWithAvatars(t *testing.T) {
+	users := map[string]slack.User{
+		"U1": {ID: "U1", Name: "alice", Profile: slack.UserProfile{Image72: "https://avatars.example.com/alice_72.png"}},
+	}
+	slackMessages := []slack.Message{
+		{Msg: slack.Msg{Timestamp: "1.1", User: "U1"}},
+		{Msg: slack.Msg{Timestamp: "2.1", SubType: "bot_message", BotID: "B1", BotProfile: &slack.BotProfile{Icons: &slack.Icons{Image72: "https://avatars.example.com/deploy_72.png"}}}},
+		{Msg: slack.Msg{Timestamp: "3.1", SubType: "bot_message", Icons: &slack.Icon{IconURL: "https://example.com/hook.png"}}},
+		{Msg: slack.Msg{Timestamp: "4.1", User: "U9"}},
+	}
+	messages := []Message{
+		{MsgID: "1.1", UserID: "U1"},
+		{MsgID: "2.1", BotName: "deploy"},
+		{MsgID: "3.1", BotName: "hook"},
+		{MsgID: "4.1", UserID: "U9"},
+	}
+
+	got := withAvatars(messages, slackMessages, users)
+	assert.Equal(t, "https://avatars.example.com/alice_72.png", got[0].AvatarURL, "cached user avatar")
+	assert.Equal(t, "https://avatars.example.com/deploy_72.png", got[1].AvatarURL, "bot profile icon")
+	assert.Equal(t, "https://example.com/hook.png", got[2].AvatarURL, "per-message icon")
+	assert.Equal(t, "", got[3].AvatarURL, "unknown users are left empty")
+
+	t.Run("search rows resolve from the cache only", func(t *testing.T) {
+		got := withAvatars([]Message{{MsgID: "5.1", UserID: "U1"}, {MsgID: "6.1", BotName: "deploy"}}, nil, users)
+		assert.Equal(t, "https://avatars.example.com/alice_72.png", got[0].AvatarURL)
+		assert.Equal(t, "", got[1].AvatarURL)
+	})
+
+	t.Run("column is only emitted with include_avatars", func(t *testing.T) {
+		result, err := marshalMessagesToCSV(got)
+		require.NoError(t, err)
+		assert.NotContains(t, result.Content[0].(mcp.TextContent).Text, "AvatarURL")
+
+		result, err = marshalMessagesToCSV(got, (&searchParams{avatars: true}).messageColumns()...)
+		require.NoError(t, err)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, ",AvatarURL,")
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "alice_72.png")
+	})
+}
+
+func TestUnitWithTeams(t *testing.T) {
+	users := map[string]slack.User{
+		"U1": {ID: "U1", Name: "alice", TeamID: "T1"},
+		"U2": {ID: "U2", Name: "partner", TeamID: "T2"},
+	}
+	slackMessages := []slack.Message{
+		{Msg: slack.Msg{Timestamp: "1.1", User: "U1", Team: "T1"}},
+		{Msg: slack.Msg{Timestamp: "2.1", User: "U2", Team: "T2"}},
+		{Msg: slack.Msg{Timestamp: "3.1", User: "U2"}},
+		{Msg: slack.Msg{Timestamp: "4.1", User: "U9"}},
+	}
+	messages := []Message{
+		{MsgID: "1.1", UserID: "U1"},
+		{MsgID: "2.1", UserID: "U2"},
+		{MsgID: "3.1", UserID: "U2"},
+		{MsgID: "4.1", UserID: "U9"},
+	}
+
+	got := withTeams(messages, slackMessages, users, "T1")
+	assert.Equal(t, "T1", got[0].Team)
+	assert.False(t, got[0].IsExternal, "internal author")
+	assert.Equal(t, "T2", got[1].Team)
+	assert.True(t, got[1].IsExternal, "partner author is flagged")
+	assert.Equal(t, "T2", got[2].Team, "team falls back to the users cache")
+	assert.True(t, got[2].IsExternal)
+	assert.Equal(t, "", got[3].Team)
+	assert.False(t, got[3].IsExternal, "unknown teams are not flagged")
+
+	result, err := marshalMessagesToCSV(got, (&conversationParams{teams: true}).messageColumns()...)
+	require.NoError(t, err)
+	header := strings.SplitN(result.Content[0].(mcp.TextContent).Text, "\n", 2)[0]
+	assert.Contains(t, header, ",Team,IsExternal,")
+
+	result, err = marshalMessagesToCSV(got)
+	require.NoError(t, err)
+	header = strings.SplitN(result.Content[0].(mcp.TextContent).Text, "\n", 2)[0]
+	assert.NotContains(t, header, "Team", "columns are absent without include_team")
+	assert.NotContains(t, header, "IsExternal")
+
+	t.Run("without home team nothing is flagged", func(t *testing.T) {
+		got := withTeams([]Message{{MsgID: "2.1", UserID: "U2"}}, slackMessages, users, "")
+		assert.Equal(t, "T2", got[0].Team)
+		assert.False(t, got[0].IsExternal)
+	})
+}
+
+func TestUnitSavedItemRef(t *testing.T) {
+	tests := []struct {
+		name      string
+		channel   string
+		timestamp string
+		fileID    string
+		expected  slack.ItemRef
+		wantErr   string
+	}{
+		{"message", "C1", "1700000000.000100", "", slack.ItemRef{Channel: "C1", Timestamp: "1700000000.000100"}, ""},
+		{"file", "", "", "F1", slack.ItemRef{File: "F1"}, ""},
+		{"nothing", "", "", "", slack.ItemRef{}, "either channel_id and timestamp, or file_id is required"},
+		{"channel without timestamp", "C1", "", "", slack.ItemRef{}, "timestamp is required with channel_id"},
+		{"timestamp without channel", "", "1700000000.000100", "", slack.ItemRef{}, "channel_id is required with timestamp"},
+		{"file with message", "C1", "1700000000.000100", "F1", slack.ItemRef{}, "file_id cannot be combined"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := savedItemRef(tt.channel, tt.timestamp, tt.fileID)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestUnitCheckSavedToolEnabled(t *testing.T) {
+	assert.NoError(t, checkSavedToolEnabled("true", ""))
+	assert.NoError(t, checkSavedToolEnabled("1", ""))
+	assert.NoError(t, checkSavedToolEnabled("", "saved_add,saved_list"))
+	assert.Error(t, checkSavedToolEnabled("", ""))
+	assert.Error(t, checkSavedToolEnabled("false", "saved_add"))
+}
+
+func TestUnitRejectBotToken(t *testing.T) {
+	assert.NoError(t, rejectBotToken("saved_list", false))
+
+	err := rejectBotToken("saved_list", true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "saved_list requires a user token")
+}
+
+func TestUnitParsePageCursor(t *testing.T) {
+	page, err := parsePageCursor("")
+	require.NoError(t, err)
+	assert.Equal(t, 1, page)
+
+	page, err = parsePageCursor(base64.StdEncoding.EncodeToString([]byte("page:3")))
+	require.NoError(t, err)
+	assert.Equal(t, 3, page)
+
+	_, err = parsePageCursor("not-base64!")
+	assert.Error(t, err)
+
+	_, err = parsePageCursor(base64.StdEncoding.EncodeToString([]byte("page:0")))
+	assert.Error(t, err)
+}
+
+func TestUnitConvertSavedItems(t *testing.T) {
+	users := map[string]slack.User{"U1": {ID: "U1", Name: "alice"}}
+	items := []slack.Item{
+		{Type: slack.TYPE_MESSAGE, Channel: "C1", Message: &slack.Message{Msg: slack.Msg{User: "U1", Timestamp: "1700000000.000100", Text: "remember this"}}},
+		{Type: slack.TYPE_FILE, File: &slack.File{ID: "F1", Name: "report.pdf"}},
+		{Type: slack.TYPE_CHANNEL, Channel: "C2"},
+	}
+
+	saved := convertSavedItems(items, users, text.TimeFormat{})
+	require.Len(t, saved, 2)
+	assert.Equal(t, "message", saved[0].Type)
+	assert.Equal(t, "C1", saved[0].Channel)
+	assert.Equal(t, "alice", saved[0].UserName)
+	assert.Equal(t, "remember this", saved[0].Text)
+	assert.Equal(t, "file", saved[1].Type)
+	assert.Equal(t, "F1", saved[1].FileID)
+	assert.Equal(t, "report.pdf", saved[1].FileName)
+}
+
+func TestUnitParseSearchSort(t *testing.T) {
+	tests := []struct {
+		name        string
+		sort        string
+		sortDir     string
+		wantSort    string
+		wantSortDir string
+		wantErr     string
+	}{
+		{name: "defaults", wantSort: "score", wantSortDir: "desc"},
+		{name: "newest first", sort: "timestamp", sortDir: "desc", wantSort: "timestamp", wantSortDir: "desc"},
+		{name: "oldest first", sort: " Timestamp ", sortDir: "ASC", wantSort: "timestamp", wantSortDir: "asc"},
+		{name: "direction only", sortDir: "asc", wantSort: "score", wantSortDir: "asc"},
+		{name: "unknown sort", sort: "date", wantErr: "invalid sort"},
+		{name: "unknown direction", sort: "timestamp", sortDir: "up", wantErr: "invalid sort_dir"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sortBy, sortDir, err := parseSearchSort(tt.sort, tt.sortDir)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSort, sortBy)
+			assert.Equal(t, tt.wantSortDir, sortDir)
+		})
+	}
+}
+
+func TestUnitSearchWindowSplit(t *testing.T) {
+	day := func(s string) time.Time {
+		d, err := time.Parse("2006-01-02", s)
+		require.NoError(t, err)
+		return d
+	}
+	// covered returns the days a window covers under Slack's exclusive after:/before: semantics
+	covered := func(w searchWindow) []string {
+		var days []string
+		for d := w.after.AddDate(0, 0, 1); d.Before(w.before); d = d.AddDate(0, 0, 1) {
+			days = append(days, d.Format("2006-01-02"))
+		}
+		return days
+	}
+
+	tests := []struct {
+		name   string
+		after  string
+		before string
+	}{
+		{"two days", "2024-01-01", "2024-01-04"},
+		{"three days", "2024-01-01", "2024-01-05"},
+		{"a month", "2024-01-01", "2024-02-01"},
+		{"across a year", "2023-12-15", "2024-01-20"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := searchWindow{after: day(tt.after), before: day(tt.before)}
+			newer, older, ok := w.split()
+			require.True(t, ok)
+
+			assert.NotEmpty(t, covered(newer))
+			assert.NotEmpty(t, covered(older))
+			assert.Equal(t, covered(w), append(covered(older), covered(newer)...), "halves must cover the window without gaps or overlap")
+		})
+	}
+
+	t.Run("single day cannot be split", func(t *testing.T) {
+		_, _, ok := searchWindow{after: day("2024-01-01"), before: day("2024-01-03")}.split()
+		assert.False(t, ok)
+	})
+}
+
+func TestUnitInitialSearchWindow(t *testing.T) {
+	now := time.Date(2024, 5, 10, 15, 0, 0, 0, time.UTC)
+
+	w, ok := initialSearchWindow(map[string][]string{}, now)
+	require.True(t, ok)
+	assert.Equal(t, searchEpoch, w.after)
+	assert.Equal(t, "2024-05-11", w.before.Format("2006-01-02"), "today is included")
+
+	w, ok = initialSearchWindow(map[string][]string{"after": {"2024-01-01"}, "before": {"2024-02-01"}}, now)
+	require.True(t, ok)
+	assert.Equal(t, "2024-01-01", w.after.Format("2006-01-02"))
+	assert.Equal(t, "2024-02-01", w.before.Format("2006-01-02"))
+
+	_, ok = initialSearchWindow(map[string][]string{"on": {"2024-01-01"}}, now)
+	assert.False(t, ok, "on: cannot be sliced")
+	_, ok = initialSearchWindow(map[string][]string{"during": {"january"}}, now)
+	assert.False(t, ok, "during: cannot be sliced")
+}
+
+func TestUnitDeepSearch(t *testing.T) {
+	match := func(channel, ts string) slack.SearchMessage {
+		return slack.SearchMessage{Timestamp: ts, Channel: slack.CtxChannel{ID: channel}}
+	}
+	window := searchWindow{
+		after:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		before: time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("splits window over page limit and dedups across slices", func(t *testing.T) {
+		var queries []string
+		fetch := func(ctx context.Context, query string, page int) (*slack.SearchMessages, error) {
+			queries = append(queries, fmt.Sprintf("%s#%d", query, page))
+			res := &slack.SearchMessages{}
+			switch {
+			case strings.Contains(query, "before:2024-01-06 after:2024-01-01"):
+				// too many pages for the whole window
+				res.Pagination = slack.Pagination{PageCount: searchPageLimit + 1}
+				res.Matches = []slack.SearchMessage{match("C1", "9.0")}
+			case strings.Contains(query, "before:2024-01-06 after:2024-01-03"):
+				res.Pagination = slack.Pagination{PageCount: 2}
+				if page == 1 {
+					res.Matches = []slack.SearchMessage{match("C1", "5.0"), match("C1", "4.0")}
+				} else {
+					res.Matches = []slack.SearchMessage{match("C1", "3.0")}
+				}
+			case strings.Contains(query, "before:2024-01-04 after:2024-01-01"):
+				// overlaps the newer slice by one match
+				res.Pagination = slack.Pagination{PageCount: 1}
+				res.Matches = []slack.SearchMessage{match("C1", "3.0"), match("C2", "2.0")}
+			}
+			return res, nil
+		}
+
+		results, capped, err := deepSearch(context.Background(), fetch, []string{"deploy"}, map[string][]string{"in": {"#ops"}}, window, true, 100)
+		require.NoError(t, err)
+		assert.False(t, capped)
+
+		var got []string
+		for _, m := range results {
+			got = append(got, m.Channel.ID+"/"+m.Timestamp)
+		}
+		assert.Equal(t, []string{"C1/5.0", "C1/4.0", "C1/3.0", "C2/2.0"}, got)
+		assert.Equal(t, []string{
+			"deploy in:#ops before:2024-01-06 after:2024-01-01#1",
+			"deploy in:#ops before:2024-01-06 after:2024-01-03#1",
+			"deploy in:#ops before:2024-01-06 after:2024-01-03#2",
+			"deploy in:#ops before:2024-01-04 after:2024-01-01#1",
+		}, queries, "newer half is searched first")
+	})
+
+	t.Run("stops at max results", func(t *testing.T) {
+		fetch := func(ctx context.Context, query string, page int) (*slack.SearchMessages, error) {
+			return &slack.SearchMessages{
+				Pagination: slack.Pagination{PageCount: 5},
+				Matches:    []slack.SearchMessage{match("C1", fmt.Sprintf("%d.1", page)), match("C1", fmt.Sprintf("%d.2", page))},
+			}, nil
+		}
+		results, capped, err := deepSearch(context.Background(), fetch, []string{"x"}, map[string][]string{}, window, false, 3)
+		require.NoError(t, err)
+		assert.True(t, capped)
+		assert.Len(t, results, 3)
+	})
+
+	t.Run("fetch errors are returned", func(t *testing.T) {
+		fetch := func(ctx context.Context, query string, page int) (*slack.SearchMessages, error) {
+			return nil, errors.New("ratelimited")
+		}
+		_, _, err := deepSearch(context.Background(), fetch, []string{"x"}, map[string][]string{}, window, true, 10)
+		assert.EqualError(t, err, "ratelimited")
+	})
+}
+
+func TestUnitResolveSearchChannelNames(t *testing.T) {
+	logger := zap.NewNop()
+	newMatches := func() []slack.SearchMessage {
+		return []slack.SearchMessage{
+			{Channel: slack.CtxChannel{ID: "C1", Name: "general"}},
+			{Channel: slack.CtxChannel{ID: "C2"}},
+			{Channel: slack.CtxChannel{ID: "C3"}},
+			{Channel: slack.CtxChannel{ID: "D1"}},
+		}
+	}
+
+	t.Run("missing IDs are resolved after a single refresh", func(t *testing.T) {
+		cache := map[string]provider.Channel{
+			"C2": {ID: "C2", Name: "#random"},
+		}
+		refreshes := 0
+		refresh := func(ctx context.Context) error {
+			refreshes++
+			cache = map[string]provider.Channel{
+				"C2": {ID: "C2", Name: "#random"},
+				"C3": {ID: "C3", Name: "#new-channel"},
+				"D1": {ID: "D1", Name: "@alice"},
+			}
+			return nil
+		}
+
+		matches := newMatches()
+		resolveSearchChannelNames(context.Background(), matches, func() map[string]provider.Channel { return cache }, refresh, logger)
+
+		assert.Equal(t, 1, refreshes)
+		assert.Equal(t, "general", matches[0].Channel.Name, "names from the payload are kept")
+		assert.Equal(t, "#random", matches[1].Channel.Name)
+		assert.Equal(t, "#new-channel", matches[2].Channel.Name)
+		assert.Equal(t, "@alice", matches[3].Channel.Name)
+
+		assert.Equal(t, "#general", searchChannelLabel(matches[0].Channel))
+		assert.Equal(t, "#new-channel", searchChannelLabel(matches[2].Channel))
+		assert.Equal(t, "@alice", searchChannelLabel(matches[3].Channel))
+		assert.Equal(t, "C9", searchChannelLabel(slack.CtxChannel{ID: "C9"}), "unresolved channels are labelled by ID")
+	})
+
+	t.Run("no refresh when every ID is cached", func(t *testing.T) {
+		cache := map[string]provider.Channel{
+			"C2": {ID: "C2", Name: "#random"},
+			"C3": {ID: "C3", Name: "#dev"},
+			"D1": {ID: "D1", Name: "@alice"},
+		}
+		refreshes := 0
+		refresh := func(ctx context.Context) error {
+			refreshes++
+			return nil
+		}
+
+		matches := newMatches()
+		resolveSearchChannelNames(context.Background(), matches, func() map[string]provider.Channel { return cache }, refresh, logger)
+
+		assert.Equal(t, 0, refreshes)
+		assert.Equal(t, "#dev", matches[2].Channel.Name)
+	})
+
+	t.Run("rate-limited refresh leaves unresolved names empty", func(t *testing.T) {
+		cache := map[string]provider.Channel{}
+		refresh := func(ctx context.Context) error {
+			return provider.ErrRefreshRateLimited
+		}
+
+		matches := newMatches()
+		resolveSearchChannelNames(context.Background(), matches, func() map[string]provider.Channel { return cache }, refresh, logger)
+
+		assert.Equal(t, "", matches[1].Channel.Name)
+	})
+
+	t.Run("without refresh only the cache is used", func(t *testing.T) {
+		cache := map[string]provider.Channel{"C2": {ID: "C2", Name: "#random"}}
+
+		matches := newMatches()
+		resolveSearchChannelNames(context.Background(), matches, func() map[string]provider.Channel { return cache }, nil, logger)
+
+		assert.Equal(t, "#random", matches[1].Channel.Name)
+		assert.Equal(t, "", matches[2].Channel.Name)
+	})
+}
+
+func TestUnitResolveChannelID(t *testing.T) {
+	logger := zap.NewNop()
+	stale := &provider.ChannelsCache{
+		Channels:    map[string]provider.Channel{"C1": {ID: "C1", Name: "#general"}},
+		ChannelsInv: map[string]string{"#general": "C1"},
+	}
+	fresh := &provider.ChannelsCache{
+		Channels: map[string]provider.Channel{
+			"C1": {ID: "C1", Name: "#general"},
+			"C2": {ID: "C2", Name: "#fresh"},
+			"D1": {ID: "D1", Name: "@alice"},
+		},
+		ChannelsInv: map[string]string{"#general": "C1", "#fresh": "C2", "@alice": "D1"},
+	}
+
+	tests := []struct {
+		name       string
+		channel    string
+		refreshErr error
+		want       string
+		wantErr    string
+		refreshes  int
+	}{
+		{name: "IDs are passed through", channel: "C9", want: "C9"},
+		{name: "cached name needs no refresh", channel: "#general", want: "C1"},
+		{name: "new channel is found after refresh", channel: "#fresh", want: "C2", refreshes: 1},
+		{name: "new DM is found after refresh", channel: "@alice", want: "D1", refreshes: 1},
+		{name: "unknown name after refresh", channel: "#missing", wantErr: `channel "#missing" not found`, refreshes: 1},
+		{name: "rate-limited refresh", channel: "#fresh", refreshErr: provider.ErrRefreshRateLimited, wantErr: "rate-limited", refreshes: 1},
+		{name: "failed refresh", channel: "#fresh", refreshErr: errors.New("boom"), wantErr: "cache refresh failed: boom", refreshes: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := stale
+			refreshes := 0
+			refresh := func(ctx context.Context) error {
+				refreshes++
+				if tt.refreshErr == nil {
+					cache = fresh
+				}
+				return tt.refreshErr
+			}
+
+			got, err := resolveChannelID(context.Background(), tt.channel, func() *provider.ChannelsCache { return cache }, refresh, logger)
+			assert.Equal(t, tt.refreshes, refreshes)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestUnitResolveAllowedChannel(t *testing.T) {
+	logger := zap.NewNop()
+	cache := &provider.ChannelsCache{
+		Channels: map[string]provider.Channel{
+			"C1": {ID: "C1", Name: "#general"},
+			"D1": {ID: "D1", Name: "@alice", IsIM: true},
+		},
+		ChannelsInv: map[string]string{"#general": "C1", "@alice": "D1"},
+	}
+	maps := func() *provider.ChannelsCache { return cache }
+	refresh := func(ctx context.Context) error { return nil }
+
+	t.Setenv("SLACK_MCP_ALLOWED_CHANNEL_TYPES", "public_channel")
+
+	got, err := resolveAllowedChannel(context.Background(), "#general", maps, refresh, logger)
+	require.NoError(t, err)
+	assert.Equal(t, "C1", got)
+
+	_, err = resolveAllowedChannel(context.Background(), "@alice", maps, refresh, logger)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is of type im")
+
+	_, err = resolveAllowedChannel(context.Background(), "D2", maps, refresh, logger)
+	require.Error(t, err, "uncached DMs are refused by their prefix, e.g. when closing or posting to them")
+	assert.Contains(t, err.Error(), "is of type im")
+
+	t.Setenv("SLACK_MCP_ALLOWED_CHANNEL_TYPES", "")
+	got, err = resolveAllowedChannel(context.Background(), "@alice", maps, refresh, logger)
+	require.NoError(t, err)
+	assert.Equal(t, "D1", got)
+}
+
+func TestUnitThreadByLink(t *testing.T) {
+	t.Run("permalink shapes", func(t *testing.T) {
+		channel, rootTs, err := threadFromPermalink("https://example.slack.com/archives/C1234567890/p1234567890123456")
+		require.NoError(t, err)
+		assert.Equal(t, "C1234567890", channel)
+		assert.Equal(t, "1234567890.123456", rootTs, "a root permalink is its own thread root")
+
+		channel, rootTs, err = threadFromPermalink("https://example.slack.com/archives/C1234567890/p1234567899000100?thread_ts=1234567890.123456&cid=C1234567890")
+		require.NoError(t, err)
+		assert.Equal(t, "C1234567890", channel)
+		assert.Equal(t, "1234567890.123456", rootTs, "a reply permalink resolves to its thread_ts")
+
+		_, _, err = threadFromPermalink("https://example.slack.com/archives/C1234567890")
+		assert.Error(t, err)
+	})
+
+	t.Run("thread is paged until exhausted", func(t *testing.T) {
+		var cursors []string
+		fetch := func(ctx context.Context, cursor string, limit int) (repliesPage, error) {
+			cursors = append(cursors, cursor)
+			if cursor == "" {
+				return repliesPage{messages: []slack.Message{
+					{Msg: slack.Msg{Timestamp: "1.000001", ThreadTimestamp: "1.000001", ReplyCount: 2}},
+					{Msg: slack.Msg{Timestamp: "1.000002", ThreadTimestamp: "1.000001"}},
+				}, next: "page2"}, nil
+			}
+			return repliesPage{messages: []slack.Message{
+				{Msg: slack.Msg{Timestamp: "1.000003", ThreadTimestamp: "1.000001"}},
+			}}, nil
+		}
+
+		messages, next, err := fetchThread(context.Background(), 500, fetch)
+		require.NoError(t, err)
+		assert.Len(t, messages, 3)
+		assert.Equal(t, "", next)
+		assert.Equal(t, []string{"", "page2"}, cursors)
+		assert.True(t, isThread(messages))
+	})
+
+	t.Run("cap returns a cursor to continue", func(t *testing.T) {
+		var limits []int
+		fetch := func(ctx context.Context, cursor string, limit int) (repliesPage, error) {
+			limits = append(limits, limit)
+			msgs := make([]slack.Message, limit)
+			return repliesPage{messages: msgs, next: "more"}, nil
+		}
+
+		messages, next, err := fetchThread(context.Background(), 250, fetch)
+		require.NoError(t, err)
+		assert.Len(t, messages, 250)
+		assert.Equal(t, "more", next)
+		assert.Equal(t, []int{200, 50}, limits, "the last page is sized to the remaining cap")
+	})
+
+	t.Run("non-thread permalink returns the single message", func(t *testing.T) {
+		fetch := func(ctx context.Context, cursor string, limit int) (repliesPage, error) {
+			return repliesPage{messages: []slack.Message{
+				{Msg: slack.Msg{Timestamp: "1.000001"}},
+			}}, nil
+		}
+
+		messages, next, err := fetchThread(context.Background(), 500, fetch)
+		require.NoError(t, err)
+		assert.Len(t, messages, 1)
+		assert.Equal(t, "", next)
+		assert.False(t, isThread(messages))
+	})
+
+	t.Run("reply permalink to a single reply is still a thread", func(t *testing.T) {
+		assert.True(t, isThread([]slack.Message{{Msg: slack.Msg{Timestamp: "1.000002", ThreadTimestamp: "1.000001"}}}))
+	})
+}
+
+func TestUnitThreadParticipantIDs(t *testing.T) {
+	// A thread of 1 parent and 6 replies, paged 3 messages at a time
+	thread := []slack.Message{
+		{Msg: slack.Msg{Timestamp: "1.000001", ThreadTimestamp: "1.000001", User: "U1", ReplyCount: 6}},
+		{Msg: slack.Msg{Timestamp: "1.000002", ThreadTimestamp: "1.000001", User: "U2"}},
+		{Msg: slack.Msg{Timestamp: "1.000003", ThreadTimestamp: "1.000001", User: "U1"}},
+		{Msg: slack.Msg{Timestamp: "1.000004", ThreadTimestamp: "1.000001", BotID: "B1"}},
+		{Msg: slack.Msg{Timestamp: "1.000005", ThreadTimestamp: "1.000001", User: "U2"}},
+		{Msg: slack.Msg{Timestamp: "1.000006", ThreadTimestamp: "1.000001", User: "U3"}},
+		{Msg: slack.Msg{Timestamp: "1.000007", ThreadTimestamp: "1.000001", User: "U4"}},
+	}
+	fetch := func(ctx context.Context, cursor string, limit int) (repliesPage, error) {
+		start := 0
+		if cursor != "" {
+			start, _ = strconv.Atoi(cursor)
+		}
+		end := min(start+min(limit, 3), len(thread))
+		page := repliesPage{messages: thread[start:end]}
+		if end < len(thread) {
+			page.next = strconv.Itoa(end)
+		}
+		return page, nil
+	}
+
+	t.Run("capped scan dedups authors seen so far", func(t *testing.T) {
+		messages, next, err := fetchThread(context.Background(), 4+1, fetch)
+		require.NoError(t, err)
+		assert.NotEmpty(t, next, "the cap leaves replies unscanned")
+
+		ids, scanned := threadParticipantIDs(messages)
+		assert.Equal(t, []string{"U1", "U2", "B1"}, ids, "each author is listed once, in order of their first message")
+		assert.Equal(t, 4, scanned, "the parent is not counted as a reply")
+	})
+
+	t.Run("full scan", func(t *testing.T) {
+		messages, next, err := fetchThread(context.Background(), 500, fetch)
+		require.NoError(t, err)
+		assert.Empty(t, next)
+
+		ids, scanned := threadParticipantIDs(messages)
+		assert.Equal(t, []string{"U1", "U2", "B1", "U3", "U4"}, ids)
+		assert.Equal(t, 6, scanned)
+	})
+}
+
+func TestUnitWithEmptyResultNote(t *testing.T) {
+	t.Run("empty history keeps headers and adds a note", func(t *testing.T) {
+		result, err := marshalMessagesToCSV(nil)
+		require.NoError(t, err)
+
+		result = withEmptyResultNote(result, 0, "No messages matched in #general for the given window")
+		require.Len(t, result.Content, 2)
+		assert.True(t, strings.HasPrefix(result.Content[0].(mcp.TextContent).Text, "MsgID,"), "headers-only CSV is kept")
+		assert.Equal(t, "No messages matched in #general for the given window", result.Content[1].(mcp.TextContent).Text)
+	})
+
+	t.Run("empty user search adds a note", func(t *testing.T) {
+		var results []UserSearchResult
+		csvBytes, err := gocsv.MarshalBytes(&results)
+		require.NoError(t, err)
+
+		result := withEmptyResultNote(mcp.NewToolResultText(string(csvBytes)), len(results), "No users found matching the query.")
+		require.Len(t, result.Content, 2)
+		assert.Equal(t, "No users found matching the query.", result.Content[1].(mcp.TextContent).Text)
+	})
+
+	t.Run("non-empty result is unchanged", func(t *testing.T) {
+		result, err := marshalMessagesToCSV([]Message{{MsgID: "1.1", Text: "hello"}})
+		require.NoError(t, err)
+
+		result = withEmptyResultNote(result, 1, "No messages matched")
+		assert.Len(t, result.Content, 1)
+	})
+}
+
+func TestUnitMessageTarget(t *testing.T) {
+	t.Run("parse", func(t *testing.T) {
+		latest, err := parseMessageTarget("1.1", "", false)
+		require.NoError(t, err)
+		assert.False(t, latest)
+
+		latest, err = parseMessageTarget("", "latest", false)
+		require.NoError(t, err)
+		assert.True(t, latest)
+
+		_, err = parseMessageTarget("", "", false)
+		assert.EqualError(t, err, `timestamp is required unless target is "latest"`)
+
+		latest, err = parseMessageTarget("", "", true)
+		require.NoError(t, err)
+		assert.True(t, latest, "an omitted ts means latest for conversations_mark")
+
+		_, err = parseMessageTarget("1.1", "latest", true)
+		assert.Error(t, err)
+
+		_, err = parseMessageTarget("", "oldest", false)
+		assert.Error(t, err)
+	})
+
+	noReplies := func(ctx context.Context, cursor string) (repliesPage, error) {
+		t.Fatal("replies should not be fetched without thread_ts")
+		return repliesPage{}, nil
+	}
+
+	t.Run("latest channel message", func(t *testing.T) {
+		newest := func(ctx context.Context) ([]slack.Message, error) {
+			return []slack.Message{{Msg: slack.Msg{Timestamp: "1700000300.000100"}}}, nil
+		}
+		ts, err := latestMessageTsFrom(context.Background(), "", newest, noReplies)
+		require.NoError(t, err)
+		assert.Equal(t, "1700000300.000100", ts)
+	})
+
+	t.Run("empty channel", func(t *testing.T) {
+		newest := func(ctx context.Context) ([]slack.Message, error) {
+			return nil, nil
+		}
+		ts, err := latestMessageTsFrom(context.Background(), "", newest, noReplies)
+		require.NoError(t, err)
+		assert.Equal(t, "", ts)
+	})
+
+	t.Run("latest thread reply across pages", func(t *testing.T) {
+		newest := func(ctx context.Context) ([]slack.Message, error) {
+			t.Fatal("channel history should not be fetched for a thread")
+			return nil, nil
+		}
+		replies := func(ctx context.Context, cursor string) (repliesPage, error) {
+			if cursor == "" {
+				return repliesPage{messages: []slack.Message{
+					{Msg: slack.Msg{Timestamp: "1700000000.000100"}},
+					{Msg: slack.Msg{Timestamp: "1700000100.000100"}},
+				}, next: "page2"}, nil
+			}
+			return repliesPage{messages: []slack.Message{
+				{Msg: slack.Msg{Timestamp: "1700000200.000100"}},
+			}}, nil
+		}
+		ts, err := latestMessageTsFrom(context.Background(), "1700000000.000100", newest, replies)
+		require.NoError(t, err)
+		assert.Equal(t, "1700000200.000100", ts)
+	})
+
+	t.Run("errors are returned", func(t *testing.T) {
+		newest := func(ctx context.Context) ([]slack.Message, error) {
+			return nil, errors.New("channel_not_found")
+		}
+		_, err := latestMessageTsFrom(context.Background(), "", newest, noReplies)
+		assert.EqualError(t, err, "channel_not_found")
+	})
+}
+
+func TestUnitSearchCount(t *testing.T) {
+	var gotQuery string
+	var gotParams slack.SearchParameters
+	search := func(ctx context.Context, query string, params slack.SearchParameters) (*slack.SearchMessages, error) {
+		gotQuery = query
+		gotParams = params
+		return &slack.SearchMessages{
+			Matches: []slack.SearchMessage{{Text: "deploy finished", Timestamp: "1700000000.000100"}},
+			Total:   42,
+		}, nil
+	}
+
+	result, err := searchCount(context.Background(), "deploy after:2025-01-01", search)
+	require.NoError(t, err)
+
+	assert.Equal(t, "deploy after:2025-01-01", gotQuery)
+	assert.Equal(t, 1, gotParams.Count, "only a single match is requested")
+	require.Len(t, result.Content, 1)
+	out := result.Content[0].(mcp.TextContent).Text
+	assert.Equal(t, "Query,Total\ndeploy after:2025-01-01,42\n", out)
+	assert.NotContains(t, out, "deploy finished", "no message rows are returned")
+
+	t.Run("pagination total is used as fallback", func(t *testing.T) {
+		search := func(ctx context.Context, query string, params slack.SearchParameters) (*slack.SearchMessages, error) {
+			return &slack.SearchMessages{Pagination: slack.Pagination{TotalCount: 7}}, nil
+		}
+		result, err := searchCount(context.Background(), "x", search)
+		require.NoError(t, err)
+		assert.Equal(t, "Query,Total\nx,7\n", result.Content[0].(mcp.TextContent).Text)
+	})
+
+	t.Run("search errors are returned", func(t *testing.T) {
+		search := func(ctx context.Context, query string, params slack.SearchParameters) (*slack.SearchMessages, error) {
+			return nil, errors.New("not_allowed_token_type")
+		}
+		_, err := searchCount(context.Background(), "x", search)
+		assert.EqualError(t, err, "not_allowed_token_type")
+	})
+}
+
+func TestUnitGroupMatchesByChannel(t *testing.T) {
+	matches := []slack.SearchMessage{
+		{Channel: slack.CtxChannel{ID: "C2", Name: "random"}, Timestamp: "1.1"},
+		{Channel: slack.CtxChannel{ID: "C1", Name: "general"}, Timestamp: "1.2"},
+		{Channel: slack.CtxChannel{ID: "C2", Name: "random"}, Timestamp: "1.3"},
+		{Channel: slack.CtxChannel{ID: "D1", Name: "@alice"}, Timestamp: "1.4"},
+		{Channel: slack.CtxChannel{ID: "C2"}, Timestamp: "1.5"},
+		{Channel: slack.CtxChannel{ID: "C3"}, Timestamp: "1.6"},
+		{Channel: slack.CtxChannel{ID: "C1", Name: "general"}, Timestamp: "1.7"},
+	}
+
+	rows := groupMatchesByChannel(matches)
+	assert.Equal(t, []SearchChannel{
+		{ChannelID: "C2", ChannelName: "#random", Matches: 3},
+		{ChannelID: "C1", ChannelName: "#general", Matches: 2},
+		{ChannelID: "C3", Matches: 1},
+		{ChannelID: "D1", ChannelName: "@alice", Matches: 1},
+	}, rows)
+
+	result, err := marshalSearchChannels(rows)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(result.Content[0].(mcp.TextContent).Text, "ChannelID,ChannelName,Matches\nC2,#random,3\n"))
+
+	t.Run("no matches", func(t *testing.T) {
+		result, err := marshalSearchChannels(groupMatchesByChannel(nil))
+		require.NoError(t, err)
+		require.Len(t, result.Content, 2)
+		assert.Equal(t, "No messages matched the search query", result.Content[1].(mcp.TextContent).Text)
+	})
+}
+func TestUnitFetchReactions(t *testing.T) {
+	const workers = 3
+	items := make([]slack.ItemRef, 12)
+	for i := range items {
+		items[i] = slack.ItemRef{Channel: fmt.Sprintf("C%02d", i%4), Timestamp: fmt.Sprintf("1700000000.%06d", i)}
+	}
+	failing := items[5]
+
+	var inFlight, maxInFlight atomic.Int32
+	fetch := func(ctx context.Context, item slack.ItemRef) ([]slack.ItemReaction, error) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		if item == failing {
+			return nil, errors.New("message_not_found")
+		}
+		// The reaction name encodes the item so mix-ups between items show up.
+		return []slack.ItemReaction{{Name: item.Channel + "-" + item.Timestamp, Count: 1}}, nil
+	}
+
+	reactions, failed := fetchReactions(context.Background(), items, workers, fetch, zap.NewNop())
+
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(workers), "concurrency is bounded by workers")
+	assert.Greater(t, maxInFlight.Load(), int32(1), "calls run concurrently")
+	assert.Equal(t, 1, failed)
+	assert.Len(t, reactions, len(items)-1)
+	assert.NotContains(t, reactions, failing, "failed lookups are left out")
+	for _, item := range items {
+		if item == failing {
+			continue
+		}
+		require.Len(t, reactions[item], 1)
+		assert.Equal(t, item.Channel+"-"+item.Timestamp, reactions[item][0].Name)
+	}
+
+	t.Run("reactions are applied to the matching rows", func(t *testing.T) {
+		matches := []slack.SearchMessage{
+			{Channel: slack.CtxChannel{ID: "C01", Name: "general"}, Timestamp: "1700000000.000001"},
+			{Channel: slack.CtxChannel{ID: "C02", Name: "random"}, Timestamp: "1700000000.000002"},
+		}
+		// Rows are in a different order than the matches.
+		messages := []Message{
+			{Channel: "#random", MsgID: "1700000000.000002"},
+			{Channel: "#general", MsgID: "1700000000.000001"},
+		}
+		reactions := map[slack.ItemRef][]slack.ItemReaction{
+			{Channel: "C01", Timestamp: "1700000000.000001"}: {{Name: "tada", Count: 2}},
+		}
+
+		got := applySearchReactions(messages, matches, reactions, nil)
+		assert.Empty(t, got[0].Reactions)
+		assert.Equal(t, formatReactions(reactions[slack.ItemRef{Channel: "C01", Timestamp: "1700000000.000001"}], nil, false), got[1].Reactions)
+		assert.NotEmpty(t, got[1].Reactions)
+	})
+
+	t.Run("unresolved channels with the same ts do not collide", func(t *testing.T) {
+		matches := []slack.SearchMessage{
+			{Channel: slack.CtxChannel{ID: "C01"}, Timestamp: "1700000000.000001"},
+			{Channel: slack.CtxChannel{ID: "C02"}, Timestamp: "1700000000.000001"},
+		}
+		messages := []Message{
+			{Channel: searchChannelLabel(matches[0].Channel), MsgID: "1700000000.000001"},
+			{Channel: searchChannelLabel(matches[1].Channel), MsgID: "1700000000.000001"},
+		}
+		reactions := map[slack.ItemRef][]slack.ItemReaction{
+			{Channel: "C01", Timestamp: "1700000000.000001"}: {{Name: "tada", Count: 2}},
+		}
+
+		got := applySearchReactions(messages, matches, reactions, nil)
+		assert.NotEmpty(t, got[0].Reactions)
+		assert.Empty(t, got[1].Reactions, "reactions of C01 must not be applied to C02")
+	})
+}
+
+func TestUnitProfileFieldRows(t *testing.T) {
+	defs := map[string]slack.TeamProfileField{
+		"Xf01": {ID: "Xf01", Label: "Department", Ordering: 1},
+		"Xf02": {ID: "Xf02", Label: "Location", Ordering: 0},
+		"Xf03": {ID: "Xf03", Label: "Manager", Ordering: 2},
+		"Xf04": {ID: "Xf04", Label: "Cost center", Ordering: 3, IsHidden: true},
+	}
+	fields := map[string]slack.UserProfileCustomField{
+		"Xf01": {Value: "Platform", Label: "Dept (old label)"},
+		"Xf02": {Value: "Berlin"},
+		"Xf03": {Value: "U0MANAGER", Alt: "Jane Doe"},
+		"Xf04": {Value: "CC-42"},
+		"Xf09": {Value: "they/them", Label: "Pronouns"},
+		"Xf08": {Value: "unlabelled"},
+		"Xf07": {Value: ""},
+	}
+
+	rows := profileFieldRows("U1", fields, defs)
+	assert.Equal(t, []ProfileField{
+		{UserID: "U1", FieldID: "Xf02", Label: "Location", Value: "Berlin"},
+		{UserID: "U1", FieldID: "Xf01", Label: "Department", Value: "Platform"},
+		{UserID: "U1", FieldID: "Xf03", Label: "Manager", Value: "U0MANAGER", Alt: "Jane Doe"},
+		{UserID: "U1", FieldID: "Xf08", Label: "Xf08", Value: "unlabelled"},
+		{UserID: "U1", FieldID: "Xf09", Label: "Pronouns", Value: "they/them"},
+	}, rows, "fields are labelled and ordered by definition, hidden and empty fields are skipped")
+
+	t.Run("without definitions the profile labels are used", func(t *testing.T) {
+		rows := profileFieldRows("U1", map[string]slack.UserProfileCustomField{
+			"Xf01": {Value: "Platform", Label: "Department"},
+		}, map[string]slack.TeamProfileField{})
+		assert.Equal(t, []ProfileField{{UserID: "U1", FieldID: "Xf01", Label: "Department", Value: "Platform"}}, rows)
+	})
+}
+
+func TestUnitLookupUsersByEmail(t *testing.T) {
+	users := map[string]slack.User{
+		"U1": {ID: "U1", Name: "alice", RealName: "Alice A", Profile: slack.UserProfile{Email: "Alice@Example.com"}},
+		"U2": {ID: "U2", Name: "bob", RealName: "Bob B", Profile: slack.UserProfile{Email: "bob@example.com"}},
+		"U3": {ID: "U3", Name: "nomail"},
+	}
+
+	var fetched []string
+	fetch := func(ctx context.Context, email string) (*slack.User, error) {
+		fetched = append(fetched, email)
+		switch email {
+		case "carol@example.com":
+			return &slack.User{ID: "U4", Name: "carol", RealName: "Carol C"}, nil
+		case "broken@example.com":
+			return nil, errors.New("ratelimited")
+		}
+		return nil, errors.New("users_not_found")
+	}
+
+	rows, calls := lookupUsersByEmail(context.Background(), []string{
+		"alice@example.com",
+		"carol@example.com",
+		"ghost@example.com",
+		"BOB@example.com",
+		"broken@example.com",
+		"Alice@example.com",
+	}, users, fetch, zap.NewNop())
+
+	assert.Equal(t, []UserByEmail{
+		{Email: "alice@example.com", Found: true, UserID: "U1", UserName: "alice", RealName: "Alice A"},
+		{Email: "carol@example.com", Found: true, UserID: "U4", UserName: "carol", RealName: "Carol C"},
+		{Email: "ghost@example.com"},
+		{Email: "BOB@example.com", Found: true, UserID: "U2", UserName: "bob", RealName: "Bob B"},
+		{Email: "broken@example.com", Error: "ratelimited"},
+	}, rows, "cached emails match case-insensitively, misses fall back to fetch, duplicates are dropped, only lookup failures carry an error")
+	assert.Equal(t, []string{"carol@example.com", "ghost@example.com", "broken@example.com"}, fetched, "only cache misses are fetched")
+	assert.Equal(t, 3, calls)
+}
+
+func TestUnitWrapMessage(t *testing.T) {
+	t.Run("text", func(t *testing.T) {
+		assert.Equal(t, "(sent via assistant)\nhello\n_automated_", wrapMessageText("hello", "(sent via assistant)", "_automated_"))
+		assert.Equal(t, "hello\n_automated_", wrapMessageText("hello", "", "_automated_"))
+		assert.Equal(t, "hello", wrapMessageText("hello", "  ", ""), "blank values are ignored")
+	})
+
+	t.Run("blocks", func(t *testing.T) {
+		body := []slack.Block{
+			slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, "*hello*", false, false), nil, nil),
+		}
+
+		wrapped := wrapMessageBlocks(body, "(sent via assistant)", "_automated_")
+		require.Len(t, wrapped, 3)
+		var texts []string
+		for _, b := range wrapped {
+			section, ok := b.(*slack.SectionBlock)
+			require.True(t, ok)
+			texts = append(texts, section.Text.Text)
+		}
+		assert.Equal(t, []string{"(sent via assistant)", "*hello*", "_automated_"}, texts)
+		assert.Equal(t, slack.MarkdownType, wrapped[0].(*slack.SectionBlock).Text.Type)
+
+		assert.Equal(t, body, wrapMessageBlocks(body, "", ""), "no wrapper leaves the blocks unchanged")
+	})
+}
+
+func TestUnitMarkdownOptions(t *testing.T) {
+	const markdown = "**unterminated"
+	failing := func(string) ([]slack.Block, error) {
+		return nil, errors.New("unexpected end of input")
+	}
+	apply := func(t *testing.T, options []slack.MsgOption) url.Values {
+		_, values, err := slack.UnsafeApplyMsgOptions("xoxp-test", "C123", "https://slack.com/api/", options...)
+		require.NoError(t, err)
+		return values
+	}
+
+	t.Run("plain is the default", func(t *testing.T) {
+		for _, fallback := range []string{"", "plain", "unknown"} {
+			options, err := markdownOptions(markdown, "", "", fallback, failing, zap.NewNop())
+			require.NoError(t, err, fallback)
+			values := apply(t, options)
+			assert.Equal(t, markdown, values.Get("text"), fallback)
+			assert.Equal(t, "false", values.Get("mrkdwn"), fallback)
+			assert.Empty(t, values.Get("blocks"), fallback)
+		}
+	})
+
+	t.Run("error fails the call", func(t *testing.T) {
+		options, err := markdownOptions(markdown, "", "", "error", failing, zap.NewNop())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unexpected end of input")
+		assert.Nil(t, options)
+	})
+
+	t.Run("raw_mrkdwn sends the text for Slack to render", func(t *testing.T) {
+		options, err := markdownOptions(markdown, "(sent via assistant)", "", " RAW_MRKDWN ", failing, zap.NewNop())
+		require.NoError(t, err)
+		values := apply(t, options)
+		assert.Equal(t, "(sent via assistant)\n"+markdown, values.Get("text"))
+		assert.False(t, values.Has("mrkdwn"), "mrkdwn is left on")
+		assert.Empty(t, values.Get("blocks"))
+	})
+
+	t.Run("converted markdown is sent as blocks whatever the fallback", func(t *testing.T) {
+		converting := func(string) ([]slack.Block, error) {
+			return []slack.Block{slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, "*bold*", false, false), nil, nil)}, nil
+		}
+		options, err := markdownOptions("**bold**", "", "", "error", converting, zap.NewNop())
+		require.NoError(t, err)
+		values := apply(t, options)
+		assert.Contains(t, values.Get("blocks"), "*bold*")
+		assert.Empty(t, values.Get("text"))
+	})
+}
+
+func TestUnitUnreadTotals(t *testing.T) {
+	t.Setenv("SLACK_MCP_ALLOWED_CHANNEL_TYPES", "")
+
+	counts := edge.ClientCountsResponse{
+		Channels: []edge.ChannelSnapshot{
+			{ID: "C1", HasUnreads: true, MentionCount: 2},
+			// unreads without mentions: the full listing would backfill this one
+			// from conversations.history, counts_only leaves it at zero mentions
+			{ID: "C2", HasUnreads: true, LastRead: fasttime.Time(time.Unix(1700000000, 0))},
+			{ID: "C3", HasUnreads: true, MentionCount: 1},
+			{ID: "C4", HasUnreads: false},
+			{ID: "C5", HasUnreads: true, MentionCount: 4},
+		},
+		MPIMs: []edge.ChannelSnapshot{{ID: "G1", HasUnreads: true, MentionCount: 3}},
+		IMs: []edge.ChannelSnapshot{
+			{ID: "D1", HasUnreads: true, MentionCount: 5},
+			{ID: "D2", HasUnreads: true, MentionCount: 1},
+		},
+	}
+	channels := map[string]provider.Channel{
+		"C1": {ID: "C1", Name: "#general"},
+		"C2": {ID: "C2", Name: "#random"},
+		"C3": {ID: "C3", Name: "#partner", IsExtShared: true},
+	}
+	params := &unreadsParams{channelTypes: "all", countsOnly: true, mutedChannels: map[string]bool{"C5": true}}
+
+	assert.Equal(t, []UnreadTotal{
+		{Type: "dm", Channels: 2, Mentions: 6},
+		{Type: "group_dm", Channels: 1, Mentions: 3},
+		{Type: "partner", Channels: 1, Mentions: 1},
+		{Type: "internal", Channels: 2, Mentions: 2},
+		{Type: "total", Channels: 6, Mentions: 12},
+	}, unreadTotals(params, counts, channels), "totals come from client.counts alone, without backfilled counts")
+
+	t.Run("filters match the full listing", func(t *testing.T) {
+		params := &unreadsParams{channelTypes: "internal", mentionsOnly: true}
+		assert.Equal(t, []UnreadTotal{
+			{Type: "dm"},
+			{Type: "group_dm"},
+			{Type: "partner"},
+			{Type: "internal", Channels: 2, Mentions: 6},
+			{Type: "total", Channels: 2, Mentions: 6},
+		}, unreadTotals(params, counts, channels))
+	})
+}
+
+func TestUnitFlagUnreadMessages(t *testing.T) {
+	messages := []Message{
+		{MsgID: "1700000300.000100"},
+		{MsgID: "1700000200.000200"},
+		{MsgID: "1700000200.000100"},
+		{MsgID: "1700000100.000100"},
+	}
+
+	flagged := flagUnreadMessages(messages, "1700000200.000100")
+	var unread []bool
+	for _, m := range flagged {
+		unread = append(unread, m.IsUnread)
+	}
+	assert.Equal(t, []bool{true, true, false, false}, unread, "only messages after last_read are unread")
+
+	t.Run("a never read channel is all unread", func(t *testing.T) {
+		flagged := flagUnreadMessages([]Message{{MsgID: "1700000100.000100"}}, "0000000000.000000")
+		assert.True(t, flagged[0].IsUnread)
+	})
+
+	t.Run("column is only emitted with mark_unread_boundary", func(t *testing.T) {
+		header := func(params conversationParams) string {
+			result, err := marshalMessagesToCSV(flagged, params.messageColumns()...)
+			require.NoError(t, err)
+			header, _, _ := strings.Cut(result.Content[0].(mcp.TextContent).Text, "\n")
+			return header
+		}
+		assert.NotContains(t, header(conversationParams{}), "IsUnread")
+		assert.Contains(t, header(conversationParams{unreadBoundary: true}), ",IsUnread,")
+	})
+}
+
+func TestUnitCallSummary(t *testing.T) {
+	users := map[string]slack.User{
+		"U1": {ID: "U1", Name: "alice"},
+		"U2": {ID: "U2", Name: "bob"},
+	}
+	callMsg := slack.Message{Msg: slack.Msg{
+		Timestamp: "1700000000.000100",
+		User:      "U1",
+		Blocks:    slack.Blocks{BlockSet: []slack.Block{slack.NewCallBlock("R123")}},
+	}}
+
+	t.Run("call messages are recognized by their call block", func(t *testing.T) {
+		assert.True(t, isCallMessage(callMsg))
+		assert.Equal(t, "R123", callIDOf(callMsg))
+		assert.True(t, isCallMessage(slack.Message{Msg: slack.Msg{SubType: "huddle_thread"}}))
+		assert.False(t, isCallMessage(slack.Message{Msg: slack.Msg{Text: "hello"}}))
+	})
+
+	t.Run("call with details", func(t *testing.T) {
+		call := &slack.Call{
+			ID:        "R123",
+			Title:     "Standup",
+			DateStart: slack.JSONTime(1700000000),
+			DateEnd:   slack.JSONTime(1700000900),
+			Participants: []slack.CallParticipant{
+				{SlackID: "U1"},
+				{SlackID: "U2"},
+				{ExternalID: "ext-1", DisplayName: "Guest"},
+			},
+		}
+		assert.Equal(t,
+			"[call] started by @alice; title: Standup; duration: 15m0s; participants: @alice, @bob, Guest",
+			callSummary(callMsg, call, users))
+	})
+
+	t.Run("huddle without details", func(t *testing.T) {
+		huddle := slack.Message{Msg: slack.Msg{SubType: "huddle_thread", User: "U2"}}
+		assert.Equal(t, "[huddle] started by @bob", callSummary(huddle, nil, users))
+	})
+}
+
+func TestUnitFetchClientCounts(t *testing.T) {
+	t.Run("a throttled call is retried", func(t *testing.T) {
+		calls := 0
+		counts, err := fetchClientCounts(context.Background(), func(ctx context.Context) (edge.ClientCountsResponse, error) {
+			calls++
+			if calls == 1 {
+				return edge.ClientCountsResponse{}, &slack.RateLimitedError{RetryAfter: time.Millisecond}
+			}
+			return edge.ClientCountsResponse{IMs: []edge.ChannelSnapshot{{ID: "D1", HasUnreads: true}}}, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, calls)
+		assert.Len(t, counts.IMs, 1, "the unreads request proceeds with the counts of the retry")
+	})
+
+	t.Run("exhausted retries suggest a cooldown", func(t *testing.T) {
+		calls := 0
+		_, err := fetchClientCounts(context.Background(), func(ctx context.Context) (edge.ClientCountsResponse, error) {
+			calls++
+			return edge.ClientCountsResponse{}, &slack.RateLimitedError{RetryAfter: time.Millisecond}
+		})
+		require.Error(t, err)
+		assert.Equal(t, 3, calls)
+		assert.Contains(t, err.Error(), "wait 1ms before trying again")
+		var rle *slack.RateLimitedError
+		assert.ErrorAs(t, err, &rle)
+	})
+
+	t.Run("other errors are not retried", func(t *testing.T) {
+		calls := 0
+		_, err := fetchClientCounts(context.Background(), func(ctx context.Context) (edge.ClientCountsResponse, error) {
+			calls++
+			return edge.ClientCountsResponse{}, errors.New("invalid_auth")
+		})
+		assert.EqualError(t, err, "invalid_auth")
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestUnitEnvBool(t *testing.T) {
+	for _, v := range []string{"true", "1", "yes"} {
+		t.Setenv("SLACK_MCP_TEST_FLAG", v)
+		assert.True(t, envBool("SLACK_MCP_TEST_FLAG"), v)
+	}
+	for _, v := range []string{"", "false", "0", "no", "TRUE"} {
+		t.Setenv("SLACK_MCP_TEST_FLAG", v)
+		assert.False(t, envBool("SLACK_MCP_TEST_FLAG"), v)
+	}
+}
+
+func TestUnitMarkAfterPost(t *testing.T) {
+	yes, no := true, false
+	tests := []struct {
+		name     string
+		markRead *bool
+		env      string
+		want     bool
+	}{
+		{"env default off", nil, "", false},
+		{"env default on", nil, "true", true},
+		{"env accepts 1", nil, "1", true},
+		{"param marks despite env off", &yes, "", true},
+		{"param suppresses despite env on", &no, "true", false},
+		{"param true with env on", &yes, "yes", true},
+		{"param false with env off", &no, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SLACK_MCP_ADD_MESSAGE_MARK", tt.env)
+			assert.Equal(t, tt.want, markAfterPost(tt.markRead))
+		})
+	}
+}
+
+func TestUnitMessageTime(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	logger := zap.New(core)
+
+	assert.Equal(t, "2023-11-14T22:13:20Z", messageTime("1700000000.000100", text.TimeFormat{}, logger))
+	assert.Zero(t, logs.Len())
+
+	for _, ts := range []string{"", "1700000000", "not-a-ts", "1700000000.abc"} {
+		assert.Empty(t, messageTime(ts, text.TimeFormat{}, logger), "malformed ts %q yields an empty time", ts)
+	}
+	require.Equal(t, 4, logs.Len())
+	for _, entry := range logs.All() {
+		assert.Equal(t, zap.DebugLevel, entry.Level, "malformed timestamps are logged at debug level")
+	}
+}
+
+func TestUnitFetchNewerThan(t *testing.T) {
+	// Slack pages history newest first; the second page reaches newer_than and
+	// includes a message at and one before it, as an inclusive boundary would.
+	pages := map[string]struct {
+		msgs []slack.Message
+		next string
+	}{
+		"": {msgs: []slack.Message{
+			{Msg: slack.Msg{Timestamp: "1700000500.000000", Text: "e"}},
+			{Msg: slack.Msg{Timestamp: "1700000400.000000", Text: "d"}},
+		}, next: "page2"},
+		"page2": {msgs: []slack.Message{
+			{Msg: slack.Msg{Timestamp: "1700000300.000000", Text: "c"}},
+			{Msg: slack.Msg{Timestamp: "1700000200.000001", Text: "b"}},
+			{Msg: slack.Msg{Timestamp: "1700000200.000000", Text: "boundary"}},
+			{Msg: slack.Msg{Timestamp: "1700000100.000000", Text: "older"}},
+		}},
+	}
+	var calls []string
+	fetch := func(ctx context.Context, cursor string) ([]slack.Message, string, error) {
+		calls = append(calls, cursor)
+		p := pages[cursor]
+		return p.msgs, p.next, nil
+	}
+
+	texts := func(msgs []slack.Message) []string {
+		var out []string
+		for _, m := range msgs {
+			out = append(out, m.Text)
+		}
+		return out
+	}
+
+	msgs, complete, err := fetchNewerThan(context.Background(), "1700000200.000000", 10, fetch)
+	require.NoError(t, err)
+	assert.True(t, complete)
+	assert.Equal(t, []string{"", "page2"}, calls)
+	assert.Equal(t, []string{"b", "c", "d", "e"}, texts(msgs), "only newer messages, oldest first")
+
+	t.Run("walk stops at max pages", func(t *testing.T) {
+		calls = nil
+		msgs, complete, err := fetchNewerThan(context.Background(), "1700000200.000000", 1, fetch)
+		require.NoError(t, err)
+		assert.False(t, complete)
+		assert.Equal(t, []string{""}, calls)
+		assert.Equal(t, []string{"d", "e"}, texts(msgs))
+	})
+
+	t.Run("fetch errors are returned", func(t *testing.T) {
+		_, _, err := fetchNewerThan(context.Background(), "1700000200.000000", 10, func(ctx context.Context, cursor string) ([]slack.Message, string, error) {
+			return nil, "", errors.New("channel_not_found")
+		})
+		assert.EqualError(t, err, "channel_not_found")
+	})
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/limiter"
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/korotovsky/slack-mcp-server/pkg/text"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+)
+
+// PinnedItem is a pinned message or file, in the shape of a
+// conversations_history row plus the kind of item that is pinned
+type PinnedItem struct {
+	Message
+	ItemType string `json:"itemType"`
+}
+
+type PinsHandler struct {
+	apiProvider   *provider.ApiProvider
+	logger        *zap.Logger
+	conversations *ConversationsHandler
+}
+
+// NewPinsHandler builds a PinsHandler on top of conversations, whose time
+// format and message conversion pinned messages share.
+func NewPinsHandler(conversations *ConversationsHandler) *PinsHandler {
+	return &PinsHandler{
+		apiProvider:   conversations.apiProvider,
+		logger:        conversations.logger,
+		conversations: conversations,
+	}
+}
+
+// PinsListHandler lists the messages and files pinned in a channel
+func (h *PinsHandler) PinsListHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.Debug("PinsListHandler called", zap.Any("params", request.Params))
+
+	if ready, err := h.apiProvider.IsReady(); !ready {
+		h.logger.Error("API provider not ready", zap.Error(err))
+		return nil, err
+	}
+
+	channel := strings.TrimSpace(request.GetString("channel_id", ""))
+	if channel == "" {
+		return nil, errors.New("channel_id is required")
+	}
+	channel, err := resolveAllowedChannel(ctx, channel, h.apiProvider.ProvideChannelsMaps, h.apiProvider.ForceRefreshChannels, h.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	pins, err := limiter.CallWithRetry(ctx, limiter.Tier2.Limiter(), 2, provider.SlackRetryAfter, func() (pinsPage, error) {
+		items, paging, err := h.apiProvider.Slack().ListPinsContext(ctx, channel)
+		return pinsPage{items: items, paging: paging}, err
+	})
+	if err != nil {
+		h.logger.Error("Slack ListPinsContext failed", zap.String("channel", channel), zap.Error(err))
+		return nil, fmt.Errorf("failed to list pinned items of %s: %w", channel, err)
+	}
+
+	h.logger.Debug("Fetched pinned items", zap.Int("count", len(pins.items)))
+
+	items := pinnedItems(pins.items, channel, h.apiProvider.ProvideUsersMap().Users, h.conversations.timeFormat, h.logger,
+		func(msg slack.Message) []Message {
+			return h.conversations.convertMessagesFromHistory(ctx, []slack.Message{msg}, channel, true)
+		})
+	csvBytes, err := messagesCSV(&items, nil)
+	if err != nil {
+		h.logger.Error("Failed to marshal pinned items to CSV", zap.Error(err))
+		return nil, err
+	}
+	return withEmptyResultNote(mcp.NewToolResultText(string(csvBytes)), len(items), "No pinned messages or files found in this channel"), nil
+}
+
+// pinnedItems converts pins.list items to rows. A pinned message is converted
+// by convert as conversations_history does, a pinned file is listed with its
+// title and permalink as text and its ID as attachment. Other kinds of items,
+// such as file comments, are skipped.
+func pinnedItems(items []slack.Item, channel string, users map[string]slack.User, tf text.TimeFormat, logger *zap.Logger, convert func(slack.Message) []Message) []PinnedItem {
+	result := make([]PinnedItem, 0, len(items))
+	for _, item := range items {
+		switch {
+		case item.Type == slack.TYPE_MESSAGE && item.Message != nil:
+			for _, msg := range convert(*item.Message) {
+				result = append(result, PinnedItem{Message: msg, ItemType: slack.TYPE_MESSAGE})
+			}
+		case item.Type == slack.TYPE_FILE && item.File != nil:
+			file := item.File
+			userName, realName, _ := getUserInfo(file.User, users)
+			result = append(result, PinnedItem{
+				Message: Message{
+					UserID:        file.User,
+					UserName:      userName,
+					RealName:      realName,
+					Channel:       channel,
+					Text:          strings.TrimSpace(file.Title + " " + file.Permalink),
+					Time:          tf.Format(file.Created.Time()),
+					FileCount:     1,
+					AttachmentIDs: file.ID,
+					HasMedia:      true,
+				},
+				ItemType: slack.TYPE_FILE,
+			})
+		default:
+			logger.Debug("Skipping pinned item", zap.String("type", item.Type))
+		}
+	}
+	return result
+}
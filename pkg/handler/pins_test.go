@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/text"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestUnitPinnedItems(t *testing.T) {
+	users := map[string]slack.User{
+		"U1": {ID: "U1", Name: "alice", RealName: "Alice A"},
+	}
+	doc := slack.File{ID: "F1", Title: "Runbook", Permalink: "https://example.slack.com/files/U1/F1/runbook", User: "U1", Created: 1700000000}
+	pins := []slack.Item{
+		{Type: "message", Channel: "C1", Message: &slack.Message{Msg: slack.Msg{
+			Timestamp: "1700000100.000200", User: "U1", Text: "Deploys freeze on Fridays",
+			Files: []slack.File{{ID: "F2"}, {ID: "F3"}},
+		}}},
+		{Type: "file", File: &doc},
+		{Type: "file_comment", Comment: &slack.Comment{ID: "Fc1"}},
+		{Type: "message"},
+	}
+
+	var converted []slack.Message
+	convert := func(msg slack.Message) []Message {
+		converted = append(converted, msg)
+		return []Message{{MsgID: msg.Timestamp, UserID: msg.User, Channel: "C1", Text: msg.Text}}
+	}
+
+	items := pinnedItems(pins, "C1", users, text.TimeFormat{}, zap.NewNop(), convert)
+	require.Len(t, items, 2, "file comments and items without a payload are skipped")
+
+	require.Len(t, converted, 1, "only pinned messages go through the history conversion")
+	assert.Equal(t, "1700000100.000200", converted[0].Timestamp)
+	assert.Equal(t, "message", items[0].ItemType)
+	assert.Equal(t, "1700000100.000200", items[0].MsgID)
+	assert.Equal(t, "C1", items[0].Channel)
+	assert.Equal(t, "Deploys freeze on Fridays", items[0].Text)
+
+	assert.Equal(t, "file", items[1].ItemType)
+	assert.Empty(t, items[1].MsgID)
+	assert.Equal(t, "alice", items[1].UserName)
+	assert.Equal(t, "Runbook https://example.slack.com/files/U1/F1/runbook", items[1].Text)
+	assert.Equal(t, "2023-11-14T22:13:20Z", items[1].Time)
+	assert.Equal(t, "F1", items[1].AttachmentIDs)
+
+	t.Run("csv keeps the message columns and adds itemType", func(t *testing.T) {
+		csvBytes, err := messagesCSV(&items, nil)
+		require.NoError(t, err)
+		header, _, _ := strings.Cut(string(csvBytes), "\n")
+		assert.True(t, strings.HasPrefix(header, "MsgID,UserID,"), header)
+		assert.True(t, strings.HasSuffix(header, ",Cursor,ItemType"), header)
+	})
+}
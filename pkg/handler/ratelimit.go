@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gocarina/gocsv"
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/mark3labs/mcp-go/mcp"
+	"go.uber.org/zap"
+)
+
+// RateLimitReport is the result row of rate_limit_status.
+type RateLimitReport struct {
+	Throttled         bool   `csv:"Throttled"`
+	LastRateLimitedAt string `csv:"LastRateLimitedAt"`
+	RetryAfterSeconds int    `csv:"RetryAfterSeconds"`
+	WaitSeconds       int    `csv:"WaitSeconds"`
+	RecentRateLimited int    `csv:"RecentRateLimited"`
+	Advice            string `csv:"Advice"`
+}
+
+type RateLimitHandler struct {
+	apiProvider *provider.ApiProvider
+	logger      *zap.Logger
+}
+
+func NewRateLimitHandler(apiProvider *provider.ApiProvider, logger *zap.Logger) *RateLimitHandler {
+	return &RateLimitHandler{
+		apiProvider: apiProvider,
+		logger:      logger,
+	}
+}
+
+// RateLimitStatusHandler reports the rate limiting Slack applied recently. It
+// makes no Slack API calls, so it works before the caches are ready.
+func (h *RateLimitHandler) RateLimitStatusHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.logger.Debug("RateLimitStatusHandler called", zap.Any("params", request.Params))
+
+	report := []RateLimitReport{rateLimitReport(h.apiProvider.RateLimitStatus())}
+	csvBytes, err := gocsv.MarshalBytes(&report)
+	if err != nil {
+		h.logger.Error("Failed to marshal rate limit status to CSV", zap.Error(err))
+		return nil, err
+	}
+	return mcp.NewToolResultText(string(csvBytes)), nil
+}
+
+// rateLimitReport turns the observed rate limiting into advice for the model.
+func rateLimitReport(st provider.RateLimitStatus) RateLimitReport {
+	r := RateLimitReport{
+		Throttled:         st.Throttled,
+		RetryAfterSeconds: int(st.RetryAfter / time.Second),
+		WaitSeconds:       int((st.WaitFor + time.Second - 1) / time.Second),
+		RecentRateLimited: st.RecentCount,
+	}
+	if !st.LastLimitedAt.IsZero() {
+		r.LastRateLimitedAt = st.LastLimitedAt.UTC().Format(time.RFC3339)
+	}
+	switch {
+	case st.Throttled:
+		r.Advice = fmt.Sprintf("Slack is throttling requests: wait %d seconds before heavy operations such as deep search or unread scans.", r.WaitSeconds)
+	case st.RecentCount > 0:
+		r.Advice = "Slack rate limited requests in the last few minutes: prefer small pages and postpone deep scans."
+	default:
+		r.Advice = "No recent rate limiting: heavy operations can proceed."
+	}
+	return r
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/provider"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnitRateLimitReport(t *testing.T) {
+	t.Run("currently throttled", func(t *testing.T) {
+		r := rateLimitReport(provider.RateLimitStatus{
+			Throttled:     true,
+			LastLimitedAt: time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC),
+			RetryAfter:    30 * time.Second,
+			WaitFor:       12500 * time.Millisecond,
+			RecentCount:   2,
+		})
+		assert.True(t, r.Throttled)
+		assert.Equal(t, "2025-01-02T15:04:05Z", r.LastRateLimitedAt)
+		assert.Equal(t, 30, r.RetryAfterSeconds)
+		assert.Equal(t, 13, r.WaitSeconds)
+		assert.Equal(t, 2, r.RecentRateLimited)
+		assert.Contains(t, r.Advice, "wait 13 seconds")
+	})
+
+	t.Run("recently throttled", func(t *testing.T) {
+		r := rateLimitReport(provider.RateLimitStatus{
+			LastLimitedAt: time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC),
+			RecentCount:   1,
+		})
+		assert.False(t, r.Throttled)
+		assert.Contains(t, r.Advice, "postpone deep scans")
+	})
+
+	t.Run("never throttled", func(t *testing.T) {
+		r := rateLimitReport(provider.RateLimitStatus{})
+		assert.Empty(t, r.LastRateLimitedAt)
+		assert.Contains(t, r.Advice, "can proceed")
+	})
+}
@@ -0,0 +1,55 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrRetryBudgetExhausted is returned by CallWithRetry instead of retrying once
+// the retry budget of the context has been used up.
+var ErrRetryBudgetExhausted = errors.New("retry budget exhausted, back off before calling again")
+
+// RetryBudget caps the total number of retries of all CallWithRetry calls that
+// share a context, e.g. every Slack call made while handling one tool call, so
+// a tool making hundreds of calls cannot turn throttling into a retry storm.
+type RetryBudget struct {
+	mu        sync.Mutex
+	remaining int
+}
+
+// NewRetryBudget returns a budget allowing n retries in total.
+func NewRetryBudget(n int) *RetryBudget {
+	return &RetryBudget{remaining: n}
+}
+
+// take consumes one retry and reports whether one was left.
+func (b *RetryBudget) take() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.remaining <= 0 {
+		return false
+	}
+	b.remaining--
+	return true
+}
+
+// Remaining returns the number of retries left.
+func (b *RetryBudget) Remaining() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.remaining
+}
+
+type retryBudgetKey struct{}
+
+// WithRetryBudget returns a copy of ctx carrying b.
+func WithRetryBudget(ctx context.Context, b *RetryBudget) context.Context {
+	return context.WithValue(ctx, retryBudgetKey{}, b)
+}
+
+// RetryBudgetFromContext returns the budget carried by ctx, or nil if there is none.
+func RetryBudgetFromContext(ctx context.Context) *RetryBudget {
+	b, _ := ctx.Value(retryBudgetKey{}).(*RetryBudget)
+	return b
+}
@@ -16,6 +16,10 @@ import (
 // the error is retryable; it should return a positive duration to retry after,
 // or 0 (or negative) to indicate a non-retryable error.
 //
+// If ctx carries a RetryBudget (see WithRetryBudget), every retry consumes one
+// unit of it, and once it is spent retryable errors fail fast with
+// ErrRetryBudgetExhausted.
+//
 // This keeps the limiter package free of slack-go dependencies — the caller
 // provides the retry classification logic via the retryAfter callback.
 //
@@ -65,6 +69,11 @@ func CallWithRetry[T any](
 			return result, err
 		}
 
+		// Retries are also capped across all calls sharing the context.
+		if budget := RetryBudgetFromContext(ctx); budget != nil && !budget.take() {
+			return result, fmt.Errorf("%w: %w", ErrRetryBudgetExhausted, err)
+		}
+
 		// Sleep for the backoff duration, then retry.
 		select {
 		case <-ctx.Done():
@@ -168,3 +168,46 @@ func TestCallWithRetry_RetryThenSuccess(t *testing.T) {
 	assert.Equal(t, 2, callCount)
 	assert.GreaterOrEqual(t, elapsed, 50*time.Millisecond, "should have slept for retryAfter duration")
 }
+
+func TestCallWithRetry_RetryBudgetCapsTotalRetries(t *testing.T) {
+	rl := rate.NewLimiter(rate.Inf, 1)
+	budget := NewRetryBudget(3)
+	ctx := WithRetryBudget(context.Background(), budget)
+
+	callCount := 0
+	var errs []error
+	for i := 0; i < 5; i++ {
+		_, err := CallWithRetry(ctx, rl, 2, testRetryAfter, func() (string, error) {
+			callCount++
+			return "", &retryableError{retryAfter: time.Millisecond}
+		})
+		errs = append(errs, err)
+	}
+
+	// 5 initial calls plus 3 retries in total, instead of 5 * 3 attempts
+	assert.Equal(t, 8, callCount)
+	assert.Equal(t, 0, budget.Remaining())
+
+	var re *retryableError
+	assert.ErrorAs(t, errs[0], &re, "first call spends two retries and fails as usual")
+	assert.NotErrorIs(t, errs[0], ErrRetryBudgetExhausted)
+	for _, err := range errs[1:] {
+		assert.ErrorIs(t, err, ErrRetryBudgetExhausted)
+		assert.ErrorAs(t, err, &re, "the rate limit error is kept in the chain")
+	}
+}
+
+func TestCallWithRetry_RetryBudgetNotUsedOnSuccess(t *testing.T) {
+	rl := rate.NewLimiter(rate.Inf, 1)
+	budget := NewRetryBudget(1)
+	ctx := WithRetryBudget(context.Background(), budget)
+
+	for i := 0; i < 3; i++ {
+		_, err := CallWithRetry(ctx, rl, 2, testRetryAfter, func() (string, error) {
+			return "ok", nil
+		})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, budget.Remaining())
+	assert.Nil(t, RetryBudgetFromContext(context.Background()))
+}
@@ -0,0 +1,170 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Recorder is the set of hooks the server and provider use to report activity.
+type Recorder interface {
+	ObserveToolCall(tool string, duration time.Duration, failed bool)
+	IncSlackAPICall(method string)
+}
+
+// DefaultBuckets are the upper bounds, in seconds, of the tool latency histogram.
+var DefaultBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Default is the process-wide registry served on SLACK_MCP_METRICS_ADDR.
+var Default = New()
+
+type histogram struct {
+	counts []uint64 // per bucket, non-cumulative
+	count  uint64
+	sum    float64
+}
+
+// Metrics holds per-tool counters and latency histograms plus a Slack API
+// call counter. It is safe for concurrent use.
+type Metrics struct {
+	mu        sync.Mutex
+	buckets   []float64
+	calls     map[string]uint64
+	errors    map[string]uint64
+	durations map[string]*histogram
+	apiCalls  map[string]uint64
+}
+
+// New returns an empty registry using DefaultBuckets.
+func New() *Metrics {
+	return &Metrics{
+		buckets:   DefaultBuckets,
+		calls:     make(map[string]uint64),
+		errors:    make(map[string]uint64),
+		durations: make(map[string]*histogram),
+		apiCalls:  make(map[string]uint64),
+	}
+}
+
+// ObserveToolCall records one invocation of tool.
+func (m *Metrics) ObserveToolCall(tool string, duration time.Duration, failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls[tool]++
+	if failed {
+		m.errors[tool]++
+	}
+
+	h, ok := m.durations[tool]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(m.buckets))}
+		m.durations[tool] = h
+	}
+	secs := duration.Seconds()
+	for i, le := range m.buckets {
+		if secs <= le {
+			h.counts[i]++
+			break
+		}
+	}
+	h.count++
+	h.sum += secs
+}
+
+// IncSlackAPICall counts one HTTP request to the Slack API method.
+func (m *Metrics) IncSlackAPICall(method string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.apiCalls[method]++
+}
+
+// ToolCalls returns how many times tool has been called.
+func (m *Metrics) ToolCalls(tool string) uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls[tool]
+}
+
+// ToolErrors returns how many calls of tool have failed.
+func (m *Metrics) ToolErrors(tool string) uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.errors[tool]
+}
+
+// SlackAPICalls returns how many requests have been made to the Slack API method.
+func (m *Metrics) SlackAPICalls(method string) uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.apiCalls[method]
+}
+
+// WriteText writes all metrics in the Prometheus text exposition format.
+func (m *Metrics) WriteText(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+
+	b.WriteString("# HELP slack_mcp_tool_calls_total Number of tool calls handled.\n")
+	b.WriteString("# TYPE slack_mcp_tool_calls_total counter\n")
+	for _, tool := range sortedKeys(m.calls) {
+		fmt.Fprintf(&b, "slack_mcp_tool_calls_total{tool=%q} %d\n", tool, m.calls[tool])
+	}
+
+	b.WriteString("# HELP slack_mcp_tool_errors_total Number of tool calls that returned an error.\n")
+	b.WriteString("# TYPE slack_mcp_tool_errors_total counter\n")
+	for _, tool := range sortedKeys(m.errors) {
+		fmt.Fprintf(&b, "slack_mcp_tool_errors_total{tool=%q} %d\n", tool, m.errors[tool])
+	}
+
+	b.WriteString("# HELP slack_mcp_tool_duration_seconds Tool call latency.\n")
+	b.WriteString("# TYPE slack_mcp_tool_duration_seconds histogram\n")
+	tools := make([]string, 0, len(m.durations))
+	for tool := range m.durations {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+	for _, tool := range tools {
+		h := m.durations[tool]
+		var cumulative uint64
+		for i, le := range m.buckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(&b, "slack_mcp_tool_duration_seconds_bucket{tool=%q,le=\"%g\"} %d\n", tool, le, cumulative)
+		}
+		fmt.Fprintf(&b, "slack_mcp_tool_duration_seconds_bucket{tool=%q,le=\"+Inf\"} %d\n", tool, h.count)
+		fmt.Fprintf(&b, "slack_mcp_tool_duration_seconds_sum{tool=%q} %g\n", tool, h.sum)
+		fmt.Fprintf(&b, "slack_mcp_tool_duration_seconds_count{tool=%q} %d\n", tool, h.count)
+	}
+
+	b.WriteString("# HELP slack_mcp_slack_api_calls_total Number of HTTP requests made to the Slack API.\n")
+	b.WriteString("# TYPE slack_mcp_slack_api_calls_total counter\n")
+	for _, method := range sortedKeys(m.apiCalls) {
+		fmt.Fprintf(&b, "slack_mcp_slack_api_calls_total{method=%q} %d\n", method, m.apiCalls[method])
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Handler serves the registry for Prometheus scraping.
+func (m *Metrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = m.WriteText(w)
+	})
+}
+
+func sortedKeys(m map[string]uint64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsWriteText(t *testing.T) {
+	m := New()
+	m.ObserveToolCall("channels_list", 30*time.Millisecond, false)
+	m.ObserveToolCall("channels_list", 2*time.Second, true)
+	m.IncSlackAPICall("conversations.list")
+
+	var buf bytes.Buffer
+	require.NoError(t, m.WriteText(&buf))
+	out := buf.String()
+
+	assert.Contains(t, out, "# TYPE slack_mcp_tool_calls_total counter\n")
+	assert.Contains(t, out, `slack_mcp_tool_calls_total{tool="channels_list"} 2`)
+	assert.Contains(t, out, `slack_mcp_tool_errors_total{tool="channels_list"} 1`)
+	assert.Contains(t, out, `slack_mcp_tool_duration_seconds_bucket{tool="channels_list",le="0.05"} 1`)
+	assert.Contains(t, out, `slack_mcp_tool_duration_seconds_bucket{tool="channels_list",le="1"} 1`)
+	assert.Contains(t, out, `slack_mcp_tool_duration_seconds_bucket{tool="channels_list",le="2.5"} 2`)
+	assert.Contains(t, out, `slack_mcp_tool_duration_seconds_bucket{tool="channels_list",le="+Inf"} 2`)
+	assert.Contains(t, out, `slack_mcp_tool_duration_seconds_count{tool="channels_list"} 2`)
+	assert.Contains(t, out, `slack_mcp_slack_api_calls_total{method="conversations.list"} 1`)
+}
+
+func TestMetricsHandler(t *testing.T) {
+	m := New()
+	m.ObserveToolCall("users_search", time.Millisecond, false)
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, 200, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+	assert.Contains(t, rec.Body.String(), `slack_mcp_tool_calls_total{tool="users_search"} 1`)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -15,8 +16,10 @@ import (
 	"time"
 
 	"github.com/korotovsky/slack-mcp-server/pkg/limiter"
+	"github.com/korotovsky/slack-mcp-server/pkg/metrics"
 	"github.com/korotovsky/slack-mcp-server/pkg/provider/edge"
 	"github.com/korotovsky/slack-mcp-server/pkg/transport"
+	edgeslack "github.com/rusq/slack"
 	"github.com/rusq/slackdump/v3/auth"
 	"github.com/slack-go/slack"
 	"go.uber.org/zap"
@@ -172,20 +175,51 @@ type Channel struct {
 	IsIM        bool     `json:"im"`
 	IsPrivate   bool     `json:"private"`
 	IsExtShared bool     `json:"is_ext_shared"`     // Shared with external organizations
+	IsArchived  bool     `json:"is_archived,omitempty"`
+	IsMember    *bool    `json:"is_member,omitempty"`  // The authenticated user is a member, nil when unknown
 	User        string   `json:"user,omitempty"`    // User ID for IM channels
 	Members     []string `json:"members,omitempty"` // Member IDs for the channel
 }
 
+// Member reports whether the authenticated user is a member of the channel and
+// whether the cache knows it at all. DMs and group DMs always count as joined.
+func (c Channel) Member() (member, known bool) {
+	if c.IsIM || c.IsMpIM {
+		return true, true
+	}
+	if c.IsMember == nil {
+		return false, false
+	}
+	return *c.IsMember, true
+}
+
 type SlackAPI interface {
 	// Standard slack-go API methods
 	AuthTest() (*slack.AuthTestResponse, error)
 	AuthTestContext(ctx context.Context) (*slack.AuthTestResponse, error)
+	GetTeamInfoContext(ctx context.Context) (*slack.TeamInfo, error)
+	GetTeamProfileContext(ctx context.Context, teamID ...string) (*slack.TeamProfile, error)
+	GetUserProfileContext(ctx context.Context, params *slack.GetUserProfileParameters) (*slack.UserProfile, error)
+	GetUserByEmailContext(ctx context.Context, email string) (*slack.User, error)
 	GetUsersContext(ctx context.Context, options ...slack.GetUsersOption) ([]slack.User, error)
 	GetUsersInfo(users ...string) (*[]slack.User, error)
 	PostMessageContext(ctx context.Context, channel string, options ...slack.MsgOption) (string, string, error)
+	JoinConversationContext(ctx context.Context, channelID string) (*slack.Channel, string, []string, error)
+	InviteUsersToConversationContext(ctx context.Context, channelID string, users ...string) (*slack.Channel, error)
+	CreateConversationContext(ctx context.Context, params slack.CreateConversationParams) (*slack.Channel, error)
+	SetTopicOfConversationContext(ctx context.Context, channelID, topic string) (*slack.Channel, error)
+	SetPurposeOfConversationContext(ctx context.Context, channelID, purpose string) (*slack.Channel, error)
+	ArchiveConversationContext(ctx context.Context, channelID string) error
+	UnArchiveConversationContext(ctx context.Context, channelID string) error
 	MarkConversationContext(ctx context.Context, channel, ts string) error
+	CloseConversationContext(ctx context.Context, channelID string) (bool, bool, error)
+	GetEmojiContext(ctx context.Context) (map[string]string, error)
 	AddReactionContext(ctx context.Context, name string, item slack.ItemRef) error
+	AddStarContext(ctx context.Context, channel string, item slack.ItemRef) error
+	GetBotInfoContext(ctx context.Context, parameters slack.GetBotInfoParameters) (*slack.Bot, error)
+	ListStarsContext(ctx context.Context, params slack.StarsParameters) ([]slack.Item, *slack.Paging, error)
 	RemoveReactionContext(ctx context.Context, name string, item slack.ItemRef) error
+	GetReactionsContext(ctx context.Context, item slack.ItemRef, params slack.GetReactionsParameters) ([]slack.ItemReaction, error)
 
 	// Used to get messages
 	GetConversationHistoryContext(ctx context.Context, params *slack.GetConversationHistoryParameters) (*slack.GetConversationHistoryResponse, error)
@@ -195,9 +229,13 @@ type SlackAPI interface {
 	// Used to get files
 	GetFileInfoContext(ctx context.Context, fileID string, count, page int) (*slack.File, []slack.Comment, *slack.Paging, error)
 	GetFileContext(ctx context.Context, downloadURL string, writer io.Writer) error
+	GetFilesContext(ctx context.Context, params slack.GetFilesParameters) ([]slack.File, *slack.Paging, error)
+	ListPinsContext(ctx context.Context, channel string) ([]slack.Item, *slack.Paging, error)
 
 	// Used to get channel info (for unread counts with xoxp tokens)
 	GetConversationInfoContext(ctx context.Context, input *slack.GetConversationInfoInput) (*slack.Channel, error)
+	GetCallContext(ctx context.Context, callID string) (slack.Call, error)
+	GetUsersInConversationContext(ctx context.Context, params *slack.GetUsersInConversationParameters) ([]string, string, error)
 
 	// Used to get channels list from both Slack and Enterprise Grid versions
 	GetConversationsContext(ctx context.Context, params *slack.GetConversationsParameters) ([]slack.Channel, string, error)
@@ -207,12 +245,21 @@ type SlackAPI interface {
 	// non-member public channels and closed DMs that cannot have unreads.
 	GetConversationsForUserContext(ctx context.Context, params *slack.GetConversationsForUserParameters) ([]slack.Channel, string, error)
 
+	// Used to list archived channels, which are excluded from the channels cache.
+	GetArchivedChannelsContext(ctx context.Context) ([]slack.Channel, error)
+
 	// Edge API methods
 	ClientUserBoot(ctx context.Context) (*edge.ClientUserBootResponse, error)
 	UsersSearch(ctx context.Context, query string, count int) ([]slack.User, error)
 	ClientCounts(ctx context.Context) (edge.ClientCountsResponse, error)
+	ActivityFeed(ctx context.Context, types []string, limit int, cursor string) (edge.ActivityFeedResponse, error)
 	GetMutedChannels(ctx context.Context) (map[string]bool, error)
 
+	// Rate limiting observed on responses of any of the above
+	RateLimitStatus(now time.Time) RateLimitStatus
+	// OAuth scopes reported on responses of any of the above
+	GrantedScopes() ([]string, bool)
+
 	// User groups API methods
 	GetUserGroupsContext(ctx context.Context, options ...slack.GetUserGroupsOption) ([]slack.UserGroup, error)
 	GetUserGroupMembersContext(ctx context.Context, userGroup string, options ...slack.GetUserGroupMembersOption) ([]string, error)
@@ -233,6 +280,9 @@ type MCPSlackClient struct {
 	isBotToken    bool
 	edgeFailed    bool // set when edge API fails; subsequent calls skip straight to standard API
 	teamEndpoint  string
+
+	rateLimits *rateLimitTracker
+	scopes     *grantedScopes
 }
 
 type ApiProvider struct {
@@ -257,10 +307,25 @@ type ApiProvider struct {
 	channelsReady             bool
 	lastForcedChannelsRefresh time.Time
 	channelsMu                sync.RWMutex // protects channelsReady, lastForcedChannelsRefresh
+
+	// Custom emoji catalog, fetched on first use and refreshed after emojiTTL
+	emojiCatalog ttlValue[map[string]string]
+
+	// Custom profile field definitions by field ID, fetched on first use and
+	// refreshed after profileFieldsTTL
+	profileFields ttlValue[map[string]slack.TeamProfileField]
+
+	// Bot names by bot ID, fetched via bots.info on first use
+	bots botInfoCache
 }
 
 func NewMCPSlackClient(authProvider auth.Provider, logger *zap.Logger) (*MCPSlackClient, error) {
 	httpClient := transport.ProvideHTTPClient(authProvider.Cookies(), logger)
+	rateLimits := &rateLimitTracker{}
+	httpClient.Transport = &rateLimitRecorder{next: httpClient.Transport, tracker: rateLimits}
+	scopes := &grantedScopes{}
+	httpClient.Transport = &scopeRecorder{next: httpClient.Transport, scopes: scopes}
+	httpClient.Transport = &apiCallCounter{next: httpClient.Transport, rec: metrics.Default}
 
 	slackOpts := []slack.Option{slack.OptionHTTPClient(httpClient)}
 	if os.Getenv("SLACK_MCP_GOVSLACK") == "true" {
@@ -268,10 +333,14 @@ func NewMCPSlackClient(authProvider auth.Provider, logger *zap.Logger) (*MCPSlac
 	}
 	slackClient := slack.New(authProvider.SlackToken(), slackOpts...)
 
+	// auth.test doubles as the probe that records the scopes of OAuth tokens
 	authResp, err := slackClient.AuthTest()
 	if err != nil {
 		return nil, err
 	}
+	if granted, ok := scopes.list(); ok {
+		logger.Debug("Detected token scopes", zap.Strings("scopes", granted))
+	}
 
 	authResponse := &slack.AuthTestResponse{
 		URL:          authResp.URL,
@@ -304,6 +373,17 @@ func NewMCPSlackClient(authProvider auth.Provider, logger *zap.Logger) (*MCPSlac
 	isOAuth := strings.HasPrefix(token, "xoxp-") || strings.HasPrefix(token, "xoxb-")
 	isBotToken := strings.HasPrefix(token, "xoxb-")
 
+	tokenConfig := detectTokenConfig(token, isEnterprise)
+	logger.Info("Detected Slack token configuration",
+		zap.String("context", "console"),
+		zap.String("token_type", tokenConfig.TokenType),
+		zap.Bool("enterprise", tokenConfig.Enterprise),
+		zap.String("api", tokenConfig.API),
+	)
+	for _, w := range tokenConfig.Warnings {
+		logger.Warn(w, zap.String("context", "console"))
+	}
+
 	return &MCPSlackClient{
 		slackClient:  slackClient,
 		edgeClient:   edgeClient,
@@ -313,9 +393,63 @@ func NewMCPSlackClient(authProvider auth.Provider, logger *zap.Logger) (*MCPSlac
 		isOAuth:      isOAuth,
 		isBotToken:   isBotToken,
 		teamEndpoint: authResp.URL,
+		rateLimits:   rateLimits,
+		scopes:       scopes,
 	}, nil
 }
 
+const (
+	TokenAPIStandard = "standard"
+	TokenAPIEdge     = "edge+standard"
+)
+
+// TokenConfig describes the detected token type and which Slack APIs the
+// client will use with it.
+type TokenConfig struct {
+	TokenType  string
+	Enterprise bool
+	API        string
+	Warnings   []string
+}
+
+// detectTokenConfig decides between the standard Web API and the edge API
+// based on the token prefix and whether the workspace is an Enterprise Grid.
+func detectTokenConfig(token string, enterprise bool) TokenConfig {
+	cfg := TokenConfig{Enterprise: enterprise}
+
+	switch {
+	case strings.HasPrefix(token, "xoxp-"):
+		cfg.TokenType = "xoxp"
+		cfg.API = TokenAPIStandard
+		if enterprise {
+			cfg.Warnings = append(cfg.Warnings,
+				"User OAuth token (xoxp) is used in an Enterprise Grid workspace: the edge API is not used, "+
+					"so channel listing and unreads rely on the standard API and may only cover the token's team. "+
+					"Use SLACK_MCP_XOXC_TOKEN/SLACK_MCP_XOXD_TOKEN if channels or unreads are missing.")
+		}
+	case strings.HasPrefix(token, "xoxb-"):
+		cfg.TokenType = "xoxb"
+		cfg.API = TokenAPIStandard
+		cfg.Warnings = append(cfg.Warnings,
+			"Bot token (xoxb) is used: search, recent activity and unreads are unavailable and only channels the bot was invited to are visible.")
+		if enterprise {
+			cfg.Warnings = append(cfg.Warnings,
+				"Bot token (xoxb) is used in an Enterprise Grid workspace: the edge API is not used, "+
+					"so only channels of the bot's team are visible.")
+		}
+	case strings.HasPrefix(token, "xoxc-"):
+		cfg.TokenType = "xoxc"
+		cfg.API = TokenAPIEdge
+	default:
+		cfg.TokenType = "unknown"
+		cfg.API = TokenAPIEdge
+		cfg.Warnings = append(cfg.Warnings,
+			"Unrecognized Slack token prefix, expected xoxp-, xoxb- or xoxc-. Falling back to the edge API.")
+	}
+
+	return cfg
+}
+
 func (c *MCPSlackClient) AuthTest() (*slack.AuthTestResponse, error) {
 	if os.Getenv("SLACK_MCP_XOXP_TOKEN") == "demo" || (os.Getenv("SLACK_MCP_XOXC_TOKEN") == "demo" && os.Getenv("SLACK_MCP_XOXD_TOKEN") == "demo") {
 		return &slack.AuthTestResponse{
@@ -340,6 +474,22 @@ func (c *MCPSlackClient) AuthTestContext(ctx context.Context) (*slack.AuthTestRe
 	return c.slackClient.AuthTestContext(ctx)
 }
 
+func (c *MCPSlackClient) GetTeamInfoContext(ctx context.Context) (*slack.TeamInfo, error) {
+	return c.slackClient.GetTeamInfoContext(ctx)
+}
+
+func (c *MCPSlackClient) GetTeamProfileContext(ctx context.Context, teamID ...string) (*slack.TeamProfile, error) {
+	return c.slackClient.GetTeamProfileContext(ctx, teamID...)
+}
+
+func (c *MCPSlackClient) GetUserProfileContext(ctx context.Context, params *slack.GetUserProfileParameters) (*slack.UserProfile, error) {
+	return c.slackClient.GetUserProfileContext(ctx, params)
+}
+
+func (c *MCPSlackClient) GetUserByEmailContext(ctx context.Context, email string) (*slack.User, error) {
+	return c.slackClient.GetUserByEmailContext(ctx, email)
+}
+
 func (c *MCPSlackClient) GetUsersContext(ctx context.Context, options ...slack.GetUsersOption) ([]slack.User, error) {
 	return c.slackClient.GetUsersContext(ctx, options...)
 }
@@ -352,6 +502,14 @@ func (c *MCPSlackClient) MarkConversationContext(ctx context.Context, channel, t
 	return c.slackClient.MarkConversationContext(ctx, channel, ts)
 }
 
+func (c *MCPSlackClient) CloseConversationContext(ctx context.Context, channelID string) (bool, bool, error) {
+	return c.slackClient.CloseConversationContext(ctx, channelID)
+}
+
+func (c *MCPSlackClient) GetEmojiContext(ctx context.Context) (map[string]string, error) {
+	return c.slackClient.GetEmojiContext(ctx)
+}
+
 func (c *MCPSlackClient) GetConversationsContext(ctx context.Context, params *slack.GetConversationsParameters) ([]slack.Channel, string, error) {
 	// Please see https://github.com/korotovsky/slack-mcp-server/issues/73
 	// It seems that `conversations.list` works with `xoxp` tokens within Enterprise Grid setups
@@ -386,34 +544,7 @@ func (c *MCPSlackClient) GetConversationsContext(ctx context.Context, params *sl
 					continue
 				}
 				seen[ec.ID] = struct{}{}
-				channels = append(channels, slack.Channel{
-					IsGeneral: ec.IsGeneral,
-					GroupConversation: slack.GroupConversation{
-						Conversation: slack.Conversation{
-							ID:                 ec.ID,
-							IsIM:               ec.IsIM,
-							IsMpIM:             ec.IsMpIM,
-							IsPrivate:          ec.IsPrivate,
-							Created:            slack.JSONTime(ec.Created.Time().UnixMilli()),
-							Unlinked:           ec.Unlinked,
-							NameNormalized:     ec.NameNormalized,
-							IsShared:           ec.IsShared,
-							IsExtShared:        ec.IsExtShared,
-							IsOrgShared:        ec.IsOrgShared,
-							IsPendingExtShared: ec.IsPendingExtShared,
-							NumMembers:         ec.NumMembers,
-						},
-						Name:       ec.Name,
-						IsArchived: ec.IsArchived,
-						Members:    ec.Members,
-						Topic: slack.Topic{
-							Value: ec.Topic.Value,
-						},
-						Purpose: slack.Purpose{
-							Value: ec.Purpose.Value,
-						},
-					},
-				})
+				channels = append(channels, edgeChannelToSlack(ec))
 			}
 
 			// Supplement with ALL pages from the standard API to fill gaps
@@ -453,6 +584,96 @@ func (c *MCPSlackClient) GetConversationsContext(ctx context.Context, params *sl
 	return c.slackClient.GetConversationsContext(ctx, params)
 }
 
+func (c *MCPSlackClient) GetArchivedChannelsContext(ctx context.Context) ([]slack.Channel, error) {
+	// Browser session tokens can query the channel browser directly for
+	// archived channels, everything else has to walk conversations.list.
+	if !c.isOAuth {
+		edgeChannels, err := c.edgeClient.SearchArchivedChannels(ctx, "")
+		if err != nil {
+			return nil, err
+		}
+		channels := make([]slack.Channel, 0, len(edgeChannels))
+		for _, ec := range edgeChannels {
+			channels = append(channels, edgeChannelToSlack(ec))
+		}
+		return channels, nil
+	}
+
+	params := &slack.GetConversationsParameters{
+		Types:           []string{PubChanType, PrivateChanType},
+		Limit:           999,
+		ExcludeArchived: false,
+	}
+	var channels []slack.Channel
+	for {
+		page, err := limiter.CallWithRetry(ctx, limiter.Tier2.Limiter(), 2, SlackRetryAfter, func() (conversationsPage, error) {
+			channels, next, err := c.slackClient.GetConversationsContext(ctx, params)
+			return conversationsPage{channels: channels, next: next}, err
+		})
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, archivedOnly(page.channels)...)
+		if page.next == "" {
+			break
+		}
+		params.Cursor = page.next
+	}
+	return channels, nil
+}
+
+// conversationsPage is a page of conversations.list results, so a page can be
+// fetched through limiter.CallWithRetry
+type conversationsPage struct {
+	channels []slack.Channel
+	next     string
+}
+
+// archivedOnly keeps only archived channels from a conversations.list page.
+func archivedOnly(channels []slack.Channel) []slack.Channel {
+	var res []slack.Channel
+	for _, c := range channels {
+		if c.IsArchived {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// edgeChannelToSlack converts a channel returned by the edge API into the
+// slack-go representation used by the rest of the server.
+func edgeChannelToSlack(ec edgeslack.Channel) slack.Channel {
+	return slack.Channel{
+		IsGeneral: ec.IsGeneral,
+		IsMember:  ec.IsMember,
+		GroupConversation: slack.GroupConversation{
+			Conversation: slack.Conversation{
+				ID:                 ec.ID,
+				IsIM:               ec.IsIM,
+				IsMpIM:             ec.IsMpIM,
+				IsPrivate:          ec.IsPrivate,
+				Created:            slack.JSONTime(ec.Created.Time().UnixMilli()),
+				Unlinked:           ec.Unlinked,
+				NameNormalized:     ec.NameNormalized,
+				IsShared:           ec.IsShared,
+				IsExtShared:        ec.IsExtShared,
+				IsOrgShared:        ec.IsOrgShared,
+				IsPendingExtShared: ec.IsPendingExtShared,
+				NumMembers:         ec.NumMembers,
+			},
+			Name:       ec.Name,
+			IsArchived: ec.IsArchived,
+			Members:    ec.Members,
+			Topic: slack.Topic{
+				Value: ec.Topic.Value,
+			},
+			Purpose: slack.Purpose{
+				Value: ec.Purpose.Value,
+			},
+		},
+	}
+}
+
 func (c *MCPSlackClient) GetConversationsForUserContext(ctx context.Context, params *slack.GetConversationsForUserParameters) ([]slack.Channel, string, error) {
 	return c.slackClient.GetConversationsForUserContext(ctx, params)
 }
@@ -473,14 +694,58 @@ func (c *MCPSlackClient) PostMessageContext(ctx context.Context, channelID strin
 	return c.slackClient.PostMessageContext(ctx, channelID, options...)
 }
 
+func (c *MCPSlackClient) JoinConversationContext(ctx context.Context, channelID string) (*slack.Channel, string, []string, error) {
+	return c.slackClient.JoinConversationContext(ctx, channelID)
+}
+
+func (c *MCPSlackClient) InviteUsersToConversationContext(ctx context.Context, channelID string, users ...string) (*slack.Channel, error) {
+	return c.slackClient.InviteUsersToConversationContext(ctx, channelID, users...)
+}
+
+func (c *MCPSlackClient) CreateConversationContext(ctx context.Context, params slack.CreateConversationParams) (*slack.Channel, error) {
+	return c.slackClient.CreateConversationContext(ctx, params)
+}
+
+func (c *MCPSlackClient) SetTopicOfConversationContext(ctx context.Context, channelID, topic string) (*slack.Channel, error) {
+	return c.slackClient.SetTopicOfConversationContext(ctx, channelID, topic)
+}
+
+func (c *MCPSlackClient) SetPurposeOfConversationContext(ctx context.Context, channelID, purpose string) (*slack.Channel, error) {
+	return c.slackClient.SetPurposeOfConversationContext(ctx, channelID, purpose)
+}
+
+func (c *MCPSlackClient) ArchiveConversationContext(ctx context.Context, channelID string) error {
+	return c.slackClient.ArchiveConversationContext(ctx, channelID)
+}
+
+func (c *MCPSlackClient) UnArchiveConversationContext(ctx context.Context, channelID string) error {
+	return c.slackClient.UnArchiveConversationContext(ctx, channelID)
+}
+
 func (c *MCPSlackClient) AddReactionContext(ctx context.Context, name string, item slack.ItemRef) error {
 	return c.slackClient.AddReactionContext(ctx, name, item)
 }
 
+func (c *MCPSlackClient) AddStarContext(ctx context.Context, channel string, item slack.ItemRef) error {
+	return c.slackClient.AddStarContext(ctx, channel, item)
+}
+
+func (c *MCPSlackClient) GetBotInfoContext(ctx context.Context, parameters slack.GetBotInfoParameters) (*slack.Bot, error) {
+	return c.slackClient.GetBotInfoContext(ctx, parameters)
+}
+
+func (c *MCPSlackClient) ListStarsContext(ctx context.Context, params slack.StarsParameters) ([]slack.Item, *slack.Paging, error) {
+	return c.slackClient.ListStarsContext(ctx, params)
+}
+
 func (c *MCPSlackClient) RemoveReactionContext(ctx context.Context, name string, item slack.ItemRef) error {
 	return c.slackClient.RemoveReactionContext(ctx, name, item)
 }
 
+func (c *MCPSlackClient) GetReactionsContext(ctx context.Context, item slack.ItemRef, params slack.GetReactionsParameters) ([]slack.ItemReaction, error) {
+	return c.slackClient.GetReactionsContext(ctx, item, params)
+}
+
 func (c *MCPSlackClient) GetFileInfoContext(ctx context.Context, fileID string, count, page int) (*slack.File, []slack.Comment, *slack.Paging, error) {
 	return c.slackClient.GetFileInfoContext(ctx, fileID, count, page)
 }
@@ -489,10 +754,26 @@ func (c *MCPSlackClient) GetFileContext(ctx context.Context, downloadURL string,
 	return c.slackClient.GetFileContext(ctx, downloadURL, writer)
 }
 
+func (c *MCPSlackClient) GetFilesContext(ctx context.Context, params slack.GetFilesParameters) ([]slack.File, *slack.Paging, error) {
+	return c.slackClient.GetFilesContext(ctx, params)
+}
+
+func (c *MCPSlackClient) ListPinsContext(ctx context.Context, channel string) ([]slack.Item, *slack.Paging, error) {
+	return c.slackClient.ListPinsContext(ctx, channel)
+}
+
 func (c *MCPSlackClient) GetConversationInfoContext(ctx context.Context, input *slack.GetConversationInfoInput) (*slack.Channel, error) {
 	return c.slackClient.GetConversationInfoContext(ctx, input)
 }
 
+func (c *MCPSlackClient) GetCallContext(ctx context.Context, callID string) (slack.Call, error) {
+	return c.slackClient.GetCallContext(ctx, callID)
+}
+
+func (c *MCPSlackClient) GetUsersInConversationContext(ctx context.Context, params *slack.GetUsersInConversationParameters) ([]string, string, error) {
+	return c.slackClient.GetUsersInConversationContext(ctx, params)
+}
+
 func (c *MCPSlackClient) ClientUserBoot(ctx context.Context) (*edge.ClientUserBootResponse, error) {
 	return c.edgeClient.ClientUserBoot(ctx)
 }
@@ -505,10 +786,28 @@ func (c *MCPSlackClient) ClientCounts(ctx context.Context) (edge.ClientCountsRes
 	return c.edgeClient.ClientCounts(ctx)
 }
 
+func (c *MCPSlackClient) ActivityFeed(ctx context.Context, types []string, limit int, cursor string) (edge.ActivityFeedResponse, error) {
+	return c.edgeClient.ActivityFeed(ctx, types, limit, cursor)
+}
+
 func (c *MCPSlackClient) GetMutedChannels(ctx context.Context) (map[string]bool, error) {
 	return c.edgeClient.GetMutedChannels(ctx)
 }
 
+func (c *MCPSlackClient) RateLimitStatus(now time.Time) RateLimitStatus {
+	if c == nil || c.rateLimits == nil {
+		return RateLimitStatus{}
+	}
+	return c.rateLimits.status(now)
+}
+
+func (c *MCPSlackClient) GrantedScopes() ([]string, bool) {
+	if c == nil || c.scopes == nil {
+		return nil, false
+	}
+	return c.scopes.list()
+}
+
 func (c *MCPSlackClient) GetUserGroupsContext(ctx context.Context, options ...slack.GetUserGroupsOption) ([]slack.UserGroup, error) {
 	return c.slackClient.GetUserGroupsContext(ctx, options...)
 }
@@ -1091,6 +1390,8 @@ func (ap *ApiProvider) getChannelsMultiType(ctx context.Context, channelTypes []
 				channel.IsExtShared,
 				ap.ProvideUsersMap().Users,
 			)
+			member := channel.IsMember || channel.IsIM || channel.IsMpIM
+			ch.IsMember = &member
 			chans = append(chans, ch)
 		}
 
@@ -1147,16 +1448,204 @@ func (ap *ApiProvider) GetChannels(ctx context.Context, channelTypes []string) [
 	return res
 }
 
+// GetArchivedChannels fetches archived public and private channels. Archived
+// channels are not part of the channels cache, so this always calls Slack.
+func (ap *ApiProvider) GetArchivedChannels(ctx context.Context) ([]Channel, error) {
+	if err := ap.rateLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	channels, err := ap.client.GetArchivedChannelsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ap.logger.Debug("Fetched archived channels", zap.Int("count", len(channels)))
+
+	usersMap := ap.ProvideUsersMap().Users
+	chans := make([]Channel, 0, len(channels))
+	for _, channel := range channels {
+		chans = append(chans, mapChannel(
+			channel.ID,
+			channel.Name,
+			channel.NameNormalized,
+			channel.Topic.Value,
+			channel.Purpose.Value,
+			channel.User,
+			channel.Members,
+			channel.NumMembers,
+			channel.IsIM,
+			channel.IsMpIM,
+			channel.IsPrivate,
+			channel.IsExtShared,
+			usersMap,
+		))
+	}
+	return chans, nil
+}
+
 func (ap *ApiProvider) ProvideUsersMap() *UsersCache {
 	// Atomic load - no lock needed, snapshot is immutable
 	return ap.usersSnapshot.Load()
 }
 
+// emojiTTL is how long the custom emoji catalog is cached
+const emojiTTL = time.Hour
+
+// ProvideEmojiCatalog returns the workspace custom emoji (name to image URL or
+// "alias:name"). It is fetched via emoji.list, paced by the provider's Tier 2
+// limiter, and cached for emojiTTL. On failure an empty catalog is returned, so
+// callers degrade to leaving shortcodes untouched, and emoji.list is not called
+// again for failedFetchBackoff.
+func (ap *ApiProvider) ProvideEmojiCatalog(ctx context.Context) map[string]string {
+	catalog, err := ap.emojiCatalog.get(ctx, emojiTTL, failedFetchBackoff, time.Now(), func(ctx context.Context) (map[string]string, error) {
+		catalog, err := limiter.CallWithRetry(ctx, ap.rateLimiter, 2, SlackRetryAfter, func() (map[string]string, error) {
+			return ap.client.GetEmojiContext(ctx)
+		})
+		if err != nil {
+			return nil, err
+		}
+		ap.logger.Debug("Loaded custom emoji catalog", zap.Int("count", len(catalog)))
+		return catalog, nil
+	})
+	if err != nil {
+		ap.logger.Warn("Failed to fetch custom emoji catalog, emoji normalization limited to standard emoji",
+			zap.Error(err))
+		return map[string]string{}
+	}
+	return catalog
+}
+
+// SlackRetryAfter returns how long to wait before retrying a rate limited
+// Slack call, or 0 when err is not worth retrying. It is the retryAfter
+// callback of limiter.CallWithRetry for slack-go calls.
+func SlackRetryAfter(err error) time.Duration {
+	var rle *slack.RateLimitedError
+	if errors.As(err, &rle) {
+		return rle.RetryAfter
+	}
+	return 0
+}
+
+// profileFieldsTTL is how long custom profile field definitions are cached
+const profileFieldsTTL = time.Hour
+
+// ProvideProfileFields returns the workspace's custom profile field definitions
+// by field ID. They are fetched via team.profile.get and cached for
+// profileFieldsTTL. On failure, e.g. without the users.profile:read scope, an
+// empty set is returned, so callers fall back to the labels returned with each
+// profile, and team.profile.get is not called again for failedFetchBackoff.
+func (ap *ApiProvider) ProvideProfileFields(ctx context.Context) map[string]slack.TeamProfileField {
+	fields, err := ap.profileFields.get(ctx, profileFieldsTTL, failedFetchBackoff, time.Now(), func(ctx context.Context) (map[string]slack.TeamProfileField, error) {
+		profile, err := ap.client.GetTeamProfileContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		fields := make(map[string]slack.TeamProfileField, len(profile.Fields))
+		for _, f := range profile.Fields {
+			fields[f.ID] = f
+		}
+		ap.logger.Debug("Loaded team profile fields", zap.Int("count", len(fields)))
+		return fields, nil
+	})
+	if err != nil {
+		ap.logger.Warn("Failed to fetch team profile fields, custom field labels limited to the user profile",
+			zap.Error(err))
+		return map[string]slack.TeamProfileField{}
+	}
+	return fields
+}
+
+// RateLimitStatus reports whether Slack is currently throttling this client,
+// based on the rate limited responses seen so far.
+func (ap *ApiProvider) RateLimitStatus() RateLimitStatus {
+	return ap.client.RateLimitStatus(time.Now())
+}
+
+// GrantedScopes returns the OAuth scopes of the token as reported by Slack in
+// the X-OAuth-Scopes header, captured from auth.test at startup and kept up to
+// date by later calls. ok is false when Slack never reported them, e.g. for
+// browser session tokens (xoxc/xoxd).
+func (ap *ApiProvider) GrantedScopes() ([]string, bool) {
+	return ap.client.GrantedScopes()
+}
+
+// ProvideBotName resolves a bot ID to the name of its app via bots.info.
+// Resolved names are cached for botInfoTTL. Lookups Slack rejected, e.g. with
+// bot_not_found or missing_scope, are not repeated for failedFetchBackoff,
+// while transient failures are retried.
+func (ap *ApiProvider) ProvideBotName(ctx context.Context, botID string) (string, bool) {
+	name := ap.bots.name(ctx, botID, botInfoTTL, time.Now(), func(ctx context.Context, botID string) (*slack.Bot, error) {
+		if err := ap.rateLimiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		return ap.client.GetBotInfoContext(ctx, slack.GetBotInfoParameters{Bot: botID})
+	}, ap.logger)
+	return name, name != ""
+}
+
 func (ap *ApiProvider) ProvideChannelsMaps() *ChannelsCache {
 	// Atomic load - no lock needed, snapshot is immutable
 	return ap.channelsSnapshot.Load()
 }
 
+// SetChannelArchived updates the archived flag of a cached channel after it was
+// archived or unarchived, so the cache reflects the change without a refresh.
+// Channels missing from the cache are left alone.
+func (ap *ApiProvider) SetChannelArchived(channelID string, archived bool) bool {
+	return ap.updateChannel(channelID, func(c *Channel) { c.IsArchived = archived })
+}
+
+// SetChannelMember records whether the authenticated user is a member of a
+// cached channel, e.g. after conversations.info told, so later checks need no
+// lookup. Channels missing from the cache are left alone.
+func (ap *ApiProvider) SetChannelMember(channelID string, member bool) bool {
+	return ap.updateChannel(channelID, func(c *Channel) { c.IsMember = &member })
+}
+
+func (ap *ApiProvider) updateChannel(channelID string, update func(c *Channel)) bool {
+	ap.channelsMu.Lock()
+	defer ap.channelsMu.Unlock()
+
+	updated, ok := withChannel(ap.channelsSnapshot.Load(), channelID, update)
+	if ok {
+		ap.channelsSnapshot.Store(updated)
+	}
+	return ok
+}
+
+// withChannelArchived returns a copy of cache with the archived flag of
+// channelID set.
+func withChannelArchived(cache *ChannelsCache, channelID string, archived bool) (*ChannelsCache, bool) {
+	return withChannel(cache, channelID, func(c *Channel) { c.IsArchived = archived })
+}
+
+// withChannel returns a copy of cache with update applied to channelID.
+// Snapshots are shared with concurrent readers, so cache itself is never
+// modified.
+func withChannel(cache *ChannelsCache, channelID string, update func(c *Channel)) (*ChannelsCache, bool) {
+	if cache == nil {
+		return nil, false
+	}
+	channel, ok := cache.Channels[channelID]
+	if !ok {
+		return cache, false
+	}
+
+	updated := &ChannelsCache{
+		Channels:    make(map[string]Channel, len(cache.Channels)),
+		ChannelsInv: make(map[string]string, len(cache.ChannelsInv)),
+	}
+	for id, c := range cache.Channels {
+		updated.Channels[id] = c
+	}
+	for name, id := range cache.ChannelsInv {
+		updated.ChannelsInv[name] = id
+	}
+	update(&channel)
+	updated.Channels[channelID] = channel
+	return updated, true
+}
+
 func (ap *ApiProvider) IsReady() (bool, error) {
 	if !ap.usersReady {
 		return false, ErrUsersNotReady
@@ -1167,6 +1656,11 @@ func (ap *ApiProvider) IsReady() (bool, error) {
 	return true, nil
 }
 
+// SyncState reports which caches have finished their initial sync.
+func (ap *ApiProvider) SyncState() (usersReady, channelsReady bool) {
+	return ap.usersReady, ap.channelsReady
+}
+
 func (ap *ApiProvider) ServerTransport() string {
 	return ap.transport
 }
@@ -1230,6 +1724,316 @@ func (ap *ApiProvider) searchUsersInCache(query string, limit int) ([]slack.User
 	return results, nil
 }
 
+// rateLimitRecentWindow is how far back rate limited responses count as recent
+const rateLimitRecentWindow = 5 * time.Minute
+
+// RateLimitStatus summarizes the rate limiting Slack applied to this client.
+type RateLimitStatus struct {
+	Throttled     bool          // the Retry-After of the last rate limited response has not passed yet
+	LastLimitedAt time.Time     // zero if no response was ever rate limited
+	RetryAfter    time.Duration // Retry-After of the last rate limited response
+	WaitFor       time.Duration // time left until RetryAfter passes
+	RecentCount   int           // rate limited responses within rateLimitRecentWindow
+}
+
+// rateLimitTracker remembers rate limited responses. The zero value is ready to use.
+type rateLimitTracker struct {
+	mu         sync.Mutex
+	limited    []time.Time
+	retryAfter time.Duration
+}
+
+func (t *rateLimitTracker) record(now time.Time, retryAfter time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.limited = append(t.pruneLocked(now), now)
+	t.retryAfter = retryAfter
+}
+
+func (t *rateLimitTracker) status(now time.Time) RateLimitStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.limited = t.pruneLocked(now)
+	if len(t.limited) == 0 {
+		return RateLimitStatus{}
+	}
+	last := t.limited[len(t.limited)-1]
+	st := RateLimitStatus{
+		LastLimitedAt: last,
+		RetryAfter:    t.retryAfter,
+		RecentCount:   len(t.limited),
+	}
+	if wait := last.Add(t.retryAfter).Sub(now); wait > 0 {
+		st.Throttled = true
+		st.WaitFor = wait
+	}
+	return st
+}
+
+// pruneLocked drops rate limited responses older than rateLimitRecentWindow
+func (t *rateLimitTracker) pruneLocked(now time.Time) []time.Time {
+	i := 0
+	for i < len(t.limited) && now.Sub(t.limited[i]) > rateLimitRecentWindow {
+		i++
+	}
+	return t.limited[i:]
+}
+
+// rateLimitRecorder is an http.RoundTripper that records HTTP 429 responses
+type rateLimitRecorder struct {
+	next    http.RoundTripper
+	tracker *rateLimitTracker
+}
+
+func (r *rateLimitRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := r.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	resp, err := next.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		var retryAfter time.Duration
+		if secs, perr := strconv.Atoi(resp.Header.Get("Retry-After")); perr == nil && secs > 0 {
+			retryAfter = time.Duration(secs) * time.Second
+		}
+		r.tracker.record(time.Now(), retryAfter)
+	}
+	return resp, err
+}
+
+// grantedScopes remembers the OAuth scopes Slack last reported for the token.
+// The zero value is ready to use.
+type grantedScopes struct {
+	mu     sync.Mutex
+	scopes []string
+	known  bool
+}
+
+// record stores the scopes of an X-OAuth-Scopes header, a comma-separated list
+func (g *grantedScopes) record(header string) {
+	var scopes []string
+	seen := make(map[string]bool)
+	for _, scope := range strings.Split(header, ",") {
+		scope = strings.TrimSpace(scope)
+		if scope == "" || seen[scope] {
+			continue
+		}
+		seen[scope] = true
+		scopes = append(scopes, scope)
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.scopes = scopes
+	g.known = true
+}
+
+func (g *grantedScopes) list() ([]string, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]string(nil), g.scopes...), g.known
+}
+
+// scopeRecorder is an http.RoundTripper that records the X-OAuth-Scopes header
+// Slack adds to Web API responses for OAuth tokens
+type scopeRecorder struct {
+	next   http.RoundTripper
+	scopes *grantedScopes
+}
+
+func (r *scopeRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := r.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	resp, err := next.RoundTrip(req)
+	if err == nil {
+		if values := resp.Header.Values("X-OAuth-Scopes"); len(values) > 0 {
+			r.scopes.record(strings.Join(values, ","))
+		}
+	}
+	return resp, err
+}
+
+// apiCallCounter is an http.RoundTripper that counts requests per Slack API
+// method (e.g. "conversations.history"). Requests outside /api/, such as file
+// downloads, are counted as "other" to keep the number of labels bounded.
+type apiCallCounter struct {
+	next http.RoundTripper
+	rec  metrics.Recorder
+}
+
+func (c *apiCallCounter) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := c.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	c.rec.IncSlackAPICall(apiMethodLabel(req.URL.Path))
+	return next.RoundTrip(req)
+}
+
+// apiMethodLabel returns the Slack API method named by a request path such as
+// "/api/conversations.history", or "other" for any other path.
+func apiMethodLabel(urlPath string) string {
+	method, ok := strings.CutPrefix(urlPath, "/api/")
+	if !ok || !strings.Contains(method, ".") {
+		return "other"
+	}
+	for _, r := range method {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && r != '.' && r != '_' {
+			return "other"
+		}
+	}
+	return method
+}
+
+// failedFetchBackoff is how long a failed on-demand fetch, e.g. without the
+// required scope, is served from cache before it is tried again
+const failedFetchBackoff = 5 * time.Minute
+
+// ttlValue caches a single value fetched on demand. Unlike sync.Once, a failed
+// fetch is only remembered for a backoff period, after which the next call
+// tries again. The zero value is ready to use.
+type ttlValue[T any] struct {
+	mu       sync.Mutex
+	value    T
+	fetched  time.Time
+	err      error
+	failed   time.Time
+	inflight chan struct{}
+}
+
+// get returns the cached value while it is younger than ttl, the cached error
+// while it is younger than failTTL, and calls fetch otherwise. Concurrent
+// callers wait for a single fetch, or until their own ctx is done. A fetch
+// that failed because its ctx was cancelled is not cached.
+func (c *ttlValue[T]) get(ctx context.Context, ttl, failTTL time.Duration, now time.Time, fetch func(ctx context.Context) (T, error)) (T, error) {
+	var zero T
+	for {
+		c.mu.Lock()
+		if !c.fetched.IsZero() && now.Sub(c.fetched) < ttl {
+			value := c.value
+			c.mu.Unlock()
+			return value, nil
+		}
+		if c.err != nil && now.Sub(c.failed) < failTTL {
+			err := c.err
+			c.mu.Unlock()
+			return zero, err
+		}
+		if c.inflight == nil {
+			break
+		}
+		inflight := c.inflight
+		c.mu.Unlock()
+		select {
+		case <-inflight:
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		}
+	}
+	done := make(chan struct{})
+	c.inflight = done
+	c.mu.Unlock()
+
+	value, err := fetch(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.inflight = nil
+	close(done)
+	if err != nil {
+		if ctx.Err() == nil {
+			c.err, c.failed = err, now
+		}
+		return zero, err
+	}
+	c.value, c.fetched, c.err = value, now, nil
+	return value, nil
+}
+
+// botInfoTTL is how long resolved bot names are cached
+const botInfoTTL = time.Hour
+
+type botInfoEntry struct {
+	name    string
+	fetched time.Time
+	failed  bool
+}
+
+// botInfoCache caches bot names by bot ID. The zero value is ready to use.
+type botInfoCache struct {
+	mu      sync.Mutex
+	entries map[string]botInfoEntry
+}
+
+// name returns the cached name of botID, fetching it when missing or older
+// than ttl. A failed fetch yields an empty name. Errors returned by the Slack
+// API are cached for failedFetchBackoff, since retrying them would only spend
+// the Tier 2 budget; transient failures such as rate limits or cancelled
+// requests are not cached, so the next message retries the lookup.
+func (c *botInfoCache) name(
+	ctx context.Context,
+	botID string,
+	ttl time.Duration,
+	now time.Time,
+	fetch func(ctx context.Context, botID string) (*slack.Bot, error),
+	logger *zap.Logger,
+) string {
+	if botID == "" {
+		return ""
+	}
+
+	c.mu.Lock()
+	entry, ok := c.entries[botID]
+	c.mu.Unlock()
+	if ok && entry.failed && now.Sub(entry.fetched) < failedFetchBackoff {
+		return ""
+	}
+	if ok && !entry.failed && now.Sub(entry.fetched) < ttl {
+		return entry.name
+	}
+
+	bot, err := fetch(ctx, botID)
+	if err != nil {
+		logger.Debug("Failed to resolve bot info", zap.String("bot_id", botID), zap.Error(err))
+		if isPermanentSlackError(err) {
+			c.store(botID, botInfoEntry{fetched: now, failed: true})
+		}
+		return ""
+	}
+	name := ""
+	if bot != nil {
+		name = bot.Name
+	}
+	c.store(botID, botInfoEntry{name: name, fetched: now})
+	return name
+}
+
+func (c *botInfoCache) store(botID string, entry botInfoEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]botInfoEntry)
+	}
+	c.entries[botID] = entry
+}
+
+// isPermanentSlackError reports whether err is an error response of the Slack
+// API, such as bot_not_found or missing_scope, that a retry would not fix.
+// Rate limits, server side failures, network errors and cancelled requests are
+// transient.
+func isPermanentSlackError(err error) bool {
+	var slackErr slack.SlackErrorResponse
+	if !errors.As(err, &slackErr) {
+		return false
+	}
+	switch slackErr.Err {
+	case "ratelimited", "internal_error", "fatal_error", "request_timeout", "service_unavailable":
+		return false
+	}
+	return true
+}
+
 func mapChannel(
 	id, name, nameNormalized, topic, purpose, user string,
 	members []string,
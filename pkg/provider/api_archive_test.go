@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithChannelArchived(t *testing.T) {
+	cache := &ChannelsCache{
+		Channels: map[string]Channel{
+			"C1": {ID: "C1", Name: "#general"},
+			"C2": {ID: "C2", Name: "#random"},
+		},
+		ChannelsInv: map[string]string{
+			"#general": "C1",
+			"#random":  "C2",
+		},
+	}
+
+	t.Run("archives cached channel without touching the original snapshot", func(t *testing.T) {
+		updated, ok := withChannelArchived(cache, "C1", true)
+		require.True(t, ok)
+
+		assert.True(t, updated.Channels["C1"].IsArchived)
+		assert.False(t, updated.Channels["C2"].IsArchived)
+		assert.Equal(t, "C1", updated.ChannelsInv["#general"], "channel stays resolvable by name")
+		assert.False(t, cache.Channels["C1"].IsArchived, "original snapshot must not change")
+	})
+
+	t.Run("unarchives cached channel", func(t *testing.T) {
+		archived, _ := withChannelArchived(cache, "C2", true)
+		updated, ok := withChannelArchived(archived, "C2", false)
+		require.True(t, ok)
+		assert.False(t, updated.Channels["C2"].IsArchived)
+	})
+
+	t.Run("unknown channel is left alone", func(t *testing.T) {
+		updated, ok := withChannelArchived(cache, "C9", true)
+		assert.False(t, ok)
+		assert.Same(t, cache, updated)
+	})
+
+	t.Run("nil cache", func(t *testing.T) {
+		updated, ok := withChannelArchived(nil, "C1", true)
+		assert.False(t, ok)
+		assert.Nil(t, updated)
+	})
+}
+
+func TestSetChannelArchived(t *testing.T) {
+	ap := &ApiProvider{}
+	ap.channelsSnapshot.Store(&ChannelsCache{
+		Channels:    map[string]Channel{"C1": {ID: "C1", Name: "#general"}},
+		ChannelsInv: map[string]string{"#general": "C1"},
+	})
+
+	assert.True(t, ap.SetChannelArchived("C1", true))
+	assert.True(t, ap.ProvideChannelsMaps().Channels["C1"].IsArchived)
+
+	assert.False(t, ap.SetChannelArchived("C9", true))
+}
+
+func TestSetChannelMember(t *testing.T) {
+	ap := &ApiProvider{}
+	ap.channelsSnapshot.Store(&ChannelsCache{
+		Channels:    map[string]Channel{"C1": {ID: "C1", Name: "#general"}},
+		ChannelsInv: map[string]string{"#general": "C1"},
+	})
+	before := ap.ProvideChannelsMaps()
+
+	assert.True(t, ap.SetChannelMember("C1", true))
+	member, known := ap.ProvideChannelsMaps().Channels["C1"].Member()
+	assert.True(t, known)
+	assert.True(t, member)
+	_, known = before.Channels["C1"].Member()
+	assert.False(t, known, "earlier snapshots must not change")
+
+	assert.False(t, ap.SetChannelMember("C9", true))
+}
@@ -0,0 +1,135 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestBotInfoCache(t *testing.T) {
+	var cache botInfoCache
+	calls := 0
+	fetch := func(ctx context.Context, botID string) (*slack.Bot, error) {
+		calls++
+		switch botID {
+		case "B404":
+			return nil, slack.SlackErrorResponse{Err: "bot_not_found"}
+		case "B429":
+			return nil, &slack.RateLimitedError{RetryAfter: time.Second}
+		}
+		return &slack.Bot{ID: botID, Name: "Deploy Bot"}, nil
+	}
+	now := time.Unix(1700000000, 0)
+	logger := zap.NewNop()
+
+	assert.Equal(t, "Deploy Bot", cache.name(context.Background(), "B1", time.Hour, now, fetch, logger))
+	assert.Equal(t, 1, calls)
+
+	assert.Equal(t, "Deploy Bot", cache.name(context.Background(), "B1", time.Hour, now.Add(30*time.Minute), fetch, logger))
+	assert.Equal(t, 1, calls, "lookup within the TTL should be served from cache")
+
+	assert.Equal(t, "Deploy Bot", cache.name(context.Background(), "B1", time.Hour, now.Add(2*time.Hour), fetch, logger))
+	assert.Equal(t, 2, calls, "lookup after the TTL should refetch")
+
+	assert.Equal(t, "", cache.name(context.Background(), "B404", time.Hour, now, fetch, logger))
+	assert.Equal(t, "", cache.name(context.Background(), "B404", time.Hour, now.Add(time.Minute), fetch, logger))
+	assert.Equal(t, 3, calls, "errors returned by Slack should be cached")
+	assert.Equal(t, "", cache.name(context.Background(), "B404", time.Hour, now.Add(failedFetchBackoff), fetch, logger))
+	assert.Equal(t, 4, calls, "errors returned by Slack should be retried after the backoff")
+
+	assert.Equal(t, "", cache.name(context.Background(), "B429", time.Hour, now, fetch, logger))
+	assert.Equal(t, "", cache.name(context.Background(), "B429", time.Hour, now, fetch, logger))
+	assert.Equal(t, 6, calls, "transient failures should not be cached")
+
+	assert.Equal(t, "", cache.name(context.Background(), "", time.Hour, now, fetch, logger))
+	assert.Equal(t, 6, calls)
+}
+
+func TestIsPermanentSlackError(t *testing.T) {
+	assert.True(t, isPermanentSlackError(slack.SlackErrorResponse{Err: "missing_scope"}))
+	assert.True(t, isPermanentSlackError(fmt.Errorf("bots.info: %w", slack.SlackErrorResponse{Err: "bot_not_found"})))
+	assert.False(t, isPermanentSlackError(slack.SlackErrorResponse{Err: "internal_error"}))
+	assert.False(t, isPermanentSlackError(&slack.RateLimitedError{RetryAfter: time.Second}))
+	assert.False(t, isPermanentSlackError(context.Canceled))
+	assert.False(t, isPermanentSlackError(errors.New("connection reset")))
+}
+
+func TestTTLValue(t *testing.T) {
+	var cache ttlValue[map[string]string]
+	calls := 0
+	fail := true
+	fetch := func(ctx context.Context) (map[string]string, error) {
+		calls++
+		if fail {
+			return nil, errors.New("missing_scope")
+		}
+		return map[string]string{"Xf1": "Team"}, nil
+	}
+	now := time.Unix(1700000000, 0)
+
+	_, err := cache.get(context.Background(), time.Hour, 5*time.Minute, now, fetch)
+	assert.Error(t, err)
+	fail = false
+	_, err = cache.get(context.Background(), time.Hour, 5*time.Minute, now.Add(time.Minute), fetch)
+	assert.EqualError(t, err, "missing_scope", "a failure is served from cache during the backoff")
+	assert.Equal(t, 1, calls)
+
+	got, err := cache.get(context.Background(), time.Hour, 5*time.Minute, now.Add(6*time.Minute), fetch)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"Xf1": "Team"}, got)
+	assert.Equal(t, 2, calls, "refetched after the backoff")
+
+	_, _ = cache.get(context.Background(), time.Hour, 5*time.Minute, now.Add(30*time.Minute), fetch)
+	assert.Equal(t, 2, calls, "served from cache within the TTL")
+
+	fail = true
+	got, err = cache.get(context.Background(), time.Hour, 5*time.Minute, now.Add(2*time.Hour), fetch)
+	assert.Error(t, err)
+	assert.Nil(t, got)
+	assert.Equal(t, 3, calls, "refetched after the TTL")
+
+	t.Run("cancelled fetches are not cached", func(t *testing.T) {
+		var cache ttlValue[string]
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := cache.get(ctx, time.Hour, time.Hour, now, func(ctx context.Context) (string, error) {
+			return "", ctx.Err()
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+
+		got, err := cache.get(context.Background(), time.Hour, time.Hour, now, func(ctx context.Context) (string, error) {
+			return "ok", nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "ok", got)
+	})
+
+	t.Run("waiters give up with their own context", func(t *testing.T) {
+		var cache ttlValue[string]
+		release := make(chan struct{})
+		started := make(chan struct{})
+		go func() {
+			_, _ = cache.get(context.Background(), time.Hour, time.Hour, now, func(ctx context.Context) (string, error) {
+				close(started)
+				<-release
+				return "slow", nil
+			})
+		}()
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := cache.get(ctx, time.Hour, time.Hour, now, func(ctx context.Context) (string, error) {
+			t.Error("a waiter must not start a second fetch")
+			return "", nil
+		})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		close(release)
+	})
+}
@@ -3,6 +3,8 @@ package provider
 import (
 	"testing"
 
+	edgeslack "github.com/rusq/slack"
+	"github.com/slack-go/slack"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -101,3 +103,71 @@ func TestAllChanTypesConstant(t *testing.T) {
 	assert.Contains(t, AllChanTypes, "im")
 	assert.Contains(t, AllChanTypes, "mpim")
 }
+
+// TestArchivedOnly verifies that only archived channels survive the
+// conversations.list fallback used by GetArchivedChannelsContext.
+func TestArchivedOnly(t *testing.T) {
+	archived := func(id string, isArchived bool) slack.Channel {
+		return slack.Channel{GroupConversation: slack.GroupConversation{
+			Conversation: slack.Conversation{ID: id},
+			IsArchived:   isArchived,
+		}}
+	}
+
+	t.Run("keeps archived channels only", func(t *testing.T) {
+		res := archivedOnly([]slack.Channel{
+			archived("C1", false),
+			archived("C2", true),
+			archived("C3", true),
+			archived("C4", false),
+		})
+		assert.Len(t, res, 2)
+		assert.Equal(t, "C2", res[0].ID)
+		assert.Equal(t, "C3", res[1].ID)
+	})
+
+	t.Run("no archived channels", func(t *testing.T) {
+		res := archivedOnly([]slack.Channel{archived("C1", false)})
+		assert.Empty(t, res)
+	})
+
+	t.Run("edge channels keep archived flag", func(t *testing.T) {
+		ec := edgeslack.Channel{}
+		ec.ID = "C5"
+		ec.IsArchived = true
+		res := archivedOnly([]slack.Channel{edgeChannelToSlack(ec)})
+		assert.Len(t, res, 1)
+		assert.Equal(t, "C5", res[0].ID)
+	})
+}
+
+// TestEdgeChannelKeepsMembership verifies the membership flag used by the
+// my_channels_only search filter survives the edge to standard mapping.
+func TestEdgeChannelKeepsMembership(t *testing.T) {
+	ec := edgeslack.Channel{}
+	ec.ID = "C1"
+	ec.IsMember = true
+	assert.True(t, edgeChannelToSlack(ec).IsMember)
+
+	ec.IsMember = false
+	assert.False(t, edgeChannelToSlack(ec).IsMember)
+}
+
+func TestChannelMember(t *testing.T) {
+	member, nonMember := true, false
+
+	isMember, known := Channel{ID: "C1", IsMember: &member}.Member()
+	assert.True(t, isMember)
+	assert.True(t, known)
+
+	isMember, known = Channel{ID: "C2", IsMember: &nonMember}.Member()
+	assert.False(t, isMember)
+	assert.True(t, known)
+
+	_, known = Channel{ID: "C3"}.Member()
+	assert.False(t, known, "a channel cached without is_member has an unknown membership")
+
+	isMember, known = Channel{ID: "D1", IsIM: true}.Member()
+	assert.True(t, isMember)
+	assert.True(t, known)
+}
@@ -0,0 +1,136 @@
+package provider
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/korotovsky/slack-mcp-server/pkg/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRateLimitTracker(t *testing.T) {
+	var tracker rateLimitTracker
+	now := time.Unix(1700000000, 0)
+
+	assert.Equal(t, RateLimitStatus{}, tracker.status(now), "never rate limited")
+
+	tracker.record(now, 30*time.Second)
+
+	st := tracker.status(now.Add(10 * time.Second))
+	assert.True(t, st.Throttled)
+	assert.Equal(t, now, st.LastLimitedAt)
+	assert.Equal(t, 30*time.Second, st.RetryAfter)
+	assert.Equal(t, 20*time.Second, st.WaitFor)
+	assert.Equal(t, 1, st.RecentCount)
+
+	st = tracker.status(now.Add(time.Minute))
+	assert.False(t, st.Throttled, "retry-after has passed")
+	assert.Equal(t, 1, st.RecentCount, "still reported as recent")
+
+	assert.Equal(t, RateLimitStatus{}, tracker.status(now.Add(rateLimitRecentWindow+time.Second)), "old rate limits are forgotten")
+}
+
+func TestRateLimitRecorder(t *testing.T) {
+	tracker := &rateLimitTracker{}
+	status := http.StatusOK
+	rec := &rateLimitRecorder{
+		tracker: tracker,
+		next: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			resp := &http.Response{StatusCode: status, Header: http.Header{}}
+			if status == http.StatusTooManyRequests {
+				resp.Header.Set("Retry-After", "30")
+			}
+			return resp, nil
+		}),
+	}
+	req, err := http.NewRequest(http.MethodPost, "https://slack.com/api/conversations.history", nil)
+	require.NoError(t, err)
+
+	_, err = rec.RoundTrip(req)
+	require.NoError(t, err)
+	assert.Equal(t, 0, tracker.status(time.Now()).RecentCount, "successful responses are not recorded")
+
+	status = http.StatusTooManyRequests
+	_, err = rec.RoundTrip(req)
+	require.NoError(t, err)
+
+	st := tracker.status(time.Now())
+	assert.True(t, st.Throttled)
+	assert.Equal(t, 30*time.Second, st.RetryAfter)
+	assert.Equal(t, 1, st.RecentCount)
+}
+
+func TestScopeRecorder(t *testing.T) {
+	scopes := &grantedScopes{}
+	header := ""
+	rec := &scopeRecorder{
+		scopes: scopes,
+		next: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+			if header != "" {
+				resp.Header.Set("X-OAuth-Scopes", header)
+			}
+			return resp, nil
+		}),
+	}
+	req, err := http.NewRequest(http.MethodPost, "https://slack.com/api/auth.test", nil)
+	require.NoError(t, err)
+
+	_, err = rec.RoundTrip(req)
+	require.NoError(t, err)
+	granted, ok := scopes.list()
+	assert.False(t, ok, "responses without the header, e.g. for browser tokens, leave scopes unknown")
+	assert.Empty(t, granted)
+
+	header = "channels:history, channels:read,search:read,channels:read"
+	_, err = rec.RoundTrip(req)
+	require.NoError(t, err)
+	granted, ok = scopes.list()
+	assert.True(t, ok)
+	assert.Equal(t, []string{"channels:history", "channels:read", "search:read"}, granted)
+
+	client := &MCPSlackClient{scopes: scopes}
+	granted, ok = client.GrantedScopes()
+	assert.True(t, ok)
+	assert.Equal(t, []string{"channels:history", "channels:read", "search:read"}, granted, "the client exposes the captured scopes")
+
+	granted[0] = "changed"
+	again, _ := client.GrantedScopes()
+	assert.Equal(t, "channels:history", again[0], "callers get a copy")
+}
+
+func TestAPICallCounter(t *testing.T) {
+	m := metrics.New()
+	counter := &apiCallCounter{
+		rec: m,
+		next: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}, nil
+		}),
+	}
+
+	for _, u := range []string{
+		"https://slack.com/api/conversations.history",
+		"https://slack.com/api/conversations.history",
+		"https://slack.com/api/users.list",
+		"https://files.slack.com/files-pri/T1-F1/report.pdf",
+		"https://slack.com/api/../download/F2",
+	} {
+		req, err := http.NewRequest(http.MethodPost, u, nil)
+		require.NoError(t, err)
+		_, err = counter.RoundTrip(req)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, uint64(2), m.SlackAPICalls("conversations.history"))
+	assert.Equal(t, uint64(1), m.SlackAPICalls("users.list"))
+	assert.Equal(t, uint64(2), m.SlackAPICalls("other"), "paths that are not API methods share one label")
+	assert.Equal(t, uint64(0), m.SlackAPICalls("report.pdf"))
+}
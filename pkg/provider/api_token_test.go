@@ -0,0 +1,37 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDetectTokenConfig covers the token type / edge API selection matrix.
+func TestDetectTokenConfig(t *testing.T) {
+	tests := []struct {
+		name         string
+		token        string
+		enterprise   bool
+		wantType     string
+		wantAPI      string
+		wantWarnings int
+	}{
+		{"xoxp standard workspace", "xoxp-1", false, "xoxp", TokenAPIStandard, 0},
+		{"xoxp enterprise grid", "xoxp-1", true, "xoxp", TokenAPIStandard, 1},
+		{"xoxb standard workspace", "xoxb-1", false, "xoxb", TokenAPIStandard, 1},
+		{"xoxb enterprise grid", "xoxb-1", true, "xoxb", TokenAPIStandard, 2},
+		{"xoxc standard workspace", "xoxc-1", false, "xoxc", TokenAPIEdge, 0},
+		{"xoxc enterprise grid", "xoxc-1", true, "xoxc", TokenAPIEdge, 0},
+		{"unknown prefix", "abc", false, "unknown", TokenAPIEdge, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := detectTokenConfig(tt.token, tt.enterprise)
+			assert.Equal(t, tt.wantType, cfg.TokenType)
+			assert.Equal(t, tt.wantAPI, cfg.API)
+			assert.Equal(t, tt.enterprise, cfg.Enterprise)
+			assert.Len(t, cfg.Warnings, tt.wantWarnings)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package edge
+
+import (
+	"context"
+	"runtime/trace"
+	"strings"
+)
+
+// activity.* API
+
+// Activity item types returned by activity.feed
+const (
+	ActivityAtUser          = "at_user"
+	ActivityAtUserGroup     = "at_user_group"
+	ActivityAtChannel       = "at_channel"
+	ActivityAtEveryone      = "at_everyone"
+	ActivityKeyword         = "keyword"
+	ActivityMessageReaction = "message_reaction"
+	ActivityThread          = "thread_v2"
+)
+
+type activityFeedForm struct {
+	BaseRequest
+	Types  string `json:"types"`
+	Mode   string `json:"mode"`
+	Limit  int    `json:"limit"`
+	Cursor string `json:"cursor,omitempty"`
+	WebClientFields
+}
+
+type ActivityFeedResponse struct {
+	baseResponse
+	Items []ActivityItem `json:"items,omitempty"`
+}
+
+// ActivityItem is one entry of the activity feed, i.e. a mention, a reaction
+// to one of the user's messages or new replies in a followed thread.
+type ActivityItem struct {
+	IsUnread bool           `json:"is_unread"`
+	FeedTs   string         `json:"feed_ts"`
+	Key      string         `json:"key"`
+	Item     ActivityDetail `json:"item"`
+}
+
+type ActivityDetail struct {
+	Type       string            `json:"type"`
+	Message    *ActivityMessage  `json:"message,omitempty"`
+	Reaction   *ActivityReaction `json:"reaction,omitempty"`
+	BundleInfo *ActivityBundle   `json:"bundle_info,omitempty"`
+}
+
+type ActivityMessage struct {
+	Ts           string `json:"ts"`
+	Channel      string `json:"channel"`
+	ThreadTs     string `json:"thread_ts,omitempty"`
+	AuthorUserID string `json:"author_user_id"`
+}
+
+type ActivityReaction struct {
+	User string `json:"user"`
+	Name string `json:"name"`
+}
+
+type ActivityBundle struct {
+	Payload struct {
+		ThreadEntry ActivityThreadEntry `json:"thread_entry"`
+	} `json:"payload"`
+}
+
+type ActivityThreadEntry struct {
+	ChannelID      string `json:"channel_id"`
+	ThreadTs       string `json:"thread_ts"`
+	LatestTs       string `json:"latest_ts"`
+	UnreadMsgCount int    `json:"unread_msg_count"`
+}
+
+// ActivityFeed returns one page of the user's activity feed, newest first,
+// limited to the given item types.
+func (cl *Client) ActivityFeed(ctx context.Context, types []string, limit int, cursor string) (ActivityFeedResponse, error) {
+	ctx, task := trace.NewTask(ctx, "ActivityFeed")
+	defer task.End()
+	trace.Logf(ctx, "params", "types=%v limit=%d cursor=%q", types, limit, cursor)
+
+	form := activityFeedForm{
+		BaseRequest:     BaseRequest{Token: cl.token},
+		Types:           strings.Join(types, ","),
+		Mode:            "chrono_reads_and_unreads",
+		Limit:           limit,
+		Cursor:          cursor,
+		WebClientFields: webclientReason("fetchActivityFeed"),
+	}
+
+	resp, err := cl.PostForm(ctx, "activity.feed", values(form, true))
+	if err != nil {
+		return ActivityFeedResponse{}, err
+	}
+	r := ActivityFeedResponse{}
+	if err := cl.ParseResponse(&r, resp); err != nil {
+		return ActivityFeedResponse{}, err
+	}
+	if err := r.validate("activity.feed"); err != nil {
+		return ActivityFeedResponse{}, err
+	}
+	return r, nil
+}
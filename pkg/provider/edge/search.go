@@ -96,7 +96,19 @@ const (
 func (cl *Client) SearchChannels(ctx context.Context, query string) ([]slack.Channel, error) {
 	ctx, task := trace.NewTask(ctx, "SearchChannels")
 	defer task.End()
-	lg := slog.With("in", "SearchChannels", "query", query)
+	return cl.searchChannels(ctx, query, scpAll)
+}
+
+// SearchArchivedChannels is the same as SearchChannels, but returns only
+// archived channels.
+func (cl *Client) SearchArchivedChannels(ctx context.Context, query string) ([]slack.Channel, error) {
+	ctx, task := trace.NewTask(ctx, "SearchArchivedChannels")
+	defer task.End()
+	return cl.searchChannels(ctx, query, scpArchived)
+}
+
+func (cl *Client) searchChannels(ctx context.Context, query string, channelType searchChannelType) ([]slack.Channel, error) {
+	lg := slog.With("in", "searchChannels", "query", query, "channel_type", channelType)
 
 	trace.Logf(ctx, "params", "query=%q", query)
 
@@ -129,7 +141,7 @@ func (cl *Client) SearchChannels(ctx context.Context, query string) ([]slack.Cha
 		MaxFilterSuggestions: 10,
 		Sort:                 sstName,
 		SortDir:              ssdAsc,
-		ChannelType:          scpAll,
+		ChannelType:          channelType,
 		ExcludeMyChannels:    0,
 		SearchOnlyMyChannels: false,
 		RecommendSource:      "channel-browser",
@@ -169,7 +181,7 @@ func (cl *Client) SearchChannels(ctx context.Context, query string) ([]slack.Cha
 				Properties:        c.Properties,
 			}
 			obj.NumMembers = c.NumMembers
-			if obj.NumMembers == 0 {
+			if obj.NumMembers == 0 || channelType == scpArchived {
 				obj.IsArchived = true
 			}
 
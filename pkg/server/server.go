@@ -2,20 +2,27 @@ package server
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/gocarina/gocsv"
 	"github.com/korotovsky/slack-mcp-server/pkg/handler"
+	"github.com/korotovsky/slack-mcp-server/pkg/limiter"
+	"github.com/korotovsky/slack-mcp-server/pkg/metrics"
 	"github.com/korotovsky/slack-mcp-server/pkg/provider"
 	"github.com/korotovsky/slack-mcp-server/pkg/server/auth"
 	"github.com/korotovsky/slack-mcp-server/pkg/text"
 	"github.com/korotovsky/slack-mcp-server/pkg/version"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/slack-go/slack"
 	"go.uber.org/zap"
 )
 
@@ -27,6 +34,14 @@ type MCPServer struct {
 const (
 	ToolConversationsHistory        = "conversations_history"
 	ToolConversationsReplies        = "conversations_replies"
+	ToolConversationsThreadByLink   = "conversations_thread_by_link"
+	ToolConversationsExtractLinks   = "conversations_extract_links"
+	ToolConversationsAudit          = "conversations_audit"
+	ToolConversationsBotMessages    = "conversations_bot_messages"
+	ToolConversationsExport         = "conversations_export"
+	ToolConversationsLatest         = "conversations_latest"
+	ToolConversationsPinsList       = "conversations_pins_list"
+	ToolConversationsThreadsList    = "conversations_threads_list"
 	ToolConversationsAddMessage     = "conversations_add_message"
 	ToolReactionsAdd                = "reactions_add"
 	ToolReactionsRemove             = "reactions_remove"
@@ -34,18 +49,47 @@ const (
 	ToolConversationsSearchMessages = "conversations_search_messages"
 	ToolConversationsUnreads        = "conversations_unreads"
 	ToolConversationsMark           = "conversations_mark"
+	ToolConversationsClose          = "conversations_close"
+	ToolConversationsMyDMs          = "conversations_my_dms"
+	ToolConversationsParticipants   = "conversations_participants"
+	ToolSavedAdd                    = "saved_add"
+	ToolSavedList                   = "saved_list"
 	ToolChannelsList                = "channels_list"
+	ToolChannelsListArchived        = "channels_list_archived"
+	ToolChannelsDefaults            = "channels_defaults"
+	ToolChannelsByPrefix            = "channels_by_prefix"
+	ToolChannelsResources           = "channels_resources"
+	ToolChannelsMemberCounts        = "channels_member_counts"
+	ToolChannelsInvite              = "channels_invite"
+	ToolChannelsCreate              = "channels_create"
+	ToolChannelsArchive             = "channels_archive"
+	ToolChannelsUnarchive           = "channels_unarchive"
+	ToolAdminTeamInfo               = "admin_team_info"
 	ToolUsergroupsList              = "usergroups_list"
 	ToolUsergroupsMe                = "usergroups_me"
 	ToolUsergroupsCreate            = "usergroups_create"
 	ToolUsergroupsUpdate            = "usergroups_update"
 	ToolUsergroupsUsersUpdate       = "usergroups_users_update"
 	ToolUsersSearch                 = "users_search"
+	ToolUsersRecentActivity         = "users_recent_activity"
+	ToolActivityFeed                = "activity_feed"
+	ToolUsersProfile                = "users_profile"
+	ToolUsersByEmail                = "users_by_email"
+	ToolCapabilities                = "capabilities"
+	ToolRateLimitStatus             = "rate_limit_status"
 )
 
 var ValidToolNames = []string{
 	ToolConversationsHistory,
 	ToolConversationsReplies,
+	ToolConversationsThreadByLink,
+	ToolConversationsExtractLinks,
+	ToolConversationsAudit,
+	ToolConversationsBotMessages,
+	ToolConversationsExport,
+	ToolConversationsLatest,
+	ToolConversationsPinsList,
+	ToolConversationsThreadsList,
 	ToolConversationsAddMessage,
 	ToolReactionsAdd,
 	ToolReactionsRemove,
@@ -53,13 +97,34 @@ var ValidToolNames = []string{
 	ToolConversationsSearchMessages,
 	ToolConversationsUnreads,
 	ToolConversationsMark,
+	ToolConversationsClose,
+	ToolConversationsMyDMs,
+	ToolConversationsParticipants,
+	ToolSavedAdd,
+	ToolSavedList,
 	ToolChannelsList,
+	ToolChannelsListArchived,
+	ToolChannelsDefaults,
+	ToolChannelsByPrefix,
+	ToolChannelsResources,
+	ToolChannelsMemberCounts,
+	ToolChannelsInvite,
+	ToolChannelsCreate,
+	ToolChannelsArchive,
+	ToolChannelsUnarchive,
+	ToolAdminTeamInfo,
 	ToolUsergroupsList,
 	ToolUsergroupsMe,
 	ToolUsergroupsCreate,
 	ToolUsergroupsUpdate,
 	ToolUsergroupsUsersUpdate,
 	ToolUsersSearch,
+	ToolUsersRecentActivity,
+	ToolActivityFeed,
+	ToolUsersProfile,
+	ToolUsersByEmail,
+	ToolCapabilities,
+	ToolRateLimitStatus,
 }
 
 func ValidateEnabledTools(tools []string) error {
@@ -101,6 +166,52 @@ func shouldAddTool(name string, enabledTools []string, envVarName string) bool {
 	return false
 }
 
+// botTokenUnsupportedTools maps tools that cannot work with bot tokens (xoxb)
+// to the reason shown by the capabilities tool.
+var botTokenUnsupportedTools = map[string]string{
+	ToolConversationsSearchMessages: "bot tokens cannot use the search.messages API",
+	ToolUsersRecentActivity:         "built on search.messages, which bot tokens cannot use",
+	ToolActivityFeed:                "the activity feed and search.messages are user-scoped, bot tokens cannot use them",
+	ToolConversationsUnreads:        "bot tokens do not support unread tracking",
+	ToolSavedAdd:                    "saved items are user-scoped, bot tokens have none",
+	ToolSavedList:                   "saved items are user-scoped, bot tokens have none",
+}
+
+// ToolCapability reports whether a tool is usable with the current token.
+type ToolCapability struct {
+	Name      string `csv:"Name"`
+	Available bool   `csv:"Available"`
+	Reason    string `csv:"Reason"`
+}
+
+// isToolSupported reports whether the tool can be used with the detected token type.
+func isToolSupported(name string, isBotToken bool) bool {
+	if !isBotToken {
+		return true
+	}
+	_, unsupported := botTokenUnsupportedTools[name]
+	return !unsupported
+}
+
+// toolCapabilities lists every known tool with its availability. Tools that the
+// token type supports but which were not registered are reported as disabled.
+func toolCapabilities(isBotToken bool, registered func(name string) bool) []ToolCapability {
+	caps := make([]ToolCapability, 0, len(ValidToolNames))
+	for _, name := range ValidToolNames {
+		c := ToolCapability{Name: name, Available: true}
+		switch {
+		case !isToolSupported(name, isBotToken):
+			c.Available = false
+			c.Reason = botTokenUnsupportedTools[name]
+		case !registered(name):
+			c.Available = false
+			c.Reason = "disabled by server configuration"
+		}
+		caps = append(caps, c)
+	}
+	return caps
+}
+
 func NewMCPServer(provider *provider.ApiProvider, logger *zap.Logger, enabledTools []string) *MCPServer {
 	s := server.NewMCPServer(
 		"Slack MCP Server",
@@ -109,6 +220,10 @@ func NewMCPServer(provider *provider.ApiProvider, logger *zap.Logger, enabledToo
 		server.WithRecovery(),
 		server.WithToolHandlerMiddleware(buildErrorRecoveryMiddleware(logger)),
 		server.WithToolHandlerMiddleware(buildLoggerMiddleware(logger)),
+		server.WithToolHandlerMiddleware(buildMetricsMiddleware(metrics.Default)),
+		server.WithToolHandlerMiddleware(buildScopeGuidanceMiddleware()),
+		server.WithToolHandlerMiddleware(buildRetryBudgetMiddleware(retryBudget(logger))),
+		server.WithToolHandlerMiddleware(buildOutputLimitMiddleware(maxOutputBytes(logger), logger)),
 		server.WithToolHandlerMiddleware(auth.BuildMiddleware(provider.ServerTransport(), logger)),
 	)
 
@@ -127,19 +242,69 @@ func NewMCPServer(provider *provider.ApiProvider, logger *zap.Logger, enabledToo
 				mcp.Description("If true, the response will include activity messages such as 'channel_join' or 'channel_leave'. Default is boolean false."),
 				mcp.DefaultBool(false),
 			),
+			mcp.WithBoolean("include_reaction_users",
+				mcp.Description("If true, the reactions column lists who reacted as handles, e.g. 'thumbsup:2[@alice,@bob]'. Default is boolean false."),
+				mcp.DefaultBool(false),
+			),
+			mcp.WithBoolean("include_client_msg_id",
+				mcp.Description("If true, adds a ClientMsgID column with Slack's client_msg_id, a stable per-message identifier that survives edits. Bot and integration posts usually have none. Default is boolean false."),
+				mcp.DefaultBool(false),
+			),
+			mcp.WithBoolean("include_subtype",
+				mcp.Description("If true, adds a Subtype column with the Slack message subtype, e.g. 'bot_message', 'file_share', 'me_message' or, with include_activity_messages, 'channel_join'. Plain user messages leave it empty. Default is boolean false."),
+				mcp.DefaultBool(false),
+			),
+			mcp.WithBoolean("include_avatars",
+				mcp.Description("If true, adds an AvatarURL column with the author's avatar from the users cache, or the bot icon for bot posts. Left empty when unknown. Default is boolean false."),
+				mcp.DefaultBool(false),
+			),
+			mcp.WithBoolean("include_team",
+				mcp.Description("If true, adds a Team column with the team ID of each author and an IsExternal column flagging authors from another organization, e.g. partners in Slack Connect channels. Default is boolean false."),
+				mcp.DefaultBool(false),
+			),
+			mcp.WithBoolean("include_calls",
+				mcp.Description("If true, huddle and call messages are returned as summary rows: who started them and, for calls readable with calls.info, their title, duration and participants. Default is boolean false."),
+				mcp.DefaultBool(false),
+			),
+			mcp.WithBoolean("mark_unread_boundary",
+				mcp.Description("If true, adds an IsUnread column, true for messages posted after the channel's last_read, fetched with conversations.info, separating what you have read from what you have not. Requires response_format 'csv'. Default is boolean false."),
+				mcp.DefaultBool(false),
+			),
 			mcp.WithString("cursor",
 				mcp.Description("Cursor for pagination. Use the value of the last row and column in the response as next_cursor field returned from the previous request."),
 			),
 			mcp.WithString("limit",
 				mcp.DefaultString("1d"),
-				mcp.Description("Limit of messages to fetch in format of maximum ranges of time (e.g. 1d - 1 day, 1w - 1 week, 30d - 30 days, 90d - 90 days which is a default limit for free tier history) or number of messages (e.g. 50). Must be empty when 'cursor' is provided."),
+				mcp.Description("Limit of messages to fetch in format of maximum ranges of time (e.g. 30min - 30 minutes, 2h - 2 hours, 1d - 1 day, 1w - 1 week, 30d - 30 days, 90d - 90 days which is a default limit for free tier history) or number of messages (e.g. 50). Must be empty when 'cursor' is provided."),
+			),
+			mcp.WithString("order",
+				mcp.DefaultString("newest"),
+				mcp.Description("Order of returned messages: 'newest' (newest first, default) or 'oldest' (oldest first, to read top to bottom). The cursor always pages back to older messages regardless of order."),
+			),
+			mcp.WithBoolean("links_only",
+				mcp.Description("If true, only messages whose text contains at least one URL are returned. Filters the fetched page locally, no extra API calls. Default is boolean false."),
+				mcp.DefaultBool(false),
+			),
+			mcp.WithString("users",
+				mcp.Description("Comma-separated user IDs or @handles, e.g. '@alice,@bob'. Only messages authored by one of them are returned, to follow an exchange between specific people. Filters the fetched page locally, no extra API calls."),
+			),
+			mcp.WithBoolean("daily_anchors",
+				mcp.Description("If true, only the first message of each calendar day (in SLACK_MCP_TIMEZONE, UTC by default) within the fetched page is returned, to build a timeline of a long-running channel. Filters the page locally, no extra API calls. Default is boolean false."),
+				mcp.DefaultBool(false),
+			),
+			mcp.WithString("newer_than",
+				mcp.Description("Forward cursor: return only messages posted after this Slack ts or RFC3339 time, oldest first unless order is given, up to limit. To follow a channel, pass the ts of the last message seen; a note gives the value for the next call when more messages follow. Without a limit, up to 50 messages are returned. Cannot be combined with cursor, which pages backward, or with a time range limit like '1d'."),
+			),
+			mcp.WithString("response_format",
+				mcp.DefaultString("csv"),
+				mcp.Description("Output format: 'csv' (default) or 'transcript', a plain text block with one '[time] @user: text' line per message, handy for summarization. In transcript mode the pagination cursor is returned as a separate 'next_cursor: ...' line."),
 			),
 		), conversationsHandler.ConversationsHistoryHandler)
 	}
 
 	if shouldAddTool(ToolConversationsReplies, enabledTools, "") {
 		s.AddTool(mcp.NewTool(ToolConversationsReplies,
-			mcp.WithDescription("Get a thread of messages posted to a conversation by channelID and thread_ts, the last row/column in the response is used as 'cursor' parameter for pagination if not empty"),
+			mcp.WithDescription("Get a thread of messages posted to a conversation by channelID and thread_ts, the last row/column in the response is used as 'cursor' parameter for pagination if not empty. The Subscribed column of the parent message tells whether the current user follows the thread; it is empty for replies and absent with bot tokens."),
 			mcp.WithTitleAnnotation("Get Thread Replies"),
 			mcp.WithReadOnlyHintAnnotation(true),
 			mcp.WithString("channel_id",
@@ -154,28 +319,215 @@ func NewMCPServer(provider *provider.ApiProvider, logger *zap.Logger, enabledToo
 				mcp.Description("If true, the response will include activity messages such as 'channel_join' or 'channel_leave'. Default is boolean false."),
 				mcp.DefaultBool(false),
 			),
+			mcp.WithBoolean("include_reaction_users",
+				mcp.Description("If true, the reactions column lists who reacted as handles, e.g. 'thumbsup:2[@alice,@bob]'. Default is boolean false."),
+				mcp.DefaultBool(false),
+			),
+			mcp.WithBoolean("include_client_msg_id",
+				mcp.Description("If true, adds a ClientMsgID column with Slack's client_msg_id, a stable per-message identifier that survives edits. Bot and integration posts usually have none. Default is boolean false."),
+				mcp.DefaultBool(false),
+			),
+			mcp.WithBoolean("include_subtype",
+				mcp.Description("If true, adds a Subtype column with the Slack message subtype, e.g. 'bot_message', 'file_share', 'me_message' or, with include_activity_messages, 'channel_join'. Plain user messages leave it empty. Default is boolean false."),
+				mcp.DefaultBool(false),
+			),
+			mcp.WithBoolean("include_avatars",
+				mcp.Description("If true, adds an AvatarURL column with the author's avatar from the users cache, or the bot icon for bot posts. Left empty when unknown. Default is boolean false."),
+				mcp.DefaultBool(false),
+			),
+			mcp.WithBoolean("include_team",
+				mcp.Description("If true, adds a Team column with the team ID of each author and an IsExternal column flagging authors from another organization, e.g. partners in Slack Connect channels. Default is boolean false."),
+				mcp.DefaultBool(false),
+			),
 			mcp.WithString("cursor",
 				mcp.Description("Cursor for pagination. Use the value of the last row and column in the response as next_cursor field returned from the previous request."),
 			),
 			mcp.WithString("limit",
 				mcp.DefaultString("1d"),
-				mcp.Description("Limit of messages to fetch in format of maximum ranges of time (e.g. 1d - 1 day, 30d - 30 days, 90d - 90 days which is a default limit for free tier history) or number of messages (e.g. 50). Must be empty when 'cursor' is provided."),
+				mcp.Description("Limit of messages to fetch in format of maximum ranges of time (e.g. 30min - 30 minutes, 2h - 2 hours, 1d - 1 day, 30d - 30 days, 90d - 90 days which is a default limit for free tier history) or number of messages (e.g. 50). Must be empty when 'cursor' is provided."),
+			),
+			mcp.WithString("since",
+				mcp.Description("Only return replies posted after this time, as RFC3339 (e.g. '2025-01-02T15:04:05Z') or Slack ts (e.g. '1234567890.123456'). Overrides the start of a time range 'limit'. Useful for following a thread incrementally."),
+			),
+			mcp.WithString("response_format",
+				mcp.DefaultString("csv"),
+				mcp.Description("Output format: 'csv' (default) or 'transcript', a plain text block with one '[time] @user: text' line per message, handy for summarization. In transcript mode the pagination cursor is returned as a separate 'next_cursor: ...' line."),
+			),
+			mcp.WithBoolean("participants_only",
+				mcp.Description("If true, returns the unique participants of the thread as CSV with columns userID, userName, realName instead of its messages, plus the reply count, scanning the thread from the start up to max_replies replies. limit, cursor, since and response_format are ignored. Default is boolean false."),
+				mcp.DefaultBool(false),
+			),
+			mcp.WithNumber("max_replies",
+				mcp.DefaultNumber(1000),
+				mcp.Description("Maximum number of replies scanned with participants_only, between 1 and 10000. A warning is added when the thread is longer."),
 			),
 		), conversationsHandler.ConversationsRepliesHandler)
 	}
 
+	if shouldAddTool(ToolConversationsThreadByLink, enabledTools, "") {
+		s.AddTool(mcp.NewTool(ToolConversationsThreadByLink,
+			mcp.WithDescription("Get the full thread a Slack message permalink points to, paging through all replies up to max_messages. Works with permalinks to a thread root or to a reply (with ?thread_ts=...); for a message that is not part of a thread only that message is returned, with a note."),
+			mcp.WithTitleAnnotation("Get Thread by Permalink"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("permalink",
+				mcp.Required(),
+				mcp.Description("Slack message permalink, e.g. https://example.slack.com/archives/C1234567890/p1234567890123456 or a reply link with ?thread_ts=1234567890.123456."),
+			),
+			mcp.WithNumber("max_messages",
+				mcp.DefaultNumber(500),
+				mcp.Description("Maximum number of messages to return, including the thread root. Must be an integer between 1 and 1000. When the thread is longer, the last row carries a cursor for conversations_replies."),
+			),
+		), conversationsHandler.ConversationsThreadByLinkHandler)
+	}
+
+	if shouldAddTool(ToolConversationsExtractLinks, enabledTools, "") {
+		s.AddTool(mcp.NewTool(ToolConversationsExtractLinks,
+			mcp.WithDescription("Get a de-duplicated list of links shared in a channel (or DM) with the message ts and author of their first occurrence, the last row/column in the response is used as 'cursor' parameter for pagination if not empty"),
+			mcp.WithTitleAnnotation("Extract Conversation Links"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("channel_id",
+				mcp.Required(),
+				mcp.Description("ID of the channel in format Cxxxxxxxxxx or its name starting with #... or @... aka #general or @username_dm."),
+			),
+			mcp.WithString("cursor",
+				mcp.Description("Cursor for pagination. Use the value of the last row and column in the response as next_cursor field returned from the previous request."),
+			),
+			mcp.WithString("limit",
+				mcp.DefaultString("1w"),
+				mcp.Description("Limit of messages to scan in format of maximum ranges of time (e.g. 30min - 30 minutes, 2h - 2 hours, 1d - 1 day, 1w - 1 week, 30d - 30 days) or number of messages (e.g. 50). Must be empty when 'cursor' is provided."),
+			),
+		), conversationsHandler.ConversationsExtractLinksHandler)
+	}
+
+	if shouldAddTool(ToolConversationsAudit, enabledTools, "") {
+		s.AddTool(mcp.NewTool(ToolConversationsAudit,
+			mcp.WithDescription("Best-effort audit of a channel (or DM): list the edited messages and the placeholders left by deleted thread parents found in its history, with edit time and editor. Slack keeps neither deleted content nor the text before an edit. The last row/column in the response is used as 'cursor' parameter for pagination if not empty"),
+			mcp.WithTitleAnnotation("Audit Conversation Edits"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("channel_id",
+				mcp.Required(),
+				mcp.Description("ID of the channel in format Cxxxxxxxxxx or its name starting with #... or @... aka #general or @username_dm."),
+			),
+			mcp.WithString("cursor",
+				mcp.Description("Cursor for pagination. Use the value of the last row and column in the response as next_cursor field returned from the previous request."),
+			),
+			mcp.WithString("limit",
+				mcp.DefaultString("1d"),
+				mcp.Description("Limit of messages to scan in format of maximum ranges of time (e.g. 30min - 30 minutes, 2h - 2 hours, 1d - 1 day, 1w - 1 week, 30d - 30 days) or number of messages (e.g. 50). Must be empty when 'cursor' is provided."),
+			),
+		), conversationsHandler.ConversationsAuditHandler)
+	}
+
+	if shouldAddTool(ToolConversationsBotMessages, enabledTools, "") {
+		s.AddTool(mcp.NewTool(ToolConversationsBotMessages,
+			mcp.WithDescription("Get only the messages posted by bots and apps in a channel (or DM), such as alerts or deploy notifications, grouped by app name resolved via bots.info. Returns CSV with columns: app, botID, msgID, channelID, time, text, cursor. The last row/column in the response is used as 'cursor' parameter for pagination if not empty"),
+			mcp.WithTitleAnnotation("Get Bot Messages"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("channel_id",
+				mcp.Required(),
+				mcp.Description("ID of the channel in format Cxxxxxxxxxx or its name starting with #... or @... aka #general or @username_dm."),
+			),
+			mcp.WithString("cursor",
+				mcp.Description("Cursor for pagination. Use the value of the last row and column in the response as next_cursor field returned from the previous request."),
+			),
+			mcp.WithString("limit",
+				mcp.DefaultString("1d"),
+				mcp.Description("Limit of messages to scan in format of maximum ranges of time (e.g. 30min - 30 minutes, 2h - 2 hours, 1d - 1 day, 1w - 1 week, 30d - 30 days) or number of messages (e.g. 50). Must be empty when 'cursor' is provided."),
+			),
+		), conversationsHandler.ConversationsBotMessagesHandler)
+	}
+
+	if shouldAddTool(ToolConversationsThreadsList, enabledTools, "") {
+		s.AddTool(mcp.NewTool(ToolConversationsThreadsList,
+			mcp.WithDescription("List the threads started in a channel (or DM), i.e. the messages with at least one reply, to triage a busy channel without paging through every message. Returns CSV with columns: msgID, userID, userUser, channelID, time, threadTs, replyCount, lastReply, participantCount, text, cursor. Pass threadTs to conversations_replies to read a thread. The last row/column in the response is used as 'cursor' parameter for pagination if not empty"),
+			mcp.WithTitleAnnotation("List Threads"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("channel_id",
+				mcp.Required(),
+				mcp.Description("ID of the channel in format Cxxxxxxxxxx or its name starting with #... or @... aka #general or @username_dm."),
+			),
+			mcp.WithString("cursor",
+				mcp.Description("Cursor for pagination. Use the value of the last row and column in the response as next_cursor field returned from the previous request."),
+			),
+			mcp.WithString("limit",
+				mcp.DefaultString("1d"),
+				mcp.Description("Limit of messages to scan in format of maximum ranges of time (e.g. 30min - 30 minutes, 2h - 2 hours, 1d - 1 day, 1w - 1 week, 30d - 30 days) or number of messages (e.g. 50). Must be empty when 'cursor' is provided."),
+			),
+		), conversationsHandler.ConversationsThreadsListHandler)
+	}
+
+	if shouldAddTool(ToolConversationsLatest, enabledTools, "") {
+		s.AddTool(mcp.NewTool(ToolConversationsLatest,
+			mcp.WithDescription("Get the latest message of each of several channels in one call, for a glanceable dashboard of watched channels. Returns one CSV row per channel with the columns of conversations_history. Channels without messages and channels that failed are listed in separate notes."),
+			mcp.WithTitleAnnotation("Get Latest Messages"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("channel_ids",
+				mcp.Required(),
+				mcp.Description("Comma-separated list of at most 50 channels, each an ID in format Cxxxxxxxxxx or a name starting with #... or @... (e.g., C1234567890,#general,@username_dm)."),
+			),
+		), conversationsHandler.ConversationsLatestHandler)
+	}
+
+	pinsHandler := handler.NewPinsHandler(conversationsHandler)
+	if shouldAddTool(ToolConversationsPinsList, enabledTools, "") {
+		s.AddTool(mcp.NewTool(ToolConversationsPinsList,
+			mcp.WithDescription("Get the messages and files pinned in a channel, e.g. to surface its key context. Returns CSV with the columns of conversations_history plus itemType, which is 'message' or 'file'. A pinned file is listed with its title and permalink as text and its ID in attachmentIDs."),
+			mcp.WithTitleAnnotation("List Pinned Items"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("channel_id",
+				mcp.Required(),
+				mcp.Description("ID of the channel in format Cxxxxxxxxxx or its name starting with #... or @... aka #general or @username_dm."),
+			),
+		), pinsHandler.PinsListHandler)
+	}
+
+	if shouldAddTool(ToolConversationsExport, enabledTools, "SLACK_MCP_EXPORT_TOOL") {
+		s.AddTool(mcp.NewTool(ToolConversationsExport,
+			mcp.WithDescription("Export the whole history of a channel (or DM), oldest first, following pagination up to max_messages. Returns a single transcript or CSV, or, when the server has SLACK_MCP_EXPORT_DIR set, writes it to a file there and returns its path. Expensive: makes one rate limited API call per 200 messages and per thread."),
+			mcp.WithTitleAnnotation("Export Channel"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("channel_id",
+				mcp.Required(),
+				mcp.Description("ID of the channel in format Cxxxxxxxxxx or its name starting with #... or @... aka #general or @username_dm."),
+			),
+			mcp.WithBoolean("include_threads",
+				mcp.Description("If true, the replies of every thread are fetched and placed right after their parent message. Default is boolean false."),
+				mcp.DefaultBool(false),
+			),
+			mcp.WithNumber("max_messages",
+				mcp.Description("Maximum number of messages to export, thread replies included, between 1 and 50000. A note says when the export was cut. Default is 5000."),
+				mcp.DefaultNumber(5000),
+			),
+			mcp.WithString("format",
+				mcp.Description("Either 'transcript' for one '[time] @author: text' line per message, or 'csv' for the columns of conversations_history. Default is 'transcript'."),
+				mcp.DefaultString("transcript"),
+			),
+		), conversationsHandler.ConversationsExportHandler)
+	}
+
 	if shouldAddTool(ToolConversationsAddMessage, enabledTools, "SLACK_MCP_ADD_MESSAGE_TOOL") {
 		s.AddTool(mcp.NewTool(ToolConversationsAddMessage,
-			mcp.WithDescription("Add a message to a public channel, private channel, or direct message (DM, or IM) conversation by channel_id and thread_ts."),
+			mcp.WithDescription("Add a message to a public channel, private channel, or direct message (DM, or IM) conversation by channel_id and thread_ts, or as a thread reply by reply_to_permalink."),
 			mcp.WithTitleAnnotation("Send Message"),
 			mcp.WithDestructiveHintAnnotation(true),
 			mcp.WithString("channel_id",
-				mcp.Required(),
-				mcp.Description("ID of the channel in format Cxxxxxxxxxx or its name starting with #... or @... aka #general or @username_dm."),
+				mcp.Description("ID of the channel in format Cxxxxxxxxxx or its name starting with #... or @... aka #general or @username_dm. Required unless reply_to_permalink is provided or SLACK_MCP_DEFAULT_CHANNEL is set."),
 			),
 			mcp.WithString("thread_ts",
 				mcp.Description("Unique identifier of either a thread's parent message or a message in the thread_ts must be the timestamp in format 1234567890.123456 of an existing message with 0 or more replies. Optional, if not provided the message will be added to the channel itself, otherwise it will be added to the thread."),
 			),
+			mcp.WithString("reactions",
+				mcp.Description("Comma-separated emoji names to add as reactions to the posted message, e.g. 'thumbsup,thumbsdown'. Requires the reactions tools to be enabled for the channel."),
+			),
+			mcp.WithBoolean("auto_join",
+				mcp.Description("If true and the message cannot be posted because the user or bot is not a member of the channel, join it once and retry. Requires SLACK_MCP_AUTO_JOIN=true on the server."),
+			),
+			mcp.WithBoolean("mark_read",
+				mcp.Description("If true, mark the conversation as read up to the posted message; if false, do not. Overrides the server default set by SLACK_MCP_ADD_MESSAGE_MARK for this call."),
+			),
+			mcp.WithString("reply_to_permalink",
+				mcp.Description("Permalink of the message to reply to in a thread, e.g. 'https://example.slack.com/archives/C1234567890/p1234567890123456'. The channel and thread_ts are taken from the link. Cannot be combined with channel_id or thread_ts."),
+			),
 			mcp.WithString("text",
 				mcp.Description("Message text in specified content_type format. Example: 'Hello, world!' for text/plain or '# Hello, world!' for text/markdown."),
 			),
@@ -183,6 +535,10 @@ func NewMCPServer(provider *provider.ApiProvider, logger *zap.Logger, enabledToo
 				mcp.DefaultString("text/markdown"),
 				mcp.Description("Content type of the message. Default is 'text/markdown'. Allowed values: 'text/markdown', 'text/plain'."),
 			),
+			mcp.WithBoolean("escape_mentions",
+				mcp.Description("If true, neutralize @channel, @here, @everyone, user group and user mentions in the text so that echoing someone's content notifies nobody. Use when quoting user content back into Slack. Default is boolean false."),
+				mcp.DefaultBool(false),
+			),
 		), conversationsHandler.ConversationsAddMessageHandler)
 	}
 
@@ -195,12 +551,17 @@ func NewMCPServer(provider *provider.ApiProvider, logger *zap.Logger, enabledToo
 				mcp.Description("ID of the channel in format Cxxxxxxxxxx or its name starting with #... or @... aka #general or @username_dm."),
 			),
 			mcp.WithString("timestamp",
-				mcp.Required(),
-				mcp.Description("Timestamp of the message to add reaction to, in format 1234567890.123456."),
+				mcp.Description("Timestamp of the message to add reaction to, in format 1234567890.123456. Required unless target is 'latest'."),
+			),
+			mcp.WithString("target",
+				mcp.Description("Set to 'latest' instead of passing timestamp to use the newest message in the channel, or in the thread given by thread_ts."),
+			),
+			mcp.WithString("thread_ts",
+				mcp.Description("With target 'latest', use the newest reply of this thread (ts of the thread's parent message) instead of the newest channel message."),
 			),
 			mcp.WithString("emoji",
 				mcp.Required(),
-				mcp.Description("The name of the emoji to add as a reaction (without colons). Example: 'thumbsup', 'heart', 'rocket'."),
+				mcp.Description("The name of the emoji to add as a reaction (without colons). Example: 'thumbsup', 'heart', 'rocket'. Use 'random:' followed by comma-separated names, e.g. 'random:wave,tada,sparkles', to react with one of them picked at random."),
 			),
 		), conversationsHandler.ReactionsAddHandler)
 	}
@@ -214,8 +575,13 @@ func NewMCPServer(provider *provider.ApiProvider, logger *zap.Logger, enabledToo
 				mcp.Description("ID of the channel in format Cxxxxxxxxxx or its name starting with #... or @... aka #general or @username_dm."),
 			),
 			mcp.WithString("timestamp",
-				mcp.Required(),
-				mcp.Description("Timestamp of the message to remove reaction from, in format 1234567890.123456."),
+				mcp.Description("Timestamp of the message to remove reaction from, in format 1234567890.123456. Required unless target is 'latest'."),
+			),
+			mcp.WithString("target",
+				mcp.Description("Set to 'latest' instead of passing timestamp to use the newest message in the channel, or in the thread given by thread_ts."),
+			),
+			mcp.WithString("thread_ts",
+				mcp.Description("With target 'latest', use the newest reply of this thread (ts of the thread's parent message) instead of the newest channel message."),
 			),
 			mcp.WithString("emoji",
 				mcp.Required(),
@@ -247,7 +613,7 @@ func NewMCPServer(provider *provider.ApiProvider, logger *zap.Logger, enabledToo
 			mcp.Description("Filter messages in a specific public/private channel by its ID or name. Example: 'C1234567890', 'G1234567890', or '#general'. If not provided, all channels will be searched."),
 		),
 		mcp.WithString("filter_in_im_or_mpim",
-			mcp.Description("Filter messages in a direct message (DM) or multi-person direct message (MPIM) conversation by its ID or name. Example: 'D1234567890' or '@username_dm'. If not provided, all DMs and MPIMs will be searched."),
+			mcp.Description("Filter messages in a direct message (DM) or multi-person direct message (MPIM) conversation by its ID, its name, or, for a DM, the other user. Example: 'D1234567890', '@username' or '@mpdm-alice--bob--carol-1'. A DM becomes in:<@U123> with the other user, a group DM in:mpdm-... with its name. If not provided, all DMs and MPIMs will be searched."),
 		),
 		mcp.WithString("filter_users_with",
 			mcp.Description("Filter messages with a specific user by their ID or display name in threads and DMs. Example: 'U1234567890' or '@username'. If not provided, all threads and DMs will be searched."),
@@ -267,9 +633,49 @@ func NewMCPServer(provider *provider.ApiProvider, logger *zap.Logger, enabledToo
 		mcp.WithString("filter_date_during",
 			mcp.Description("Filter messages sent during a specific period in format 'YYYY-MM-DD'. Example: 'July', 'Yesterday' or 'Today'. If not provided, all dates will be searched."),
 		),
+		mcp.WithString("filter_date_range",
+			mcp.Description("Filter messages sent within a date range in format 'start..end', both days included, e.g. a single day as 'start..start'. Example: '2023-01-01..2023-01-15' or 'Jan 1, 2023..Jan 15, 2023'. Cannot be combined with other date filters."),
+		),
 		mcp.WithBoolean("filter_threads_only",
 			mcp.Description("If true, the response will include only messages from threads. Default is boolean false."),
 		),
+		mcp.WithBoolean("include_thread_root",
+			mcp.Description("If true, for matches that are thread replies the thread's root message is fetched and included right before the reply as context (up to 10 roots). An IsContext column is added, true for these root rows and false for the actual matches. Default is boolean false."),
+		),
+		mcp.WithNumber("expand_threads",
+			mcp.Description("Number of top matches, 0 to 3, whose whole thread is fetched and listed right under the match, up to 50 messages per thread. Chains search and conversations_replies in one call. Cannot be combined with deep_search, count_only or include_thread_root. Default is 0."),
+		),
+		mcp.WithBoolean("my_channels_only",
+			mcp.Description("If true, only matches from channels, DMs and group DMs the user is a member of are returned. Default is boolean false."),
+		),
+		mcp.WithBoolean("include_reactions",
+			mcp.Description("If true, the reactions of every match are fetched, which search does not return, and filled into the reactions column. Costs one rate limited API call per match, made a few at a time. Cannot be combined with deep_search. Default is boolean false."),
+		),
+		mcp.WithBoolean("include_avatars",
+			mcp.Description("If true, adds an AvatarURL column with the author's avatar from the users cache. Left empty for unknown users and bots. Default is boolean false."),
+		),
+		mcp.WithBoolean("count_only",
+			mcp.Description("If true, only the total number of matching messages is returned as CSV with columns query and total, without message rows. Cannot be combined with deep_search or my_channels_only. Default is boolean false."),
+		),
+		mcp.WithBoolean("group_by_channel",
+			mcp.Description("If true, instead of messages the distinct channels with matches are returned as CSV with columns ChannelID, ChannelName and Matches, most matches first. Covers the requested page, or all matches with deep_search. Cannot be combined with count_only, include_reactions, expand_threads or include_thread_root. Default is boolean false."),
+		),
+		mcp.WithBoolean("resolve_channel_names",
+			mcp.Description("If true, channels of matches that Slack returned without a name and that are missing from the channels cache trigger a single cache refresh, so the Channel column shows names instead of IDs. Default is boolean false."),
+		),
+		mcp.WithBoolean("deep_search",
+			mcp.Description("If true, all pages are fetched and stitched together in one response, newest first, splitting the date range when a query has more results than Slack can page through. Cannot be combined with cursor. Default is boolean false."),
+		),
+		mcp.WithNumber("max_results",
+			mcp.DefaultNumber(1000),
+			mcp.Description("Maximum number of matches returned by deep_search. Must be an integer between 1 and 10000."),
+		),
+		mcp.WithString("sort",
+			mcp.Description("Order of the matches: 'score' (Slack's relevance ranking, default) or 'timestamp' (by posting time). Cannot be combined with deep_search, which always returns the newest matches first."),
+		),
+		mcp.WithString("sort_dir",
+			mcp.Description("Direction of the sort: 'desc' (default, best or newest first) or 'asc'. Use sort=timestamp and sort_dir=desc to get the newest matching message first."),
+		),
 		mcp.WithString("cursor",
 			mcp.DefaultString(""),
 			mcp.Description("Cursor for pagination. Use the value of the last row and column in the response as next_cursor field returned from the previous request."),
@@ -280,7 +686,7 @@ func NewMCPServer(provider *provider.ApiProvider, logger *zap.Logger, enabledToo
 		),
 	)
 	// Only register search tool for non-bot tokens (bot tokens cannot use search.messages API)
-	if !provider.IsBotToken() && shouldAddTool(ToolConversationsSearchMessages, enabledTools, "") {
+	if isToolSupported(ToolConversationsSearchMessages, provider.IsBotToken()) && shouldAddTool(ToolConversationsSearchMessages, enabledTools, "") {
 		s.AddTool(conversationsSearchTool, conversationsHandler.ConversationsSearchHandler)
 	}
 
@@ -300,9 +706,74 @@ func NewMCPServer(provider *provider.ApiProvider, logger *zap.Logger, enabledToo
 		), conversationsHandler.UsersSearchHandler)
 	}
 
+	if shouldAddTool(ToolUsersProfile, enabledTools, "") {
+		s.AddTool(mcp.NewTool(ToolUsersProfile,
+			mcp.WithDescription("Get the custom profile fields of a user, such as department, location or manager, labelled by the workspace's field definitions. Returns CSV with columns: userID, fieldID, label, value, alt."),
+			mcp.WithTitleAnnotation("Get User Profile Fields"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("user",
+				mcp.Required(),
+				mcp.Description("User ID or handle. Example: 'U1234567890' or '@username'."),
+			),
+		), conversationsHandler.UsersProfileHandler)
+	}
+
+	if shouldAddTool(ToolUsersByEmail, enabledTools, "") {
+		s.AddTool(mcp.NewTool(ToolUsersByEmail,
+			mcp.WithDescription("Resolve email addresses to Slack users, using the users cache first and Slack only for emails not found there. Unknown emails are returned with found=false instead of failing the call; when the lookup itself failed, the error column says why. Returns CSV with columns: email, found, userID, userName, realName, error."),
+			mcp.WithTitleAnnotation("Find Users by Email"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("emails",
+				mcp.Required(),
+				mcp.Description("Comma-separated list of email addresses, at most 100. Example: 'alice@example.com,bob@example.com'."),
+			),
+		), conversationsHandler.UsersByEmailHandler)
+	}
+
+	// Recent activity is built on search.messages, so it is not available for bot tokens either
+	if isToolSupported(ToolUsersRecentActivity, provider.IsBotToken()) && shouldAddTool(ToolUsersRecentActivity, enabledTools, "") {
+		s.AddTool(mcp.NewTool(ToolUsersRecentActivity,
+			mcp.WithDescription("Get recent messages posted by a user across all channels, sorted by time (newest first). Useful for onboarding and handoff summaries."),
+			mcp.WithTitleAnnotation("Get User Recent Activity"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("user",
+				mcp.Required(),
+				mcp.Description("User ID or handle. Example: 'U1234567890' or '@username'."),
+			),
+			mcp.WithNumber("days",
+				mcp.DefaultNumber(7),
+				mcp.Description("How many days back to look for messages. Default is 7."),
+			),
+			mcp.WithNumber("limit",
+				mcp.DefaultNumber(20),
+				mcp.Description("The maximum number of messages to return. Must be an integer between 1 and 100."),
+			),
+		), conversationsHandler.UsersRecentActivityHandler)
+	}
+
+	// The activity feed is user-scoped, and the fallback for OAuth tokens is built on search.messages
+	if isToolSupported(ToolActivityFeed, provider.IsBotToken()) && shouldAddTool(ToolActivityFeed, enabledTools, "") {
+		s.AddTool(mcp.NewTool(ToolActivityFeed,
+			mcp.WithDescription("Get your recent activity across all channels, newest first: mentions, reactions to your messages and new replies in threads you follow. Browser session tokens (xoxc/xoxd) read Slack's activity feed directly; other tokens fall back to searching for mentions only. Returns CSV with columns: type, time, channelID, channelName, msgID, threadTs, userID, userName, reaction, unread, cursor."),
+			mcp.WithTitleAnnotation("Get Activity Feed"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("types",
+				mcp.DefaultString("mentions,reactions,threads"),
+				mcp.Description("Comma-separated kinds of activity to return. Allowed values: 'mentions', 'reactions', 'threads'."),
+			),
+			mcp.WithNumber("limit",
+				mcp.DefaultNumber(20),
+				mcp.Description("The maximum number of items to return. Must be an integer between 1 and 50."),
+			),
+			mcp.WithString("cursor",
+				mcp.Description("Cursor for pagination. Use the value of the last row and column in the response as next_cursor field returned from the previous request."),
+			),
+		), conversationsHandler.ActivityFeedHandler)
+	}
+
 	// Register unreads tool - gets all unread messages across channels efficiently.
 	// Bot tokens (xoxb) don't support unread tracking, so exclude them (same pattern as search tool).
-	if !provider.IsBotToken() && shouldAddTool(ToolConversationsUnreads, enabledTools, "") {
+	if isToolSupported(ToolConversationsUnreads, provider.IsBotToken()) && shouldAddTool(ToolConversationsUnreads, enabledTools, "") {
 		s.AddTool(mcp.NewTool(ToolConversationsUnreads,
 			mcp.WithDescription("Get unread messages across all channels. With browser session tokens (xoxc/xoxd), uses a single API call for complete results. With OAuth user tokens (xoxp), scans a subset of channels per type (limited by max_channels) — results may be partial on large workspaces. Results are prioritized: DMs > group DMs > partner channels > internal channels."),
 			mcp.WithTitleAnnotation("Get Unread Messages"),
@@ -331,6 +802,14 @@ func NewMCPServer(provider *provider.ApiProvider, logger *zap.Logger, enabledToo
 				mcp.Description("If true, includes muted channels in results. Default is false (muted channels are excluded, matching Slack app behavior)."),
 				mcp.DefaultBool(false),
 			),
+			mcp.WithBoolean("group_by_channel",
+				mcp.Description("If true (and include_messages is true), returns JSON with messages nested under each channel object instead of a flat CSV. Default is false."),
+				mcp.DefaultBool(false),
+			),
+			mcp.WithBoolean("counts_only",
+				mcp.Description("If true, returns only approximate totals per conversation type (dm, group_dm, partner, internal, total) as CSV with columns type, channels and mentions, straight from client.counts with no per-channel calls. Meant for fast unread badges. Requires browser session tokens (xoxc/xoxd). Default is false."),
+				mcp.DefaultBool(false),
+			),
 		), conversationsHandler.ConversationsUnreadsHandler)
 	}
 
@@ -347,8 +826,79 @@ func NewMCPServer(provider *provider.ApiProvider, logger *zap.Logger, enabledToo
 			mcp.WithString("ts",
 				mcp.Description("Timestamp of the message to mark as read up to. If not provided, marks all messages as read."),
 			),
+			mcp.WithString("target",
+				mcp.Description("Set to 'latest' to explicitly mark up to the newest message, the same as omitting ts. Cannot be combined with ts."),
+			),
 		), conversationsHandler.ConversationsMarkHandler)
 	}
+
+	if shouldAddTool(ToolConversationsClose, enabledTools, "SLACK_MCP_MEMBERSHIP_TOOL") {
+		s.AddTool(mcp.NewTool(ToolConversationsClose,
+			mcp.WithDescription("Close a direct message (DM) or group DM, hiding it from the sidebar. Does not apply to channels."),
+			mcp.WithTitleAnnotation("Close DM"),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithString("channel_id",
+				mcp.Required(),
+				mcp.Description("ID of the DM or group DM in format Dxxxxxxxxxx, or a DM name starting with @... (e.g., @username)."),
+			),
+		), conversationsHandler.ConversationsCloseHandler)
+	}
+
+	if shouldAddTool(ToolConversationsMyDMs, enabledTools, "") {
+		s.AddTool(mcp.NewTool(ToolConversationsMyDMs,
+			mcp.WithDescription("List your direct messages and group DMs sorted by latest activity, with the other participants and a preview of the latest message of each. Does not require unread state. At most 100 DMs are scanned: with browser session tokens (xoxc/xoxd) the most recently active ones, with OAuth tokens the first ones in Slack's listing order, which may miss recent activity in older DMs."),
+			mcp.WithTitleAnnotation("My DMs"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithNumber("limit",
+				mcp.DefaultNumber(20),
+				mcp.Description("The maximum number of DMs to return. Must be an integer between 1 and 100."),
+			),
+		), conversationsHandler.ConversationsMyDMsHandler)
+	}
+
+	if shouldAddTool(ToolConversationsParticipants, enabledTools, "") {
+		s.AddTool(mcp.NewTool(ToolConversationsParticipants,
+			mcp.WithDescription("List who is in a direct message or group DM: the other user of a DM, or all members of a group DM, resolved to handles. Returns CSV with columns: userID, userName, realName."),
+			mcp.WithTitleAnnotation("Get DM Participants"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("channel_id",
+				mcp.Required(),
+				mcp.Description("ID of the DM (Dxxxxxxxxxx) or group DM, or @username for a DM."),
+			),
+		), conversationsHandler.ConversationsParticipantsHandler)
+	}
+
+	if isToolSupported(ToolSavedAdd, provider.IsBotToken()) && shouldAddTool(ToolSavedAdd, enabledTools, "SLACK_MCP_SAVED_TOOL") {
+		s.AddTool(mcp.NewTool(ToolSavedAdd,
+			mcp.WithDescription("Save a message or file for later. Pass either channel_id and timestamp of a message, or file_id of a file."),
+			mcp.WithTitleAnnotation("Save Item"),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithString("channel_id",
+				mcp.Description("ID of the channel in format Cxxxxxxxxxx or its name starting with #... or @... aka #general or @username_dm."),
+			),
+			mcp.WithString("timestamp",
+				mcp.Description("Timestamp of the message to save, in format 1234567890.123456."),
+			),
+			mcp.WithString("file_id",
+				mcp.Description("ID of the file to save in format Fxxxxxxxxxx. Cannot be combined with channel_id and timestamp."),
+			),
+		), conversationsHandler.SavedAddHandler)
+	}
+
+	if isToolSupported(ToolSavedList, provider.IsBotToken()) && shouldAddTool(ToolSavedList, enabledTools, "") {
+		s.AddTool(mcp.NewTool(ToolSavedList,
+			mcp.WithDescription("List your saved messages and files, most recently saved first."),
+			mcp.WithTitleAnnotation("List Saved Items"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithNumber("limit",
+				mcp.DefaultNumber(20),
+				mcp.Description("The maximum number of items to return. Must be an integer between 1 and 100."),
+			),
+			mcp.WithString("cursor",
+				mcp.Description("Cursor for pagination. Use the value of the last row and column in the response as next_cursor field returned from the previous request."),
+			),
+		), conversationsHandler.SavedListHandler)
+	}
 	channelsHandler := handler.NewChannelsHandler(provider, logger)
 	usergroupsHandler := handler.NewUsergroupsHandler(provider, logger)
 
@@ -362,7 +912,7 @@ func NewMCPServer(provider *provider.ApiProvider, logger *zap.Logger, enabledToo
 				mcp.Description("Comma-separated channel types. Allowed values: 'mpim', 'im', 'public_channel', 'private_channel'. Example: 'public_channel,private_channel,im'"),
 			),
 			mcp.WithString("sort",
-				mcp.Description("Type of sorting. Allowed values: 'popularity' - sort by number of members/participants in each channel."),
+				mcp.Description("Type of sorting. Allowed values: 'popularity' - sort by number of members/participants in each channel, 'last_activity' - most recently active channels first (requires browser session tokens, falls back to popularity otherwise)."),
 			),
 			mcp.WithNumber("limit",
 				mcp.DefaultNumber(100),
@@ -371,9 +921,173 @@ func NewMCPServer(provider *provider.ApiProvider, logger *zap.Logger, enabledToo
 			mcp.WithString("cursor",
 				mcp.Description("Cursor for pagination. Use the value of the last row and column in the response as next_cursor field returned from the previous request."),
 			),
+			mcp.WithString("active_since",
+				mcp.Description("Only return channels with a message in this window, e.g. '7d', '2w' or '1m', to find living channels. Uses client.counts, so it requires browser session tokens (xoxc/xoxd) and only covers channels you are a member of; with other tokens the list is not filtered and a note says so."),
+			),
+			mcp.WithBoolean("refresh_member_counts",
+				mcp.Description("If true, fetch fresh member counts for the returned page via conversations.info before sorting. Costs one rate limited API call per returned channel; at most the first 50 channels are refreshed and a note says when the page was larger. Default is boolean false."),
+				mcp.DefaultBool(false),
+			),
 		), channelsHandler.ChannelsHandler)
 	}
 
+	if shouldAddTool(ToolChannelsListArchived, enabledTools, "") {
+		s.AddTool(mcp.NewTool(ToolChannelsListArchived,
+			mcp.WithDescription("Get list of archived public and private channels. Archived channels are not returned by channels_list."),
+			mcp.WithTitleAnnotation("List Archived Channels"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("query",
+				mcp.Description("Only return archived channels whose name contains this text. Example: 'project' or '#project-x'."),
+			),
+			mcp.WithNumber("limit",
+				mcp.DefaultNumber(100),
+				mcp.Description("The maximum number of items to return. Must be an integer between 1 and 1000 (maximum 999)."),
+			),
+			mcp.WithString("cursor",
+				mcp.Description("Cursor for pagination. Use the value of the last row and column in the response as next_cursor field returned from the previous request."),
+			),
+		), channelsHandler.ChannelsListArchivedHandler)
+	}
+
+	if shouldAddTool(ToolChannelsDefaults, enabledTools, "") {
+		s.AddTool(mcp.NewTool(ToolChannelsDefaults,
+			mcp.WithDescription("List the workspace's default channels, which every new member joins automatically. Useful for onboarding, e.g. which channels a new hire should be in. Returns CSV with columns: id, name, topic, purpose, memberCount. Requires a browser session token; with other tokens an empty list and a note are returned."),
+			mcp.WithTitleAnnotation("List Default Channels"),
+			mcp.WithReadOnlyHintAnnotation(true),
+		), channelsHandler.ChannelsDefaultsHandler)
+	}
+
+	if shouldAddTool(ToolChannelsByPrefix, enabledTools, "") {
+		s.AddTool(mcp.NewTool(ToolChannelsByPrefix,
+			mcp.WithDescription("List channels whose name starts with a prefix, sorted alphabetically. Useful for naming conventions, e.g. all incident channels with prefix 'inc-'. Returns CSV with columns: id, name, topic, purpose, memberCount."),
+			mcp.WithTitleAnnotation("List Channels by Prefix"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("prefix",
+				mcp.Required(),
+				mcp.Description("Name prefix to match, case-insensitive. A leading # is ignored. Example: 'inc-' or '#proj-'."),
+			),
+			mcp.WithString("channel_types",
+				mcp.Description("Comma-separated channel types. Allowed values: 'mpim', 'im', 'public_channel', 'private_channel'. Default is 'public_channel,private_channel'."),
+			),
+			mcp.WithNumber("limit",
+				mcp.DefaultNumber(100),
+				mcp.Description("The maximum number of items to return. Must be an integer between 1 and 1000 (maximum 999)."),
+			),
+			mcp.WithString("cursor",
+				mcp.Description("Cursor for pagination. Use the value of the last row and column in the response as next_cursor field returned from the previous request."),
+			),
+		), channelsHandler.ChannelsByPrefixHandler)
+	}
+
+	if shouldAddTool(ToolChannelsResources, enabledTools, "") {
+		s.AddTool(mcp.NewTool(ToolChannelsResources,
+			mcp.WithDescription("List the documents of a channel as a reference shelf: its canvases and posts plus any pinned files, each listed once with pinned files first. Returns CSV with columns: id, title, type, permalink, updated, pinned."),
+			mcp.WithTitleAnnotation("List Channel Resources"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("channel_id",
+				mcp.Required(),
+				mcp.Description("ID of the channel in format Cxxxxxxxxxx or its name starting with #... (e.g., #general)."),
+			),
+			mcp.WithNumber("limit",
+				mcp.DefaultNumber(100),
+				mcp.Description("The number of channel files scanned per page. Must be an integer between 1 and 100."),
+			),
+			mcp.WithString("cursor",
+				mcp.Description("Cursor for pagination. Use the value of the last row and column in the response as next_cursor field returned from the previous request."),
+			),
+		), channelsHandler.ChannelsResourcesHandler)
+	}
+
+	if shouldAddTool(ToolChannelsMemberCounts, enabledTools, "") {
+		s.AddTool(mcp.NewTool(ToolChannelsMemberCounts,
+			mcp.WithDescription("Get the member count of several channels at once. Counts are taken from the channels cache, and only channels missing from it or cached with no members are looked up with conversations.info. Returns CSV with columns: id, name, memberCount, source (cache, api or unavailable)."),
+			mcp.WithTitleAnnotation("Get Channel Member Counts"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("channel_ids",
+				mcp.Required(),
+				mcp.Description("Comma-separated list of at most 100 channels, each an ID in format Cxxxxxxxxxx or a name starting with #... (e.g., C1234567890,#general)."),
+			),
+		), channelsHandler.ChannelsMemberCountsHandler)
+	}
+
+	if shouldAddTool(ToolChannelsInvite, enabledTools, "SLACK_MCP_INVITE_TOOL") {
+		s.AddTool(mcp.NewTool(ToolChannelsInvite,
+			mcp.WithDescription("Invite users to a channel. Each user is invited separately and the result is reported per user, so users who are already members do not fail the whole request."),
+			mcp.WithTitleAnnotation("Invite Users to Channel"),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithString("channel_id",
+				mcp.Required(),
+				mcp.Description("ID of the channel in format Cxxxxxxxxxx or its name starting with #... (e.g., #general)."),
+			),
+			mcp.WithString("users",
+				mcp.Required(),
+				mcp.Description("Comma-separated list of users to invite, as user IDs (Uxxxxxxxxxx) or handles starting with @... (e.g., @alice,U0123456789)."),
+			),
+		), channelsHandler.ChannelsInviteHandler)
+	}
+
+	if shouldAddTool(ToolChannelsCreate, enabledTools, "SLACK_MCP_CHANNEL_ADMIN_TOOL") {
+		s.AddTool(mcp.NewTool(ToolChannelsCreate,
+			mcp.WithDescription("Create a public or private channel. Returns the ID and name of the new channel."),
+			mcp.WithTitleAnnotation("Create Channel"),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithString("name",
+				mcp.Required(),
+				mcp.Description("Name of the channel. Must be lowercase without spaces or periods, up to 80 characters of letters, numbers, hyphens and underscores (e.g., project-apollo)."),
+			),
+			mcp.WithBoolean("is_private",
+				mcp.DefaultBool(false),
+				mcp.Description("Create a private channel instead of a public one."),
+			),
+			mcp.WithString("topic",
+				mcp.Description("Optional initial topic of the channel."),
+			),
+			mcp.WithString("purpose",
+				mcp.Description("Optional initial purpose of the channel."),
+			),
+		), channelsHandler.ChannelsCreateHandler)
+	}
+
+	if shouldAddTool(ToolChannelsArchive, enabledTools, "SLACK_MCP_CHANNEL_ADMIN_TOOL") {
+		s.AddTool(mcp.NewTool(ToolChannelsArchive,
+			mcp.WithDescription("Archive a channel. Requires confirm=true. Returns the resulting state of the channel."),
+			mcp.WithTitleAnnotation("Archive Channel"),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithString("channel_id",
+				mcp.Required(),
+				mcp.Description("ID of the channel in format Cxxxxxxxxxx or its name starting with #... (e.g., #general)."),
+			),
+			mcp.WithBoolean("confirm",
+				mcp.Required(),
+				mcp.Description("Must be true to archive the channel."),
+			),
+		), channelsHandler.ChannelsArchiveHandler)
+	}
+
+	if shouldAddTool(ToolChannelsUnarchive, enabledTools, "SLACK_MCP_CHANNEL_ADMIN_TOOL") {
+		s.AddTool(mcp.NewTool(ToolChannelsUnarchive,
+			mcp.WithDescription("Unarchive a channel. Requires confirm=true. Returns the resulting state of the channel."),
+			mcp.WithTitleAnnotation("Unarchive Channel"),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithString("channel_id",
+				mcp.Required(),
+				mcp.Description("ID of the archived channel in format Cxxxxxxxxxx. Archived channels are not cached, so use the ID from channels_list_archived."),
+			),
+			mcp.WithBoolean("confirm",
+				mcp.Required(),
+				mcp.Description("Must be true to unarchive the channel."),
+			),
+		), channelsHandler.ChannelsUnarchiveHandler)
+	}
+
+	if shouldAddTool(ToolAdminTeamInfo, enabledTools, "SLACK_MCP_ADMIN_TOOL") {
+		s.AddTool(mcp.NewTool(ToolAdminTeamInfo,
+			mcp.WithDescription("Get non-sensitive workspace settings for admins: team name and domain, default channels, whether Slack Connect is enabled and retention settings. Settings the token cannot read are omitted. Returns CSV with columns: setting, value."),
+			mcp.WithTitleAnnotation("Get Team Info"),
+			mcp.WithReadOnlyHintAnnotation(true),
+		), channelsHandler.AdminTeamInfoHandler)
+	}
+
 	// User groups tools
 	if shouldAddTool(ToolUsergroupsList, enabledTools, "") {
 		s.AddTool(mcp.NewTool(ToolUsergroupsList,
@@ -470,6 +1184,33 @@ func NewMCPServer(provider *provider.ApiProvider, logger *zap.Logger, enabledToo
 		), usergroupsHandler.UsergroupsUsersUpdateHandler)
 	}
 
+	if shouldAddTool(ToolCapabilities, enabledTools, "") {
+		s.AddTool(mcp.NewTool(ToolCapabilities,
+			mcp.WithDescription("List the tools of this server and whether each one is usable with the current Slack token, with a reason for any that are not. Check this before calling tools that may be unsupported."),
+			mcp.WithTitleAnnotation("List Tool Capabilities"),
+			mcp.WithReadOnlyHintAnnotation(true),
+		), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			caps := toolCapabilities(provider.IsBotToken(), func(name string) bool {
+				return s.GetTool(name) != nil
+			})
+			csvBytes, err := gocsv.MarshalBytes(&caps)
+			if err != nil {
+				logger.Error("Failed to marshal capabilities to CSV", zap.Error(err))
+				return nil, err
+			}
+			return mcp.NewToolResultText(string(csvBytes)), nil
+		})
+	}
+
+	rateLimitHandler := handler.NewRateLimitHandler(provider, logger)
+	if shouldAddTool(ToolRateLimitStatus, enabledTools, "") {
+		s.AddTool(mcp.NewTool(ToolRateLimitStatus,
+			mcp.WithDescription("Report whether Slack is currently rate limiting this server, based on recent HTTP 429 responses, and advise whether to wait. Check this before heavy operations such as deep search or unread scans. Makes no Slack API calls. Returns CSV with columns: Throttled, LastRateLimitedAt, RetryAfterSeconds, WaitSeconds, RecentRateLimited, Advice."),
+			mcp.WithTitleAnnotation("Get Rate Limit Status"),
+			mcp.WithReadOnlyHintAnnotation(true),
+		), rateLimitHandler.RateLimitStatusHandler)
+	}
+
 	logger.Info("Authenticating with Slack API...",
 		zap.String("context", "console"),
 	)
@@ -586,6 +1327,220 @@ func buildErrorRecoveryMiddleware(logger *zap.Logger) server.ToolHandlerMiddlewa
 	}
 }
 
+// defaultRetryBudget is the number of Slack API retries one tool call may make
+// in total when SLACK_MCP_RETRY_BUDGET is not set.
+const defaultRetryBudget = 20
+
+// retryBudget reads SLACK_MCP_RETRY_BUDGET, 0 means unlimited.
+func retryBudget(logger *zap.Logger) int {
+	raw := os.Getenv("SLACK_MCP_RETRY_BUDGET")
+	if raw == "" {
+		return defaultRetryBudget
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		logger.Warn("Invalid SLACK_MCP_RETRY_BUDGET, using the default",
+			zap.String("context", "console"),
+			zap.String("value", raw),
+			zap.Int("default", defaultRetryBudget),
+		)
+		return defaultRetryBudget
+	}
+	return n
+}
+
+// buildRetryBudgetMiddleware gives every tool call its own retry budget, shared
+// by all rate limited Slack calls the handler makes through limiter.CallWithRetry.
+func buildRetryBudgetMiddleware(budget int) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if budget <= 0 {
+				return next(ctx, req)
+			}
+			return next(limiter.WithRetryBudget(ctx, limiter.NewRetryBudget(budget)), req)
+		}
+	}
+}
+
+// maxOutputBytes reads SLACK_MCP_MAX_OUTPUT_BYTES, 0 means unlimited.
+func maxOutputBytes(logger *zap.Logger) int {
+	raw := os.Getenv("SLACK_MCP_MAX_OUTPUT_BYTES")
+	if raw == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		logger.Warn("Invalid SLACK_MCP_MAX_OUTPUT_BYTES, output size is not limited",
+			zap.String("context", "console"),
+			zap.String("value", raw),
+		)
+		return 0
+	}
+	return n
+}
+
+// buildOutputLimitMiddleware truncates text results larger than maxBytes to
+// complete rows or JSON elements, so oversized results are not silently
+// dropped by clients.
+func buildOutputLimitMiddleware(maxBytes int, logger *zap.Logger) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			res, err := next(ctx, req)
+			if err != nil || res == nil || maxBytes <= 0 {
+				return res, err
+			}
+
+			// CSV is cut to complete rows, keeping the last one with the
+			// cursor, and JSON arrays to complete elements. Other JSON is never
+			// cut, and anything else is cut to maxBytes. Notes are added as
+			// their own content so the CSV or JSON stays parseable.
+			var notes []mcp.Content
+			for i, content := range res.Content {
+				tc, ok := content.(mcp.TextContent)
+				if !ok || len(tc.Text) <= maxBytes {
+					continue
+				}
+				var (
+					truncated string
+					note      string
+				)
+				switch {
+				case text.LooksLikeCSV(tc.Text):
+					var rows, omitted int
+					truncated, rows, omitted, ok = text.TruncateRows(tc.Text, maxBytes)
+					note = fmt.Sprintf("output truncated to %d of %d rows, keeping the last row and its cursor when it fits; narrow your query or lower the limit", rows, rows+omitted)
+				case json.Valid([]byte(tc.Text)):
+					var items, omitted int
+					truncated, items, omitted, ok = text.TruncateJSONArray(tc.Text, maxBytes)
+					note = fmt.Sprintf("output truncated to %d of %d items; narrow your query or paginate", items, items+omitted)
+					if !ok {
+						logger.Warn("Tool output exceeds SLACK_MCP_MAX_OUTPUT_BYTES but is a JSON object, not truncating",
+							zap.String("tool", req.Params.Name),
+							zap.Int("bytes", len(tc.Text)),
+							zap.Int("max_bytes", maxBytes),
+						)
+						notes = append(notes, mcp.NewTextContent(fmt.Sprintf("output of %d bytes exceeds the %d byte limit but was not truncated to keep the JSON valid; narrow your query or paginate", len(tc.Text), maxBytes)))
+						continue
+					}
+				default:
+					truncated, ok = text.TruncateBytes(tc.Text, maxBytes)
+					note = fmt.Sprintf("output truncated at %d bytes; narrow your query or paginate", len(truncated))
+				}
+				if !ok {
+					continue
+				}
+				logger.Warn("Tool output exceeds SLACK_MCP_MAX_OUTPUT_BYTES, truncating",
+					zap.String("tool", req.Params.Name),
+					zap.Int("bytes", len(tc.Text)),
+					zap.Int("max_bytes", maxBytes),
+				)
+				tc.Text = truncated
+				res.Content[i] = tc
+				notes = append(notes, mcp.NewTextContent(note))
+			}
+			res.Content = append(res.Content, notes...)
+			return res, nil
+		}
+	}
+}
+
+// toolScopes names the OAuth scopes each tool needs, used to explain
+// missing_scope and not_allowed_token_type errors from Slack
+var toolScopes = map[string]string{
+	ToolConversationsHistory:        "channels:history, groups:history, im:history and mpim:history",
+	ToolConversationsReplies:        "channels:history, groups:history, im:history and mpim:history",
+	ToolConversationsThreadByLink:   "channels:history, groups:history, im:history and mpim:history",
+	ToolConversationsExtractLinks:   "channels:history, groups:history, im:history and mpim:history",
+	ToolConversationsAudit:          "channels:history, groups:history, im:history and mpim:history",
+	ToolConversationsBotMessages:    "channels:history, groups:history, im:history and mpim:history",
+	ToolConversationsExport:         "channels:history, groups:history, im:history and mpim:history",
+	ToolConversationsLatest:         "channels:history, groups:history, im:history and mpim:history",
+	ToolConversationsPinsList:       "pins:read",
+	ToolConversationsThreadsList:    "channels:history, groups:history, im:history and mpim:history",
+	ToolConversationsAddMessage:     "chat:write",
+	ToolReactionsAdd:                "reactions:write",
+	ToolReactionsRemove:             "reactions:write",
+	ToolAttachmentGetData:           "files:read",
+	ToolConversationsSearchMessages: "search:read",
+	ToolConversationsUnreads:        "channels:read, groups:read, im:read and mpim:read",
+	ToolConversationsMark:           "channels:write, groups:write, im:write and mpim:write",
+	ToolConversationsClose:          "im:write and mpim:write",
+	ToolConversationsMyDMs:          "im:read and mpim:read",
+	ToolConversationsParticipants:   "im:read and mpim:read",
+	ToolSavedAdd:                    "stars:write",
+	ToolSavedList:                   "stars:read",
+	ToolChannelsList:                "channels:read and groups:read",
+	ToolChannelsListArchived:        "channels:read and groups:read",
+	ToolChannelsDefaults:            "channels:read and groups:read",
+	ToolChannelsByPrefix:            "channels:read and groups:read",
+	ToolChannelsResources:           "files:read and pins:read",
+	ToolChannelsMemberCounts:        "channels:read, groups:read, im:read and mpim:read",
+	ToolChannelsInvite:              "channels:write.invites and groups:write.invites",
+	ToolChannelsCreate:              "channels:write and groups:write",
+	ToolChannelsArchive:             "channels:write and groups:write",
+	ToolChannelsUnarchive:           "channels:write and groups:write",
+	ToolAdminTeamInfo:               "team:read",
+	ToolUsergroupsList:              "usergroups:read",
+	ToolUsergroupsMe:                "usergroups:read",
+	ToolUsergroupsCreate:            "usergroups:write",
+	ToolUsergroupsUpdate:            "usergroups:write",
+	ToolUsergroupsUsersUpdate:       "usergroups:write",
+	ToolUsersSearch:                 "users:read",
+	ToolUsersRecentActivity:         "search:read",
+	ToolActivityFeed:                "search:read",
+	ToolUsersProfile:                "users.profile:read",
+	ToolUsersByEmail:                "users:read and users:read.email",
+}
+
+// scopeGuidance replaces an opaque missing_scope or not_allowed_token_type
+// error from Slack with one naming the scopes tool needs. Other errors, and
+// tools without known scopes, are returned unchanged.
+func scopeGuidance(tool string, err error) error {
+	scopes, ok := toolScopes[tool]
+	if err == nil || !ok {
+		return err
+	}
+
+	code := err.Error()
+	var slackErr slack.SlackErrorResponse
+	if errors.As(err, &slackErr) {
+		code = slackErr.Err
+	}
+	switch code {
+	case "missing_scope":
+		return fmt.Errorf("%s requires the %s scope; re-authorize the Slack app with it: %w", tool, scopes, err)
+	case "not_allowed_token_type":
+		return fmt.Errorf("%s is not available to this token type; it needs a user token (xoxp) or browser session tokens (xoxc/xoxd) with the %s scope: %w", tool, scopes, err)
+	}
+	return err
+}
+
+// buildScopeGuidanceMiddleware explains scope errors of tool calls, see scopeGuidance.
+func buildScopeGuidanceMiddleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			res, err := next(ctx, req)
+			return res, scopeGuidance(req.Params.Name, err)
+		}
+	}
+}
+
+// buildMetricsMiddleware reports each tool call's latency and outcome to rec.
+// Both Go errors and results flagged IsError count as failures.
+func buildMetricsMiddleware(rec metrics.Recorder) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			startTime := time.Now()
+
+			res, err := next(ctx, req)
+
+			rec.ObserveToolCall(req.Params.Name, time.Since(startTime), err != nil || (res != nil && res.IsError))
+
+			return res, err
+		}
+	}
+}
+
 func buildLoggerMiddleware(logger *zap.Logger) server.ToolHandlerMiddleware {
 	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
 		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -3,15 +3,21 @@ package server
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/korotovsky/slack-mcp-server/pkg/limiter"
+	"github.com/korotovsky/slack-mcp-server/pkg/metrics"
 	"github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/client/transport"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/slack-go/slack"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -22,6 +28,7 @@ func TestShouldAddTool_ReadOnly_EmptyEnabledTools(t *testing.T) {
 		readOnlyTools := []string{
 			ToolConversationsHistory,
 			ToolConversationsReplies,
+			ToolConversationsThreadByLink,
 			ToolConversationsSearchMessages,
 			ToolChannelsList,
 			ToolUsersSearch,
@@ -96,6 +103,14 @@ func TestValidToolNames(t *testing.T) {
 		expectedTools := map[string]bool{
 			ToolConversationsHistory:        true,
 			ToolConversationsReplies:        true,
+			ToolConversationsThreadByLink:   true,
+			ToolConversationsExtractLinks:   true,
+			ToolConversationsAudit:          true,
+			ToolConversationsBotMessages:    true,
+			ToolConversationsExport:         true,
+			ToolConversationsLatest:         true,
+			ToolConversationsPinsList:       true,
+			ToolConversationsThreadsList:    true,
 			ToolConversationsAddMessage:     true,
 			ToolReactionsAdd:                true,
 			ToolReactionsRemove:             true,
@@ -103,13 +118,34 @@ func TestValidToolNames(t *testing.T) {
 			ToolConversationsSearchMessages: true,
 			ToolConversationsUnreads:        true,
 			ToolConversationsMark:           true,
+			ToolConversationsClose:          true,
+			ToolConversationsMyDMs:          true,
+			ToolConversationsParticipants:   true,
+			ToolSavedAdd:                    true,
+			ToolSavedList:                   true,
 			ToolChannelsList:                true,
+			ToolChannelsListArchived:        true,
+			ToolChannelsDefaults:            true,
+			ToolChannelsByPrefix:            true,
+			ToolChannelsResources:           true,
+			ToolChannelsMemberCounts:        true,
+			ToolChannelsInvite:              true,
+			ToolChannelsCreate:              true,
+			ToolChannelsArchive:             true,
+			ToolChannelsUnarchive:           true,
+			ToolAdminTeamInfo:               true,
 			ToolUsergroupsList:              true,
 			ToolUsergroupsMe:                true,
 			ToolUsergroupsCreate:            true,
 			ToolUsergroupsUpdate:            true,
 			ToolUsergroupsUsersUpdate:       true,
 			ToolUsersSearch:                 true,
+			ToolUsersRecentActivity:         true,
+			ToolActivityFeed:                true,
+			ToolUsersProfile:                true,
+			ToolUsersByEmail:                true,
+			ToolCapabilities:                true,
+			ToolRateLimitStatus:             true,
 		}
 
 		assert.Equal(t, len(expectedTools), len(ValidToolNames), "ValidToolNames should have %d tools", len(expectedTools))
@@ -122,6 +158,14 @@ func TestValidToolNames(t *testing.T) {
 	t.Run("constants match their string values", func(t *testing.T) {
 		assert.Equal(t, "conversations_history", ToolConversationsHistory)
 		assert.Equal(t, "conversations_replies", ToolConversationsReplies)
+		assert.Equal(t, "conversations_thread_by_link", ToolConversationsThreadByLink)
+		assert.Equal(t, "conversations_extract_links", ToolConversationsExtractLinks)
+		assert.Equal(t, "conversations_audit", ToolConversationsAudit)
+		assert.Equal(t, "conversations_bot_messages", ToolConversationsBotMessages)
+		assert.Equal(t, "conversations_export", ToolConversationsExport)
+		assert.Equal(t, "conversations_latest", ToolConversationsLatest)
+		assert.Equal(t, "conversations_pins_list", ToolConversationsPinsList)
+		assert.Equal(t, "conversations_threads_list", ToolConversationsThreadsList)
 		assert.Equal(t, "conversations_add_message", ToolConversationsAddMessage)
 		assert.Equal(t, "reactions_add", ToolReactionsAdd)
 		assert.Equal(t, "reactions_remove", ToolReactionsRemove)
@@ -129,13 +173,34 @@ func TestValidToolNames(t *testing.T) {
 		assert.Equal(t, "conversations_search_messages", ToolConversationsSearchMessages)
 		assert.Equal(t, "conversations_unreads", ToolConversationsUnreads)
 		assert.Equal(t, "conversations_mark", ToolConversationsMark)
+		assert.Equal(t, "conversations_close", ToolConversationsClose)
+		assert.Equal(t, "conversations_my_dms", ToolConversationsMyDMs)
+		assert.Equal(t, "conversations_participants", ToolConversationsParticipants)
+		assert.Equal(t, "saved_add", ToolSavedAdd)
+		assert.Equal(t, "saved_list", ToolSavedList)
 		assert.Equal(t, "channels_list", ToolChannelsList)
+		assert.Equal(t, "channels_list_archived", ToolChannelsListArchived)
+		assert.Equal(t, "channels_defaults", ToolChannelsDefaults)
+		assert.Equal(t, "channels_by_prefix", ToolChannelsByPrefix)
+		assert.Equal(t, "channels_resources", ToolChannelsResources)
+		assert.Equal(t, "channels_member_counts", ToolChannelsMemberCounts)
+		assert.Equal(t, "channels_invite", ToolChannelsInvite)
+		assert.Equal(t, "channels_create", ToolChannelsCreate)
+		assert.Equal(t, "channels_archive", ToolChannelsArchive)
+		assert.Equal(t, "channels_unarchive", ToolChannelsUnarchive)
+		assert.Equal(t, "admin_team_info", ToolAdminTeamInfo)
 		assert.Equal(t, "usergroups_list", ToolUsergroupsList)
 		assert.Equal(t, "usergroups_me", ToolUsergroupsMe)
 		assert.Equal(t, "usergroups_create", ToolUsergroupsCreate)
 		assert.Equal(t, "usergroups_update", ToolUsergroupsUpdate)
 		assert.Equal(t, "usergroups_users_update", ToolUsergroupsUsersUpdate)
 		assert.Equal(t, "users_search", ToolUsersSearch)
+		assert.Equal(t, "users_recent_activity", ToolUsersRecentActivity)
+		assert.Equal(t, "activity_feed", ToolActivityFeed)
+		assert.Equal(t, "users_profile", ToolUsersProfile)
+		assert.Equal(t, "users_by_email", ToolUsersByEmail)
+		assert.Equal(t, "capabilities", ToolCapabilities)
+		assert.Equal(t, "rate_limit_status", ToolRateLimitStatus)
 	})
 }
 
@@ -399,6 +464,259 @@ func TestIntegrationErrorRecoveryMiddleware(t *testing.T) {
 	})
 }
 
+func TestIntegrationOutputLimitMiddleware(t *testing.T) {
+	logger := zap.NewNop()
+
+	var sb strings.Builder
+	sb.WriteString("MsgID,Text\n")
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&sb, "%d,\"row %d, with a comma\"\n", i, i)
+	}
+	large := sb.String()
+
+	t.Run("large result is truncated to complete rows with a note", func(t *testing.T) {
+		c := setupMCPClientServer(t,
+			[]server.ServerOption{server.WithToolHandlerMiddleware(buildOutputLimitMiddleware(1024, logger))},
+			func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText(large), nil
+			},
+		)
+
+		var callReq mcp.CallToolRequest
+		callReq.Params.Name = "test_tool"
+		result, err := c.CallTool(context.Background(), callReq)
+
+		require.NoError(t, err)
+		require.Len(t, result.Content, 2, "the note is its own content")
+		body := result.Content[0].(mcp.TextContent).Text
+		note := result.Content[1].(mcp.TextContent).Text
+
+		assert.LessOrEqual(t, len(body), 1024)
+		assert.True(t, strings.HasPrefix(body, "MsgID,Text\n"))
+		assert.True(t, strings.HasSuffix(body, "999,\"row 999, with a comma\"\n"), "the last row with the cursor should be kept")
+		assert.Contains(t, body, "\n0,\"row 0, with a comma\"\n", "the first rows should be kept")
+		assert.NotContains(t, body, "output truncated")
+
+		rows := strings.Count(body, "\n") - 1
+		assert.Equal(t, fmt.Sprintf("output truncated to %d of 1000 rows, keeping the last row and its cursor when it fits; narrow your query or lower the limit", rows), note)
+	})
+
+	t.Run("JSON array is truncated to complete elements", func(t *testing.T) {
+		items := make([]string, 200)
+		for i := range items {
+			items[i] = fmt.Sprintf(`{"id":%d,"name":"item %d"}`, i, i)
+		}
+		array := "[" + strings.Join(items, ",") + "]"
+		c := setupMCPClientServer(t,
+			[]server.ServerOption{server.WithToolHandlerMiddleware(buildOutputLimitMiddleware(1024, logger))},
+			func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText(array), nil
+			},
+		)
+
+		var callReq mcp.CallToolRequest
+		callReq.Params.Name = "test_tool"
+		result, err := c.CallTool(context.Background(), callReq)
+
+		require.NoError(t, err)
+		require.Len(t, result.Content, 2)
+		body := result.Content[0].(mcp.TextContent).Text
+		assert.LessOrEqual(t, len(body), 1024)
+		var decoded []map[string]any
+		require.NoError(t, json.Unmarshal([]byte(body), &decoded), "truncated JSON should stay valid")
+		assert.Equal(t, fmt.Sprintf("output truncated to %d of 200 items; narrow your query or paginate", len(decoded)), result.Content[1].(mcp.TextContent).Text)
+	})
+
+	t.Run("JSON object is never cut", func(t *testing.T) {
+		object := `{"text":"` + strings.Repeat("x", 2000) + `"}`
+		c := setupMCPClientServer(t,
+			[]server.ServerOption{server.WithToolHandlerMiddleware(buildOutputLimitMiddleware(1024, logger))},
+			func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText(object), nil
+			},
+		)
+
+		var callReq mcp.CallToolRequest
+		callReq.Params.Name = "test_tool"
+		result, err := c.CallTool(context.Background(), callReq)
+
+		require.NoError(t, err)
+		require.Len(t, result.Content, 2)
+		assert.Equal(t, object, result.Content[0].(mcp.TextContent).Text)
+		assert.Contains(t, result.Content[1].(mcp.TextContent).Text, "was not truncated to keep the JSON valid")
+	})
+
+	t.Run("single line result is byte capped", func(t *testing.T) {
+		line := strings.Repeat("x", 2000)
+		c := setupMCPClientServer(t,
+			[]server.ServerOption{server.WithToolHandlerMiddleware(buildOutputLimitMiddleware(1024, logger))},
+			func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText(line), nil
+			},
+		)
+
+		var callReq mcp.CallToolRequest
+		callReq.Params.Name = "test_tool"
+		result, err := c.CallTool(context.Background(), callReq)
+
+		require.NoError(t, err)
+		require.Len(t, result.Content, 2)
+		assert.Equal(t, line[:1024], result.Content[0].(mcp.TextContent).Text)
+		assert.Equal(t, "output truncated at 1024 bytes; narrow your query or paginate", result.Content[1].(mcp.TextContent).Text)
+	})
+
+	t.Run("header longer than the limit is byte capped", func(t *testing.T) {
+		header := strings.Repeat("Column,", 200) + "Last\n1\n"
+		c := setupMCPClientServer(t,
+			[]server.ServerOption{server.WithToolHandlerMiddleware(buildOutputLimitMiddleware(1024, logger))},
+			func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText(header), nil
+			},
+		)
+
+		var callReq mcp.CallToolRequest
+		callReq.Params.Name = "test_tool"
+		result, err := c.CallTool(context.Background(), callReq)
+
+		require.NoError(t, err)
+		require.Len(t, result.Content, 2)
+		assert.Len(t, result.Content[0].(mcp.TextContent).Text, 1024)
+		assert.Equal(t, "output truncated to 0 of 1 rows, keeping the last row and its cursor when it fits; narrow your query or lower the limit", result.Content[1].(mcp.TextContent).Text)
+	})
+
+	t.Run("small result passes through unchanged", func(t *testing.T) {
+		c := setupMCPClientServer(t,
+			[]server.ServerOption{server.WithToolHandlerMiddleware(buildOutputLimitMiddleware(1024, logger))},
+			func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText("all good"), nil
+			},
+		)
+
+		var callReq mcp.CallToolRequest
+		callReq.Params.Name = "test_tool"
+		result, err := c.CallTool(context.Background(), callReq)
+
+		require.NoError(t, err)
+		textContent, ok := result.Content[0].(mcp.TextContent)
+		require.True(t, ok)
+		assert.Equal(t, "all good", textContent.Text)
+	})
+}
+
+func TestIntegrationMetricsMiddleware(t *testing.T) {
+	m := metrics.New()
+	fail := false
+
+	c := setupMCPClientServer(t,
+		[]server.ServerOption{server.WithToolHandlerMiddleware(buildMetricsMiddleware(m))},
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if fail {
+				return mcp.NewToolResultError("simulated failure"), nil
+			}
+			return mcp.NewToolResultText("all good"), nil
+		},
+	)
+
+	var callReq mcp.CallToolRequest
+	callReq.Params.Name = "test_tool"
+
+	_, err := c.CallTool(context.Background(), callReq)
+	require.NoError(t, err)
+	_, err = c.CallTool(context.Background(), callReq)
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(2), m.ToolCalls("test_tool"))
+	assert.Equal(t, uint64(0), m.ToolErrors("test_tool"))
+
+	fail = true
+	_, err = c.CallTool(context.Background(), callReq)
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(3), m.ToolCalls("test_tool"))
+	assert.Equal(t, uint64(1), m.ToolErrors("test_tool"))
+
+	var buf bytes.Buffer
+	require.NoError(t, m.WriteText(&buf))
+	assert.Contains(t, buf.String(), `slack_mcp_tool_calls_total{tool="test_tool"} 3`)
+	assert.Contains(t, buf.String(), `slack_mcp_tool_duration_seconds_count{tool="test_tool"} 3`)
+}
+
+func TestIntegrationRetryBudgetMiddleware(t *testing.T) {
+	var budgets []*limiter.RetryBudget
+	c := setupMCPClientServer(t,
+		[]server.ServerOption{server.WithToolHandlerMiddleware(buildRetryBudgetMiddleware(5))},
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			budgets = append(budgets, limiter.RetryBudgetFromContext(ctx))
+			return mcp.NewToolResultText("all good"), nil
+		},
+	)
+
+	var callReq mcp.CallToolRequest
+	callReq.Params.Name = "test_tool"
+	for i := 0; i < 2; i++ {
+		_, err := c.CallTool(context.Background(), callReq)
+		require.NoError(t, err)
+	}
+
+	require.Len(t, budgets, 2)
+	require.NotNil(t, budgets[0])
+	assert.Equal(t, 5, budgets[0].Remaining())
+	assert.NotSame(t, budgets[0], budgets[1], "every tool call gets its own budget")
+}
+
+func TestRetryBudget(t *testing.T) {
+	logger := zap.NewNop()
+
+	t.Setenv("SLACK_MCP_RETRY_BUDGET", "")
+	assert.Equal(t, defaultRetryBudget, retryBudget(logger))
+
+	t.Setenv("SLACK_MCP_RETRY_BUDGET", "3")
+	assert.Equal(t, 3, retryBudget(logger))
+
+	t.Setenv("SLACK_MCP_RETRY_BUDGET", "0")
+	assert.Equal(t, 0, retryBudget(logger), "0 disables the budget")
+
+	t.Setenv("SLACK_MCP_RETRY_BUDGET", "lots")
+	assert.Equal(t, defaultRetryBudget, retryBudget(logger))
+}
+
+func TestScopeGuidance(t *testing.T) {
+	t.Run("missing scope names the tool's scope", func(t *testing.T) {
+		slackErr := slack.SlackErrorResponse{Err: "missing_scope"}
+		err := scopeGuidance(ToolConversationsSearchMessages, slackErr)
+		assert.EqualError(t, err, "conversations_search_messages requires the search:read scope; re-authorize the Slack app with it: missing_scope")
+		var wrapped slack.SlackErrorResponse
+		require.ErrorAs(t, err, &wrapped, "the Slack error stays in the chain")
+		assert.Equal(t, "missing_scope", wrapped.Err)
+	})
+
+	t.Run("wrapped slack errors are detected", func(t *testing.T) {
+		err := scopeGuidance(ToolUsergroupsCreate, fmt.Errorf("failed to create usergroup: %w", slack.SlackErrorResponse{Err: "missing_scope"}))
+		assert.ErrorContains(t, err, "usergroups_create requires the usergroups:write scope")
+	})
+
+	t.Run("token type errors", func(t *testing.T) {
+		err := scopeGuidance(ToolUsersRecentActivity, errors.New("not_allowed_token_type"))
+		assert.ErrorContains(t, err, "users_recent_activity is not available to this token type")
+		assert.ErrorContains(t, err, "search:read")
+	})
+
+	t.Run("other errors are unchanged", func(t *testing.T) {
+		orig := slack.SlackErrorResponse{Err: "channel_not_found"}
+		assert.Equal(t, error(orig), scopeGuidance(ToolConversationsHistory, orig))
+		assert.NoError(t, scopeGuidance(ToolConversationsHistory, nil))
+	})
+
+	t.Run("tools without scopes are unchanged", func(t *testing.T) {
+		orig := errors.New("missing_scope")
+		assert.Equal(t, orig, scopeGuidance(ToolRateLimitStatus, orig))
+	})
+
+	for tool := range toolScopes {
+		assert.Contains(t, ValidToolNames, tool)
+	}
+}
+
 func TestShouldAddTool_Matrix(t *testing.T) {
 	// Test the complete matrix from the plan:
 	// | ENABLED_TOOLS | TOOL_ENV_VAR | Result |
@@ -469,3 +787,40 @@ func TestShouldAddTool_Matrix(t *testing.T) {
 		})
 	}
 }
+
+func TestToolCapabilities(t *testing.T) {
+	allRegistered := func(string) bool { return true }
+	byName := func(caps []ToolCapability) map[string]ToolCapability {
+		m := make(map[string]ToolCapability, len(caps))
+		for _, c := range caps {
+			m[c.Name] = c
+		}
+		return m
+	}
+
+	t.Run("bot token reports user-only tools as unavailable", func(t *testing.T) {
+		caps := byName(toolCapabilities(true, allRegistered))
+		require.Len(t, caps, len(ValidToolNames))
+
+		for _, name := range []string{ToolConversationsUnreads, ToolConversationsSearchMessages, ToolUsersRecentActivity, ToolActivityFeed, ToolSavedAdd, ToolSavedList} {
+			assert.False(t, caps[name].Available, "%s should be unavailable for bot tokens", name)
+			assert.NotEmpty(t, caps[name].Reason, "%s should have a reason", name)
+		}
+		assert.True(t, caps[ToolConversationsHistory].Available)
+		assert.Empty(t, caps[ToolConversationsHistory].Reason)
+	})
+
+	t.Run("user token reports all registered tools as available", func(t *testing.T) {
+		for _, c := range toolCapabilities(false, allRegistered) {
+			assert.True(t, c.Available, "%s should be available", c.Name)
+		}
+	})
+
+	t.Run("unregistered tools are reported as disabled", func(t *testing.T) {
+		caps := byName(toolCapabilities(false, func(name string) bool {
+			return name != ToolConversationsAddMessage
+		}))
+		assert.False(t, caps[ToolConversationsAddMessage].Available)
+		assert.Equal(t, "disabled by server configuration", caps[ToolConversationsAddMessage].Reason)
+	})
+}
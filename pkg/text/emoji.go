@@ -0,0 +1,193 @@
+package text
+
+import (
+	"regexp"
+	"strings"
+)
+
+// EmojiMode controls how emoji shortcodes in message text are normalized.
+type EmojiMode struct {
+	// Custom is how workspace custom emoji are rendered: "annotate" wraps them
+	// as [:name:], "strip" drops them, empty leaves them untouched.
+	Custom string
+	// Unicode converts standard shortcodes such as :thumbsup: to unicode.
+	Unicode bool
+}
+
+func (m EmojiMode) Enabled() bool {
+	return m.Custom != "" || m.Unicode
+}
+
+// ParseEmojiMode parses a comma-separated SLACK_MCP_NORMALIZE_EMOJI value made
+// of "annotate" or "strip", and optionally "unicode". Unknown items are ignored.
+func ParseEmojiMode(config string) EmojiMode {
+	var mode EmojiMode
+	for _, item := range strings.Split(config, ",") {
+		switch strings.ToLower(strings.TrimSpace(item)) {
+		case "annotate":
+			mode.Custom = "annotate"
+		case "strip":
+			mode.Custom = "strip"
+		case "unicode":
+			mode.Unicode = true
+		}
+	}
+	return mode
+}
+
+var (
+	emojiShortcodeRegex = regexp.MustCompile(`:([a-zA-Z0-9_\-]+):`)
+	emojiSkinToneRegex  = regexp.MustCompile(`^skin-tone-[2-6]$`)
+	emojiDigitsRegex    = regexp.MustCompile(`^[0-9]+$`)
+	emojiSpacesRegex    = regexp.MustCompile(`[ \t]{2,}`)
+)
+
+// standardEmoji maps common standard Slack shortcodes to unicode.
+var standardEmoji = map[string]string{
+	"thumbsup":                      "👍",
+	"thumbsdown":                    "👎",
+	"-1":                            "👎",
+	"ok_hand":                       "👌",
+	"clap":                          "👏",
+	"wave":                          "👋",
+	"pray":                          "🙏",
+	"raised_hands":                  "🙌",
+	"muscle":                        "💪",
+	"point_up":                      "☝️",
+	"point_right":                   "👉",
+	"eyes":                          "👀",
+	"smile":                         "😄",
+	"smiley":                        "😃",
+	"grinning":                      "😀",
+	"laughing":                      "😆",
+	"joy":                           "😂",
+	"rolling_on_the_floor_laughing": "🤣",
+	"slightly_smiling_face":         "🙂",
+	"wink":                          "😉",
+	"blush":                         "😊",
+	"heart_eyes":                    "😍",
+	"thinking_face":                 "🤔",
+	"neutral_face":                  "😐",
+	"confused":                      "😕",
+	"disappointed":                  "😞",
+	"cry":                           "😢",
+	"sob":                           "😭",
+	"scream":                        "😱",
+	"sweat_smile":                   "😅",
+	"sunglasses":                    "😎",
+	"upside_down_face":              "🙃",
+	"facepalm":                      "🤦",
+	"shrug":                         "🤷",
+	"heart":                         "❤️",
+	"broken_heart":                  "💔",
+	"fire":                          "🔥",
+	"tada":                          "🎉",
+	"rocket":                        "🚀",
+	"star":                          "⭐",
+	"sparkles":                      "✨",
+	"100":                           "💯",
+	"boom":                          "💥",
+	"zap":                           "⚡",
+	"white_check_mark":              "✅",
+	"heavy_check_mark":              "✔️",
+	"x":                             "❌",
+	"warning":                       "⚠️",
+	"no_entry":                      "⛔",
+	"question":                      "❓",
+	"exclamation":                   "❗",
+	"bulb":                          "💡",
+	"memo":                          "📝",
+	"pushpin":                       "📌",
+	"link":                          "🔗",
+	"lock":                          "🔒",
+	"key":                           "🔑",
+	"bug":                           "🐛",
+	"hammer_and_wrench":             "🛠️",
+	"gear":                          "⚙️",
+	"calendar":                      "📆",
+	"hourglass":                     "⌛",
+	"stopwatch":                     "⏱️",
+	"chart_with_upwards_trend":      "📈",
+	"chart_with_downwards_trend":    "📉",
+	"coffee":                        "☕",
+	"beers":                         "🍻",
+	"cake":                          "🍰",
+	"trophy":                        "🏆",
+	"dart":                          "🎯",
+	"red_circle":                    "🔴",
+	"large_green_circle":            "🟢",
+	"large_yellow_circle":           "🟡",
+	"large_blue_circle":             "🔵",
+	"arrow_up":                      "⬆️",
+	"arrow_down":                    "⬇️",
+	"arrow_right":                   "➡️",
+	"arrow_left":                    "⬅️",
+	"speech_balloon":                "💬",
+	"mag":                           "🔍",
+	"email":                         "📧",
+	"phone":                         "☎️",
+	"computer":                      "💻",
+	"sunny":                         "☀️",
+	"rainbow":                       "🌈",
+	"see_no_evil":                   "🙈",
+	"skull":                         "💀",
+	"ghost":                         "👻",
+	"robot_face":                    "🤖",
+}
+
+// NormalizeEmoji rewrites emoji shortcodes in s according to mode. custom is
+// the workspace emoji catalog from emoji.list (name to URL or "alias:name").
+// Custom emoji carry no meaning for a reader without the image, so they are
+// annotated or stripped; standard ones are optionally converted to unicode.
+// Shortcodes that are neither are left untouched.
+func NormalizeEmoji(s string, custom map[string]string, mode EmojiMode) string {
+	if !mode.Enabled() {
+		return s
+	}
+
+	out := emojiShortcodeRegex.ReplaceAllStringFunc(s, func(match string) string {
+		name := strings.Trim(match, ":")
+		if emojiDigitsRegex.MatchString(name) && name != "100" {
+			// most likely a clock time such as 12:30:45
+			return match
+		}
+
+		if emojiSkinToneRegex.MatchString(name) {
+			if mode.Unicode {
+				return ""
+			}
+			return match
+		}
+
+		// aliases of standard emoji behave like the standard emoji
+		if target, ok := custom[name]; ok && strings.HasPrefix(target, "alias:") {
+			aliased := strings.TrimPrefix(target, "alias:")
+			if _, isCustom := custom[aliased]; !isCustom {
+				name = aliased
+			}
+		}
+
+		if _, ok := custom[name]; ok {
+			switch mode.Custom {
+			case "annotate":
+				return "[:" + name + ":]"
+			case "strip":
+				return ""
+			}
+			return match
+		}
+
+		if mode.Unicode {
+			if u, ok := standardEmoji[name]; ok {
+				return u
+			}
+		}
+		return match
+	})
+
+	if mode.Custom == "strip" || mode.Unicode {
+		// removed shortcodes leave double spaces behind
+		out = strings.TrimSpace(emojiSpacesRegex.ReplaceAllString(out, " "))
+	}
+	return out
+}
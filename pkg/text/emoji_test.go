@@ -0,0 +1,62 @@
+package text
+
+import (
+	"testing"
+)
+
+func TestParseEmojiMode(t *testing.T) {
+	tests := []struct {
+		config string
+		want   EmojiMode
+	}{
+		{"", EmojiMode{}},
+		{"annotate", EmojiMode{Custom: "annotate"}},
+		{"strip", EmojiMode{Custom: "strip"}},
+		{"unicode", EmojiMode{Unicode: true}},
+		{"Annotate, unicode", EmojiMode{Custom: "annotate", Unicode: true}},
+		{"bogus", EmojiMode{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.config, func(t *testing.T) {
+			if got := ParseEmojiMode(tt.config); got != tt.want {
+				t.Errorf("ParseEmojiMode(%q) = %+v, want %+v", tt.config, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeEmoji(t *testing.T) {
+	custom := map[string]string{
+		"partyparrot": "https://emoji.slack-edge.com/T1/partyparrot/abc.gif",
+		"parrot":      "alias:partyparrot",
+		"yes":         "alias:white_check_mark",
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		mode  EmojiMode
+		want  string
+	}{
+		{"disabled", "deploy :partyparrot: :thumbsup:", EmojiMode{}, "deploy :partyparrot: :thumbsup:"},
+		{"annotate custom", "deploy :partyparrot: done", EmojiMode{Custom: "annotate"}, "deploy [:partyparrot:] done"},
+		{"strip custom", "deploy :partyparrot: done", EmojiMode{Custom: "strip"}, "deploy done"},
+		{"standard left alone without unicode", "nice :thumbsup:", EmojiMode{Custom: "strip"}, "nice :thumbsup:"},
+		{"standard to unicode", "nice :thumbsup: :tada:", EmojiMode{Unicode: true}, "nice 👍 🎉"},
+		{"custom alias of custom emoji", "yay :parrot:", EmojiMode{Custom: "annotate"}, "yay [:parrot:]"},
+		{"custom alias of standard emoji", "ok :yes:", EmojiMode{Custom: "strip", Unicode: true}, "ok ✅"},
+		{"skin tone dropped with unicode", "hi :wave::skin-tone-3:", EmojiMode{Unicode: true}, "hi 👋"},
+		{"unknown shortcode untouched", "see :not_an_emoji_we_know:", EmojiMode{Custom: "strip", Unicode: true}, "see :not_an_emoji_we_know:"},
+		{"clock time untouched", "meet at 12:30:45", EmojiMode{Custom: "strip", Unicode: true}, "meet at 12:30:45"},
+		{"newlines kept", "line one :partyparrot:\nline two", EmojiMode{Custom: "strip"}, "line one \nline two"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeEmoji(tt.input, custom, tt.mode); got != tt.want {
+				t.Errorf("NormalizeEmoji(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
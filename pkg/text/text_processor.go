@@ -2,12 +2,15 @@ package text
 
 import (
 	"crypto/x509"
+	"encoding/json"
 	"fmt"
 	"net/url"
+	"os"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/slack-go/slack"
 	"go.uber.org/zap"
@@ -72,6 +75,60 @@ func AttachmentsTo2CSV(msgText string, attachments []slack.Attachment) string {
 	return prefix + strings.Join(descriptions, ", ")
 }
 
+// BlockActionsToText describes the interactive elements of actions blocks,
+// e.g. "[Button: Approve] [Button: Deny]", so the choices an app offered
+// survive in the plain text of a message.
+func BlockActionsToText(blocks slack.Blocks) string {
+	var parts []string
+	for _, block := range blocks.BlockSet {
+		action, ok := block.(*slack.ActionBlock)
+		if !ok || action.Elements == nil {
+			continue
+		}
+		for _, element := range action.Elements.ElementSet {
+			switch e := element.(type) {
+			case *slack.ButtonBlockElement:
+				if e.Text != nil && e.Text.Text != "" {
+					parts = append(parts, fmt.Sprintf("[Button: %s]", e.Text.Text))
+				}
+			case *slack.SelectBlockElement:
+				if e.Placeholder != nil && e.Placeholder.Text != "" {
+					parts = append(parts, fmt.Sprintf("[Select: %s]", e.Placeholder.Text))
+				}
+			}
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+var (
+	// Slack mention syntax: <@U123>, <@U123|name>, <!here>, <!subteam^S123|@group>
+	slackMentionRegex = regexp.MustCompile(`<(?:@([UW][A-Z0-9]+)|!(here|channel|everyone|subteam\^[A-Z0-9]+))(?:\|([^<>]*))?>`)
+	// Plain broadcast mentions: @channel, @here, @everyone, not inside an email address
+	broadcastMentionRegex = regexp.MustCompile(`(^|[^\w.@])@(channel|here|everyone)\b`)
+)
+
+// EscapeMentions neutralizes user, group and broadcast mentions in s so that
+// echoing it into Slack notifies nobody. Mentions stay readable, a zero-width
+// space after the @ is what breaks them.
+func EscapeMentions(s string) string {
+	s = slackMentionRegex.ReplaceAllStringFunc(s, func(m string) string {
+		parts := slackMentionRegex.FindStringSubmatch(m)
+		label := strings.TrimPrefix(parts[3], "@")
+		switch {
+		case label != "":
+		case parts[1] != "":
+			label = parts[1]
+		case strings.HasPrefix(parts[2], "subteam^"):
+			label = strings.TrimPrefix(parts[2], "subteam^")
+		default:
+			label = parts[2]
+		}
+		return "@\u200b" + label
+	})
+	return broadcastMentionRegex.ReplaceAllString(s, "${1}@\u200b$2")
+}
+
 func IsUnfurlingEnabled(text string, opt string, logger *zap.Logger) bool {
 	if opt == "" || opt == "no" || opt == "false" || opt == "0" {
 		return false
@@ -153,32 +210,118 @@ func Workspace(rawURL string) (string, error) {
 }
 
 func TimestampToIsoRFC3339(slackTS string) (string, error) {
+	t, err := parseSlackTimestamp(slackTS)
+	if err != nil {
+		return "", err
+	}
+
+	return t.UTC().Format(time.RFC3339), nil
+}
+
+func parseSlackTimestamp(slackTS string) (time.Time, error) {
 	parts := strings.Split(slackTS, ".")
 	if len(parts) != 2 {
-		return "", fmt.Errorf("invalid slack timestamp format: %s", slackTS)
+		return time.Time{}, fmt.Errorf("invalid slack timestamp format: %s", slackTS)
 	}
 
 	seconds, err := strconv.ParseInt(parts[0], 10, 64)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse seconds: %v", err)
+		return time.Time{}, fmt.Errorf("failed to parse seconds: %v", err)
 	}
 
 	microseconds, err := strconv.ParseInt(parts[1], 10, 64)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse microseconds: %v", err)
+		return time.Time{}, fmt.Errorf("failed to parse microseconds: %v", err)
 	}
 
-	t := time.Unix(seconds, microseconds*1000)
-
-	return t.UTC().Format(time.RFC3339), nil
+	return time.Unix(seconds, microseconds*1000), nil
 }
 
 func ProcessText(s string) string {
-	s = filterSpecialChars(s)
+	s = filterOutsideCode(s)
+	// Compacting runs last, so lines emptied by the filter collapse as well
+	if IsWhitespaceCompactingEnabled(os.Getenv("SLACK_MCP_COMPACT_WHITESPACE")) {
+		s = CompactWhitespace(s)
+	}
 
 	return s
 }
 
+// filterOutsideCode runs filterSpecialChars on the text outside ``` code
+// blocks. Code blocks, fences included, are kept as is.
+func filterOutsideCode(s string) string {
+	var parts, prose []string
+	flush := func() {
+		if len(prose) == 0 {
+			return
+		}
+		if filtered := filterSpecialChars(strings.Join(prose, "\n")); filtered != "" {
+			parts = append(parts, filtered)
+		}
+		prose = nil
+	}
+
+	inCode := false
+	for _, line := range strings.Split(s, "\n") {
+		fence := strings.Count(line, "```")%2 == 1
+		switch {
+		case inCode:
+			parts = append(parts, line)
+			inCode = !fence
+		case fence:
+			flush()
+			parts = append(parts, line)
+			inCode = true
+		default:
+			prose = append(prose, line)
+		}
+	}
+	flush()
+	return strings.Join(parts, "\n")
+}
+
+// IsWhitespaceCompactingEnabled reports whether CompactWhitespace runs in
+// ProcessText. It is on unless opt disables it.
+func IsWhitespaceCompactingEnabled(opt string) bool {
+	opt = strings.ToLower(strings.TrimSpace(opt))
+	return opt != "no" && opt != "false" && opt != "0"
+}
+
+// CompactWhitespace trims trailing whitespace from every line and collapses
+// runs of blank lines into a single one. Lines inside ``` code blocks are
+// kept as is, since whitespace there may matter.
+func CompactWhitespace(s string) string {
+	lines := strings.Split(s, "\n")
+	out := make([]string, 0, len(lines))
+	inCode := false
+	blank := false
+	for _, line := range lines {
+		fences := strings.Count(line, "```")
+		if inCode {
+			out = append(out, line)
+			blank = false
+			if fences%2 == 1 {
+				inCode = false
+			}
+			continue
+		}
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			if blank {
+				continue
+			}
+			blank = true
+		} else {
+			blank = false
+		}
+		out = append(out, line)
+		if fences%2 == 1 {
+			inCode = true
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
 func HumanizeCertificates(certs []*x509.Certificate) string {
 	var descriptions []string
 	for _, cert := range certs {
@@ -192,6 +335,159 @@ func HumanizeCertificates(certs []*x509.Certificate) string {
 	return strings.Join(descriptions, ", ")
 }
 
+// csvHeaderRegex matches a header row of plain column names, as written by gocsv
+var csvHeaderRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(,[A-Za-z_][A-Za-z0-9_]*)+\r?\n`)
+
+// LooksLikeCSV reports whether s starts with a header row followed by a line
+// break, i.e. whether it can be truncated row by row
+func LooksLikeCSV(s string) bool {
+	return csvHeaderRegex.MatchString(s)
+}
+
+// TruncateRows cuts s to at most maxBytes, keeping only complete rows. The first
+// row is treated as a header and is kept when it fits; a header longer than
+// maxBytes is byte capped like any other text. The last row, which carries the
+// cursor of paginated tool results, is kept too whenever it fits next to the
+// header, so rows are then left out before it rather than at the end. Newlines
+// inside quoted CSV fields do not end a row. It returns the number of data rows
+// kept and left out, and whether s was truncated.
+func TruncateRows(s string, maxBytes int) (string, int, int, bool) {
+	if maxBytes <= 0 || len(s) <= maxBytes {
+		return s, 0, 0, false
+	}
+
+	var (
+		inQuotes bool
+		rowEnds  []int
+	)
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			inQuotes = !inQuotes
+		case '\n':
+			if !inQuotes {
+				rowEnds = append(rowEnds, i+1)
+			}
+		}
+	}
+	if len(rowEnds) > 0 && rowEnds[len(rowEnds)-1] < len(s) {
+		// a last row without a trailing line break
+		rowEnds = append(rowEnds, len(s))
+	}
+	if len(rowEnds) == 0 || rowEnds[0] > maxBytes {
+		truncated, _ := TruncateBytes(s, maxBytes)
+		return truncated, 0, max(len(rowEnds)-1, 0), true
+	}
+
+	// rowEnds[0] closes the header row, rowEnds[i] data row i
+	rows := len(rowEnds) - 1
+	if rows >= 2 {
+		last := s[rowEnds[rows-1]:]
+		if budget := maxBytes - len(last); rowEnds[0] <= budget {
+			kept := 0
+			for kept+1 < rows-1 && rowEnds[kept+1] <= budget {
+				kept++
+			}
+			return s[:rowEnds[kept]] + last, kept + 1, rows - kept - 1, true
+		}
+	}
+
+	kept := 0
+	for kept+1 < len(rowEnds) && rowEnds[kept+1] <= maxBytes {
+		kept++
+	}
+	return s[:rowEnds[kept]], kept, rows - kept, true
+}
+
+// TruncateJSONArray cuts a JSON array to at most maxBytes by dropping whole
+// elements from its end, so the result is still valid JSON. It returns the
+// number of elements kept and left out, and whether s was truncated; s is left
+// alone when it fits or is not a JSON array.
+func TruncateJSONArray(s string, maxBytes int) (string, int, int, bool) {
+	if maxBytes <= 0 || len(s) <= maxBytes {
+		return s, 0, 0, false
+	}
+	var elems []json.RawMessage
+	if err := json.Unmarshal([]byte(s), &elems); err != nil {
+		return s, 0, 0, false
+	}
+
+	var sb strings.Builder
+	sb.WriteByte('[')
+	kept := 0
+	for _, e := range elems {
+		size := len(e)
+		if kept > 0 {
+			size++
+		}
+		// +1 for the closing bracket
+		if sb.Len()+size+1 > maxBytes {
+			break
+		}
+		if kept > 0 {
+			sb.WriteByte(',')
+		}
+		sb.Write(e)
+		kept++
+	}
+	sb.WriteByte(']')
+	return sb.String(), kept, len(elems) - kept, true
+}
+
+// TruncateBytes cuts s to at most maxBytes without splitting a UTF-8 sequence.
+// It reports whether s was truncated.
+func TruncateBytes(s string, maxBytes int) (string, bool) {
+	if maxBytes <= 0 || len(s) <= maxBytes {
+		return s, false
+	}
+	end := maxBytes
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+	return s[:end], true
+}
+
+var (
+	// Slack-style links: <URL|Description>
+	slackLinkRegex = regexp.MustCompile(`<(https?://[^>|]+)\|([^>]+)>`)
+	// Markdown links: [Description](URL)
+	markdownLinkRegex = regexp.MustCompile(`\[([^\]]+)\]\((https?://[^)]+)\)`)
+	htmlLinkRegex     = regexp.MustCompile(`<a\s+href=["']([^"']+)["'][^>]*>([^<]+)</a>`)
+	urlRegex          = regexp.MustCompile(`https?://[^\s<>"{}|\\^` + "`" + `\[\]]+`)
+)
+
+// ExtractLinks returns the de-duplicated URLs found in text, in order of
+// appearance. It recognizes the same link forms as ProcessText: Slack links,
+// markdown links, HTML anchors and bare URLs.
+func ExtractLinks(text string) []string {
+	var links []string
+	seen := make(map[string]bool)
+	add := func(url string) {
+		if !seen[url] {
+			seen[url] = true
+			links = append(links, url)
+		}
+	}
+
+	for _, m := range slackLinkRegex.FindAllStringSubmatch(text, -1) {
+		add(m[1])
+	}
+	text = slackLinkRegex.ReplaceAllString(text, " ")
+	for _, m := range markdownLinkRegex.FindAllStringSubmatch(text, -1) {
+		add(m[2])
+	}
+	text = markdownLinkRegex.ReplaceAllString(text, " ")
+	for _, m := range htmlLinkRegex.FindAllStringSubmatch(text, -1) {
+		add(m[1])
+	}
+	text = htmlLinkRegex.ReplaceAllString(text, " ")
+	for _, url := range urlRegex.FindAllString(text, -1) {
+		add(url)
+	}
+
+	return links
+}
+
 func filterSpecialChars(text string) string {
 	replaceWithCommaCheck := func(match []string, isLast bool) string {
 		var url, linkText string
@@ -224,7 +520,6 @@ func filterSpecialChars(text string) string {
 	}
 
 	// Handle Slack-style links: <URL|Description>
-	slackLinkRegex := regexp.MustCompile(`<(https?://[^>|]+)\|([^>]+)>`)
 	slackMatches := slackLinkRegex.FindAllStringSubmatch(text, -1)
 	for _, match := range slackMatches {
 		original := match[0]
@@ -234,7 +529,6 @@ func filterSpecialChars(text string) string {
 	}
 
 	// Handle markdown links: [Description](URL)
-	markdownLinkRegex := regexp.MustCompile(`\[([^\]]+)\]\((https?://[^)]+)\)`)
 	markdownMatches := markdownLinkRegex.FindAllStringSubmatch(text, -1)
 	for _, match := range markdownMatches {
 		original := match[0]
@@ -243,7 +537,6 @@ func filterSpecialChars(text string) string {
 		text = strings.Replace(text, original, replacement, 1)
 	}
 
-	htmlLinkRegex := regexp.MustCompile(`<a\s+href=["']([^"']+)["'][^>]*>([^<]+)</a>`)
 	htmlMatches := htmlLinkRegex.FindAllStringSubmatch(text, -1)
 	for _, match := range htmlMatches {
 		original := match[0]
@@ -257,7 +550,6 @@ func filterSpecialChars(text string) string {
 		text = strings.Replace(text, original, replacement, 1)
 	}
 
-	urlRegex := regexp.MustCompile(`https?://[^\s<>"{}|\\^` + "`" + `\[\]]+`)
 	urls := urlRegex.FindAllString(text, -1)
 
 	protected := text
@@ -1,7 +1,10 @@
 package text
 
 import (
+	"encoding/json"
 	"testing"
+
+	"github.com/slack-go/slack"
 )
 
 func TestIsUnfurlingEnabled(t *testing.T) {
@@ -160,3 +163,288 @@ func TestFilterSpecialCharsWithCommas(t *testing.T) {
 		})
 	}
 }
+
+func TestTruncateRows(t *testing.T) {
+	csv := "ID,Text\n1,one\n2,\"two\nlines\"\n3,three\n"
+
+	tests := []struct {
+		name          string
+		maxBytes      int
+		want          string
+		wantRows      int
+		wantOmitted   int
+		wantTruncated bool
+	}{
+		{"unlimited", 0, csv, 0, 0, false},
+		{"fits", len(csv), csv, 0, 0, false},
+		{"keeps the last row", len(csv) - 1, "ID,Text\n1,one\n3,three\n", 2, 1, true},
+		{"does not split quoted newline", 21, "ID,Text\n3,three\n", 1, 2, true},
+		{"last row too large keeps the first rows", 15, "ID,Text\n1,one\n", 1, 2, true},
+		{"header only", 8, "ID,Text\n", 0, 3, true},
+		{"header longer than the limit is byte capped", 5, "ID,Te", 0, 3, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rows, omitted, truncated := TruncateRows(csv, tt.maxBytes)
+			if got != tt.want || rows != tt.wantRows || omitted != tt.wantOmitted || truncated != tt.wantTruncated {
+				t.Errorf("TruncateRows(%d) = (%q, %d, %d, %v), want (%q, %d, %d, %v)",
+					tt.maxBytes, got, rows, omitted, truncated, tt.want, tt.wantRows, tt.wantOmitted, tt.wantTruncated)
+			}
+		})
+	}
+}
+
+func TestTruncateRowsWithoutTrailingNewline(t *testing.T) {
+	got, rows, omitted, truncated := TruncateRows("ID,Cursor\n1,\n2,\n3,abc", 18)
+	if got != "ID,Cursor\n1,\n3,abc" || rows != 2 || omitted != 1 || !truncated {
+		t.Errorf("TruncateRows() = (%q, %d, %d, %v), want (%q, 2, 1, true)", got, rows, omitted, truncated, "ID,Cursor\n1,\n3,abc")
+	}
+}
+
+func TestTruncateRowsSingleLine(t *testing.T) {
+	got, rows, omitted, truncated := TruncateRows("a single line without a break", 8)
+	if got != "a single" || rows != 0 || omitted != 0 || !truncated {
+		t.Errorf("TruncateRows() = (%q, %d, %d, %v), want (%q, 0, 0, true)", got, rows, omitted, truncated, "a single")
+	}
+}
+
+func TestTruncateJSONArray(t *testing.T) {
+	input := `[{"id":1},{"id":2},{"id":3}]`
+
+	tests := []struct {
+		name          string
+		input         string
+		maxBytes      int
+		want          string
+		wantKept      int
+		wantOmitted   int
+		wantTruncated bool
+	}{
+		{"fits", input, len(input), input, 0, 0, false},
+		{"drops whole elements", input, len(input) - 1, `[{"id":1},{"id":2}]`, 2, 1, true},
+		{"keeps an empty array", input, 5, `[]`, 0, 3, true},
+		{"object is left alone", `{"groups":[1,2,3]}`, 5, `{"groups":[1,2,3]}`, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, kept, omitted, truncated := TruncateJSONArray(tt.input, tt.maxBytes)
+			if got != tt.want || kept != tt.wantKept || omitted != tt.wantOmitted || truncated != tt.wantTruncated {
+				t.Errorf("TruncateJSONArray(%d) = (%q, %d, %d, %v), want (%q, %d, %d, %v)",
+					tt.maxBytes, got, kept, omitted, truncated, tt.want, tt.wantKept, tt.wantOmitted, tt.wantTruncated)
+			}
+			if !json.Valid([]byte(got)) {
+				t.Errorf("TruncateJSONArray(%d) = %q is not valid JSON", tt.maxBytes, got)
+			}
+		})
+	}
+}
+
+func TestTruncateBytes(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		maxBytes      int
+		want          string
+		wantTruncated bool
+	}{
+		{"unlimited", "hello", 0, "hello", false},
+		{"fits", "hello", 5, "hello", false},
+		{"cut", "hello world", 5, "hello", true},
+		{"does not split a rune", "caf\u00e9!", 4, "caf", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, truncated := TruncateBytes(tt.input, tt.maxBytes)
+			if got != tt.want || truncated != tt.wantTruncated {
+				t.Errorf("TruncateBytes(%q, %d) = (%q, %v), want (%q, %v)",
+					tt.input, tt.maxBytes, got, truncated, tt.want, tt.wantTruncated)
+			}
+		})
+	}
+}
+
+func TestLooksLikeCSV(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"MsgID,UserID,Text\n1,U1,hi\n", true},
+		{"MsgID,Text\n", true},
+		{"MsgID,Text", false},
+		{"[2024-01-01] @alice: hi\n", false},
+		{"all good", false},
+		{"Hello, world\n", false},
+	}
+
+	for _, tt := range tests {
+		if got := LooksLikeCSV(tt.input); got != tt.want {
+			t.Errorf("LooksLikeCSV(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestExtractLinks(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"no links", "just text", nil},
+		{"slack link", "see <https://example.com/a|the doc> please", []string{"https://example.com/a"}},
+		{"slack bare link", "see <https://example.com/a>", []string{"https://example.com/a"}},
+		{"markdown link", "see [doc](https://example.com/b).", []string{"https://example.com/b"}},
+		{"html link", `see <a href="https://example.com/c">doc</a>`, []string{"https://example.com/c"}},
+		{"bare url", "see https://example.com/d?x=1 now", []string{"https://example.com/d?x=1"}},
+		{
+			"mixed and duplicated",
+			"<https://example.com/a|A> and https://example.com/b then <https://example.com/a|again>",
+			[]string{"https://example.com/a", "https://example.com/b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExtractLinks(tt.input)
+			if len(got) != len(tt.want) {
+				t.Fatalf("ExtractLinks(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("ExtractLinks(%q)[%d] = %q, want %q", tt.input, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBlockActionsToText(t *testing.T) {
+	raw := `[
+		{"type": "section", "text": {"type": "mrkdwn", "text": "Deploy to prod requested"}},
+		{"type": "actions", "elements": [
+			{"type": "button", "text": {"type": "plain_text", "text": "Approve"}, "value": "approve", "style": "primary"},
+			{"type": "button", "text": {"type": "plain_text", "text": "Deny"}, "value": "deny", "style": "danger"}
+		]}
+	]`
+	var blocks slack.Blocks
+	if err := json.Unmarshal([]byte(raw), &blocks); err != nil {
+		t.Fatalf("unmarshal blocks: %v", err)
+	}
+
+	if got, want := BlockActionsToText(blocks), "[Button: Approve] [Button: Deny]"; got != want {
+		t.Errorf("BlockActionsToText() = %q, want %q", got, want)
+	}
+
+	section := slack.Blocks{BlockSet: blocks.BlockSet[:1]}
+	if got := BlockActionsToText(section); got != "" {
+		t.Errorf("BlockActionsToText() without actions = %q, want empty", got)
+	}
+}
+
+func TestEscapeMentions(t *testing.T) {
+	const zwsp = "\u200b"
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"plain channel", "You said: @channel deploy now", "You said: @" + zwsp + "channel deploy now"},
+		{"plain here and everyone", "@here and @everyone", "@" + zwsp + "here and @" + zwsp + "everyone"},
+		{"slack broadcast", "<!channel> look", "@" + zwsp + "channel look"},
+		{"slack broadcast with label", "<!here|here> look", "@" + zwsp + "here look"},
+		{"user mention", "ping <@U123ABC>", "ping @" + zwsp + "U123ABC"},
+		{"user mention with name", "ping <@U123ABC|alice>", "ping @" + zwsp + "alice"},
+		{"user group", "<!subteam^S0123|@eng> please", "@" + zwsp + "eng please"},
+		{"email and words are kept", "mail bob@channel.io about @channels", "mail bob@channel.io about @channels"},
+		{"links are kept", "<https://example.com|docs>", "<https://example.com|docs>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EscapeMentions(tt.input); got != tt.want {
+				t.Errorf("EscapeMentions(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompactWhitespace(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "runs of blank lines collapse to one",
+			input: "first\n\n\n\n\nsecond\n\nthird",
+			want:  "first\n\nsecond\n\nthird",
+		},
+		{
+			name:  "trailing whitespace is trimmed and whitespace-only lines count as blank",
+			input: "line one   \n \t \n\t\nline two\t",
+			want:  "line one\n\nline two",
+		},
+		{
+			name:  "code blocks are kept as is",
+			input: "trace:\n```\nat main()   \n\n\n\n    at run()\n```\n\n\n\nafter   ",
+			want:  "trace:\n```\nat main()   \n\n\n\n    at run()\n```\n\nafter",
+		},
+		{
+			name:  "inline code does not open a block",
+			input: "use ```x``` here\n\n\n\ndone",
+			want:  "use ```x``` here\n\ndone",
+		},
+		{
+			name:  "unterminated code block runs to the end",
+			input: "```\na  \n\n\n\nb",
+			want:  "```\na  \n\n\n\nb",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CompactWhitespace(tt.input); got != tt.want {
+				t.Errorf("CompactWhitespace() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessTextFiltersBeforeCompacting(t *testing.T) {
+	tests := []struct {
+		name    string
+		compact string
+		input   string
+		want    string
+	}{
+		{"emptied lines collapse", "", "a\n\n***\n\n>>>\n\nb", "a\n\nb"},
+		{"code block keeps fences and indentation", "", "Run this:\n```\nif x {\n    y()\n}\n```\n*done*", "Run this:\n```\nif x {\n    y()\n}\n```\ndone"},
+		{"blank lines inside code are kept", "", "```\na\n\n\nb\n```", "```\na\n\n\nb\n```"},
+		{"unterminated block runs to the end", "", "see\n```\n<tag> & more", "see\n```\n<tag> & more"},
+		{"compaction disabled", "false", "a\n\n***\n\nb", "a\n\n\n\nb"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SLACK_MCP_COMPACT_WHITESPACE", tt.compact)
+			if got := ProcessText(tt.input); got != tt.want {
+				t.Errorf("ProcessText(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsWhitespaceCompactingEnabled(t *testing.T) {
+	for _, opt := range []string{"", "true", "1", "yes"} {
+		if !IsWhitespaceCompactingEnabled(opt) {
+			t.Errorf("IsWhitespaceCompactingEnabled(%q) = false, want true", opt)
+		}
+	}
+	for _, opt := range []string{"false", "0", "no", " FALSE "} {
+		if IsWhitespaceCompactingEnabled(opt) {
+			t.Errorf("IsWhitespaceCompactingEnabled(%q) = true, want false", opt)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package text
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TimeFormat controls how message times are rendered. The zero value renders
+// RFC3339 in UTC.
+type TimeFormat struct {
+	// Layout is a Go time layout, RFC3339 when empty.
+	Layout string
+	// Location is the time zone times are shown in, UTC when nil.
+	Location *time.Location
+}
+
+// ParseTimeFormat builds a TimeFormat from SLACK_MCP_DATE_FORMAT and
+// SLACK_MCP_TIMEZONE values. An unknown time zone is an error, in which case
+// the layout is kept and times are shown in UTC.
+func ParseTimeFormat(layout, zone string) (TimeFormat, error) {
+	f := TimeFormat{Layout: strings.TrimSpace(layout)}
+	if zone = strings.TrimSpace(zone); zone != "" {
+		loc, err := time.LoadLocation(zone)
+		if err != nil {
+			return f, fmt.Errorf("invalid time zone %q: %w", zone, err)
+		}
+		f.Location = loc
+	}
+	return f, nil
+}
+
+// Format renders t in the configured layout and time zone.
+func (f TimeFormat) Format(t time.Time) string {
+	layout := f.Layout
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	loc := f.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	return t.In(loc).Format(layout)
+}
+
+// FormatTimestamp renders a Slack ts such as 1700000000.000100.
+func (f TimeFormat) FormatTimestamp(slackTS string) (string, error) {
+	t, err := parseSlackTimestamp(slackTS)
+	if err != nil {
+		return "", err
+	}
+	return f.Format(t), nil
+}
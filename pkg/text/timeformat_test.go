@@ -0,0 +1,53 @@
+package text
+
+import (
+	"testing"
+)
+
+func TestTimeFormat(t *testing.T) {
+	const ts = "1704321000.000100" // 2024-01-03T22:30:00Z
+
+	tests := []struct {
+		name   string
+		layout string
+		zone   string
+		want   string
+	}{
+		{"default is RFC3339 in UTC", "", "", "2024-01-03T22:30:00Z"},
+		{"custom layout", "Jan 2, 2006 3:04 PM MST", "", "Jan 3, 2024 10:30 PM UTC"},
+		{"custom time zone", "", "America/Los_Angeles", "2024-01-03T14:30:00-08:00"},
+		{"custom layout and time zone", "Jan 2, 2006 3:04 PM MST", "America/Los_Angeles", "Jan 3, 2024 2:30 PM PST"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := ParseTimeFormat(tt.layout, tt.zone)
+			if err != nil {
+				t.Fatalf("ParseTimeFormat(%q, %q) error: %v", tt.layout, tt.zone, err)
+			}
+			got, err := f.FormatTimestamp(ts)
+			if err != nil {
+				t.Fatalf("FormatTimestamp(%q) error: %v", ts, err)
+			}
+			if got != tt.want {
+				t.Errorf("FormatTimestamp(%q) = %q, want %q", ts, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("unknown time zone", func(t *testing.T) {
+		f, err := ParseTimeFormat("Jan 2", "Mars/Olympus_Mons")
+		if err == nil {
+			t.Fatal("expected an error for an unknown time zone")
+		}
+		if f != (TimeFormat{Layout: "Jan 2"}) {
+			t.Errorf("expected the layout to be kept in UTC on error, got %+v", f)
+		}
+	})
+
+	t.Run("malformed ts", func(t *testing.T) {
+		if _, err := (TimeFormat{}).FormatTimestamp("not-a-ts"); err == nil {
+			t.Error("expected an error for a malformed ts")
+		}
+	})
+}